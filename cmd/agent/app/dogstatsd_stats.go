@@ -107,6 +107,15 @@ func requestDogstatsdStats() error {
 			fmt.Printf("Could not format the statistics, the data must be inconsistent. You may want to try the JSON output. Contact the support if you continue having issues.\n")
 			return nil
 		}
+
+		// The samples dropped by the drop rules and per-origin limits are reported by a separate endpoint,
+		// agents without it only report the metrics stats.
+		urlstr = fmt.Sprintf("https://%v:%v/agent/dogstatsd-drop-stats", ipcAddress, config.Datadog.GetInt("cmd_port"))
+		if r, e := util.DoGet(c, urlstr); e == nil {
+			if dropStats, e := dogstatsd.FormatDropStats(r); e == nil && dropStats != "" {
+				s += "\n\n" + dropStats
+			}
+		}
 	}
 
 	if dsdStatsFilePath == "" {
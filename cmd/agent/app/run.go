@@ -56,6 +56,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/net"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/nvidia/jetson"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/systemd"
 
@@ -241,6 +242,9 @@ func StartAgent() error {
 	if config.Datadog.GetBool("telemetry.enabled") {
 		http.Handle("/telemetry", telemetry.Handler())
 	}
+	if config.Datadog.GetBool("prometheus_endpoint.enabled") {
+		http.Handle("/metrics", aggregator.PrometheusHandler())
+	}
 	go func() {
 		err := http.ListenAndServe("127.0.0.1:"+port, http.DefaultServeMux)
 		if err != nil && err != http.ErrServerClosed {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -213,6 +214,7 @@ type BufferedAggregator struct {
 	stopChan           chan struct{}
 	health             *health.Handle
 	agentName          string // Name of the agent for telemetry metrics
+	lastFlush          lastFlush
 
 	tlmContainerTagsEnabled bool                                              // Whether we should call the tagger to tag agent telemetry metrics
 	agentTags               func(collectors.TagCardinality) ([]string, error) // This function gets the agent tags from the tagger (defined as a struct field to ease testing)
@@ -260,10 +262,37 @@ func NewBufferedAggregator(s serializer.MetricSerializer, hostname string, flush
 		tlmContainerTagsEnabled: config.Datadog.GetBool("basic_telemetry_add_container_tags"),
 		agentTags:               tagger.AgentTags,
 	}
+	aggregator.statsdSampler.contextResolver.dropRules = newDogstatsdDropRules()
 
 	return aggregator
 }
 
+// newDogstatsdDropRules returns the rules dropping tags and samples from DogStatsD metrics, or nil if none is configured
+func newDogstatsdDropRules() *mapper.DropRuleSet {
+	rules, err := config.GetDogstatsdDropRules()
+	if err != nil {
+		log.Warnf("Could not parse drop rules: %v", err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	dropRules, err := mapper.NewDropRuleSet(rules, config.Datadog.GetInt("dogstatsd_mapper_cache_size"))
+	if err != nil {
+		log.Warnf("Could not create drop rules: %v", err)
+		return nil
+	}
+	return dropRules
+}
+
+// GetDogstatsdDropRuleStats returns the number of DogStatsD samples and tags dropped by each drop rule
+func (agg *BufferedAggregator) GetDogstatsdDropRuleStats() []mapper.DropRuleStats {
+	if dropRules := agg.statsdSampler.contextResolver.dropRules; dropRules != nil {
+		return dropRules.Stats()
+	}
+	return nil
+}
+
 // AddRecurrentSeries adds a serie to the series that are sent at every flush
 func AddRecurrentSeries(newSerie *metrics.Serie) {
 	recurrentSeriesLock.Lock()
@@ -526,6 +555,7 @@ func (agg *BufferedAggregator) sendSketches(start time.Time, sketches metrics.Sk
 
 func (agg *BufferedAggregator) flushSeriesAndSketches(start time.Time, waitForSerializer bool) {
 	series, sketches := agg.GetSeriesAndSketches(start)
+	agg.lastFlush.store(series, sketches, config.Datadog.GetBool("prometheus_endpoint.enabled"))
 
 	agg.sendSketches(start, sketches, waitForSerializer)
 	agg.sendSeries(start, series, waitForSerializer)
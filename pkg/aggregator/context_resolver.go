@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -24,6 +25,9 @@ type ContextResolver struct {
 	contextsByKey map[ckey.ContextKey]*Context
 	lastSeenByKey map[ckey.ContextKey]float64
 	keyGenerator  *ckey.KeyGenerator
+	// dropRules drop tags, or whole samples, before contexts are created. It is only set
+	// for DogStatsD samples, and nil otherwise.
+	dropRules *mapper.DropRuleSet
 	// buffer slice allocated once per ContextResolver to combine and sort
 	// tags, origin detection tags and k8s tags.
 	tagsSliceBuffer []string
@@ -43,9 +47,19 @@ func newContextResolver() *ContextResolver {
 	}
 }
 
-// trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) ckey.ContextKey {
+// trackContext returns the contextKey associated with the context of the metricSample and tracks that context.
+// It returns false if the sample is dropped by the drop rules, in which case no context is tracked.
+func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) (ckey.ContextKey, bool) {
 	cr.tagsSliceBuffer = metricSampleContext.GetTags(cr.tagsSliceBuffer)
+	if cr.dropRules != nil {
+		// The rules see the tags added by origin detection, and drop tags from the buffer in place.
+		var keep bool
+		cr.tagsSliceBuffer, keep = cr.dropRules.Apply(metricSampleContext.GetName(), cr.tagsSliceBuffer, 1)
+		if !keep {
+			cr.tagsSliceBuffer = cr.tagsSliceBuffer[0:0] // reset tags buffer
+			return 0, false
+		}
+	}
 	contextKey := cr.generateContextKey(metricSampleContext, cr.tagsSliceBuffer)
 
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
@@ -63,7 +77,7 @@ func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSample
 	cr.lastSeenByKey[contextKey] = currentTimestamp
 
 	cr.tagsSliceBuffer = cr.tagsSliceBuffer[0:0] // reset tags buffer
-	return contextKey
+	return contextKey, true
 }
 
 // updateTrackedContext updates the last seen timestamp on a given context key
@@ -12,11 +12,25 @@ import (
 
 	// 3p
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
+// originSample is a sample whose tags are enriched with fixed tags, as origin detection would do
+type originSample struct {
+	metrics.MetricSample
+	originTags []string
+}
+
+func (s *originSample) GetTags(tagsBuffer []string) []string {
+	tagsBuffer = s.MetricSample.GetTags(tagsBuffer)
+	return append(tagsBuffer, s.originTags...)
+}
+
 func TestGenerateContextKey(t *testing.T) {
 	mSample := metrics.MetricSample{
 		Name:       "my.metric.name",
@@ -70,9 +84,9 @@ func TestTrackContext(t *testing.T) {
 	contextResolver := newContextResolver()
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 1)
-	contextKey2 := contextResolver.trackContext(&mSample2, 1)
-	contextKey3 := contextResolver.trackContext(&mSample3, 1)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 1)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 1)
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 1)
 
 	// When we look up the 2 keys, they return the correct contexts
 	context1 := contextResolver.contextsByKey[contextKey1]
@@ -110,8 +124,8 @@ func TestExpireContexts(t *testing.T) {
 	contextResolver := newContextResolver()
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 4)
-	contextKey2 := contextResolver.trackContext(&mSample2, 6)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 4)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 6)
 
 	// With an expireTimestap of 3, both contexts are still valid
 	assert.Len(t, contextResolver.expireContexts(3), 0)
@@ -132,3 +146,44 @@ func TestExpireContexts(t *testing.T) {
 	_, ok = contextResolver.contextsByKey[contextKey2]
 	assert.True(t, ok)
 }
+
+func TestTrackContextDropRules(t *testing.T) {
+	dropRules, err := mapper.NewDropRuleSet([]config.DropRule{
+		{Name: "no_pod_name", Match: "my.metric.*", DropTags: []string{"pod_name"}},
+		{Name: "no_dev", MatchTags: []string{"kube_namespace:dev"}, DropMetric: true},
+	}, 10)
+	require.NoError(t, err)
+	contextResolver := newContextResolver()
+	contextResolver.dropRules = dropRules
+
+	sample1 := originSample{
+		MetricSample: metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo"}},
+		originTags:   []string{"pod_name:pod-1", "kube_namespace:prod"},
+	}
+	sample2 := originSample{
+		MetricSample: metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo"}},
+		originTags:   []string{"pod_name:pod-2", "kube_namespace:prod"},
+	}
+	sample3 := originSample{
+		MetricSample: metrics.MetricSample{Name: "my.metric.name", Tags: []string{"foo"}},
+		originTags:   []string{"pod_name:pod-3", "kube_namespace:dev"},
+	}
+
+	// The tags added by origin detection are dropped, so both samples share the same context
+	contextKey1, ok := contextResolver.trackContext(&sample1, 1)
+	assert.True(t, ok)
+	contextKey2, ok := contextResolver.trackContext(&sample2, 1)
+	assert.True(t, ok)
+	assert.Equal(t, contextKey1, contextKey2)
+	assert.Equal(t, []string{"foo", "kube_namespace:prod"}, contextResolver.contextsByKey[contextKey1].Tags)
+
+	// Samples matching the tags added by origin detection are dropped without creating a context
+	_, ok = contextResolver.trackContext(&sample3, 1)
+	assert.False(t, ok)
+	assert.Len(t, contextResolver.contextsByKey, 1)
+
+	assert.Equal(t, []mapper.DropRuleStats{
+		{Name: "no_pod_name", SamplesMatched: 3, TagsDropped: 3},
+		{Name: "no_dev", SamplesMatched: 1, SamplesDropped: 1},
+	}, dropRules.Stats())
+}
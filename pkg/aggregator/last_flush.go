@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+// SketchSummary is the summary of a flushed sketch series: the quantiles, count
+// and sum of all its points.
+type SketchSummary struct {
+	Name     string   `json:"metric"`
+	Tags     []string `json:"tags"`
+	Host     string   `json:"host"`
+	Interval int64    `json:"interval"`
+	Ts       int64    `json:"ts"`
+	Count    int64    `json:"count"`
+	Sum      float64  `json:"sum"`
+	Min      float64  `json:"min"`
+	Max      float64  `json:"max"`
+	P50      float64  `json:"p50"`
+	P95      float64  `json:"p95"`
+	P99      float64  `json:"p99"`
+}
+
+// flushedSerie is a copy of the last point of a flushed serie. Series are copied
+// as the serializer modifies their tags while sending them.
+type flushedSerie struct {
+	name  string
+	tags  []string
+	host  string
+	mType metrics.APIMetricType
+	value float64
+}
+
+// lastFlush holds the series and sketches of the latest flush
+type lastFlush struct {
+	m        sync.RWMutex
+	series   []flushedSerie
+	sketches metrics.SketchSeriesList
+}
+
+// store replaces the content of the last flush. Series are only kept if keepSeries is true.
+func (l *lastFlush) store(series metrics.Series, sketches metrics.SketchSeriesList, keepSeries bool) {
+	var flushedSeries []flushedSerie
+	if keepSeries {
+		flushedSeries = make([]flushedSerie, 0, len(series))
+		for _, serie := range series {
+			if len(serie.Points) == 0 {
+				continue
+			}
+			flushedSeries = append(flushedSeries, flushedSerie{
+				name:  serie.Name,
+				tags:  append([]string(nil), serie.Tags...),
+				host:  serie.Host,
+				mType: serie.MType,
+				value: serie.Points[len(serie.Points)-1].Value,
+			})
+		}
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.series = flushedSeries
+	l.sketches = sketches
+}
+
+func (l *lastFlush) getSeries() []flushedSerie {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	return l.series
+}
+
+// getSketchSummaries computes the summaries of the flushed sketches
+func (l *lastFlush) getSketchSummaries() []SketchSummary {
+	l.m.RLock()
+	sketches := l.sketches
+	l.m.RUnlock()
+
+	summaries := make([]SketchSummary, 0, len(sketches))
+	for _, ss := range sketches {
+		if summary, ok := summarizeSketchSeries(ss); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+// summarizeSketchSeries merges the points of a sketch series, and returns
+// their quantiles. It returns false if the series has no sketch.
+func summarizeSketchSeries(ss metrics.SketchSeries) (SketchSummary, bool) {
+	c := quantile.Default()
+	merged := &quantile.Sketch{}
+	var ts int64
+	found := false
+	for _, point := range ss.Points {
+		if point.Sketch == nil {
+			continue
+		}
+		merged.Merge(c, point.Sketch)
+		if point.Ts > ts {
+			ts = point.Ts
+		}
+		found = true
+	}
+	if !found {
+		return SketchSummary{}, false
+	}
+
+	return SketchSummary{
+		Name:     ss.Name,
+		Tags:     ss.Tags,
+		Host:     ss.Host,
+		Interval: ss.Interval,
+		Ts:       ts,
+		Count:    merged.Basic.Cnt,
+		Sum:      merged.Basic.Sum,
+		Min:      merged.Basic.Min,
+		Max:      merged.Basic.Max,
+		P50:      merged.Quantile(c, 0.5),
+		P95:      merged.Quantile(c, 0.95),
+		P99:      merged.Quantile(c, 0.99),
+	}, true
+}
+
+// GetLastFlushedSketches returns the summaries of the sketches flushed by the
+// default aggregator during its latest flush.
+func GetLastFlushedSketches() []SketchSummary {
+	if aggregatorInstance == nil {
+		return []SketchSummary{}
+	}
+	return aggregatorInstance.lastFlush.getSketchSummaries()
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+func newTestSketch(values ...float64) *quantile.Sketch {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), values...)
+	return sketch
+}
+
+func TestLastFlushSketchSummaries(t *testing.T) {
+	var values []float64
+	for i := 1; i <= 100; i++ {
+		values = append(values, float64(i))
+	}
+
+	var l lastFlush
+	l.store(nil, metrics.SketchSeriesList{
+		{
+			Name:     "my.distribution",
+			Tags:     []string{"env:prod"},
+			Host:     "myhost",
+			Interval: 10,
+			Points: []metrics.SketchPoint{
+				{Sketch: newTestSketch(values[:50]...), Ts: 10},
+				{Sketch: newTestSketch(values[50:]...), Ts: 20},
+			},
+		},
+		{
+			Name:   "empty.distribution",
+			Points: []metrics.SketchPoint{{Ts: 10}},
+		},
+	}, false)
+
+	summaries := l.getSketchSummaries()
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
+	assert.Equal(t, "my.distribution", summary.Name)
+	assert.Equal(t, []string{"env:prod"}, summary.Tags)
+	assert.Equal(t, "myhost", summary.Host)
+	assert.Equal(t, int64(10), summary.Interval)
+	assert.Equal(t, int64(20), summary.Ts)
+	assert.Equal(t, int64(100), summary.Count)
+	assert.Equal(t, float64(5050), summary.Sum)
+	assert.Equal(t, float64(1), summary.Min)
+	assert.Equal(t, float64(100), summary.Max)
+	// quantiles are approximated
+	assert.InEpsilon(t, 50, summary.P50, 0.05)
+	assert.InEpsilon(t, 95, summary.P95, 0.05)
+	assert.InEpsilon(t, 99, summary.P99, 0.05)
+
+	assert.Nil(t, l.getSeries())
+}
+
+func TestLastFlushSeries(t *testing.T) {
+	serie := &metrics.Serie{
+		Name:   "my.gauge",
+		Tags:   []string{"env:prod"},
+		Host:   "myhost",
+		MType:  metrics.APIGaugeType,
+		Points: []metrics.Point{{Ts: 10, Value: 1}, {Ts: 20, Value: 2}},
+	}
+
+	var l lastFlush
+	l.store(metrics.Series{serie, {Name: "no.points"}}, nil, true)
+
+	// series are copied, as they are modified by the serializer
+	serie.Tags[0] = "device:sda"
+	assert.Equal(t, []flushedSerie{
+		{name: "my.gauge", tags: []string{"env:prod"}, host: "myhost", mType: metrics.APIGaugeType, value: 2},
+	}, l.getSeries())
+	assert.Empty(t, l.getSketchSummaries())
+}
@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// PrometheusHandler returns an http handler rendering the series and the sketch
+// quantiles of the latest flush of the default aggregator in the Prometheus text format.
+func PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if aggregatorInstance == nil {
+			return
+		}
+		w.Write(renderPrometheusMetrics(aggregatorInstance.lastFlush.getSeries(), aggregatorInstance.lastFlush.getSketchSummaries()))
+	})
+}
+
+// renderPrometheusMetrics renders series as gauges or untyped metrics, and sketches
+// as summaries. The tags become labels.
+func renderPrometheusMetrics(series []flushedSerie, sketches []SketchSummary) []byte {
+	var bw bytes.Buffer
+
+	// samples of a metric must be grouped, sort them by their sanitized name
+	seriesNames := make([]string, len(series))
+	seriesOrder := make([]int, len(series))
+	for i := range series {
+		seriesNames[i] = prometheusName(series[i].name)
+		seriesOrder[i] = i
+	}
+	sort.SliceStable(seriesOrder, func(i, j int) bool { return seriesNames[seriesOrder[i]] < seriesNames[seriesOrder[j]] })
+
+	previous := ""
+	for _, i := range seriesOrder {
+		name := seriesNames[i]
+		if name != previous {
+			bw.WriteString("# TYPE " + name + " " + prometheusType(series[i].mType) + "\n")
+			previous = name
+		}
+		writePrometheusSample(&bw, name, prometheusLabels(series[i].tags, series[i].host), "", series[i].value)
+	}
+
+	sketchesNames := make([]string, len(sketches))
+	sketchesOrder := make([]int, len(sketches))
+	for i := range sketches {
+		sketchesNames[i] = prometheusName(sketches[i].Name)
+		sketchesOrder[i] = i
+	}
+	sort.SliceStable(sketchesOrder, func(i, j int) bool { return sketchesNames[sketchesOrder[i]] < sketchesNames[sketchesOrder[j]] })
+
+	previous = ""
+	for _, i := range sketchesOrder {
+		name, sketch := sketchesNames[i], sketches[i]
+		if name != previous {
+			bw.WriteString("# TYPE " + name + " summary\n")
+			previous = name
+		}
+		labels := prometheusLabels(sketch.Tags, sketch.Host)
+		writePrometheusSample(&bw, name, labels, `quantile="0.5"`, sketch.P50)
+		writePrometheusSample(&bw, name, labels, `quantile="0.95"`, sketch.P95)
+		writePrometheusSample(&bw, name, labels, `quantile="0.99"`, sketch.P99)
+		writePrometheusSample(&bw, name+"_sum", labels, "", sketch.Sum)
+		writePrometheusSample(&bw, name+"_count", labels, "", float64(sketch.Count))
+	}
+
+	return bw.Bytes()
+}
+
+func writePrometheusSample(w *bytes.Buffer, name string, labels string, extraLabel string, value float64) {
+	w.WriteString(name)
+	if labels != "" || extraLabel != "" {
+		w.WriteByte('{')
+		w.WriteString(labels)
+		if labels != "" && extraLabel != "" {
+			w.WriteByte(',')
+		}
+		w.WriteString(extraLabel)
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.WriteByte('\n')
+}
+
+// prometheusType returns the Prometheus type of a serie. Counts and rates are
+// computed over the flush interval, so only gauges keep their type.
+func prometheusType(mType metrics.APIMetricType) string {
+	if mType == metrics.APIGaugeType {
+		return "gauge"
+	}
+	return "untyped"
+}
+
+// prometheusLabels converts tags to labels, sorted by name. Tags without value get
+// the "true" value, and the values of tags sharing the same name are joined with commas.
+func prometheusLabels(tags []string, host string) string {
+	values := make(map[string][]string, len(tags)+1)
+	for _, tag := range tags {
+		name, value := tag, "true"
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			name, value = tag[:i], tag[i+1:]
+		}
+		name = prometheusLabelName(name)
+		values[name] = append(values[name], value)
+	}
+	if _, found := values["host"]; !found && host != "" {
+		values["host"] = []string{host}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(prometheusLabelValue(strings.Join(values[name], ",")))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// prometheusName replaces the characters not allowed in metric names, such as dots, by underscores
+func prometheusName(name string) string {
+	return sanitizePrometheusName(name, true)
+}
+
+// prometheusLabelName replaces the characters not allowed in label names by underscores
+func prometheusLabelName(name string) string {
+	return sanitizePrometheusName(name, false)
+}
+
+func sanitizePrometheusName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	if name[0] >= '0' && name[0] <= '9' {
+		b.WriteByte('_')
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || (allowColon && c == ':') {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func prometheusLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestRenderPrometheusMetrics(t *testing.T) {
+	series := []flushedSerie{
+		{name: "my.rate", tags: []string{"env:prod"}, host: "myhost", mType: metrics.APIRateType, value: 0.5},
+		{name: "my.gauge", tags: []string{"env:prod", "role:db", "role:cache"}, host: "myhost", mType: metrics.APIGaugeType, value: 1},
+		{name: "my.gauge", tags: []string{"env:dev", "canary", "host:other"}, host: "myhost", mType: metrics.APIGaugeType, value: 2},
+		{name: "9lives", tags: []string{`path:C:\dir "quoted"`}, mType: metrics.APIGaugeType, value: 3},
+	}
+	sketches := []SketchSummary{
+		{Name: "my.distribution", Tags: []string{"env:prod"}, Count: 3, Sum: 6.5, P50: 2, P95: 3, P99: 3.5},
+	}
+
+	expected := `# TYPE _9lives gauge
+_9lives{path="C:\\dir \"quoted\""} 3
+# TYPE my_gauge gauge
+my_gauge{env="prod",host="myhost",role="db,cache"} 1
+my_gauge{canary="true",env="dev",host="other"} 2
+# TYPE my_rate untyped
+my_rate{env="prod",host="myhost"} 0.5
+# TYPE my_distribution summary
+my_distribution{env="prod",quantile="0.5"} 2
+my_distribution{env="prod",quantile="0.95"} 3
+my_distribution{env="prod",quantile="0.99"} 3.5
+my_distribution_sum{env="prod"} 6.5
+my_distribution_count{env="prod"} 3
+`
+	assert.Equal(t, expected, string(renderPrometheusMetrics(series, sketches)))
+}
+
+func TestPrometheusNames(t *testing.T) {
+	assert.Equal(t, "a_b:c_d", prometheusName("a.b:c-d"))
+	assert.Equal(t, "a_b_c_d", prometheusLabelName("a.b:c-d"))
+	assert.Equal(t, "_1a", prometheusName("1a"))
+	assert.Equal(t, "_", prometheusLabelName(""))
+}
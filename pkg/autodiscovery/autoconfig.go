@@ -6,6 +6,7 @@
 package autodiscovery
 
 import (
+	"bytes"
 	"expvar"
 	"fmt"
 	"sync"
@@ -16,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -33,7 +35,26 @@ var (
 	errorStats            = newAcErrorStats()
 )
 
-var secretsDecrypt = secrets.Decrypt
+var (
+	secretsDecrypt          = secrets.Decrypt
+	secretsDecryptUntrusted = secrets.DecryptUntrusted
+	secretsRefresh          = secrets.Refresh
+)
+
+// containerConfigProviders are the providers of the configs written along the monitored
+// workloads, in container labels or pod and service annotations. The built-in secret
+// backends aren't used for their configs unless explicitly allowed, as they would let
+// anyone able to label a container read the secrets of the agent.
+var containerConfigProviders = map[string]bool{
+	names.CloudFoundryBBS:    true,
+	names.Docker:             true,
+	names.ECS:                true,
+	names.Kubernetes:         true,
+	names.KubeServices:       true,
+	names.KubeEndpoints:      true,
+	names.PrometheusPods:     true,
+	names.PrometheusServices: true,
+}
 
 func init() {
 	acErrors = expvar.NewMap("autoconfig")
@@ -67,7 +88,9 @@ type AutoConfig struct {
 	newService         chan listeners.Service
 	delService         chan listeners.Service
 	store              *store
-	m                  sync.RWMutex
+	// secretRefreshInterval is the interval at which the secrets are fetched again, 0 disables it
+	secretRefreshInterval time.Duration
+	m                     sync.RWMutex
 	// ranOnce is an atomic uint32 set to 1 once the AutoConfig has been executed
 	ranOnce uint32
 }
@@ -84,6 +107,8 @@ func NewAutoConfig(scheduler *scheduler.MetaScheduler) *AutoConfig {
 		delService:         make(chan listeners.Service),
 		store:              newStore(),
 		scheduler:          scheduler,
+
+		secretRefreshInterval: time.Duration(config.Datadog.GetInt("secret_refresh_interval")) * time.Second,
 	}
 	// We need to listen to the service channels before anything is sent to them
 	go ac.serviceListening()
@@ -97,6 +122,14 @@ func (ac *AutoConfig) serviceListening() {
 	tagFreshnessTicker := time.NewTicker(15 * time.Second) // we can miss tags for one run
 	defer tagFreshnessTicker.Stop()
 
+	// the configs using secrets are rescheduled from this goroutine, as services
+	var secretRefreshC <-chan time.Time
+	if ac.secretRefreshInterval > 0 {
+		secretRefreshTicker := time.NewTicker(ac.secretRefreshInterval)
+		defer secretRefreshTicker.Stop()
+		secretRefreshC = secretRefreshTicker.C
+	}
+
 	for {
 		select {
 		case <-ac.listenerStop:
@@ -109,6 +142,8 @@ func (ac *AutoConfig) serviceListening() {
 			ac.processDelService(svc)
 		case <-tagFreshnessTicker.C:
 			ac.checkTagFreshness()
+		case <-secretRefreshC:
+			ac.refreshSecrets()
 		}
 	}
 }
@@ -134,6 +169,53 @@ func (ac *AutoConfig) checkTagFreshness() {
 	}
 }
 
+// refreshSecrets fetches the secrets again, and reschedules the configs using the
+// secrets whose value changed. Configs resolved from a template are rescheduled
+// by processing their service again.
+func (ac *AutoConfig) refreshSecrets() {
+	handles, err := secretsRefresh()
+	if err != nil {
+		log.Warnf("Unable to refresh secrets: %s", err)
+	}
+	if len(handles) == 0 {
+		return
+	}
+
+	var servicesToRefresh []listeners.Service
+	seenServices := map[string]bool{}
+	for _, c := range ac.store.getSecretConfigs() {
+		if !usesSecretHandles(c.encrypted, handles) {
+			continue
+		}
+
+		// configs resolved for a service are rescheduled with the other configs of the service
+		if svc := ac.store.getServiceForEntity(c.decrypted.Entity); c.decrypted.Entity != "" && svc != nil {
+			if !seenServices[c.decrypted.Entity] {
+				seenServices[c.decrypted.Entity] = true
+				servicesToRefresh = append(servicesToRefresh, svc)
+			}
+			continue
+		}
+
+		log.Infof("Secrets changed for config %s, rescheduling it", c.decrypted.Name)
+		ac.processRemovedConfigs([]integration.Config{c.decrypted})
+		config, err := decryptConfig(copyInstances(c.encrypted))
+		if err != nil {
+			log.Errorf("Dropping conf for '%s': %s", c.encrypted.Name, err)
+			continue
+		}
+		ac.store.setLoadedConfig(config)
+		ac.store.setSecretConfig(config, c.encrypted)
+		ac.schedule([]integration.Config{config})
+	}
+
+	for _, service := range servicesToRefresh {
+		log.Infof("Secrets changed for service %s, rescheduling associated checks", service.GetEntity())
+		ac.processDelService(service)
+		ac.processNewService(service)
+	}
+}
+
 // Stop just shuts down AutoConfig in a clean way.
 // AutoConfig is not supposed to be restarted, so this is expected
 // to be called only once at program exit.
@@ -300,6 +382,7 @@ func (ac *AutoConfig) processNewConfig(config integration.Config) []integration.
 	}
 
 	// decrypt and store non-template config in AC as well
+	encrypted := copyInstances(config)
 	config, err := decryptConfig(config)
 	if err != nil {
 		log.Errorf("Dropping conf for '%s': %s", config.Name, err.Error())
@@ -308,6 +391,9 @@ func (ac *AutoConfig) processNewConfig(config integration.Config) []integration.
 	configs = append(configs, config)
 
 	ac.store.setLoadedConfig(config)
+	if hasSecrets(encrypted) {
+		ac.store.setSecretConfig(config, encrypted)
+	}
 
 	return configs
 }
@@ -424,28 +510,33 @@ func (ac *AutoConfig) RemoveScheduler(name string) {
 func decryptConfig(conf integration.Config) (integration.Config, error) {
 	var err error
 
+	decrypt := secretsDecrypt
+	if containerConfigProviders[conf.Provider] && !config.Datadog.GetBool("secret_backend_builtin_backends_allow_container_configs") {
+		decrypt = secretsDecryptUntrusted
+	}
+
 	// init_config
-	conf.InitConfig, err = secretsDecrypt(conf.InitConfig, conf.Name)
+	conf.InitConfig, err = decrypt(conf.InitConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets in 'init_config': %s", err)
 	}
 
 	// instances
 	for idx := range conf.Instances {
-		conf.Instances[idx], err = secretsDecrypt(conf.Instances[idx], conf.Name)
+		conf.Instances[idx], err = decrypt(conf.Instances[idx], conf.Name)
 		if err != nil {
 			return conf, fmt.Errorf("error while decrypting secrets in an instance: %s", err)
 		}
 	}
 
 	// metrics
-	conf.MetricConfig, err = secretsDecrypt(conf.MetricConfig, conf.Name)
+	conf.MetricConfig, err = decrypt(conf.MetricConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets in 'metrics': %s", err)
 	}
 
 	// logs
-	conf.LogsConfig, err = secretsDecrypt(conf.LogsConfig, conf.Name)
+	conf.LogsConfig, err = decrypt(conf.LogsConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets 'logs': %s", err)
 	}
@@ -453,10 +544,41 @@ func decryptConfig(conf integration.Config) (integration.Config, error) {
 	return conf, nil
 }
 
+// copyInstances returns the config with a copy of its instances, as decryptConfig
+// replaces them in place
+func copyInstances(conf integration.Config) integration.Config {
+	conf.Instances = append([]integration.Data(nil), conf.Instances...)
+	return conf
+}
+
+// hasSecrets returns true if a config contains secret handles
+func hasSecrets(conf integration.Config) bool {
+	return usesSecretHandles(conf, nil)
+}
+
+// usesSecretHandles returns true if a config contains one of the given secret
+// handles, or any handle if handles is empty
+func usesSecretHandles(conf integration.Config, handles []string) bool {
+	data := []integration.Data{conf.InitConfig, conf.MetricConfig, conf.LogsConfig}
+	data = append(data, conf.Instances...)
+	for _, d := range data {
+		if len(handles) == 0 && bytes.Contains(d, []byte("ENC[")) {
+			return true
+		}
+		for _, handle := range handles {
+			if bytes.Contains(d, []byte("ENC["+handle+"]")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (ac *AutoConfig) processRemovedConfigs(configs []integration.Config) {
 	ac.unschedule(configs)
 	for _, c := range configs {
 		ac.store.removeLoadedConfig(c)
+		ac.store.removeSecretConfig(c)
 	}
 }
 
@@ -536,12 +658,16 @@ func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc list
 		errorStats.setResolveWarning(tpl.Name, newErr.Error())
 		return tpl, log.Warn(newErr)
 	}
+	encrypted := copyInstances(config)
 	resolvedConfig, err := decryptConfig(config)
 	if err != nil {
 		newErr := fmt.Errorf("error decrypting secrets in config %s for service %s: %v", config.Name, svc.GetEntity(), err)
 		return config, log.Warn(newErr)
 	}
 	ac.store.setLoadedConfig(resolvedConfig)
+	if hasSecrets(encrypted) {
+		ac.store.setSecretConfig(resolvedConfig, encrypted)
+	}
 	ac.store.addConfigForService(svc.GetEntity(), resolvedConfig)
 	ac.store.addConfigForTemplate(tpl.Digest(), resolvedConfig)
 	ac.store.setTagsHashForService(
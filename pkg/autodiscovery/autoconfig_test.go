@@ -482,3 +482,134 @@ func TestSecretDecrypt(t *testing.T) {
 
 	assert.True(t, mockDecrypt.haveAllScenariosBeenCalled())
 }
+
+func TestDecryptConfigFromContainer(t *testing.T) {
+	originalSecretsDecrypt, originalSecretsDecryptUntrusted := secretsDecrypt, secretsDecryptUntrusted
+	defer func() { secretsDecrypt, secretsDecryptUntrusted = originalSecretsDecrypt, originalSecretsDecryptUntrusted }()
+	var calls []string
+	secretsDecrypt = func(data []byte, origin string) ([]byte, error) {
+		calls = append(calls, "trusted")
+		return data, nil
+	}
+	secretsDecryptUntrusted = func(data []byte, origin string) ([]byte, error) {
+		calls = append(calls, "untrusted")
+		return data, nil
+	}
+
+	for _, tc := range []struct {
+		provider        string
+		allowContainers bool
+		expected        string
+	}{
+		{names.File, false, "trusted"},
+		{names.Docker, false, "untrusted"},
+		{names.Kubernetes, false, "untrusted"},
+		{names.KubeServices, false, "untrusted"},
+		{names.Kubernetes, true, "trusted"},
+	} {
+		t.Run(fmt.Sprintf("%s allowed=%v", tc.provider, tc.allowContainers), func(t *testing.T) {
+			config.Datadog.Set("secret_backend_builtin_backends_allow_container_configs", tc.allowContainers)
+			defer config.Datadog.Set("secret_backend_builtin_backends_allow_container_configs", false)
+			calls = nil
+
+			_, err := decryptConfig(integration.Config{
+				Name:      "redis",
+				Provider:  tc.provider,
+				Instances: []integration.Data{integration.Data("password: ENC[foo]")},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{tc.expected, tc.expected, tc.expected, tc.expected}, calls)
+		})
+	}
+}
+
+// recordingScheduler records the instances of the scheduled and unscheduled checks
+type recordingScheduler struct {
+	scheduled   []string
+	unscheduled []string
+}
+
+func (s *recordingScheduler) Schedule(configs []integration.Config) {
+	for _, c := range configs {
+		for _, instance := range c.Instances {
+			s.scheduled = append(s.scheduled, c.Name+": "+string(instance))
+		}
+	}
+}
+
+func (s *recordingScheduler) Unschedule(configs []integration.Config) {
+	for _, c := range configs {
+		for _, instance := range c.Instances {
+			s.unscheduled = append(s.unscheduled, c.Name+": "+string(instance))
+		}
+	}
+}
+
+func (s *recordingScheduler) Stop() {}
+
+func TestRefreshSecrets(t *testing.T) {
+	values := map[string]string{"foo": "password1", "bar": "password2"}
+	var changed []string
+
+	originalSecretsDecrypt, originalSecretsRefresh := secretsDecrypt, secretsRefresh
+	defer func() { secretsDecrypt, secretsRefresh = originalSecretsDecrypt, originalSecretsRefresh }()
+	secretsDecrypt = func(data []byte, origin string) ([]byte, error) {
+		for handle, value := range values {
+			data = bytes.Replace(data, []byte("ENC["+handle+"]"), []byte(value), -1)
+		}
+		return data, nil
+	}
+	secretsRefresh = func() ([]string, error) { return changed, nil }
+
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	s := &recordingScheduler{}
+	ac.AddScheduler("test", s, false)
+
+	ac.schedule(ac.processNewConfig(integration.Config{
+		Name:      "memory",
+		Instances: []integration.Data{integration.Data("password: ENC[foo]")},
+	}))
+	ac.schedule(ac.processNewConfig(integration.Config{
+		Name:      "disk",
+		Instances: []integration.Data{integration.Data("password: none")},
+	}))
+	ac.processNewConfig(integration.Config{
+		Name:          "redis",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("password: ENC[bar]")},
+	})
+	ac.processNewService(&dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+	})
+	assert.ElementsMatch(t, []string{"memory: password: password1", "disk: password: none", "redis: password: password2"}, s.scheduled)
+	assert.Len(t, ac.store.getSecretConfigs(), 2)
+
+	// nothing changed
+	s.scheduled = nil
+	ac.refreshSecrets()
+	assert.Empty(t, s.scheduled)
+	assert.Empty(t, s.unscheduled)
+
+	// the template resolved for the service is rescheduled
+	values["bar"] = "new_password2"
+	changed = []string{"bar"}
+	ac.refreshSecrets()
+	assert.Equal(t, []string{"redis: password: password2"}, s.unscheduled)
+	assert.Equal(t, []string{"redis: password: new_password2"}, s.scheduled)
+
+	// the static config is rescheduled
+	s.scheduled, s.unscheduled = nil, nil
+	values["foo"] = "new_password1"
+	changed = []string{"foo"}
+	ac.refreshSecrets()
+	assert.Equal(t, []string{"memory: password: password1"}, s.unscheduled)
+	assert.Equal(t, []string{"memory: password: new_password1"}, s.scheduled)
+
+	var loaded []string
+	for _, c := range ac.GetLoadedConfigs() {
+		loaded = append(loaded, c.Name+": "+string(c.Instances[0]))
+	}
+	assert.ElementsMatch(t, []string{"memory: password: new_password1", "disk: password: none", "redis: password: new_password2"}, loaded)
+	assert.Len(t, ac.store.getSecretConfigs(), 2)
+}
@@ -57,6 +57,7 @@ type Config struct {
 	IgnoreAutodiscoveryTags bool         `json:"ignore_autodiscovery_tags"` // used to ignore tags coming from autodiscovery (include in digest: true)
 	MetricsExcluded         bool         `json:"-"`                         // whether metrics collection is disabled (set by container listeners only) (include in digest: false)
 	LogsExcluded            bool         `json:"-"`                         // whether logs collection is disabled (set by container listeners only) (include in digest: false)
+	Placement               *Placement   `json:"placement,omitempty"`       // placement constraints of a cluster check (optional) (include in digest: true)
 }
 
 // CommonInstanceConfig holds the reserved fields for the yaml instance data
@@ -314,6 +315,9 @@ func (c *Config) Digest() string {
 	h.Write([]byte(c.LogsConfig))                                  //nolint:errcheck
 	h.Write([]byte(c.Entity))                                      //nolint:errcheck
 	h.Write([]byte(strconv.FormatBool(c.IgnoreAutodiscoveryTags))) //nolint:errcheck
+	if !c.Placement.IsEmpty() {
+		h.Write([]byte(c.Placement.String())) //nolint:errcheck
+	}
 
 	return strconv.FormatUint(h.Sum64(), 16)
 }
@@ -279,3 +279,34 @@ func BenchmarkID(b *testing.B) {
 	}
 	result = id
 }
+
+func TestDigestPlacement(t *testing.T) {
+	config := &Config{Name: "foo"}
+	digest := config.Digest()
+
+	// an empty placement doesn't change the digest
+	config.Placement = &Placement{}
+	assert.Equal(t, digest, config.Digest())
+
+	config.Placement = &Placement{
+		NodeSelector: map[string]string{"pool": "db", "disk": "ssd"},
+		Zones:        []string{"us-east-1b", "us-east-1a"},
+	}
+	withPlacement := config.Digest()
+	assert.NotEqual(t, digest, withPlacement)
+
+	// the order of zones doesn't matter
+	config.Placement.Zones = []string{"us-east-1a", "us-east-1b"}
+	assert.Equal(t, withPlacement, config.Digest())
+
+	config.Placement.AntiAffinity = true
+	assert.NotEqual(t, withPlacement, config.Digest())
+}
+
+func TestPlacementIsEmpty(t *testing.T) {
+	var placement *Placement
+	assert.True(t, placement.IsEmpty())
+	assert.True(t, (&Placement{}).IsEmpty())
+	assert.False(t, (&Placement{Zones: []string{"us-east-1a"}}).IsEmpty())
+	assert.False(t, (&Placement{AntiAffinity: true}).IsEmpty())
+}
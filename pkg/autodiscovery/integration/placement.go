@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Placement holds the constraints on the nodes a cluster check can be dispatched to
+type Placement struct {
+	// NodeSelector lists the labels the node must have
+	NodeSelector map[string]string `json:"node_selector,omitempty" yaml:"node_selector"`
+	// Zones lists the availability zones the node must be in, one of them is enough
+	Zones []string `json:"zones,omitempty" yaml:"zones"`
+	// AntiAffinity prevents two configurations of the same check from running on the same node
+	AntiAffinity bool `json:"anti_affinity,omitempty" yaml:"anti_affinity"`
+}
+
+// IsEmpty returns true if the placement doesn't constrain the dispatching
+func (p *Placement) IsEmpty() bool {
+	return p == nil || (len(p.NodeSelector) == 0 && len(p.Zones) == 0 && !p.AntiAffinity)
+}
+
+// String returns a stable representation of the placement, used in the config digest
+func (p *Placement) String() string {
+	if p == nil {
+		return ""
+	}
+	selector := make([]string, 0, len(p.NodeSelector))
+	for label, value := range p.NodeSelector {
+		selector = append(selector, label+"="+value)
+	}
+	sort.Strings(selector)
+	zones := append([]string(nil), p.Zones...)
+	sort.Strings(zones)
+	return fmt.Sprintf("node_selector:%s zones:%s anti_affinity:%t", strings.Join(selector, ","), strings.Join(zones, ","), p.AntiAffinity)
+}
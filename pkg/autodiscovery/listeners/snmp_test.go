@@ -161,6 +161,7 @@ func TestExtraConfig(t *testing.T) {
 		Community: "public",
 		Timeout:   5,
 		Retries:   2,
+		Loader:    "core",
 	}
 
 	svc := SNMPService{
@@ -186,6 +187,10 @@ func TestExtraConfig(t *testing.T) {
 	info, err = svc.GetExtraConfig([]byte("retries"))
 	assert.Equal(t, nil, err)
 	assert.Equal(t, "2", string(info))
+
+	info, err = svc.GetExtraConfig([]byte("loader"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "core", string(info))
 }
 
 func TestExtraConfigv3(t *testing.T) {
@@ -14,10 +14,15 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-const defaultGraceDuration = 60 * time.Second
+const (
+	defaultGraceDuration = 60 * time.Second
+	// nodeLabelsRefreshInterval is the interval at which the node labels reported to the cluster-agent are fetched
+	nodeLabelsRefreshInterval = 10 * time.Minute
+)
 
 // ClusterChecksConfigProvider implements the ConfigProvider interface
 // for the cluster check feature.
@@ -28,6 +33,8 @@ type ClusterChecksConfigProvider struct {
 	lastChange     int64
 	nodeName       string
 	flushedConfigs bool
+	nodeLabels     map[string]string
+	nodeLabelsTime time.Time
 }
 
 // NewClusterChecksConfigProvider returns a new ConfigProvider collecting
@@ -87,6 +94,7 @@ func (c *ClusterChecksConfigProvider) IsUpToDate() (bool, error) {
 
 	status := types.NodeStatus{
 		LastChange: c.lastChange,
+		Labels:     c.getNodeLabels(),
 	}
 
 	reply, err := c.dcaClient.PostClusterCheckStatus(c.nodeName, status)
@@ -109,6 +117,23 @@ func (c *ClusterChecksConfigProvider) IsUpToDate() (bool, error) {
 	return reply.IsUpToDate, nil
 }
 
+// getNodeLabels returns the labels of the node, used by the cluster-agent to honor
+// the placement constraints of the cluster checks. They're fetched periodically.
+func (c *ClusterChecksConfigProvider) getNodeLabels() map[string]string {
+	if time.Since(c.nodeLabelsTime) < nodeLabelsRefreshInterval {
+		return c.nodeLabels
+	}
+	c.nodeLabelsTime = time.Now()
+
+	labels, err := hostinfo.GetNodeLabels()
+	if err != nil {
+		log.Debugf("Cannot get the node labels, placement constraints of cluster checks will not match this node: %s", err)
+		return c.nodeLabels
+	}
+	c.nodeLabels = labels
+	return c.nodeLabels
+}
+
 // Collect retrieves configurations the cluster-agent dispatched to this agent
 func (c *ClusterChecksConfigProvider) Collect() ([]integration.Config, error) {
 	if c.dcaClient == nil {
@@ -29,8 +29,9 @@ type configFormat struct {
 	MetricConfig            interface{} `yaml:"jmx_metrics"`
 	LogsConfig              interface{} `yaml:"logs"`
 	Instances               []integration.RawMap
-	DockerImages            []string `yaml:"docker_images"`             // Only imported for deprecation warning
-	IgnoreAutodiscoveryTags bool     `yaml:"ignore_autodiscovery_tags"` // Use to ignore tags coming from autodiscovery
+	DockerImages            []string               `yaml:"docker_images"`             // Only imported for deprecation warning
+	IgnoreAutodiscoveryTags bool                   `yaml:"ignore_autodiscovery_tags"` // Use to ignore tags coming from autodiscovery
+	Placement               *integration.Placement `yaml:"placement"`                 // Placement constraints of a cluster check
 }
 
 type configPkg struct {
@@ -315,6 +316,9 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Copy ignore_autodiscovery_tags parameter
 	config.IgnoreAutodiscoveryTags = cf.IgnoreAutodiscoveryTags
 
+	// Copy placement constraints
+	config.Placement = cf.Placement
+
 	// DockerImages entry was found: we ignore it if no ADIdentifiers has been found
 	if len(cf.DockerImages) > 0 && len(cf.ADIdentifiers) == 0 {
 		return config, errors.New("the 'docker_images' section is deprecated, please use 'ad_identifiers' instead")
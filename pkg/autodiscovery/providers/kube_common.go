@@ -3,14 +3,22 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-2020 Datadog, Inc.
 
-// +build clusterchecks
-// +build kubeapiserver
+// +build clusterchecks,kubeapiserver
 
 package providers
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
 
-const ignoreADTagsAnnotationSuffix = "ignore_autodiscovery_tags"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+const (
+	ignoreADTagsAnnotationSuffix = "ignore_autodiscovery_tags"
+	placementAnnotationSuffix    = "placement"
+)
 
 // ignoreADTagsFromAnnotations returns whether the check should have autodiscovery tags from the service (e.g kube_namespace)
 // based on the value of the annotation ad.datadoghq.com/ignore_autodiscovery_tags
@@ -20,3 +28,17 @@ func ignoreADTagsFromAnnotations(annotations map[string]string, prefix string) b
 	}
 	return strings.ToLower(annotations[prefix+ignoreADTagsAnnotationSuffix]) == "true"
 }
+
+// placementFromAnnotations returns the placement constraints of the cluster checks
+// set in JSON in the annotation ad.datadoghq.com/service.placement, nil if not set
+func placementFromAnnotations(annotations map[string]string, prefix string) (*integration.Placement, error) {
+	value, found := annotations[prefix+placementAnnotationSuffix]
+	if !found {
+		return nil, nil
+	}
+	placement := &integration.Placement{}
+	if err := json.Unmarshal([]byte(value), placement); err != nil {
+		return nil, fmt.Errorf("cannot parse the %s annotation: %s", prefix+placementAnnotationSuffix, err)
+	}
+	return placement, nil
+}
@@ -3,8 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-2020 Datadog, Inc.
 
-// +build clusterchecks
-// +build kubeapiserver
+// +build clusterchecks,kubeapiserver
 
 package providers
 
@@ -155,11 +154,16 @@ func parseServiceAnnotations(services []*v1.Service) ([]integration.Config, erro
 			log.Errorf("Cannot parse service template for service %s/%s: %s", svc.Namespace, svc.Name, err)
 		}
 		ignoreADTags := ignoreADTagsFromAnnotations(svc.GetAnnotations(), kubeServiceAnnotationPrefix)
+		placement, err := placementFromAnnotations(svc.GetAnnotations(), kubeServiceAnnotationPrefix)
+		if err != nil {
+			log.Errorf("Cannot parse placement constraints for service %s/%s: %s", svc.Namespace, svc.Name, err)
+		}
 		// All configurations are cluster checks
 		for i := range svcConf {
 			svcConf[i].ClusterCheck = true
 			svcConf[i].Source = "kube_services:" + serviceID
 			svcConf[i].IgnoreAutodiscoveryTags = ignoreADTags
+			svcConf[i].Placement = placement
 		}
 		configs = append(configs, svcConf...)
 	}
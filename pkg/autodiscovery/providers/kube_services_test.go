@@ -3,8 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-2020 Datadog, Inc.
 
-// +build clusterchecks
-// +build kubeapiserver
+// +build clusterchecks,kubeapiserver
 
 package providers
 
@@ -80,6 +79,35 @@ func TestParseKubeServiceAnnotations(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "placement constraints",
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					UID: types.UID("test"),
+					Annotations: map[string]string{
+						"ad.datadoghq.com/service.check_names":  "[\"http_check\"]",
+						"ad.datadoghq.com/service.init_configs": "[{}]",
+						"ad.datadoghq.com/service.instances":    "[{\"name\": \"My service\", \"url\": \"http://%%host%%\", \"timeout\": 1}]",
+						"ad.datadoghq.com/service.placement":    "{\"node_selector\": {\"pool\": \"db\"}, \"zones\": [\"us-east-1a\"], \"anti_affinity\": true}",
+					},
+				},
+			},
+			expectedOut: []integration.Config{
+				{
+					Name:          "http_check",
+					ADIdentifiers: []string{"kube_service_uid://test"},
+					InitConfig:    integration.Data("{}"),
+					Instances:     []integration.Data{integration.Data("{\"name\":\"My service\",\"timeout\":1,\"url\":\"http://%%host%%\"}")},
+					ClusterCheck:  true,
+					Source:        "kube_services:kube_service_uid://test",
+					Placement: &integration.Placement{
+						NodeSelector: map[string]string{"pool": "db"},
+						Zones:        []string{"us-east-1a"},
+						AntiAffinity: true,
+					},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf(tc.name), func(t *testing.T) {
 			cfgs, _ := parseServiceAnnotations([]*v1.Service{tc.service})
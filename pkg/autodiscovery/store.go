@@ -21,10 +21,18 @@ type store struct {
 	nameToJMXMetrics  map[string]integration.Data
 	adIDToServices    map[string]map[string]bool
 	entityToService   map[string]listeners.Service
+	secretConfigs     map[string]secretConfig
 	templateCache     *TemplateCache
 	m                 sync.RWMutex
 }
 
+// secretConfig is a loaded config using secrets, along with the config before
+// its secrets were decrypted
+type secretConfig struct {
+	decrypted integration.Config
+	encrypted integration.Config
+}
+
 // newStore creates a store
 func newStore() *store {
 	s := store{
@@ -35,6 +43,7 @@ func newStore() *store {
 		nameToJMXMetrics:  make(map[string]integration.Data),
 		adIDToServices:    make(map[string]map[string]bool),
 		entityToService:   make(map[string]listeners.Service),
+		secretConfigs:     make(map[string]secretConfig),
 		templateCache:     NewTemplateCache(),
 	}
 
@@ -130,6 +139,31 @@ func (s *store) getLoadedConfigs() map[string]integration.Config {
 	return s.loadedConfigs
 }
 
+// setSecretConfig stores the encrypted version of a loaded config by the digest of the decrypted one
+func (s *store) setSecretConfig(decrypted integration.Config, encrypted integration.Config) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.secretConfigs[decrypted.Digest()] = secretConfig{decrypted: decrypted, encrypted: encrypted}
+}
+
+// removeSecretConfig removes the encrypted version of a loaded config
+func (s *store) removeSecretConfig(decrypted integration.Config) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.secretConfigs, decrypted.Digest())
+}
+
+// getSecretConfigs returns the loaded configs using secrets
+func (s *store) getSecretConfigs() []secretConfig {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	configs := make([]secretConfig, 0, len(s.secretConfigs))
+	for _, c := range s.secretConfigs {
+		configs = append(configs, c)
+	}
+	return configs
+}
+
 // setJMXMetricsForConfigName stores the jmx metrics config for a config name
 func (s *store) setJMXMetricsForConfigName(config string, metrics integration.Data) {
 	s.m.Lock()
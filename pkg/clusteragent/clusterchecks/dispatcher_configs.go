@@ -27,8 +27,16 @@ func (d *dispatcher) getState() (types.StateResponse, error) {
 	defer d.store.RUnlock()
 
 	response := types.StateResponse{
-		Warmup:   !d.store.active,
-		Dangling: makeConfigArray(d.store.danglingConfigs),
+		Warmup:      !d.store.active,
+		Dangling:    []integration.Config{},
+		Unplaceable: []types.UnplaceableConfig{},
+	}
+	for digest, config := range d.store.danglingConfigs {
+		if reason, found := d.store.unplaceable[digest]; found {
+			response.Unplaceable = append(response.Unplaceable, types.UnplaceableConfig{Config: config, Reason: reason})
+		} else {
+			response.Dangling = append(response.Dangling, config)
+		}
 	}
 	for _, node := range d.store.nodes {
 		n := types.StateNodeResponse{
@@ -62,6 +70,8 @@ func (d *dispatcher) addConfig(config integration.Config, targetNodeName string)
 	currentNode, foundCurrent := d.store.getNodeStore(d.store.digestToNode[digest])
 	targetNode := d.store.getOrCreateNodeStore(targetNodeName, "")
 
+	delete(d.store.unplaceable, digest)
+
 	// Dispatch to target node
 	targetNode.Lock()
 	targetNode.addConfig(config)
@@ -87,6 +97,7 @@ func (d *dispatcher) removeConfig(digest string) {
 	delete(d.store.digestToNode, digest)
 	delete(d.store.digestToConfig, digest)
 	delete(d.store.danglingConfigs, digest)
+	delete(d.store.unplaceable, digest)
 
 	for k, v := range d.store.idToDigest {
 		if v == digest {
@@ -102,6 +113,15 @@ func (d *dispatcher) removeConfig(digest string) {
 	}
 }
 
+// setUnplaceable records why a dangling config can't be dispatched
+func (d *dispatcher) setUnplaceable(digest, reason string) {
+	d.store.Lock()
+	defer d.store.Unlock()
+	if _, found := d.store.danglingConfigs[digest]; found {
+		d.store.unplaceable[digest] = reason
+	}
+}
+
 // shouldDispatchDanling returns true if there are dangling configs
 // and node registered, available for dispatching.
 func (d *dispatcher) shouldDispatchDanling() bool {
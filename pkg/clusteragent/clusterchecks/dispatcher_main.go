@@ -130,15 +130,24 @@ func (d *dispatcher) reschedule(configs []integration.Config) {
 
 // add stores and delegates a given configuration
 func (d *dispatcher) add(config integration.Config) {
-	target := d.getLeastBusyNode()
+	target := d.getLeastBusyNode(config)
+	reason := ""
 	if target == "" {
 		// If no node is found, store it in the danglingConfigs map for retrying later.
-		log.Warnf("No available node to dispatch %s:%s on, will retry later", config.Name, config.Digest())
+		reason = d.unplaceableReason(config)
+		if reason != "" {
+			log.Warnf("Cannot dispatch %s:%s, %s, will retry later", config.Name, config.Digest(), reason)
+		} else {
+			log.Warnf("No available node to dispatch %s:%s on, will retry later", config.Name, config.Digest())
+		}
 	} else {
 		log.Infof("Dispatching configuration %s:%s to node %s", config.Name, config.Digest(), target)
 	}
 
 	d.addConfig(config, target)
+	if reason != "" {
+		d.setUnplaceable(config.Digest(), reason)
+	}
 }
 
 // remove deletes a given configuration
@@ -69,9 +69,10 @@ func (d *dispatcher) processNodeStatus(nodeName, clientIP string, status types.N
 }
 
 // getLeastBusyNode returns the name of the node that is assigned
-// the lowest number of checks. In case of equality, one is chosen
+// the lowest number of checks, among the nodes satisfying the placement
+// constraints of the config. In case of equality, one is chosen
 // randomly, based on map iterations being randomized.
-func (d *dispatcher) getLeastBusyNode() string {
+func (d *dispatcher) getLeastBusyNode(config integration.Config) string {
 	var leastBusyNode string
 	minCheckCount := int(-1)
 	minBusyness := int(-1)
@@ -83,6 +84,12 @@ func (d *dispatcher) getLeastBusyNode() string {
 		if name == "" {
 			continue
 		}
+		store.RLock()
+		accepted, _ := store.acceptsConfig(config)
+		store.RUnlock()
+		if !accepted {
+			continue
+		}
 		if d.advancedDispatching && store.busyness > defaultBusynessValue {
 			// dispatching based on clc runners stats
 			// only when advancedDispatching is true and
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// zoneLabels are the node labels holding the availability zone, by order of preference
+var zoneLabels = []string{
+	"topology.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/zone",
+}
+
+// nodeZone returns the availability zone of a node, based on its labels
+func nodeZone(labels map[string]string) string {
+	for _, label := range zoneLabels {
+		if zone, found := labels[label]; found {
+			return zone
+		}
+	}
+	return ""
+}
+
+// acceptsConfig returns whether a config can be dispatched to the node according to
+// its placement constraints, and the reason why it can't otherwise.
+// The node lock must be held by the caller.
+func (s *nodeStore) acceptsConfig(config integration.Config) (bool, string) {
+	placement := config.Placement
+	if placement.IsEmpty() {
+		return true, ""
+	}
+
+	labels := s.lastStatus.Labels
+	selector := make([]string, 0, len(placement.NodeSelector))
+	for label := range placement.NodeSelector {
+		selector = append(selector, label)
+	}
+	sort.Strings(selector)
+	for _, label := range selector {
+		if value, found := labels[label]; !found || value != placement.NodeSelector[label] {
+			return false, fmt.Sprintf("missing label %s=%s", label, placement.NodeSelector[label])
+		}
+	}
+
+	if len(placement.Zones) > 0 {
+		zone := nodeZone(labels)
+		found := false
+		for _, z := range placement.Zones {
+			if z == zone {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, fmt.Sprintf("not in zones %s", strings.Join(placement.Zones, ", "))
+		}
+	}
+
+	if placement.AntiAffinity {
+		digest := config.Digest()
+		for d, c := range s.digestToConfig {
+			if c.Name == config.Name && d != digest {
+				return false, fmt.Sprintf("already running a %s check", config.Name)
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// unplaceableReason explains why no node satisfies the placement constraints of
+// a config. It returns an empty string if the config has no placement constraints
+// or if no node is reporting.
+func (d *dispatcher) unplaceableReason(config integration.Config) string {
+	if config.Placement.IsEmpty() {
+		return ""
+	}
+
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	reasons := map[string]int{}
+	for name, node := range d.store.nodes {
+		if name == "" {
+			continue
+		}
+		node.RLock()
+		if ok, reason := node.acceptsConfig(config); !ok {
+			reasons[reason]++
+		}
+		node.RUnlock()
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	details := make([]string, 0, len(reasons))
+	for reason, count := range reasons {
+		details = append(details, fmt.Sprintf("%d node(s) %s", count, reason))
+	}
+	sort.Strings(details)
+	return "no node satisfies the placement constraints: " + strings.Join(details, ", ")
+}
+
+// filterNodesForConfig returns the entries of a map keyed by node name for the nodes
+// satisfying the placement constraints of a config
+func (d *dispatcher) filterNodesForConfig(diffMap map[string]int, config integration.Config) map[string]int {
+	if config.Placement.IsEmpty() {
+		return diffMap
+	}
+
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	filtered := make(map[string]int, len(diffMap))
+	for name, diff := range diffMap {
+		node, found := d.store.getNodeStore(name)
+		if !found {
+			continue
+		}
+		node.RLock()
+		ok, _ := node.acceptsConfig(config)
+		node.RUnlock()
+		if ok {
+			filtered[name] = diff
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+)
+
+func generatePlacedIntegration(name string, instance string, placement *integration.Placement) integration.Config {
+	return integration.Config{
+		Name:         name,
+		ClusterCheck: true,
+		Instances:    []integration.Data{integration.Data(instance)},
+		Placement:    placement,
+	}
+}
+
+func TestNodeZone(t *testing.T) {
+	assert.Equal(t, "", nodeZone(nil))
+	assert.Equal(t, "us-east-1a", nodeZone(map[string]string{"failure-domain.beta.kubernetes.io/zone": "us-east-1a"}))
+	assert.Equal(t, "us-east-1b", nodeZone(map[string]string{
+		"failure-domain.beta.kubernetes.io/zone": "us-east-1a",
+		"topology.kubernetes.io/zone":            "us-east-1b",
+	}))
+}
+
+func TestAcceptsConfig(t *testing.T) {
+	node := newNodeStore("node1", "")
+	node.lastStatus = types.NodeStatus{Labels: map[string]string{
+		"pool":                        "db",
+		"topology.kubernetes.io/zone": "us-east-1a",
+	}}
+	node.addConfig(generatePlacedIntegration("postgres", "host: db1", nil))
+
+	for _, tc := range []struct {
+		name      string
+		placement *integration.Placement
+		accepted  bool
+		reason    string
+	}{
+		{
+			name:     "no placement",
+			accepted: true,
+		},
+		{
+			name:      "matching selector and zone",
+			placement: &integration.Placement{NodeSelector: map[string]string{"pool": "db"}, Zones: []string{"us-east-1b", "us-east-1a"}},
+			accepted:  true,
+		},
+		{
+			name:      "label value mismatch",
+			placement: &integration.Placement{NodeSelector: map[string]string{"pool": "web"}},
+			reason:    "missing label pool=web",
+		},
+		{
+			name:      "missing label",
+			placement: &integration.Placement{NodeSelector: map[string]string{"disk": "ssd"}},
+			reason:    "missing label disk=ssd",
+		},
+		{
+			name:      "zone mismatch",
+			placement: &integration.Placement{Zones: []string{"us-east-1b", "us-east-1c"}},
+			reason:    "not in zones us-east-1b, us-east-1c",
+		},
+		{
+			name:      "anti-affinity",
+			placement: &integration.Placement{AntiAffinity: true},
+			reason:    "already running a postgres check",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			accepted, reason := node.acceptsConfig(generatePlacedIntegration("postgres", "host: db2", tc.placement))
+			assert.Equal(t, tc.accepted, accepted)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+
+	// anti-affinity doesn't prevent a config from staying on its node
+	config := generatePlacedIntegration("redis", "host: cache1", &integration.Placement{AntiAffinity: true})
+	node.addConfig(config)
+	accepted, _ := node.acceptsConfig(config)
+	assert.True(t, accepted)
+}
+
+func TestGetLeastBusyNodePlacement(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.processNodeStatus("node1", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "db"}})
+	dispatcher.processNodeStatus("node2", "10.0.0.2", types.NodeStatus{})
+	dispatcher.addConfig(generateIntegration("A"), "node1")
+
+	// node2 is less busy, but only node1 has the label
+	placement := &integration.Placement{NodeSelector: map[string]string{"pool": "db"}}
+	assert.Equal(t, "node2", dispatcher.getLeastBusyNode(generatePlacedIntegration("postgres", "host: db1", nil)))
+	assert.Equal(t, "node1", dispatcher.getLeastBusyNode(generatePlacedIntegration("postgres", "host: db1", placement)))
+
+	// anti-affinity spreads the instances of a check
+	placement = &integration.Placement{AntiAffinity: true}
+	dispatcher.add(generatePlacedIntegration("postgres", "host: db1", placement))
+	dispatcher.add(generatePlacedIntegration("postgres", "host: db2", placement))
+	dispatcher.add(generatePlacedIntegration("postgres", "host: db3", placement))
+
+	state, err := dispatcher.getState()
+	require.NoError(t, err)
+	nodes := map[string][]string{}
+	for _, node := range state.Nodes {
+		nodes[node.Name] = extractCheckNames(node.Configs)
+	}
+	assert.Equal(t, map[string][]string{"node1": {"A", "postgres"}, "node2": {"postgres"}}, nodes)
+	require.Len(t, state.Unplaceable, 1)
+	assert.Equal(t, "host: db3", string(state.Unplaceable[0].Config.Instances[0]))
+	assert.Equal(t, "no node satisfies the placement constraints: 2 node(s) already running a postgres check", state.Unplaceable[0].Reason)
+	assert.Empty(t, state.Dangling)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestUnplaceableConfig(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.processNodeStatus("node1", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"}})
+
+	config := generatePlacedIntegration("postgres", "host: db1", &integration.Placement{Zones: []string{"us-east-1b"}})
+	dispatcher.add(config)
+	dispatcher.add(generateIntegration("http_check"))
+
+	state, err := dispatcher.getState()
+	require.NoError(t, err)
+	assert.Empty(t, state.Dangling)
+	assert.Equal(t, []types.UnplaceableConfig{
+		{Config: config, Reason: "no node satisfies the placement constraints: 1 node(s) not in zones us-east-1b"},
+	}, state.Unplaceable)
+
+	// a node in the zone reports, the config is dispatched on the next retry
+	dispatcher.processNodeStatus("node2", "10.0.0.2", types.NodeStatus{Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1b"}})
+	require.True(t, dispatcher.shouldDispatchDanling())
+	dispatcher.reschedule(dispatcher.retrieveAndClearDangling())
+
+	state, err = dispatcher.getState()
+	require.NoError(t, err)
+	assert.Empty(t, state.Unplaceable)
+	assert.Equal(t, "node2", dispatcher.store.digestToNode[config.Digest()])
+
+	// removing an unplaceable config forgets it
+	dispatcher.add(generatePlacedIntegration("postgres", "host: db2", &integration.Placement{Zones: []string{"us-east-1c"}}))
+	assert.Len(t, dispatcher.store.unplaceable, 1)
+	dispatcher.remove(generatePlacedIntegration("postgres", "host: db2", &integration.Placement{Zones: []string{"us-east-1c"}}))
+	assert.Empty(t, dispatcher.store.unplaceable)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestFilterNodesForConfig(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.processNodeStatus("node1", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "db"}})
+	dispatcher.processNodeStatus("node2", "10.0.0.2", types.NodeStatus{})
+	dispatcher.processNodeStatus("node3", "10.0.0.3", types.NodeStatus{Labels: map[string]string{"pool": "db"}})
+	diffMap := map[string]int{"node1": 10, "node2": -20, "node3": 5}
+
+	// without placement, every node can receive the check
+	assert.Equal(t, diffMap, dispatcher.filterNodesForConfig(diffMap, generateIntegration("postgres")))
+
+	config := generatePlacedIntegration("postgres", "host: db1", &integration.Placement{NodeSelector: map[string]string{"pool": "db"}})
+	filtered := dispatcher.filterNodesForConfig(diffMap, config)
+	assert.Equal(t, map[string]int{"node1": 10, "node3": 5}, filtered)
+	assert.Equal(t, "node3", pickNode(filtered, "node1"))
+	assert.Equal(t, "", pickNode(dispatcher.filterNodesForConfig(map[string]int{"node1": 10, "node2": -20}, config), "node1"))
+
+	requireNotLocked(t, dispatcher.store)
+}
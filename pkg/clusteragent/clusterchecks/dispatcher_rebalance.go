@@ -205,7 +205,13 @@ func (d *dispatcher) rebalance() []types.RebalanceResponse {
 				break
 			}
 
-			destNodeName := pickNode(diffMap, sourceNodeName)
+			// only consider the nodes satisfying the placement constraints of the check
+			config, _ := d.getConfigAndDigest(checkID)
+			destNodeName := pickNode(d.filterNodesForConfig(diffMap, config), sourceNodeName)
+			if destNodeName == "" {
+				log.Debugf("No node can receive check %s from node %s", checkID, sourceNodeName)
+				break
+			}
 			sourceDiff := diffMap[sourceNodeName]
 			destDiff := diffMap[destNodeName]
 
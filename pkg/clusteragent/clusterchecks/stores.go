@@ -28,6 +28,7 @@ type clusterStore struct {
 	digestToNode     map[string]string                        // Node running a config
 	nodes            map[string]*nodeStore                    // All nodes known to the cluster-agent
 	danglingConfigs  map[string]integration.Config            // Configs we could not dispatch to any node
+	unplaceable      map[string]string                        // Why dangling configs don't satisfy their placement constraints
 	endpointsConfigs map[string]map[string]integration.Config // Endpoints configs to be consumed by node agents
 	idToDigest       map[check.ID]string                      // link check IDs to check configs
 }
@@ -45,6 +46,7 @@ func (s *clusterStore) reset() {
 	s.digestToNode = make(map[string]string)
 	s.nodes = make(map[string]*nodeStore)
 	s.danglingConfigs = make(map[string]integration.Config)
+	s.unplaceable = make(map[string]string)
 	s.endpointsConfigs = make(map[string]map[string]integration.Config)
 	s.idToDigest = make(map[check.ID]string)
 }
@@ -75,6 +77,7 @@ func (s *clusterStore) getOrCreateNodeStore(nodeName, clientIP string) *nodeStor
 // clearDangling resets the danglingConfigs map to a new empty one
 func (s *clusterStore) clearDangling() {
 	s.danglingConfigs = make(map[string]integration.Config)
+	s.unplaceable = make(map[string]string)
 }
 
 // nodeStore holds the state store for one node.
@@ -11,7 +11,8 @@ import (
 
 // NodeStatus holds the status report from the node-agent
 type NodeStatus struct {
-	LastChange int64 `json:"last_change"`
+	LastChange int64             `json:"last_change"`
+	Labels     map[string]string `json:"labels,omitempty"` // labels of the node, used by the placement constraints
 }
 
 // StatusResponse holds the DCA response for a status report
@@ -39,10 +40,17 @@ type ConfigResponse struct {
 
 // StateResponse holds the DCA response for a dispatching state query
 type StateResponse struct {
-	NotRunning string               `json:"not_running"` // Reason why not running, empty if leading
-	Warmup     bool                 `json:"warmup"`
-	Nodes      []StateNodeResponse  `json:"nodes"`
-	Dangling   []integration.Config `json:"dangling"`
+	NotRunning  string               `json:"not_running"` // Reason why not running, empty if leading
+	Warmup      bool                 `json:"warmup"`
+	Nodes       []StateNodeResponse  `json:"nodes"`
+	Dangling    []integration.Config `json:"dangling"`
+	Unplaceable []UnplaceableConfig  `json:"unplaceable"` // Configs no node can run because of their placement constraints
+}
+
+// UnplaceableConfig is a config that can't be dispatched because of its placement constraints
+type UnplaceableConfig struct {
+	Config integration.Config `json:"config"`
+	Reason string             `json:"reason"`
 }
 
 // StateNodeResponse is a chunk of StateResponse
@@ -0,0 +1,308 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+)
+
+const (
+	defaultPort               = 161
+	defaultTimeout            = 1
+	defaultRetries            = 5
+	defaultOidBatchSize       = 10
+	defaultBulkMaxRepetitions = 10
+)
+
+// number is an integer that can also be given as a string, since autodiscovery
+// template variables (eg. `port: "%%port%%"`) always resolve to strings.
+type number int
+
+func (n *number) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value int
+	if err := unmarshal(&value); err == nil {
+		*n = number(value)
+		return nil
+	}
+
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if str == "" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil {
+		return fmt.Errorf("cannot convert %q to a number", str)
+	}
+	*n = number(value)
+	return nil
+}
+
+// stringList is a list of strings that can also be given as a single string.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var values []string
+	if err := unmarshal(&values); err == nil {
+		*l = values
+		return nil
+	}
+
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	*l = []string{value}
+	return nil
+}
+
+// symbolConfig identifies a scalar OID or a table column.
+type symbolConfig struct {
+	OID  string `yaml:"OID"`
+	Name string `yaml:"name"`
+}
+
+// metricTagConfig describes how to build a tag, either from a scalar OID (global metric tags)
+// or from an index component or a column of the table a metric belongs to.
+type metricTagConfig struct {
+	Tag string `yaml:"tag"`
+
+	// Global metric tags.
+	OID    string `yaml:"OID"`
+	Symbol string `yaml:"symbol"`
+
+	// Table metric tags.
+	Index  uint         `yaml:"index"`
+	Column symbolConfig `yaml:"column"`
+}
+
+// metricsConfig describes either a scalar metric (`symbol`) or a set of table metrics (`table` and `symbols`).
+type metricsConfig struct {
+	MIB string `yaml:"MIB"`
+
+	// Legacy scalar definition.
+	OID  string `yaml:"OID"`
+	Name string `yaml:"name"`
+
+	Symbol symbolConfig `yaml:"symbol"`
+
+	Table      symbolConfig      `yaml:"table"`
+	Symbols    []symbolConfig    `yaml:"symbols"`
+	MetricTags []metricTagConfig `yaml:"metric_tags"`
+
+	ForcedType string `yaml:"forced_type"`
+}
+
+func (m *metricsConfig) isScalar() bool {
+	return m.Symbol.OID != "" || m.OID != ""
+}
+
+// scalarSymbol returns the symbol of a scalar metric, handling the legacy format.
+func (m *metricsConfig) scalarSymbol() symbolConfig {
+	if m.Symbol.OID != "" {
+		return m.Symbol
+	}
+	return symbolConfig{OID: m.OID, Name: m.Name}
+}
+
+type snmpInitConfig struct {
+	Profiles           map[string]profileConfig `yaml:"profiles"`
+	OidBatchSize       number                   `yaml:"oid_batch_size"`
+	BulkMaxRepetitions number                   `yaml:"bulk_max_repetitions"`
+}
+
+type snmpInstanceConfig struct {
+	IPAddress          string `yaml:"ip_address"`
+	Port               number `yaml:"port"`
+	SnmpVersion        string `yaml:"snmp_version"`
+	Timeout            number `yaml:"timeout"`
+	Retries            number `yaml:"retries"`
+	CommunityString    string `yaml:"community_string"`
+	User               string `yaml:"user"`
+	AuthKey            string `yaml:"authKey"`
+	AuthProtocol       string `yaml:"authProtocol"`
+	PrivKey            string `yaml:"privKey"`
+	PrivProtocol       string `yaml:"privProtocol"`
+	ContextEngineID    string `yaml:"context_engine_id"`
+	ContextName        string `yaml:"context_name"`
+	Profile            string `yaml:"profile"`
+	OidBatchSize       number `yaml:"oid_batch_size"`
+	BulkMaxRepetitions number `yaml:"bulk_max_repetitions"`
+
+	Metrics    []metricsConfig   `yaml:"metrics"`
+	MetricTags []metricTagConfig `yaml:"metric_tags"`
+	Tags       []string          `yaml:"tags"`
+}
+
+// snmpConfig is the configuration of a check instance, resolved from the instance and init configurations.
+type snmpConfig struct {
+	ipAddress          string
+	params             *gosnmp.GoSNMP
+	oidBatchSize       int
+	bulkMaxRepetitions uint8
+	tags               []string
+
+	// Metrics and tags defined in the instance configuration.
+	metrics    []metricsConfig
+	metricTags []metricTagConfig
+
+	// Profile, either set in the instance configuration or detected from the sysObjectID of the device.
+	profile  string
+	profiles profileDefinitionMap
+}
+
+func (c *snmpConfig) parse(data []byte, initData []byte) error {
+	var instance snmpInstanceConfig
+	var initConfig snmpInitConfig
+
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(initData, &initConfig); err != nil {
+		return err
+	}
+
+	if instance.IPAddress == "" {
+		return errors.New("`ip_address` is required")
+	}
+	c.ipAddress = instance.IPAddress
+
+	params, err := buildParams(instance)
+	if err != nil {
+		return err
+	}
+	c.params = params
+
+	c.oidBatchSize = firstPositive(int(instance.OidBatchSize), int(initConfig.OidBatchSize), defaultOidBatchSize)
+	bulkMaxRepetitions := firstPositive(int(instance.BulkMaxRepetitions), int(initConfig.BulkMaxRepetitions), defaultBulkMaxRepetitions)
+	if bulkMaxRepetitions > math.MaxUint8 {
+		return fmt.Errorf("`bulk_max_repetitions` must be at most %d", math.MaxUint8)
+	}
+	c.bulkMaxRepetitions = uint8(bulkMaxRepetitions)
+
+	for _, metric := range instance.Metrics {
+		if err := validateMetric(metric); err != nil {
+			return err
+		}
+	}
+	for _, tag := range instance.MetricTags {
+		if err := validateGlobalMetricTag(tag); err != nil {
+			return err
+		}
+	}
+	c.metrics = instance.Metrics
+	c.metricTags = instance.MetricTags
+
+	c.profiles, err = loadProfiles(initConfig.Profiles)
+	if err != nil {
+		return err
+	}
+	if instance.Profile != "" {
+		if _, ok := c.profiles[instance.Profile]; !ok {
+			return fmt.Errorf("unknown profile %q", instance.Profile)
+		}
+		c.profile = instance.Profile
+	}
+
+	c.tags = append([]string{"snmp_device:" + c.ipAddress}, instance.Tags...)
+
+	return nil
+}
+
+// buildParams returns GoSNMP params for the device of an instance, using the same
+// configuration handling as the SNMP autodiscovery listener.
+func buildParams(instance snmpInstanceConfig) (*gosnmp.GoSNMP, error) {
+	config := snmp.Config{
+		Port:            uint16(firstPositive(int(instance.Port), defaultPort)),
+		Version:         strings.TrimPrefix(instance.SnmpVersion, "v"),
+		Timeout:         firstPositive(int(instance.Timeout), defaultTimeout),
+		Retries:         firstPositive(int(instance.Retries), defaultRetries),
+		Community:       instance.CommunityString,
+		User:            instance.User,
+		AuthKey:         instance.AuthKey,
+		AuthProtocol:    instance.AuthProtocol,
+		PrivKey:         instance.PrivKey,
+		PrivProtocol:    instance.PrivProtocol,
+		ContextEngineID: instance.ContextEngineID,
+		ContextName:     instance.ContextName,
+	}
+	// The Python integration uses "2" for SNMP v2c, and so does the autodiscovery listener.
+	if config.Version == "2c" {
+		config.Version = "2"
+	}
+
+	params, err := config.BuildSNMPParams()
+	if err != nil {
+		return nil, err
+	}
+	params.Target = instance.IPAddress
+	return params, nil
+}
+
+func validateMetric(metric metricsConfig) error {
+	switch metric.ForcedType {
+	case "", forcedTypeGauge, forcedTypeCounter, forcedTypeMonotonicCount, forcedTypeMonotonicCountAndRate:
+	default:
+		return fmt.Errorf("unsupported forced type %q", metric.ForcedType)
+	}
+
+	if metric.isScalar() {
+		if metric.scalarSymbol().Name == "" {
+			return fmt.Errorf("metric with OID %s must have a name", metric.scalarSymbol().OID)
+		}
+		return nil
+	}
+
+	if metric.Table.OID == "" {
+		return errors.New("metrics must define either a `symbol` OID or a `table` OID")
+	}
+	if len(metric.Symbols) == 0 {
+		return fmt.Errorf("table %s must define `symbols`", metric.Table.OID)
+	}
+	for _, symbol := range metric.Symbols {
+		if symbol.OID == "" || symbol.Name == "" {
+			return fmt.Errorf("symbols of table %s must have an OID and a name", metric.Table.OID)
+		}
+	}
+	for _, tag := range metric.MetricTags {
+		if tag.Tag == "" {
+			return fmt.Errorf("metric tags of table %s must have a `tag` name", metric.Table.OID)
+		}
+		if tag.Index == 0 && tag.Column.OID == "" {
+			return fmt.Errorf("metric tag %s of table %s must define either an `index` or a `column` OID", tag.Tag, metric.Table.OID)
+		}
+	}
+
+	return nil
+}
+
+func validateGlobalMetricTag(tag metricTagConfig) error {
+	if tag.OID == "" || tag.Tag == "" {
+		return errors.New("global metric tags must have an `OID` and a `tag` name")
+	}
+	return nil
+}
+
+func firstPositive(values ...int) int {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureDefaults(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	config := new(snmpConfig)
+	err := config.parse([]byte(`
+ip_address: 1.2.3.4
+community_string: public
+`), []byte(``))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2.3.4", config.ipAddress)
+	assert.Equal(t, "1.2.3.4", config.params.Target)
+	assert.Equal(t, uint16(defaultPort), config.params.Port)
+	assert.Equal(t, gosnmp.Version2c, config.params.Version)
+	assert.Equal(t, "public", config.params.Community)
+	assert.Equal(t, defaultTimeout*time.Second, config.params.Timeout)
+	assert.Equal(t, defaultRetries, config.params.Retries)
+	assert.Equal(t, defaultOidBatchSize, config.oidBatchSize)
+	assert.Equal(t, uint8(defaultBulkMaxRepetitions), config.bulkMaxRepetitions)
+	assert.Equal(t, []string{"snmp_device:1.2.3.4"}, config.tags)
+	assert.Equal(t, "", config.profile)
+	assert.Contains(t, config.profiles, "generic-router")
+	assert.Contains(t, config.profiles, "cisco-nexus")
+}
+
+func TestConfigureAutodiscoveryTemplate(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	// Template variables resolved by the SNMP autodiscovery listener are all strings.
+	config := new(snmpConfig)
+	err := config.parse([]byte(`
+ip_address: "10.0.0.1"
+port: "1161"
+snmp_version: "3"
+timeout: "5"
+retries: ""
+community_string: ""
+user: "admin"
+authProtocol: "sha"
+authKey: "auth-password"
+privProtocol: "aes"
+privKey: "priv-password"
+context_engine_id: ""
+context_name: ""
+profile: cisco-nexus
+tags:
+  - "autodiscovery_subnet:10.0.0.0/24"
+`), []byte(`
+oid_batch_size: 20
+bulk_max_repetitions: 50
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(1161), config.params.Port)
+	assert.Equal(t, gosnmp.Version3, config.params.Version)
+	assert.Equal(t, gosnmp.AuthPriv, config.params.MsgFlags)
+	assert.Equal(t, 5*time.Second, config.params.Timeout)
+	assert.Equal(t, defaultRetries, config.params.Retries)
+	assert.Equal(t, 20, config.oidBatchSize)
+	assert.Equal(t, uint8(50), config.bulkMaxRepetitions)
+	assert.Equal(t, "cisco-nexus", config.profile)
+	assert.Equal(t, []string{"snmp_device:10.0.0.1", "autodiscovery_subnet:10.0.0.0/24"}, config.tags)
+}
+
+func TestConfigureErrors(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	for name, instance := range map[string]string{
+		"missing ip address":      `community_string: public`,
+		"missing authentication":  `ip_address: 1.2.3.4`,
+		"unknown profile":         "ip_address: 1.2.3.4\ncommunity_string: public\nprofile: foo",
+		"invalid port":            "ip_address: 1.2.3.4\ncommunity_string: public\nport: foo",
+		"unknown auth protocol":   "ip_address: 1.2.3.4\nuser: admin\nauthProtocol: foo",
+		"metric without name":     "ip_address: 1.2.3.4\ncommunity_string: public\nmetrics:\n- OID: 1.2.3",
+		"metric without oid":      "ip_address: 1.2.3.4\ncommunity_string: public\nmetrics:\n- MIB: FOO-MIB",
+		"table without symbols":   "ip_address: 1.2.3.4\ncommunity_string: public\nmetrics:\n- table: {OID: 1.2.3, name: fooTable}",
+		"unsupported forced type": "ip_address: 1.2.3.4\ncommunity_string: public\nmetrics:\n- symbol: {OID: 1.2.3.0, name: foo}\n  forced_type: histogram",
+		"global tag without oid":  "ip_address: 1.2.3.4\ncommunity_string: public\nmetric_tags:\n- tag: foo",
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := new(snmpConfig)
+			assert.Error(t, config.parse([]byte(instance), []byte(``)))
+		})
+	}
+}
+
+func TestConfigureMetrics(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	config := new(snmpConfig)
+	err := config.parse([]byte(`
+ip_address: 1.2.3.4
+community_string: public
+metrics:
+  - OID: 1.3.6.1.2.1.6.5.0
+    name: tcpActiveOpens
+  - MIB: IF-MIB
+    table:
+      OID: 1.3.6.1.2.1.2.2
+      name: ifTable
+    symbols:
+      - OID: 1.3.6.1.2.1.2.2.1.14
+        name: ifInErrors
+    metric_tags:
+      - tag: interface
+        column:
+          OID: 1.3.6.1.2.1.31.1.1.1.1
+          name: ifName
+metric_tags:
+  - OID: 1.3.6.1.2.1.1.5.0
+    symbol: sysName
+    tag: snmp_host
+`), []byte(``))
+	require.NoError(t, err)
+
+	require.Len(t, config.metrics, 2)
+	assert.True(t, config.metrics[0].isScalar())
+	assert.Equal(t, symbolConfig{OID: "1.3.6.1.2.1.6.5.0", Name: "tcpActiveOpens"}, config.metrics[0].scalarSymbol())
+	assert.False(t, config.metrics[1].isScalar())
+	require.Len(t, config.metricTags, 1)
+
+	scalarOIDs, columnOIDs := collectOIDs(config.metrics, config.metricTags)
+	assert.Equal(t, []string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.2.1.6.5.0"}, scalarOIDs)
+	assert.Equal(t, []string{"1.3.6.1.2.1.2.2.1.14", "1.3.6.1.2.1.31.1.1.1.1"}, columnOIDs)
+}
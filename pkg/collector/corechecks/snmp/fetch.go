@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// snmpSession is the subset of GoSNMP operations used to poll a device.
+type snmpSession interface {
+	Connect() error
+	Close() error
+	Get(oids []string) (*gosnmp.SnmpPacket, error)
+	GetBulk(oids []string, maxRepetitions uint8) (*gosnmp.SnmpPacket, error)
+	GetNext(oids []string) (*gosnmp.SnmpPacket, error)
+	Version() gosnmp.SnmpVersion
+}
+
+type gosnmpSession struct {
+	params *gosnmp.GoSNMP
+}
+
+func (s *gosnmpSession) Connect() error {
+	return s.params.Connect()
+}
+
+func (s *gosnmpSession) Close() error {
+	return s.params.Conn.Close()
+}
+
+func (s *gosnmpSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	return s.params.Get(oids)
+}
+
+func (s *gosnmpSession) GetBulk(oids []string, maxRepetitions uint8) (*gosnmp.SnmpPacket, error) {
+	return s.params.GetBulk(oids, 0, maxRepetitions)
+}
+
+func (s *gosnmpSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	return s.params.GetNext(oids)
+}
+
+func (s *gosnmpSession) Version() gosnmp.SnmpVersion {
+	return s.params.Version
+}
+
+// for testing purpose
+var newSession = func(params *gosnmp.GoSNMP) snmpSession {
+	return &gosnmpSession{params: params}
+}
+
+// fetchValues fetches scalar OIDs with GET requests and table columns with GETBULK requests
+// (GETNEXT for SNMPv1), querying at most batchSize OIDs per request.
+func fetchValues(session snmpSession, scalarOIDs []string, columnOIDs []string, batchSize int, bulkMaxRepetitions uint8) (*valueStore, error) {
+	values := newValueStore()
+
+	for _, batch := range createBatches(scalarOIDs, batchSize) {
+		if err := fetchScalarValues(session, batch, values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, batch := range createBatches(columnOIDs, batchSize) {
+		if err := fetchColumnValues(session, batch, bulkMaxRepetitions, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func fetchScalarValues(session snmpSession, oids []string, values *valueStore) error {
+	packet, err := session.Get(oids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch scalar OIDs: %s", err)
+	}
+	if packet.Error != gosnmp.NoError {
+		// SNMPv1 agents fail the whole request if one of the OIDs doesn't exist.
+		log.Debugf("Failed to fetch scalar OIDs %v: error status %v at index %d", oids, packet.Error, packet.ErrorIndex)
+		return nil
+	}
+
+	for _, pdu := range packet.Variables {
+		if value, ok := newSnmpValue(pdu); ok {
+			values.scalarValues[normalizeOID(pdu.Name)] = value
+		}
+	}
+	return nil
+}
+
+// fetchColumnValues walks the given table columns simultaneously, until every column has been fully read.
+func fetchColumnValues(session snmpSession, columns []string, bulkMaxRepetitions uint8, values *valueStore) error {
+	// Last OID read for each column still being walked.
+	nextOIDs := make(map[string]string, len(columns))
+	for _, column := range columns {
+		nextOIDs[column] = column
+		if _, ok := values.columnValues[column]; !ok {
+			values.columnValues[column] = make(map[string]snmpValue)
+		}
+	}
+
+	remaining := columns
+	for len(remaining) > 0 {
+		requestOIDs := make([]string, 0, len(remaining))
+		for _, column := range remaining {
+			requestOIDs = append(requestOIDs, nextOIDs[column])
+		}
+
+		var packet *gosnmp.SnmpPacket
+		var err error
+		if session.Version() == gosnmp.Version1 {
+			packet, err = session.GetNext(requestOIDs)
+		} else {
+			packet, err = session.GetBulk(requestOIDs, bulkMaxRepetitions)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch table columns: %s", err)
+		}
+		if packet.Error != gosnmp.NoError {
+			// SNMPv1 agents return an error when walking past the end of the MIB.
+			log.Debugf("Failed to fetch table columns %v: error status %v at index %d", requestOIDs, packet.Error, packet.ErrorIndex)
+			break
+		}
+
+		// Responses hold the next OID of each requested column, repeated up to bulkMaxRepetitions times.
+		done := make(map[string]bool, len(remaining))
+		advanced := make(map[string]bool, len(remaining))
+		for i, pdu := range packet.Variables {
+			column := remaining[i%len(remaining)]
+			if done[column] {
+				continue
+			}
+
+			oid := normalizeOID(pdu.Name)
+			if pdu.Type == gosnmp.EndOfMibView || !strings.HasPrefix(oid, column+".") || oid == nextOIDs[column] {
+				// We walked past the end of the column.
+				done[column] = true
+				continue
+			}
+
+			if value, ok := newSnmpValue(pdu); ok {
+				values.columnValues[column][strings.TrimPrefix(oid, column+".")] = value
+			}
+			nextOIDs[column] = oid
+			advanced[column] = true
+		}
+
+		next := make([]string, 0, len(remaining))
+		for _, column := range remaining {
+			if !done[column] && advanced[column] {
+				next = append(next, column)
+			}
+		}
+		remaining = next
+	}
+
+	return nil
+}
+
+func createBatches(oids []string, batchSize int) [][]string {
+	var batches [][]string
+	for batchSize < len(oids) {
+		oids, batches = oids[batchSize:], append(batches, oids[:batchSize])
+	}
+	if len(oids) > 0 {
+		batches = append(batches, oids)
+	}
+	return batches
+}
@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession is an in-memory SNMP agent serving a fixed set of OIDs.
+type fakeSession struct {
+	version  gosnmp.SnmpVersion
+	pdus     map[string]gosnmp.SnmpPDU
+	oids     []string
+	requests map[string]int
+}
+
+func newFakeSession(version gosnmp.SnmpVersion, pdus ...gosnmp.SnmpPDU) *fakeSession {
+	s := &fakeSession{
+		version:  version,
+		pdus:     make(map[string]gosnmp.SnmpPDU),
+		requests: make(map[string]int),
+	}
+	for _, pdu := range pdus {
+		s.pdus[pdu.Name] = gosnmp.SnmpPDU{Name: "." + pdu.Name, Type: pdu.Type, Value: pdu.Value}
+		s.oids = append(s.oids, pdu.Name)
+	}
+	sort.Slice(s.oids, func(i, j int) bool { return compareOIDs(s.oids[i], s.oids[j]) < 0 })
+	return s
+}
+
+func compareOIDs(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		x, _ := strconv.Atoi(aParts[i])
+		y, _ := strconv.Atoi(bParts[i])
+		if x != y {
+			return x - y
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func (s *fakeSession) next(oid string) gosnmp.SnmpPDU {
+	for _, candidate := range s.oids {
+		if compareOIDs(candidate, oid) > 0 {
+			return s.pdus[candidate]
+		}
+	}
+	return gosnmp.SnmpPDU{Name: "." + oid, Type: gosnmp.EndOfMibView}
+}
+
+func (s *fakeSession) Connect() error { return nil }
+func (s *fakeSession) Close() error   { return nil }
+
+func (s *fakeSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	s.requests["get"]++
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		pdu, ok := s.pdus[oid]
+		if !ok {
+			pdu = gosnmp.SnmpPDU{Name: "." + oid, Type: gosnmp.NoSuchObject}
+		}
+		packet.Variables = append(packet.Variables, pdu)
+	}
+	return packet, nil
+}
+
+func (s *fakeSession) GetBulk(oids []string, maxRepetitions uint8) (*gosnmp.SnmpPacket, error) {
+	s.requests["getbulk"]++
+	packet := &gosnmp.SnmpPacket{}
+	current := append([]string{}, oids...)
+	for i := 0; i < int(maxRepetitions); i++ {
+		for j, oid := range current {
+			pdu := s.next(oid)
+			packet.Variables = append(packet.Variables, pdu)
+			current[j] = normalizeOID(pdu.Name)
+		}
+	}
+	return packet, nil
+}
+
+func (s *fakeSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	s.requests["getnext"]++
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		packet.Variables = append(packet.Variables, s.next(oid))
+	}
+	return packet, nil
+}
+
+func (s *fakeSession) Version() gosnmp.SnmpVersion {
+	return s.version
+}
+
+var testInterfacesPDUs = []gosnmp.SnmpPDU{
+	// sysName
+	{Name: "1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("router-1")},
+	// tcpActiveOpens
+	{Name: "1.3.6.1.2.1.6.5.0", Type: gosnmp.Counter32, Value: uint(42)},
+	// ifInErrors
+	{Name: "1.3.6.1.2.1.2.2.1.14.1", Type: gosnmp.Counter32, Value: uint(10)},
+	{Name: "1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(20)},
+	{Name: "1.3.6.1.2.1.2.2.1.14.3", Type: gosnmp.Counter32, Value: uint(30)},
+	// ifOutErrors
+	{Name: "1.3.6.1.2.1.2.2.1.20.1", Type: gosnmp.Counter32, Value: uint(1)},
+	{Name: "1.3.6.1.2.1.2.2.1.20.2", Type: gosnmp.Counter32, Value: uint(2)},
+	{Name: "1.3.6.1.2.1.2.2.1.20.3", Type: gosnmp.Counter32, Value: uint(3)},
+	// ifName
+	{Name: "1.3.6.1.2.1.31.1.1.1.1.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+	{Name: "1.3.6.1.2.1.31.1.1.1.1.2", Type: gosnmp.OctetString, Value: []byte("eth1")},
+	{Name: "1.3.6.1.2.1.31.1.1.1.1.3", Type: gosnmp.OctetString, Value: []byte("eth2")},
+	// ifHCInOctets, following ifName in the MIB
+	{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(1000)},
+}
+
+func TestFetchValues(t *testing.T) {
+	for _, version := range []gosnmp.SnmpVersion{gosnmp.Version1, gosnmp.Version2c} {
+		session := newFakeSession(version, testInterfacesPDUs...)
+
+		values, err := fetchValues(
+			session,
+			[]string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.2.1.6.5.0", "1.3.6.1.2.1.1.1.0"},
+			[]string{"1.3.6.1.2.1.2.2.1.14", "1.3.6.1.2.1.2.2.1.20", "1.3.6.1.2.1.31.1.1.1.1"},
+			2,
+			2,
+		)
+		require.NoError(t, err)
+
+		assert.Len(t, values.scalarValues, 2)
+		assert.Equal(t, "router-1", values.scalarValues["1.3.6.1.2.1.1.5.0"].toString())
+		assert.NotContains(t, values.scalarValues, "1.3.6.1.2.1.1.1.0")
+
+		assert.Len(t, values.columnValues, 3)
+		for _, column := range []string{"1.3.6.1.2.1.2.2.1.14", "1.3.6.1.2.1.2.2.1.20", "1.3.6.1.2.1.31.1.1.1.1"} {
+			assert.Len(t, values.columnValues[column], 3, column)
+		}
+		outErrors, err := values.columnValues["1.3.6.1.2.1.2.2.1.20"]["2"].toFloat64()
+		assert.NoError(t, err)
+		assert.Equal(t, float64(2), outErrors)
+		assert.Equal(t, "eth2", values.columnValues["1.3.6.1.2.1.31.1.1.1.1"]["3"].toString())
+
+		// 3 scalar OIDs in batches of 2
+		assert.Equal(t, 2, session.requests["get"])
+		if version == gosnmp.Version1 {
+			assert.Equal(t, 0, session.requests["getbulk"])
+			assert.NotZero(t, session.requests["getnext"])
+		} else {
+			assert.Equal(t, 0, session.requests["getnext"])
+			assert.NotZero(t, session.requests["getbulk"])
+		}
+	}
+}
+
+func TestFetchEmptyColumn(t *testing.T) {
+	session := newFakeSession(gosnmp.Version2c, testInterfacesPDUs...)
+
+	values, err := fetchValues(session, nil, []string{"1.3.6.1.2.1.2.2.1.15", "1.3.6.1.4.1.9.9"}, 10, 10)
+	require.NoError(t, err)
+	assert.Empty(t, values.columnValues["1.3.6.1.2.1.2.2.1.15"])
+	assert.Empty(t, values.columnValues["1.3.6.1.4.1.9.9"])
+}
+
+func TestCreateBatches(t *testing.T) {
+	assert.Equal(t, [][]string(nil), createBatches(nil, 2))
+	assert.Equal(t, [][]string{{"a", "b"}}, createBatches([]string{"a", "b"}, 2))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, createBatches([]string{"a", "b", "c"}, 2))
+}
+
+func TestSnmpValue(t *testing.T) {
+	for _, tc := range []struct {
+		pdu       gosnmp.SnmpPDU
+		float     float64
+		str       string
+		isCounter bool
+	}{
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -12}, -12, "-12", false},
+		{gosnmp.SnmpPDU{Type: gosnmp.Gauge32, Value: uint(12)}, 12, "12", false},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter32, Value: uint(12)}, 12, "12", true},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1 << 40)}, 1 << 40, "1099511627776", true},
+		{gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(1000)}, 1000, "1000", false},
+		{gosnmp.SnmpPDU{Type: gosnmp.OpaqueFloat, Value: float32(1.5)}, 1.5, "1.5", false},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte(" 98.5 ")}, 98.5, " 98.5 ", false},
+		{gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1745"}, 0, "1.3.6.1.4.1.9.1.1745", false},
+	} {
+		value, ok := newSnmpValue(tc.pdu)
+		require.True(t, ok)
+		assert.Equal(t, tc.str, value.toString())
+		assert.Equal(t, tc.isCounter, value.isCounter())
+		if tc.pdu.Type != gosnmp.ObjectIdentifier {
+			f, err := value.toFloat64()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.float, f)
+		}
+	}
+
+	for _, pduType := range []gosnmp.Asn1BER{gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView} {
+		_, ok := newSnmpValue(gosnmp.SnmpPDU{Type: pduType})
+		assert.False(t, ok)
+	}
+
+	value, _ := newSnmpValue(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("eth0")})
+	_, err := value.toFloat64()
+	assert.Error(t, err)
+}
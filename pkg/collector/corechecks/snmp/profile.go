@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// profileConfig references a profile definition file from the init configuration.
+type profileConfig struct {
+	DefinitionFile string `yaml:"definition_file"`
+}
+
+// profileDefinition is the content of a profile definition file, using the same format as the Python SNMP integration.
+type profileDefinition struct {
+	Extends     []string          `yaml:"extends"`
+	SysObjectID stringList        `yaml:"sysobjectid"`
+	Metrics     []metricsConfig   `yaml:"metrics"`
+	MetricTags  []metricTagConfig `yaml:"metric_tags"`
+}
+
+type profileDefinitionMap map[string]profileDefinition
+
+var (
+	// Default profiles are shared by all check instances that don't define their own profiles.
+	defaultProfilesMu sync.Mutex
+	defaultProfiles   profileDefinitionMap
+)
+
+// getProfilesRoot returns the directory containing the default profiles and the base profiles they extend.
+func getProfilesRoot() string {
+	return filepath.Join(config.Datadog.GetString("confd_path"), "snmp.d", "profiles")
+}
+
+// loadProfiles loads the profiles defined in the init configuration, or the default profiles if none is defined.
+func loadProfiles(profiles map[string]profileConfig) (profileDefinitionMap, error) {
+	if len(profiles) == 0 {
+		return loadDefaultProfiles()
+	}
+	return readProfiles(profiles)
+}
+
+func loadDefaultProfiles() (profileDefinitionMap, error) {
+	defaultProfilesMu.Lock()
+	defer defaultProfilesMu.Unlock()
+
+	if defaultProfiles != nil {
+		return defaultProfiles, nil
+	}
+
+	profiles, err := listDefaultProfiles(getProfilesRoot())
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := readProfiles(profiles)
+	if err != nil {
+		return nil, err
+	}
+	defaultProfiles = definitions
+	return defaultProfiles, nil
+}
+
+// listDefaultProfiles lists the profile files of a directory. Files whose name starts
+// with an underscore are base profiles meant to be extended, and are not profiles themselves.
+func listDefaultProfiles(root string) (map[string]profileConfig, error) {
+	profiles := make(map[string]profileConfig)
+
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Debugf("No default SNMP profiles found in %s", root)
+			return profiles, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, "_") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		profiles[strings.TrimSuffix(name, ".yaml")] = profileConfig{DefinitionFile: name}
+	}
+	return profiles, nil
+}
+
+func readProfiles(profiles map[string]profileConfig) (profileDefinitionMap, error) {
+	definitions := make(profileDefinitionMap, len(profiles))
+	for name, profile := range profiles {
+		definition, err := readProfileDefinition(profile.DefinitionFile, map[string]bool{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load profile %q: %s", name, err)
+		}
+		definitions[name] = *definition
+	}
+	return definitions, nil
+}
+
+// readProfileDefinition reads a profile definition file, merging the metrics and
+// metric tags of the profiles it extends.
+func readProfileDefinition(definitionFile string, extendedFiles map[string]bool) (*profileDefinition, error) {
+	filePath := resolveProfilePath(definitionFile)
+	if extendedFiles[filePath] {
+		return nil, fmt.Errorf("cyclic profile extension of %s", filePath)
+	}
+	extendedFiles[filePath] = true
+
+	buf, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var definition profileDefinition
+	if err := yaml.Unmarshal(buf, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", filePath, err)
+	}
+
+	for _, metric := range definition.Metrics {
+		if err := validateMetric(metric); err != nil {
+			return nil, fmt.Errorf("invalid metric in %s: %s", filePath, err)
+		}
+	}
+	for _, tag := range definition.MetricTags {
+		if err := validateGlobalMetricTag(tag); err != nil {
+			return nil, fmt.Errorf("invalid metric tag in %s: %s", filePath, err)
+		}
+	}
+
+	for _, extended := range definition.Extends {
+		base, err := readProfileDefinition(extended, extendedFiles)
+		if err != nil {
+			return nil, err
+		}
+		definition.Metrics = append(definition.Metrics, base.Metrics...)
+		definition.MetricTags = append(definition.MetricTags, base.MetricTags...)
+	}
+	definition.Extends = nil
+
+	return &definition, nil
+}
+
+func resolveProfilePath(definitionFile string) string {
+	if filepath.IsAbs(definitionFile) {
+		return definitionFile
+	}
+	return filepath.Join(getProfilesRoot(), definitionFile)
+}
+
+// matchSysObjectID returns the name of the profile whose sysobjectid pattern matches the given
+// sysObjectID most specifically. Patterns use the same wildcards as the Python integration, eg. `1.3.6.1.4.1.9.1.*`.
+func (m profileDefinitionMap) matchSysObjectID(sysObjectID string) (string, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	// Sort profiles so that ties are resolved consistently.
+	sort.Strings(names)
+
+	matchedProfile := ""
+	matchedPattern := ""
+	for _, name := range names {
+		for _, pattern := range m[name].SysObjectID {
+			matched, err := path.Match(pattern, sysObjectID)
+			if err != nil {
+				return "", fmt.Errorf("invalid sysobjectid pattern %q in profile %q: %s", pattern, name, err)
+			}
+			if matched && len(pattern) > len(matchedPattern) {
+				matchedProfile = name
+				matchedPattern = pattern
+			}
+		}
+	}
+
+	if matchedProfile == "" {
+		return "", fmt.Errorf("no profile matches sysObjectID %s", sysObjectID)
+	}
+	return matchedProfile, nil
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// setupProfiles makes the profiles of the given configuration directory the default profiles.
+func setupProfiles(t *testing.T, confdPath string) {
+	previousConfdPath := config.Datadog.GetString("confd_path")
+	config.Datadog.Set("confd_path", confdPath)
+	defaultProfiles = nil
+
+	t.Cleanup(func() {
+		config.Datadog.Set("confd_path", previousConfdPath)
+		defaultProfiles = nil
+	})
+}
+
+func TestLoadDefaultProfiles(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	profiles, err := loadProfiles(nil)
+	require.NoError(t, err)
+
+	// Base profiles are only used through `extends`.
+	assert.Len(t, profiles, 2)
+
+	router := profiles["generic-router"]
+	assert.Equal(t, stringList{"1.3.6.1.4.1.*"}, router.SysObjectID)
+	require.Len(t, router.Metrics, 2)
+	assert.Equal(t, "tcpActiveOpens", router.Metrics[0].scalarSymbol().Name)
+	assert.Equal(t, "ifTable", router.Metrics[1].Table.Name)
+	require.Len(t, router.MetricTags, 1)
+	assert.Equal(t, "snmp_host", router.MetricTags[0].Tag)
+
+	nexus := profiles["cisco-nexus"]
+	assert.Equal(t, stringList{"1.3.6.1.4.1.9.12.3.1.3.*", "1.3.6.1.4.1.9.12.3.1.4.*"}, nexus.SysObjectID)
+	require.Len(t, nexus.Metrics, 1)
+	require.Len(t, nexus.MetricTags, 1)
+}
+
+func TestLoadConfiguredProfiles(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	absolutePath, err := filepath.Abs("testdata/conf.d/snmp.d/profiles/cisco-nexus.yaml")
+	require.NoError(t, err)
+
+	profiles, err := loadProfiles(map[string]profileConfig{
+		"router": {DefinitionFile: "generic-router.yaml"},
+		"nexus":  {DefinitionFile: absolutePath},
+	})
+	require.NoError(t, err)
+	assert.Len(t, profiles, 2)
+	assert.Len(t, profiles["router"].Metrics, 2)
+	assert.Len(t, profiles["nexus"].Metrics, 1)
+
+	_, err = loadProfiles(map[string]profileConfig{"missing": {DefinitionFile: "missing.yaml"}})
+	assert.Error(t, err)
+}
+
+func TestLoadCyclicProfiles(t *testing.T) {
+	setupProfiles(t, "testdata/cyclic")
+
+	_, err := loadProfiles(nil)
+	assert.Error(t, err)
+}
+
+func TestMatchSysObjectID(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+
+	profiles, err := loadProfiles(nil)
+	require.NoError(t, err)
+
+	for sysObjectID, expected := range map[string]string{
+		"1.3.6.1.4.1.9.12.3.1.3.1812": "cisco-nexus",
+		"1.3.6.1.4.1.9.12.3.1.4.1":    "cisco-nexus",
+		"1.3.6.1.4.1.9.1.1745":        "generic-router",
+		"1.3.6.1.4.1.3375.2.1.3.4.43": "generic-router",
+	} {
+		profile, err := profiles.matchSysObjectID(sysObjectID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, profile, sysObjectID)
+	}
+
+	_, err = profiles.matchSysObjectID("1.3.6.1.2.1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Metric types that can be set with `forced_type`, using the same names as the Python SNMP integration.
+const (
+	forcedTypeGauge                 = "gauge"
+	forcedTypeCounter               = "counter"
+	forcedTypeMonotonicCount        = "monotonic_count"
+	forcedTypeMonotonicCountAndRate = "monotonic_count_and_rate"
+)
+
+const metricPrefix = "snmp."
+
+// collectOIDs returns the scalar and column OIDs needed to report the given metrics and tags.
+func collectOIDs(metrics []metricsConfig, metricTags []metricTagConfig) ([]string, []string) {
+	var scalarOIDs, columnOIDs []string
+	seen := make(map[string]bool)
+	add := func(oids *[]string, oid string) {
+		oid = normalizeOID(oid)
+		if !seen[oid] {
+			seen[oid] = true
+			*oids = append(*oids, oid)
+		}
+	}
+
+	for _, tag := range metricTags {
+		add(&scalarOIDs, tag.OID)
+	}
+	for _, metric := range metrics {
+		if metric.isScalar() {
+			add(&scalarOIDs, metric.scalarSymbol().OID)
+			continue
+		}
+		for _, symbol := range metric.Symbols {
+			add(&columnOIDs, symbol.OID)
+		}
+		for _, tag := range metric.MetricTags {
+			if tag.Column.OID != "" {
+				add(&columnOIDs, tag.Column.OID)
+			}
+		}
+	}
+
+	return scalarOIDs, columnOIDs
+}
+
+// getGlobalTags returns the tags built from scalar values, applied to all metrics of a device.
+func getGlobalTags(metricTags []metricTagConfig, values *valueStore) []string {
+	var tags []string
+	for _, tag := range metricTags {
+		value, ok := values.scalarValues[normalizeOID(tag.OID)]
+		if !ok {
+			log.Debugf("No value for metric tag %s (OID %s)", tag.Tag, tag.OID)
+			continue
+		}
+		tags = append(tags, tag.Tag+":"+value.toString())
+	}
+	return tags
+}
+
+// getRowTags returns the tags of a table row, built from its index or from other columns of the row.
+func getRowTags(metricTags []metricTagConfig, index string, values *valueStore) []string {
+	var tags []string
+	for _, tag := range metricTags {
+		if tag.Index > 0 {
+			components := strings.Split(index, ".")
+			if int(tag.Index) > len(components) {
+				log.Debugf("Index %s has no component %d for tag %s", index, tag.Index, tag.Tag)
+				continue
+			}
+			tags = append(tags, tag.Tag+":"+components[tag.Index-1])
+			continue
+		}
+
+		value, ok := values.columnValues[normalizeOID(tag.Column.OID)][index]
+		if !ok {
+			log.Debugf("No value for tag %s at index %s (column %s)", tag.Tag, index, tag.Column.OID)
+			continue
+		}
+		tags = append(tags, tag.Tag+":"+value.toString())
+	}
+	return tags
+}
+
+// reportMetrics submits the values of the given metrics.
+func reportMetrics(sender aggregator.Sender, metrics []metricsConfig, values *valueStore, tags []string) {
+	for _, metric := range metrics {
+		if metric.isScalar() {
+			symbol := metric.scalarSymbol()
+			value, ok := values.scalarValues[normalizeOID(symbol.OID)]
+			if !ok {
+				log.Debugf("No value for metric %s (OID %s)", symbol.Name, symbol.OID)
+				continue
+			}
+			sendMetric(sender, symbol.Name, value, metric.ForcedType, tags)
+			continue
+		}
+
+		for _, symbol := range metric.Symbols {
+			for index, value := range values.columnValues[normalizeOID(symbol.OID)] {
+				rowTags := append(copyTags(tags), getRowTags(metric.MetricTags, index, values)...)
+				sendMetric(sender, symbol.Name, value, metric.ForcedType, rowTags)
+			}
+		}
+	}
+}
+
+func sendMetric(sender aggregator.Sender, name string, value snmpValue, forcedType string, tags []string) {
+	floatValue, err := value.toFloat64()
+	if err != nil {
+		log.Debugf("Value of metric %s cannot be submitted: %s", name, err)
+		return
+	}
+
+	if forcedType == "" {
+		forcedType = forcedTypeGauge
+		if value.isCounter() {
+			forcedType = forcedTypeCounter
+		}
+	}
+
+	metricName := metricPrefix + name
+	switch forcedType {
+	case forcedTypeGauge:
+		sender.Gauge(metricName, floatValue, "", tags)
+	case forcedTypeCounter:
+		sender.Rate(metricName, floatValue, "", tags)
+	case forcedTypeMonotonicCount:
+		sender.MonotonicCount(metricName, floatValue, "", tags)
+	case forcedTypeMonotonicCountAndRate:
+		sender.MonotonicCount(metricName, floatValue, "", tags)
+		sender.Rate(metricName+".rate", floatValue, "", tags)
+	}
+}
+
+func copyTags(tags []string) []string {
+	return append(make([]string, 0, len(tags)), tags...)
+}
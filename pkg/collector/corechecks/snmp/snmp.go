@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	snmpCheckName = "snmp"
+
+	sysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+)
+
+// SNMPCheck polls a single SNMP device. Instances are usually scheduled by the SNMP autodiscovery listener,
+// and run alongside the other checks in the collector runners, so that many devices are polled concurrently.
+type SNMPCheck struct {
+	core.CheckBase
+	config *snmpConfig
+}
+
+// Configure parses the check configuration and initializes the check
+func (c *SNMPCheck) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	config := new(snmpConfig)
+	if err := config.parse(data, initConfig); err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+
+	return c.CommonConfigure(data, source)
+}
+
+// Run polls the device and submits its metrics
+func (c *SNMPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	tags, err := c.collect(sender)
+
+	serviceCheckStatus := metrics.ServiceCheckOK
+	serviceCheckMessage := ""
+	if err != nil {
+		serviceCheckStatus = metrics.ServiceCheckCritical
+		serviceCheckMessage = err.Error()
+	}
+	sender.ServiceCheck("snmp.can_check", serviceCheckStatus, "", tags, serviceCheckMessage)
+	sender.Gauge("snmp.devices_monitored", 1, "", tags)
+
+	sender.Commit()
+
+	return err
+}
+
+// collect fetches the values of the configured metrics and submits them. It returns the tags of the device.
+func (c *SNMPCheck) collect(sender aggregator.Sender) ([]string, error) {
+	tags := copyTags(c.config.tags)
+
+	session := newSession(c.config.params)
+	if err := session.Connect(); err != nil {
+		return tags, fmt.Errorf("failed to connect to %s: %s", c.config.ipAddress, err)
+	}
+	defer session.Close() //nolint:errcheck
+
+	if c.config.profile == "" && len(c.config.metrics) == 0 {
+		profile, err := c.detectProfile(session)
+		if err != nil {
+			return tags, err
+		}
+		log.Infof("Detected profile %q for device %s", profile, c.config.ipAddress)
+		c.config.profile = profile
+	}
+
+	metrics := c.config.metrics
+	metricTags := c.config.metricTags
+	if c.config.profile != "" {
+		profile := c.config.profiles[c.config.profile]
+		metrics = append(copyMetrics(metrics), profile.Metrics...)
+		metricTags = append(append([]metricTagConfig{}, metricTags...), profile.MetricTags...)
+		tags = append(tags, "snmp_profile:"+c.config.profile)
+	}
+
+	scalarOIDs, columnOIDs := collectOIDs(metrics, metricTags)
+	values, err := fetchValues(session, scalarOIDs, columnOIDs, c.config.oidBatchSize, c.config.bulkMaxRepetitions)
+	if err != nil {
+		return tags, err
+	}
+
+	tags = append(tags, getGlobalTags(metricTags, values)...)
+	reportMetrics(sender, metrics, values, tags)
+
+	return tags, nil
+}
+
+// detectProfile returns the profile matching the sysObjectID of the device.
+func (c *SNMPCheck) detectProfile(session snmpSession) (string, error) {
+	values, err := fetchValues(session, []string{sysObjectIDOID}, nil, 1, 0)
+	if err != nil {
+		return "", err
+	}
+	sysObjectID, ok := values.scalarValues[sysObjectIDOID]
+	if !ok {
+		return "", fmt.Errorf("no sysObjectID reported by %s, cannot detect its profile", c.config.ipAddress)
+	}
+	return c.config.profiles.matchSysObjectID(sysObjectID.toString())
+}
+
+func copyMetrics(metrics []metricsConfig) []metricsConfig {
+	return append(make([]metricsConfig, 0, len(metrics)), metrics...)
+}
+
+func snmpFactory() check.Check {
+	return &SNMPCheck{
+		CheckBase: core.NewCheckBase(snmpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(snmpCheckName, snmpFactory)
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func setupFakeSession(t *testing.T, session snmpSession) {
+	previousNewSession := newSession
+	newSession = func(*gosnmp.GoSNMP) snmpSession { return session }
+	t.Cleanup(func() { newSession = previousNewSession })
+}
+
+func TestRunWithDetectedProfile(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+	pdus := append([]gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1745"},
+	}, testInterfacesPDUs...)
+	setupFakeSession(t, newFakeSession(gosnmp.Version2c, pdus...))
+
+	snmpCheck := snmpFactory().(*SNMPCheck)
+	rawInstance := []byte("ip_address: 1.2.3.4\ncommunity_string: public\ntags: [env:test]")
+
+	// the custom tags are set on the sender when configuring the check
+	sender := mocksender.NewMockSender(check.BuildID(snmpCheck.String(), rawInstance, []byte(``)))
+	sender.SetupAcceptAll()
+
+	err := snmpCheck.Configure(rawInstance, []byte(``), "test")
+	require.NoError(t, err)
+
+	err = snmpCheck.Run()
+	require.NoError(t, err)
+
+	tags := []string{"snmp_device:1.2.3.4", "env:test", "snmp_profile:generic-router", "snmp_host:router-1"}
+	sender.AssertMetric(t, "MonotonicCount", "snmp.tcpActiveOpens", 42, "", tags)
+	for index, name := range []string{"eth0", "eth1", "eth2"} {
+		rowTags := append(append([]string{}, tags...), "interface:"+name, "interface_index:"+strconv.Itoa(index+1))
+		sender.AssertMetric(t, "Rate", "snmp.ifInErrors", float64(10*(index+1)), "", rowTags)
+		sender.AssertMetric(t, "Rate", "snmp.ifOutErrors", float64(index+1), "", rowTags)
+	}
+	sender.AssertMetric(t, "Gauge", "snmp.devices_monitored", 1, "", tags)
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertNumberOfCalls(t, "Rate", 6)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+
+	// The detected profile is kept for the following runs.
+	assert.Equal(t, "generic-router", snmpCheck.config.profile)
+}
+
+func TestRunWithInstanceMetrics(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+	setupFakeSession(t, newFakeSession(gosnmp.Version2c, testInterfacesPDUs...))
+
+	snmpCheck := snmpFactory().(*SNMPCheck)
+	err := snmpCheck.Configure([]byte(`
+ip_address: 1.2.3.4
+community_string: public
+metrics:
+  - symbol:
+      OID: 1.3.6.1.2.1.6.5.0
+      name: tcpActiveOpens
+    forced_type: monotonic_count_and_rate
+  - table:
+      OID: 1.3.6.1.2.1.31.1.1
+      name: ifXTable
+    symbols:
+      - OID: 1.3.6.1.2.1.31.1.1.1.6
+        name: ifHCInOctets
+    metric_tags:
+      - tag: interface
+        column:
+          OID: 1.3.6.1.2.1.31.1.1.1.1
+          name: ifName
+`), []byte(``), "test")
+	require.NoError(t, err)
+
+	sender := mocksender.NewMockSender(snmpCheck.ID())
+	sender.SetupAcceptAll()
+
+	err = snmpCheck.Run()
+	require.NoError(t, err)
+
+	tags := []string{"snmp_device:1.2.3.4"}
+	sender.AssertMetric(t, "MonotonicCount", "snmp.tcpActiveOpens", 42, "", tags)
+	sender.AssertMetric(t, "Rate", "snmp.tcpActiveOpens.rate", 42, "", tags)
+	sender.AssertMetric(t, "Rate", "snmp.ifHCInOctets", 1000, "", append(tags, "interface:eth0"))
+	sender.AssertNumberOfCalls(t, "Rate", 2)
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckOK, "", tags, "")
+	assert.Equal(t, "", snmpCheck.config.profile)
+}
+
+func TestRunWithoutMatchingProfile(t *testing.T) {
+	setupProfiles(t, "testdata/conf.d")
+	setupFakeSession(t, newFakeSession(gosnmp.Version2c,
+		gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.2.1"},
+	))
+
+	snmpCheck := snmpFactory().(*SNMPCheck)
+	err := snmpCheck.Configure([]byte("ip_address: 1.2.3.4\ncommunity_string: public"), []byte(``), "test")
+	require.NoError(t, err)
+
+	sender := mocksender.NewMockSender(snmpCheck.ID())
+	sender.SetupAcceptAll()
+
+	err = snmpCheck.Run()
+	assert.Error(t, err)
+
+	tags := []string{"snmp_device:1.2.3.4"}
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckCritical, "", tags, err.Error())
+	sender.AssertMetric(t, "Gauge", "snmp.devices_monitored", 1, "", tags)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
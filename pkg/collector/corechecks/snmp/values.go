@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+)
+
+// snmpValue is a value fetched from a device.
+type snmpValue struct {
+	valueType gosnmp.Asn1BER
+	value     interface{}
+}
+
+// valueStore holds the values fetched from a device during a check run.
+type valueStore struct {
+	// scalarValues maps OIDs to values.
+	scalarValues map[string]snmpValue
+	// columnValues maps column OIDs to their values, indexed by row index.
+	columnValues map[string]map[string]snmpValue
+}
+
+func newValueStore() *valueStore {
+	return &valueStore{
+		scalarValues: make(map[string]snmpValue),
+		columnValues: make(map[string]map[string]snmpValue),
+	}
+}
+
+// newSnmpValue converts a PDU to a value. It returns false if the PDU holds no value.
+func newSnmpValue(pdu gosnmp.SnmpPDU) (snmpValue, bool) {
+	switch pdu.Type {
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.UnknownType:
+		return snmpValue{}, false
+	}
+	if pdu.Value == nil {
+		return snmpValue{}, false
+	}
+	return snmpValue{valueType: pdu.Type, value: pdu.Value}, true
+}
+
+// isCounter returns whether the value is a counter, which is submitted as a rate by default.
+func (v snmpValue) isCounter() bool {
+	return v.valueType == gosnmp.Counter32 || v.valueType == gosnmp.Counter64
+}
+
+// toFloat64 returns the numeric representation of the value.
+// Strings are supported as long as they contain a number, as some devices report numbers as strings.
+func (v snmpValue) toFloat64() (float64, error) {
+	switch value := v.value.(type) {
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case []byte:
+		return strconv.ParseFloat(strings.TrimSpace(string(value)), 64)
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		f, _ := new(big.Float).SetInt(gosnmp.ToBigInt(value)).Float64()
+		return f, nil
+	}
+	return 0, fmt.Errorf("unsupported value type %T", v.value)
+}
+
+// toString returns the string representation of the value, used for tags.
+func (v snmpValue) toString() string {
+	switch value := v.value.(type) {
+	case []byte:
+		return string(value)
+	case string:
+		// Object identifiers are returned with a leading dot.
+		if v.valueType == gosnmp.ObjectIdentifier {
+			return normalizeOID(value)
+		}
+		return value
+	}
+	return fmt.Sprintf("%v", v.value)
+}
+
+// normalizeOID returns an OID without its leading dot, as GoSNMP returns OIDs with one.
+func normalizeOID(oid string) string {
+	return strings.TrimPrefix(oid, ".")
+}
@@ -26,9 +26,22 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.obfuscation.sql_exec_plan_normalize.obfuscate_sql_values")
 	config.SetKnown("apm_config.obfuscation.http.remove_query_string")
 	config.SetKnown("apm_config.obfuscation.http.remove_paths_with_digits")
+	config.SetKnown("apm_config.obfuscation.http.obfuscate_sql_values")
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.obfuscation.graphql.enabled")
+	config.SetKnown("apm_config.obfuscation.kafka.enabled")
+	config.SetKnown("apm_config.obfuscation.amqp.enabled")
+	config.SetKnown("apm_config.obfuscation.dynamodb.enabled")
+	config.SetKnown("apm_config.extra_aggregators")
+	config.SetKnown("apm_config.extra_aggregators_max_cardinality")
+	config.SetKnown("apm_config.tail_sampling.enabled")
+	config.SetKnown("apm_config.tail_sampling.decision_wait_seconds")
+	config.SetKnown("apm_config.tail_sampling.max_buffered_spans")
+	config.SetKnown("apm_config.tail_sampling.keep_errors")
+	config.SetKnown("apm_config.tail_sampling.latency_threshold_ms")
+	config.SetKnown("apm_config.tail_sampling.rules")
 	config.SetKnown("apm_config.extra_sample_rate")
 	config.SetKnown("apm_config.dd_agent_bin")
 	config.SetKnown("apm_config.trace_writer.connection_limit")
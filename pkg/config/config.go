@@ -133,6 +133,16 @@ type MetricMapping struct {
 	Tags      map[string]string `mapstructure:"tags" json:"tags"`
 }
 
+// DropRule represent a rule dropping tags, or whole metrics, from the metrics received by DogStatsD
+type DropRule struct {
+	Name       string   `mapstructure:"name" json:"name"`
+	Match      string   `mapstructure:"match" json:"match"`
+	MatchType  string   `mapstructure:"match_type" json:"match_type"`
+	MatchTags  []string `mapstructure:"match_tags" json:"match_tags"`
+	DropTags   []string `mapstructure:"drop_tags" json:"drop_tags"`
+	DropMetric bool     `mapstructure:"drop_metric" json:"drop_metric"`
+}
+
 // Warnings represent the warnings in the config
 type Warnings struct {
 	TraceMallocEnabledWithPy2 bool
@@ -239,6 +249,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("secret_backend_output_max_size", secrets.SecretBackendOutputMaxSize)
 	config.BindEnvAndSetDefault("secret_backend_timeout", 5)
 	config.BindEnvAndSetDefault("secret_backend_command_allow_group_exec_perm", false)
+	config.BindEnvAndSetDefault("secret_backend_builtin_backends", []string{})
+	config.BindEnvAndSetDefault("secret_backend_builtin_backends_allow_container_configs", false)
+	config.BindEnvAndSetDefault("secret_backend_vault.address", "")    // Notice: empty means VAULT_ADDR is used
+	config.BindEnvAndSetDefault("secret_backend_vault.token_file", "") // Notice: empty means VAULT_TOKEN is used
+	config.BindEnvAndSetDefault("secret_refresh_interval", 0)          // in seconds, 0 disables the refresh
 
 	// Use to output logs in JSON format
 	config.BindEnvAndSetDefault("log_format_json", false)
@@ -358,7 +373,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_queue_size", 1024)
 
 	config.BindEnvAndSetDefault("dogstatsd_non_local_traffic", false)
-	config.BindEnvAndSetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_socket", "")                // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_tcp_port", 0)               // Notice: 0 means TCP port closed
+	config.BindEnvAndSetDefault("dogstatsd_stream_socket", "")         // Notice: empty means feature disabled
+	config.BindEnvAndSetDefault("dogstatsd_stream_framing", "newline") // "newline" or "length_prefix"
 	config.BindEnvAndSetDefault("dogstatsd_stats_port", 5000)
 	config.BindEnvAndSetDefault("dogstatsd_stats_enable", false)
 	config.BindEnvAndSetDefault("dogstatsd_stats_buffer", 10)
@@ -369,6 +387,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_mapper_cache_size", 1000)
 	config.BindEnvAndSetDefault("dogstatsd_string_interner_size", 4096)
+	// Per-origin (container or pod) limits, 0 means no limit
+	config.BindEnvAndSetDefault("dogstatsd_origin_max_samples_per_second", 0)
+	config.BindEnvAndSetDefault("dogstatsd_origin_max_new_contexts_per_minute", 0)
 	// Enable check for Entity-ID presence when enriching Dogstatsd metrics with tags
 	config.BindEnvAndSetDefault("dogstatsd_entity_id_precedence", false)
 	// Sends Dogstatsd parse errors to the Debug level instead of the Error level
@@ -383,6 +404,15 @@ func InitConfig(config Config) {
 		return mappings
 	})
 
+	_ = config.BindEnv("dogstatsd_drop_rules")
+	config.SetEnvKeyTransformer("dogstatsd_drop_rules", func(in string) interface{} {
+		var rules []DropRule
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"dogstatsd_drop_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
@@ -595,13 +625,24 @@ func InitConfig(config Config) {
 	// This field lets you increase the read timeout to prevent the client from
 	// timing out too early in such a situation. Value in seconds.
 	config.BindEnvAndSetDefault("logs_config.docker_client_read_timeout", 30)
+	// detect multi-line logs automatically for sources without a multi_line processing rule
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
+	// number of lines sampled, seconds to wait for them and ratio of lines that must match
+	// a known format before aggregating multi-line logs automatically
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_sample_size", 500)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection_timeout", 30) // in seconds
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_match_threshold", 0.75)
+	// buffer on disk the payloads that can not be sent over HTTP, 0 means disabled
+	config.BindEnvAndSetDefault("logs_config.disk_buffer_max_size_in_bytes", 0)
+	config.BindEnvAndSetDefault("logs_config.disk_buffer_path", "") // defaults to <logs_config.run_path>/logs_to_retry
 	// Internal Use Only: avoid modifying those configuration parameters, this could lead to unexpected results.
 	config.BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
 	config.BindEnv("logs_config.dd_url") //nolint:errcheck
 	config.BindEnvAndSetDefault("logs_config.use_http", false)
 	config.BindEnvAndSetDefault("logs_config.use_tcp", false)
 	config.BindEnvAndSetDefault("logs_config.use_compression", true)
-	config.BindEnvAndSetDefault("logs_config.compression_level", 6) // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault("logs_config.compression_level", 6)     // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault("logs_config.compression_kind", "gzip") // zstd requires an agent built with zstd
 	config.BindEnvAndSetDefault("logs_config.batch_wait", DefaultBatchWait)
 	config.BindEnvAndSetDefault("logs_config.connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("logs_config.dd_port", 10516)
@@ -680,6 +721,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("telemetry.enabled", false)
 	config.SetKnown("telemetry.checks")
 
+	// Serve the series and sketches of the latest flush in the Prometheus format on the expvar port
+	config.BindEnvAndSetDefault("prometheus_endpoint.enabled", false)
+
 	// Declare other keys that don't have a default/env var.
 	// Mostly, keys we use IsSet() on, because IsSet always returns true if a key has a default.
 	config.SetKnown("metadata_providers")
@@ -773,6 +817,9 @@ func InitConfig(config Config) {
 	config.SetKnown("system_probe_config.windows.driver_buffer_size")
 	config.SetKnown("network_config.enabled")
 	config.SetKnown("network_config.enable_http_monitoring")
+	config.SetKnown("network_config.enable_https_monitoring")
+	config.SetKnown("network_config.enable_protocol_classification")
+	config.SetKnown("network_config.enable_tcp_extended_stats")
 
 	// Network
 	config.BindEnv("network.id") //nolint:errcheck
@@ -991,25 +1038,32 @@ func ResolveSecrets(config Config, origin string) error {
 		config.GetInt("secret_backend_output_max_size"),
 		config.GetBool("secret_backend_command_allow_group_exec_perm"),
 	)
+	secrets.InitBuiltinBackends(config.GetStringSlice("secret_backend_builtin_backends"))
+	secrets.InitVault(
+		config.GetString("secret_backend_vault.address"),
+		config.GetString("secret_backend_vault.token_file"),
+	)
 
-	if config.GetString("secret_backend_command") != "" {
-		// Viper doesn't expose the final location of the file it
-		// loads. Since we are searching for 'datadog.yaml' in multiple
-		// locations we let viper determine the one to use before
-		// updating it.
-		yamlConf, err := yaml.Marshal(config.AllSettings())
-		if err != nil {
-			return fmt.Errorf("unable to marshal configuration to YAML to decrypt secrets: %v", err)
-		}
+	// Viper doesn't expose the final location of the file it
+	// loads. Since we are searching for 'datadog.yaml' in multiple
+	// locations we let viper determine the one to use before
+	// updating it.
+	yamlConf, err := yaml.Marshal(config.AllSettings())
+	if err != nil {
+		return fmt.Errorf("unable to marshal configuration to YAML to decrypt secrets: %v", err)
+	}
 
-		finalYamlConf, err := secrets.Decrypt(yamlConf, origin)
-		if err != nil {
-			return fmt.Errorf("unable to decrypt secret from datadog.yaml: %v", err)
-		}
-		r := bytes.NewReader(finalYamlConf)
-		if err = config.MergeConfigOverride(r); err != nil {
-			return fmt.Errorf("could not update main configuration after decrypting secrets: %v", err)
-		}
+	// Handles using a built-in backend can be decrypted even without secret_backend_command
+	finalYamlConf, err := secrets.Decrypt(yamlConf, origin)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt secret from datadog.yaml: %v", err)
+	}
+	if config.GetString("secret_backend_command") == "" && bytes.Equal(finalYamlConf, yamlConf) {
+		return nil
+	}
+	r := bytes.NewReader(finalYamlConf)
+	if err = config.MergeConfigOverride(r); err != nil {
+		return fmt.Errorf("could not update main configuration after decrypting secrets: %v", err)
 	}
 	return nil
 }
@@ -1272,6 +1326,22 @@ func getDogstatsdMappingProfilesConfig(config Config) ([]MappingProfile, error)
 	return mappings, nil
 }
 
+// GetDogstatsdDropRules returns the rules used by DogStatsD to drop tags and metrics
+func GetDogstatsdDropRules() ([]DropRule, error) {
+	return getDogstatsdDropRulesConfig(Datadog)
+}
+
+func getDogstatsdDropRulesConfig(config Config) ([]DropRule, error) {
+	var rules []DropRule
+	if config.IsSet("dogstatsd_drop_rules") {
+		err := config.UnmarshalKey("dogstatsd_drop_rules", &rules)
+		if err != nil {
+			return []DropRule{}, log.Errorf("Could not parse dogstatsd_drop_rules: %v", err)
+		}
+	}
+	return rules, nil
+}
+
 // IsCLCRunner returns whether the Agent is in cluster check runner mode
 func IsCLCRunner() bool {
 	if !Datadog.GetBool("clc_runner_enabled") {
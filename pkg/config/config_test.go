@@ -921,3 +921,68 @@ func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	mappings, _ := GetDogstatsdMappingProfiles()
 	assert.Equal(t, mappings, expected)
 }
+
+func TestDogstatsdDropRulesOk(t *testing.T) {
+	datadogYaml := `
+dogstatsd_drop_rules:
+  - name: "no_request_id"
+    match: "http.*"
+    drop_tags:
+      - request_id
+      - pod_name
+  - name: "no_debug"
+    match: 'debug\..*'
+    match_type: "regex"
+    match_tags:
+      - "env:dev"
+    drop_metric: true
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	rules, err := getDogstatsdDropRulesConfig(testConfig)
+
+	expectedRules := []DropRule{
+		{
+			Name:     "no_request_id",
+			Match:    "http.*",
+			DropTags: []string{"request_id", "pod_name"},
+		},
+		{
+			Name:       "no_debug",
+			Match:      "debug\\..*",
+			MatchType:  "regex",
+			MatchTags:  []string{"env:dev"},
+			DropMetric: true,
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRules, rules)
+}
+
+func TestDogstatsdDropRulesError(t *testing.T) {
+	datadogYaml := `
+dogstatsd_drop_rules:
+  - abc
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+	rules, err := getDogstatsdDropRulesConfig(testConfig)
+
+	expectedErrorMsg := "Could not parse dogstatsd_drop_rules"
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), expectedErrorMsg)
+	assert.Empty(t, rules)
+}
+
+func TestDogstatsdDropRulesEnv(t *testing.T) {
+	env := "DD_DOGSTATSD_DROP_RULES"
+	err := os.Setenv(env, `[{"name":"no_request_id","match":"http.*","drop_tags":["request_id"]},{"name":"no_debug","match_tags":["env:dev"],"drop_metric":true}]`)
+	assert.Nil(t, err)
+	defer os.Unsetenv(env)
+	expected := []DropRule{
+		{Name: "no_request_id", Match: "http.*", DropTags: []string{"request_id"}},
+		{Name: "no_debug", MatchTags: []string{"env:dev"}, DropMetric: true},
+	}
+	rules, _ := GetDogstatsdDropRules()
+	assert.Equal(t, rules, expected)
+}
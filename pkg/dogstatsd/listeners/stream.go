@@ -0,0 +1,348 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listeners
+
+import (
+	"bufio"
+	"encoding/binary"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	framingNewline      = "newline"
+	framingLengthPrefix = "length_prefix"
+
+	// lengthPrefixSize is the size of the little-endian uint32 preceding each payload
+	// when using the length_prefix framing.
+	lengthPrefixSize = 4
+)
+
+var (
+	tcpExpvars       = expvar.NewMap("dogstatsd-tcp")
+	tcpStats         = &streamStats{}
+	udsStreamExpvars = expvar.NewMap("dogstatsd-uds-stream")
+	udsStreamStats   = &streamStats{}
+
+	tlmStreamConnections = telemetry.NewGauge("dogstatsd", "stream_connections",
+		[]string{"transport"}, "Dogstatsd stream connections count")
+	tlmStreamPackets = telemetry.NewCounter("dogstatsd", "stream_packets",
+		[]string{"transport", "state"}, "Dogstatsd stream packets count")
+	tlmStreamPacketsBytes = telemetry.NewCounter("dogstatsd", "stream_packets_bytes",
+		[]string{"transport"}, "Dogstatsd stream packets bytes count")
+	tlmStreamOriginDetectionError = telemetry.NewCounter("dogstatsd", "stream_origin_detection_error",
+		[]string{"transport"}, "Dogstatsd stream origin detection error count")
+)
+
+// streamStats are the expvars of a stream transport.
+type streamStats struct {
+	connections           expvar.Int
+	packets               expvar.Int
+	bytes                 expvar.Int
+	readingErrors         expvar.Int
+	oversizedMessages     expvar.Int
+	originDetectionErrors expvar.Int
+}
+
+func (s *streamStats) publish(m *expvar.Map) {
+	m.Set("Connections", &s.connections)
+	m.Set("Packets", &s.packets)
+	m.Set("Bytes", &s.bytes)
+	m.Set("ReadingErrors", &s.readingErrors)
+	m.Set("OversizedMessages", &s.oversizedMessages)
+	m.Set("OriginDetectionErrors", &s.originDetectionErrors)
+}
+
+func init() {
+	tcpStats.publish(tcpExpvars)
+	udsStreamStats.publish(udsStreamExpvars)
+}
+
+// StreamListener implements the StatsdListener interface for stream protocols,
+// TCP and Unix Domain Socket stream. It accepts connections on a given address
+// and sends back packets ready to be processed.
+// Messages are delimited either by newlines or by a length prefix.
+// Origin detection is implemented for UDS.
+type StreamListener struct {
+	transport        string
+	listener         net.Listener
+	packetsBuffer    *packetsBuffer
+	sharedPacketPool *PacketPool
+	bufferSize       int
+	lengthPrefix     bool
+	stats            *streamStats
+	// getOrigin returns the origin of the messages received on a connection, if origin detection is enabled
+	getOrigin func(conn net.Conn) (string, error)
+
+	conns   map[net.Conn]struct{}
+	stopped bool
+	m       sync.Mutex
+}
+
+func newStreamListener(transport string, listener net.Listener, packetOut chan Packets, sharedPacketPool *PacketPool, stats *streamStats) (*StreamListener, error) {
+	var lengthPrefix bool
+	switch framing := config.Datadog.GetString("dogstatsd_stream_framing"); framing {
+	case framingNewline:
+	case framingLengthPrefix:
+		lengthPrefix = true
+	default:
+		listener.Close()
+		return nil, fmt.Errorf("dogstatsd-%s: invalid framing %q, must be `%s` or `%s`", transport, framing, framingNewline, framingLengthPrefix)
+	}
+
+	return &StreamListener{
+		transport: transport,
+		listener:  listener,
+		packetsBuffer: newPacketsBuffer(uint(config.Datadog.GetInt("dogstatsd_packet_buffer_size")),
+			config.Datadog.GetDuration("dogstatsd_packet_buffer_flush_timeout"), packetOut),
+		sharedPacketPool: sharedPacketPool,
+		bufferSize:       config.Datadog.GetInt("dogstatsd_buffer_size"),
+		lengthPrefix:     lengthPrefix,
+		stats:            stats,
+		conns:            make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Listen runs the intake loop. Should be called in its own goroutine
+func (l *StreamListener) Listen() {
+	log.Infof("dogstatsd-%s: starting to listen on %s", l.transport, l.listener.Addr())
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			// listener has been closed
+			if strings.HasSuffix(err.Error(), " use of closed network connection") {
+				return
+			}
+
+			log.Errorf("dogstatsd-%s: error accepting connection: %v", l.transport, err)
+			continue
+		}
+
+		if !l.track(conn) {
+			conn.Close()
+			return
+		}
+		go l.handleConnection(conn)
+	}
+}
+
+// track registers a connection so that it is closed when the listener stops.
+// It returns false if the listener is already stopped.
+func (l *StreamListener) track(conn net.Conn) bool {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.stopped {
+		return false
+	}
+	l.conns[conn] = struct{}{}
+	l.stats.connections.Add(1)
+	tlmStreamConnections.Inc(l.transport)
+	return true
+}
+
+func (l *StreamListener) untrack(conn net.Conn) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	delete(l.conns, conn)
+	l.stats.connections.Add(-1)
+	tlmStreamConnections.Dec(l.transport)
+}
+
+// handleConnection reads messages from a connection until it is closed. Messages are
+// merged into packets, which are sent when they are full or when no more data is
+// immediately available on the connection.
+func (l *StreamListener) handleConnection(conn net.Conn) {
+	defer l.untrack(conn)
+	defer conn.Close()
+
+	origin := NoOrigin
+	if l.getOrigin != nil {
+		var err error
+		if origin, err = l.getOrigin(conn); err != nil {
+			log.Warnf("dogstatsd-%s: error processing origin, data will not be tagged : %v", l.transport, err)
+			l.stats.originDetectionErrors.Add(1)
+			tlmStreamOriginDetectionError.Inc(l.transport)
+		}
+	}
+
+	reader := bufio.NewReaderSize(conn, l.bufferSize)
+	assembler := &streamPacketAssembler{
+		listener: l,
+		origin:   origin,
+	}
+	assembler.reset()
+
+	for {
+		var message []byte
+		var err error
+		if l.lengthPrefix {
+			message, err = l.readLengthPrefixedMessage(reader)
+		} else {
+			message, err = l.readNewlineMessage(reader)
+		}
+
+		if len(message) > 0 {
+			assembler.add(message, l.lengthPrefix)
+		}
+
+		if err != nil {
+			assembler.flush()
+			assembler.release()
+			if err != io.EOF && !strings.HasSuffix(err.Error(), " use of closed network connection") {
+				log.Errorf("dogstatsd-%s: error reading from connection: %v", l.transport, err)
+				l.stats.readingErrors.Add(1)
+				tlmStreamPackets.Inc(l.transport, "error")
+			}
+			return
+		}
+
+		// Don't hold messages while waiting for more data.
+		if reader.Buffered() == 0 {
+			assembler.flush()
+		}
+	}
+}
+
+// readNewlineMessage reads a message terminated by a newline, including the newline.
+// The returned slice is only valid until the next read.
+func (l *StreamListener) readNewlineMessage(reader *bufio.Reader) ([]byte, error) {
+	for {
+		message, err := reader.ReadSlice(messageSeparator)
+		if err != bufio.ErrBufferFull {
+			return message, err
+		}
+
+		// The message is larger than the buffer, skip it.
+		l.stats.oversizedMessages.Add(1)
+		tlmStreamPackets.Inc(l.transport, "oversized")
+		for err == bufio.ErrBufferFull {
+			_, err = reader.ReadSlice(messageSeparator)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readLengthPrefixedMessage reads a payload preceded by its length.
+// The returned slice is only valid until the next read.
+func (l *StreamListener) readLengthPrefixedMessage(reader *bufio.Reader) ([]byte, error) {
+	for {
+		var prefix [lengthPrefixSize]byte
+		if _, err := io.ReadFull(reader, prefix[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		length := int(binary.LittleEndian.Uint32(prefix[:]))
+
+		if length > l.bufferSize {
+			// The payload is larger than the buffer, skip it.
+			l.stats.oversizedMessages.Add(1)
+			tlmStreamPackets.Inc(l.transport, "oversized")
+			if _, err := reader.Discard(length); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		message, err := reader.Peek(length)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			// Truncated payload, drop it.
+			return nil, err
+		}
+		reader.Discard(length) //nolint:errcheck
+		return message, nil
+	}
+}
+
+// Stop closes the listener and the open connections, and stops listening
+func (l *StreamListener) Stop() {
+	l.m.Lock()
+	l.stopped = true
+	l.listener.Close()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.m.Unlock()
+
+	l.packetsBuffer.close()
+}
+
+// streamPacketAssembler merges the messages read from a connection into packets.
+// Unlike packetAssembler, it is owned by a single goroutine and keeps the origin of the connection.
+type streamPacketAssembler struct {
+	listener     *StreamListener
+	origin       string
+	packet       *Packet
+	packetLength int
+}
+
+// reset retrieves an available packet from the packet pool,
+// which will be pushed back by the server when processed.
+func (a *streamPacketAssembler) reset() {
+	a.packet = a.listener.sharedPacketPool.Get()
+	a.packet.Origin = a.origin
+	a.packetLength = 0
+}
+
+// add appends a message to the current packet. If terminate is true, a newline is
+// appended to the message if needed, so that the packet only contains terminated messages.
+func (a *streamPacketAssembler) add(message []byte, terminate bool) {
+	size := len(message)
+	needsSeparator := terminate && message[len(message)-1] != messageSeparator
+	if needsSeparator {
+		size++
+	}
+	if size > len(a.packet.buffer) {
+		a.listener.stats.oversizedMessages.Add(1)
+		tlmStreamPackets.Inc(a.listener.transport, "oversized")
+		return
+	}
+	if a.packetLength+size > len(a.packet.buffer) {
+		a.flush()
+	}
+
+	a.packetLength += copy(a.packet.buffer[a.packetLength:], message)
+	if needsSeparator {
+		a.packet.buffer[a.packetLength] = messageSeparator
+		a.packetLength++
+	}
+}
+
+// flush sends the current packet, if it is not empty.
+func (a *streamPacketAssembler) flush() {
+	if a.packetLength == 0 {
+		return
+	}
+	a.packet.Contents = a.packet.buffer[:a.packetLength]
+	a.listener.stats.packets.Add(1)
+	a.listener.stats.bytes.Add(int64(a.packetLength))
+	tlmStreamPackets.Inc(a.listener.transport, "ok")
+	tlmStreamPacketsBytes.Add(float64(a.packetLength), a.listener.transport)
+
+	// packetsBuffer handles the forwarding of the packets to the dogstatsd server intake channel
+	a.listener.packetsBuffer.append(a.packet)
+	a.reset()
+}
+
+// release puts the current, unsent, packet back into the pool.
+func (a *streamPacketAssembler) release() {
+	a.listener.sharedPacketPool.Put(a.packet)
+	a.packet = nil
+}
@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+// +build !windows
+
+package listeners
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var packetPoolStream = NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size"))
+
+// newTestTCPListener starts a TCP listener on a random port.
+func newTestTCPListener(t *testing.T, framing string, packetsChannel chan Packets) *StreamListener {
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_tcp_port", 0)
+	mockConfig.Set("dogstatsd_non_local_traffic", false)
+	mockConfig.Set("dogstatsd_stream_framing", framing)
+
+	s, err := NewTCPListener(packetsChannel, packetPoolStream)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	go s.Listen()
+	return s
+}
+
+// receiveContents returns the contents of the packets received until the expected length is reached.
+func receiveContents(t *testing.T, packetsChannel chan Packets, expectedLength int) (string, []*Packet) {
+	var contents []byte
+	var received []*Packet
+	for len(contents) < expectedLength {
+		select {
+		case packets := <-packetsChannel:
+			for _, packet := range packets {
+				contents = append(contents, packet.Contents...)
+				received = append(received, packet)
+			}
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "Timeout on receive channel")
+		}
+	}
+	return string(contents), received
+}
+
+func lengthPrefixed(payload string) []byte {
+	buf := make([]byte, lengthPrefixSize+len(payload))
+	binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+	copy(buf[lengthPrefixSize:], payload)
+	return buf
+}
+
+func TestTCPReceiveNewline(t *testing.T) {
+	packetsChannel := make(chan Packets, 10)
+	s := newTestTCPListener(t, framingNewline, packetsChannel)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Messages can be split across writes.
+	conn.Write([]byte("daemon:666|g|#sometag1:somevalue1\ndaemon:"))
+	time.Sleep(10 * time.Millisecond)
+	conn.Write([]byte("667|g\n"))
+
+	expected := "daemon:666|g|#sometag1:somevalue1\ndaemon:667|g\n"
+	contents, packets := receiveContents(t, packetsChannel, len(expected))
+	assert.Equal(t, expected, contents)
+	for _, packet := range packets {
+		assert.Equal(t, NoOrigin, packet.Origin)
+	}
+}
+
+func TestTCPReceiveLengthPrefix(t *testing.T) {
+	packetsChannel := make(chan Packets, 10)
+	s := newTestTCPListener(t, framingLengthPrefix, packetsChannel)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	payload := append(lengthPrefixed("daemon:666|g"), lengthPrefixed("daemon:1|c\ndaemon:2|c")...)
+	conn.Write(payload)
+
+	expected := "daemon:666|g\ndaemon:1|c\ndaemon:2|c\n"
+	contents, _ := receiveContents(t, packetsChannel, len(expected))
+	assert.Equal(t, expected, contents)
+}
+
+func TestTCPOversizedMessages(t *testing.T) {
+	for _, framing := range []string{framingNewline, framingLengthPrefix} {
+		t.Run(framing, func(t *testing.T) {
+			packetsChannel := make(chan Packets, 10)
+			s := newTestTCPListener(t, framing, packetsChannel)
+			defer s.Stop()
+
+			conn, err := net.Dial("tcp", s.listener.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			oversized := "daemon:1|g|#" + string(make([]byte, s.bufferSize))
+			if framing == framingNewline {
+				conn.Write([]byte(oversized + "\ndaemon:2|g\n"))
+			} else {
+				conn.Write(append(lengthPrefixed(oversized), lengthPrefixed("daemon:2|g")...))
+			}
+
+			expected := "daemon:2|g\n"
+			contents, _ := receiveContents(t, packetsChannel, len(expected))
+			assert.Equal(t, expected, contents)
+		})
+	}
+}
+
+func TestTCPMultipleConnections(t *testing.T) {
+	packetsChannel := make(chan Packets, 10)
+	s := newTestTCPListener(t, framingNewline, packetsChannel)
+	defer s.Stop()
+
+	conn1, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn1.Close()
+	conn2, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn2.Close()
+
+	conn1.Write([]byte("daemon:1|c\n"))
+	conn2.Write([]byte("daemon:2|c\n"))
+
+	contents, _ := receiveContents(t, packetsChannel, len("daemon:1|c\n")*2)
+	assert.Contains(t, contents, "daemon:1|c\n")
+	assert.Contains(t, contents, "daemon:2|c\n")
+}
+
+func TestTCPStopClosesConnections(t *testing.T) {
+	s := newTestTCPListener(t, framingNewline, make(chan Packets, 10))
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Wait for the connection to be accepted.
+	require.Eventually(t, func() bool {
+		s.m.Lock()
+		defer s.m.Unlock()
+		return len(s.conns) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	s.Stop()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = net.Dial("tcp", s.listener.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestInvalidStreamFraming(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_tcp_port", 0)
+	mockConfig.Set("dogstatsd_stream_framing", "zero_terminated")
+
+	_, err := NewTCPListener(nil, packetPoolStream)
+	assert.Error(t, err)
+}
+
+func TestUDSStreamReceive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dd-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // clean up
+	socketPath := filepath.Join(dir, "dsd.socket")
+
+	mockConfig := config.Mock()
+	mockConfig.Set("dogstatsd_stream_socket", socketPath)
+	mockConfig.Set("dogstatsd_origin_detection", false)
+
+	packetsChannel := make(chan Packets, 10)
+	s, err := NewUDSStreamListener(packetsChannel, packetPoolStream)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	fi, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Srwx-w--w-", fi.Mode().String())
+
+	go s.Listen()
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	var contents = "daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2\n"
+	conn.Write([]byte(contents))
+
+	received, _ := receiveContents(t, packetsChannel, len(contents))
+	assert.Equal(t, contents, received)
+
+	s.Stop()
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err), "the socket file should have been removed")
+}
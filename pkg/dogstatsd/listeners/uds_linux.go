@@ -76,6 +76,34 @@ func processUDSOrigin(ancillary []byte) (string, error) {
 	return entity, nil
 }
 
+// processUDSPeerOrigin determines the origin of the messages received on a
+// UDS stream connection, from the credentials of the peer process stored by
+// the Linux kernel when the connection was established.
+func processUDSPeerOrigin(conn *net.UnixConn) (string, error) {
+	rawconn, err := conn.SyscallConn()
+	if err != nil {
+		return NoOrigin, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	err = rawconn.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return NoOrigin, err
+	}
+	if credErr != nil {
+		return NoOrigin, credErr
+	}
+
+	if cred.Pid == 0 {
+		return NoOrigin, fmt.Errorf("matched PID for the process is 0, it belongs " +
+			"probably to another namespace. Is the agent in host PID mode?")
+	}
+
+	return getEntityForPID(cred.Pid)
+}
+
 // getEntityForPID returns the container entity name and caches the value for future lookups
 // As the result is cached and the lookup is really fast (parsing local files), it can be
 // called from the intake goroutine.
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listeners
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// NewUDSStreamListener returns an idle Statsd listener for the Unix Domain Socket
+// stream protocol. Origin detection is implemented using the credentials of the
+// process that opened each connection. The socket file is removed when the listener stops.
+func NewUDSStreamListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*StreamListener, error) {
+	socketPath := config.Datadog.GetString("dogstatsd_stream_socket")
+	originDetection := config.Datadog.GetBool("dogstatsd_origin_detection")
+
+	address, addrErr := net.ResolveUnixAddr("unix", socketPath)
+	if addrErr != nil {
+		return nil, fmt.Errorf("dogstatsd-uds-stream: can't ResolveUnixAddr: %v", addrErr)
+	}
+	fileInfo, err := os.Stat(socketPath)
+	// Socket file already exists
+	if err == nil {
+		// Make sure it's a UNIX socket
+		if fileInfo.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("dogstatsd-uds-stream: cannot reuse %s socket path: path already exists and is not a UNIX socket", socketPath)
+		}
+		err = os.Remove(socketPath)
+		if err != nil {
+			return nil, fmt.Errorf("dogstatsd-uds-stream: cannot remove stale UNIX socket: %v", err)
+		}
+	}
+
+	listener, err := net.ListenUnix("unix", address)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+	// Connecting to a stream socket requires the write permission.
+	err = os.Chmod(socketPath, 0722)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("can't set the socket at write only: %s", err)
+	}
+
+	streamListener, err := newStreamListener("uds-stream", listener, packetOut, sharedPacketPool, udsStreamStats)
+	if err != nil {
+		return nil, err
+	}
+
+	if originDetection {
+		log.Debugf("dogstatsd-uds-stream: enabling origin detection on %s", listener.Addr())
+		streamListener.getOrigin = func(conn net.Conn) (string, error) {
+			return processUDSPeerOrigin(conn.(*net.UnixConn))
+		}
+	}
+
+	log.Debugf("dogstatsd-uds-stream: %s successfully initialized", listener.Addr())
+	return streamListener, nil
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package mapper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/hashicorp/golang-lru"
+)
+
+// DropRuleSet contains drop rules and cache instance
+type DropRuleSet struct {
+	Rules []*DropRule
+	// cache contains, for each metric name, the indexes of the rules whose match pattern matches it
+	cache *lru.Cache
+}
+
+// DropRule represent one drop rule
+type DropRule struct {
+	// counters are accessed atomically, keep them first for 64-bit alignment
+	samplesMatched uint64
+	samplesDropped uint64
+	tagsDropped    uint64
+
+	Name       string
+	regex      *regexp.Regexp
+	matchTags  []string
+	dropTags   []string
+	dropMetric bool
+}
+
+// DropRuleStats represent the number of samples and tags a rule has dropped
+type DropRuleStats struct {
+	Name           string `json:"name"`
+	SamplesMatched uint64 `json:"samples_matched"`
+	SamplesDropped uint64 `json:"samples_dropped"`
+	TagsDropped    uint64 `json:"tags_dropped"`
+}
+
+// NewDropRuleSet creates, validates, prepares a new DropRuleSet
+func NewDropRuleSet(configRules []config.DropRule, cacheSize int) (*DropRuleSet, error) {
+	var rules []*DropRule
+	names := make(map[string]bool, len(configRules))
+	for i, configRule := range configRules {
+		if configRule.Name == "" {
+			return nil, fmt.Errorf("drop rule num %d: name is required", i)
+		}
+		if names[configRule.Name] {
+			return nil, fmt.Errorf("drop rule: %s: name must be unique", configRule.Name)
+		}
+		names[configRule.Name] = true
+
+		if configRule.DropMetric == (len(configRule.DropTags) > 0) {
+			return nil, fmt.Errorf("drop rule: %s: exactly one of `drop_tags` or `drop_metric` is required", configRule.Name)
+		}
+		if configRule.DropMetric && configRule.Match == "" && len(configRule.MatchTags) == 0 {
+			return nil, fmt.Errorf("drop rule: %s: `drop_metric` requires `match` or `match_tags`", configRule.Name)
+		}
+
+		rule := &DropRule{
+			Name:       configRule.Name,
+			matchTags:  configRule.MatchTags,
+			dropTags:   configRule.DropTags,
+			dropMetric: configRule.DropMetric,
+		}
+		if configRule.Match != "" {
+			matchType := configRule.MatchType
+			if matchType == "" {
+				matchType = matchTypeWildcard
+			}
+			if matchType != matchTypeWildcard && matchType != matchTypeRegex {
+				return nil, fmt.Errorf("drop rule: %s: invalid match type, must be `wildcard` or `regex`", configRule.Name)
+			}
+			regex, err := buildRegex(configRule.Match, matchType)
+			if err != nil {
+				return nil, fmt.Errorf("drop rule: %s: %v", configRule.Name, err)
+			}
+			rule.regex = regex
+		}
+		rules = append(rules, rule)
+	}
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &DropRuleSet{Rules: rules, cache: cache}, nil
+}
+
+// Apply applies the rules, in order, to the samples of a metric. All the samples share the
+// given name and tags. Dropped tags are removed in place from the tags slice.
+// Apply returns the remaining tags, and false if the samples must be dropped.
+func (r *DropRuleSet) Apply(metricName string, tags []string, samples int) ([]string, bool) {
+	for _, i := range r.matchingRules(metricName) {
+		rule := r.Rules[i]
+		if !rule.matchesTags(tags) {
+			continue
+		}
+		atomic.AddUint64(&rule.samplesMatched, uint64(samples))
+		if rule.dropMetric {
+			atomic.AddUint64(&rule.samplesDropped, uint64(samples))
+			return tags, false
+		}
+		var dropped int
+		tags, dropped = rule.removeTags(tags)
+		atomic.AddUint64(&rule.tagsDropped, uint64(dropped*samples))
+	}
+	return tags, true
+}
+
+// Stats returns the number of samples and tags dropped by each rule
+func (r *DropRuleSet) Stats() []DropRuleStats {
+	stats := make([]DropRuleStats, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		stats = append(stats, DropRuleStats{
+			Name:           rule.Name,
+			SamplesMatched: atomic.LoadUint64(&rule.samplesMatched),
+			SamplesDropped: atomic.LoadUint64(&rule.samplesDropped),
+			TagsDropped:    atomic.LoadUint64(&rule.tagsDropped),
+		})
+	}
+	return stats
+}
+
+// matchingRules returns the indexes of the rules whose match pattern matches the metric name
+func (r *DropRuleSet) matchingRules(metricName string) []int {
+	if result, ok := r.cache.Get(metricName); ok {
+		return result.([]int)
+	}
+	var indexes []int
+	for i, rule := range r.Rules {
+		if rule.regex == nil || rule.regex.MatchString(metricName) {
+			indexes = append(indexes, i)
+		}
+	}
+	r.cache.Add(metricName, indexes)
+	return indexes
+}
+
+// matchesTags returns whether the tags contain all the tags of the rule. A rule tag
+// without a value (e.g. `env`) matches any value of the tag.
+func (d *DropRule) matchesTags(tags []string) bool {
+	for _, matchTag := range d.matchTags {
+		found := false
+		for _, tag := range tags {
+			if tag == matchTag || (!strings.Contains(matchTag, ":") && tagKey(tag) == matchTag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// removeTags removes the tags whose key is one of the rule tags to drop, and returns the number of removed tags
+func (d *DropRule) removeTags(tags []string) ([]string, int) {
+	kept := tags[:0]
+	for _, tag := range tags {
+		if !d.isDropped(tagKey(tag)) {
+			kept = append(kept, tag)
+		}
+	}
+	return kept, len(tags) - len(kept)
+}
+
+func (d *DropRule) isDropped(key string) bool {
+	for _, dropTag := range d.dropTags {
+		if key == dropTag {
+			return true
+		}
+	}
+	return false
+}
+
+func tagKey(tag string) string {
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
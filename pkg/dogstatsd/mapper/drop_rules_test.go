@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package mapper
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropRules(t *testing.T) {
+	rules, err := NewDropRuleSet([]config.DropRule{
+		{Name: "no_request_id", Match: "http.*", DropTags: []string{"request_id", "pod_name"}},
+		{Name: "no_debug", Match: `debug\..*`, MatchType: "regex", MatchTags: []string{"env:dev"}, DropMetric: true},
+		{Name: "no_canary", MatchTags: []string{"canary"}, DropMetric: true},
+		{Name: "no_host_id", DropTags: []string{"host_id"}},
+	}, 1000)
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		name         string
+		metricName   string
+		tags         []string
+		expectedTags []string
+		expectedKeep bool
+	}{
+		{
+			name:         "tags dropped by name match",
+			metricName:   "http.requests",
+			tags:         []string{"env:prod", "request_id:123", "pod_name:web-1", "request_id"},
+			expectedTags: []string{"env:prod"},
+			expectedKeep: true,
+		},
+		{
+			name:         "no wildcard match on nested names",
+			metricName:   "http.requests.count",
+			tags:         []string{"request_id:123"},
+			expectedTags: []string{"request_id:123"},
+			expectedKeep: true,
+		},
+		{
+			name:         "metric dropped by name and tag",
+			metricName:   "debug.allocations",
+			tags:         []string{"env:dev"},
+			expectedKeep: false,
+		},
+		{
+			name:         "metric kept if tags don't match",
+			metricName:   "debug.allocations",
+			tags:         []string{"env:prod", "host_id:abc"},
+			expectedTags: []string{"env:prod"},
+			expectedKeep: true,
+		},
+		{
+			name:         "metric dropped by tag key",
+			metricName:   "queue.size",
+			tags:         []string{"canary:true"},
+			expectedKeep: false,
+		},
+		{
+			name:         "rules without match apply to all metrics",
+			metricName:   "queue.size",
+			tags:         []string{"host_id:abc", "queue:jobs"},
+			expectedTags: []string{"queue:jobs"},
+			expectedKeep: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			tags, keep := rules.Apply(scenario.metricName, scenario.tags, 1)
+			assert.Equal(t, scenario.expectedKeep, keep)
+			if keep {
+				assert.Equal(t, scenario.expectedTags, tags)
+			}
+		})
+	}
+
+	// Results are the same once the name matches are cached.
+	tags, keep := rules.Apply("http.requests", []string{"request_id:456", "env:prod"}, 3)
+	assert.True(t, keep)
+	assert.Equal(t, []string{"env:prod"}, tags)
+
+	assert.Equal(t, []DropRuleStats{
+		{Name: "no_request_id", SamplesMatched: 4, SamplesDropped: 0, TagsDropped: 6},
+		{Name: "no_debug", SamplesMatched: 1, SamplesDropped: 1, TagsDropped: 0},
+		{Name: "no_canary", SamplesMatched: 1, SamplesDropped: 1, TagsDropped: 0},
+		{Name: "no_host_id", SamplesMatched: 7, SamplesDropped: 0, TagsDropped: 2},
+	}, rules.Stats())
+}
+
+func TestDropRulesErrors(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		rules         []config.DropRule
+		expectedError string
+	}{
+		{
+			name:          "missing name",
+			rules:         []config.DropRule{{Match: "test.*", DropMetric: true}},
+			expectedError: "drop rule num 0: name is required",
+		},
+		{
+			name: "duplicate name",
+			rules: []config.DropRule{
+				{Name: "test", Match: "test.*", DropMetric: true},
+				{Name: "test", DropTags: []string{"foo"}},
+			},
+			expectedError: "drop rule: test: name must be unique",
+		},
+		{
+			name:          "no action",
+			rules:         []config.DropRule{{Name: "test", Match: "test.*"}},
+			expectedError: "drop rule: test: exactly one of `drop_tags` or `drop_metric` is required",
+		},
+		{
+			name:          "both actions",
+			rules:         []config.DropRule{{Name: "test", Match: "test.*", DropMetric: true, DropTags: []string{"foo"}}},
+			expectedError: "drop rule: test: exactly one of `drop_tags` or `drop_metric` is required",
+		},
+		{
+			name:          "dropping all metrics",
+			rules:         []config.DropRule{{Name: "test", DropMetric: true}},
+			expectedError: "drop rule: test: `drop_metric` requires `match` or `match_tags`",
+		},
+		{
+			name:          "invalid match type",
+			rules:         []config.DropRule{{Name: "test", Match: "test.*", MatchType: "glob", DropMetric: true}},
+			expectedError: "drop rule: test: invalid match type, must be `wildcard` or `regex`",
+		},
+		{
+			name:          "invalid wildcard",
+			rules:         []config.DropRule{{Name: "test", Match: "test.**", DropMetric: true}},
+			expectedError: "drop rule: test: invalid wildcard match pattern `test.**`, it should not contain consecutive `*`",
+		},
+		{
+			name:          "invalid regex",
+			rules:         []config.DropRule{{Name: "test", Match: "test.(", MatchType: "regex", DropMetric: true}},
+			expectedError: "drop rule: test: invalid match `test.(`. cannot compile regex: error parsing regexp: missing closing ): `^test.($`",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := NewDropRuleSet(scenario.rules, 1000)
+			assert.EqualError(t, err, scenario.expectedError)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+const (
+	// originLimiterTopOffenders is the number of origins reported as top offenders.
+	originLimiterTopOffenders = 10
+
+	reasonSampleRate     = "samples_per_second"
+	reasonContextsCreate = "new_contexts_per_minute"
+)
+
+var (
+	tlmOriginLimitDropped = telemetry.NewCounter("dogstatsd", "origin_limit_dropped",
+		[]string{"reason"}, "Count of samples dropped by the per-origin limits")
+	tlmOriginLimitTopOffenders = telemetry.NewGauge("dogstatsd", "origin_limit_top_offenders",
+		[]string{"origin", "reason"}, "Samples dropped by the per-origin limits, for the origins with the most dropped samples")
+)
+
+// originLimitStat holds how many samples of an origin have been dropped by the limits.
+type originLimitStat struct {
+	Origin                string    `json:"origin"`
+	SamplesDroppedRate    uint64    `json:"samples_dropped_rate"`
+	SamplesDroppedContext uint64    `json:"samples_dropped_contexts"`
+	LastSeen              time.Time `json:"last_seen"`
+}
+
+func (s originLimitStat) dropped() uint64 {
+	return s.SamplesDroppedRate + s.SamplesDroppedContext
+}
+
+// originState tracks the samples and the contexts of an origin.
+// Each origin has its own lock, so that the samples of different origins don't contend.
+type originState struct {
+	sync.Mutex
+	stat originLimitStat
+
+	// samples received during the current second
+	second  time.Time
+	samples int
+
+	// contexts created during the current minute
+	minute      time.Time
+	newContexts int
+	// last time each context of the origin was seen, contexts unseen
+	// for longer than the expiry are considered new again
+	contexts map[ckey.ContextKey]time.Time
+	keyGen   *ckey.KeyGenerator
+}
+
+// originLimiter limits the number of samples per second and of new contexts per minute
+// of each origin (container or pod), so that a single origin can't flood DogStatsD.
+// Samples without an origin are not limited.
+type originLimiter struct {
+	samplesPerSecond     int
+	newContextsPerMinute int
+	contextExpiry        time.Duration
+
+	// lastCleanup is the time of the last cleanup in nanoseconds, accessed atomically
+	lastCleanup int64
+
+	// m protects the origins map, the state of each origin is protected by its own lock
+	m       sync.RWMutex
+	origins map[string]*originState
+	// top offenders reported in the telemetry, so that they can be removed when they change
+	reportedOffenders []originLimitStat
+	now               func() time.Time
+}
+
+// newOriginLimiter returns an originLimiter, or nil if no limit is set. A limit of 0 disables it.
+func newOriginLimiter(samplesPerSecond int, newContextsPerMinute int, contextExpiry time.Duration) *originLimiter {
+	if samplesPerSecond <= 0 && newContextsPerMinute <= 0 {
+		return nil
+	}
+	return &originLimiter{
+		samplesPerSecond:     samplesPerSecond,
+		newContextsPerMinute: newContextsPerMinute,
+		contextExpiry:        contextExpiry,
+		origins:              make(map[string]*originState),
+		now:                  time.Now,
+	}
+}
+
+// allow returns whether the samples of a message, which all share the same context, are allowed.
+// The tags slice is sorted in place.
+func (l *originLimiter) allow(origin string, name string, hostname string, tags []string, count int) bool {
+	if origin == "" {
+		return true
+	}
+
+	now := l.now()
+	lastCleanup := atomic.LoadInt64(&l.lastCleanup)
+	if now.UnixNano()-lastCleanup >= int64(time.Minute) && atomic.CompareAndSwapInt64(&l.lastCleanup, lastCleanup, now.UnixNano()) {
+		l.cleanup(now)
+	}
+
+	state := l.getState(origin)
+	state.Lock()
+	defer state.Unlock()
+	state.stat.LastSeen = now
+
+	if l.samplesPerSecond > 0 {
+		second := now.Truncate(time.Second)
+		if !second.Equal(state.second) {
+			state.second = second
+			state.samples = 0
+		}
+		if state.samples+count > l.samplesPerSecond {
+			state.stat.SamplesDroppedRate += uint64(count)
+			tlmOriginLimitDropped.Add(float64(count), reasonSampleRate)
+			return false
+		}
+	}
+
+	if l.newContextsPerMinute > 0 {
+		minute := now.Truncate(time.Minute)
+		if !minute.Equal(state.minute) {
+			state.minute = minute
+			state.newContexts = 0
+		}
+		key := state.keyGen.Generate(name, hostname, tags)
+		if lastSeen, ok := state.contexts[key]; !ok || now.Sub(lastSeen) > l.contextExpiry {
+			if state.newContexts >= l.newContextsPerMinute {
+				state.stat.SamplesDroppedContext += uint64(count)
+				tlmOriginLimitDropped.Add(float64(count), reasonContextsCreate)
+				return false
+			}
+			state.newContexts++
+		}
+		state.contexts[key] = now
+	}
+
+	state.samples += count
+	return true
+}
+
+// getState returns the state of an origin, creating it if needed.
+func (l *originLimiter) getState(origin string) *originState {
+	l.m.RLock()
+	state, found := l.origins[origin]
+	l.m.RUnlock()
+	if found {
+		return state
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if state, found = l.origins[origin]; !found {
+		state = &originState{
+			stat:     originLimitStat{Origin: origin},
+			contexts: make(map[ckey.ContextKey]time.Time),
+			keyGen:   ckey.NewKeyGenerator(),
+		}
+		l.origins[origin] = state
+	}
+	return state
+}
+
+// cleanup forgets the expired contexts and the origins that haven't sent
+// anything for longer than the context expiry, and updates the telemetry.
+func (l *originLimiter) cleanup(now time.Time) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	for origin, state := range l.origins {
+		state.Lock()
+		if now.Sub(state.stat.LastSeen) > l.contextExpiry {
+			delete(l.origins, origin)
+		} else {
+			for key, lastSeen := range state.contexts {
+				if now.Sub(lastSeen) > l.contextExpiry {
+					delete(state.contexts, key)
+				}
+			}
+		}
+		state.Unlock()
+	}
+
+	for _, stat := range l.reportedOffenders {
+		tlmOriginLimitTopOffenders.Delete(stat.Origin, reasonSampleRate)
+		tlmOriginLimitTopOffenders.Delete(stat.Origin, reasonContextsCreate)
+	}
+	l.reportedOffenders = l.topOffenders()
+	for _, stat := range l.reportedOffenders {
+		tlmOriginLimitTopOffenders.Set(float64(stat.SamplesDroppedRate), stat.Origin, reasonSampleRate)
+		tlmOriginLimitTopOffenders.Set(float64(stat.SamplesDroppedContext), stat.Origin, reasonContextsCreate)
+	}
+}
+
+// topOffenders returns the origins with the most dropped samples, the most dropped first.
+// Must be called with the lock held.
+func (l *originLimiter) topOffenders() []originLimitStat {
+	var offenders []originLimitStat
+	for _, state := range l.origins {
+		state.Lock()
+		stat := state.stat
+		state.Unlock()
+		if stat.dropped() > 0 {
+			offenders = append(offenders, stat)
+		}
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].dropped() != offenders[j].dropped() {
+			return offenders[i].dropped() > offenders[j].dropped()
+		}
+		return offenders[i].Origin < offenders[j].Origin
+	})
+	if len(offenders) > originLimiterTopOffenders {
+		offenders = offenders[:originLimiterTopOffenders]
+	}
+	return offenders
+}
+
+// getTopOffenders returns the origins with the most dropped samples, the most dropped first.
+func (l *originLimiter) getTopOffenders() []originLimitStat {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	return l.topOffenders()
+}
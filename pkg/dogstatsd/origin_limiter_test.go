@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOriginLimiter(samplesPerSecond int, newContextsPerMinute int) (*originLimiter, *time.Time) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newOriginLimiter(samplesPerSecond, newContextsPerMinute, 5*time.Minute)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestOriginLimiterDisabled(t *testing.T) {
+	assert.Nil(t, newOriginLimiter(0, 0, time.Minute))
+}
+
+func TestOriginLimiterSamplesPerSecond(t *testing.T) {
+	l, now := newTestOriginLimiter(10, 0)
+
+	assert.True(t, l.allow("container_id://a", "metric", "host", nil, 8))
+	assert.False(t, l.allow("container_id://a", "metric", "host", nil, 3))
+	assert.True(t, l.allow("container_id://a", "metric", "host", nil, 2))
+	assert.False(t, l.allow("container_id://a", "metric", "host", nil, 1))
+
+	// other origins and samples without origin aren't affected
+	assert.True(t, l.allow("container_id://b", "metric", "host", nil, 10))
+	assert.True(t, l.allow("", "metric", "host", nil, 100))
+
+	// the limit is per second
+	*now = now.Add(time.Second)
+	assert.True(t, l.allow("container_id://a", "metric", "host", nil, 10))
+
+	assert.Equal(t, []originLimitStat{
+		{Origin: "container_id://a", SamplesDroppedRate: 4, LastSeen: *now},
+	}, l.getTopOffenders())
+}
+
+func TestOriginLimiterNewContextsPerMinute(t *testing.T) {
+	l, now := newTestOriginLimiter(0, 2)
+
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:1"}, 1))
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"b:1", "a:2"}, 1))
+	assert.False(t, l.allow("container_id://a", "metric", "host", []string{"a:3"}, 5))
+	assert.False(t, l.allow("container_id://a", "other_metric", "host", []string{"a:1"}, 1))
+	// existing contexts are still accepted, whatever the tags order
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:1"}, 1))
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:2", "b:1"}, 1))
+
+	// the limit is per minute
+	*now = now.Add(time.Minute)
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:3"}, 1))
+
+	assert.Equal(t, []originLimitStat{
+		{Origin: "container_id://a", SamplesDroppedContext: 5 + 1, LastSeen: *now},
+	}, l.getTopOffenders())
+}
+
+func TestOriginLimiterContextExpiry(t *testing.T) {
+	l, now := newTestOriginLimiter(0, 1)
+
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:1"}, 1))
+	*now = now.Add(time.Minute)
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:2"}, 1))
+
+	// a:1 has expired and is forgotten, it counts as a new context again
+	*now = now.Add(5 * time.Minute)
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:2"}, 1))
+	assert.Len(t, l.origins["container_id://a"].contexts, 1)
+	assert.True(t, l.allow("container_id://a", "metric", "host", []string{"a:1"}, 1))
+	assert.False(t, l.allow("container_id://a", "metric", "host", []string{"a:3"}, 1))
+
+	// idle origins are forgotten
+	*now = now.Add(10 * time.Minute)
+	assert.True(t, l.allow("container_id://b", "metric", "host", nil, 1))
+	assert.Len(t, l.origins, 1)
+	assert.Empty(t, l.getTopOffenders())
+}
+
+func TestOriginLimiterTopOffenders(t *testing.T) {
+	l, _ := newTestOriginLimiter(1, 0)
+
+	for i := 0; i < originLimiterTopOffenders+5; i++ {
+		origin := fmt.Sprintf("container_id://%02d", i)
+		require.True(t, l.allow(origin, "metric", "host", nil, 1))
+		require.False(t, l.allow(origin, "metric", "host", nil, i+1))
+	}
+
+	offenders := l.getTopOffenders()
+	require.Len(t, offenders, originLimiterTopOffenders)
+	assert.Equal(t, "container_id://14", offenders[0].Origin)
+	assert.Equal(t, uint64(15), offenders[0].SamplesDroppedRate)
+	assert.Equal(t, "container_id://05", offenders[originLimiterTopOffenders-1].Origin)
+}
+
+func TestOriginLimiterConcurrent(t *testing.T) {
+	l := newOriginLimiter(100, 10, 5*time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			origin := fmt.Sprintf("container_id://%d", i%4)
+			for j := 0; j < 1000; j++ {
+				l.allow(origin, "metric", "host", []string{fmt.Sprintf("tag:%d", j%20)}, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	offenders := l.getTopOffenders()
+	assert.Len(t, offenders, 4)
+	for _, offender := range offenders {
+		// every origin sent 2000 samples over 20 contexts, more than the limits allow
+		assert.NotZero(t, offender.dropped())
+	}
+}
@@ -84,6 +84,7 @@ type Server struct {
 	extraTags                 []string
 	Debug                     *dsdServerDebug
 	mapper                    *mapper.MetricMapper
+	originLimiter             *originLimiter
 	eolTerminationEnabled     bool
 	telemetryEnabled          bool
 	entityIDPrecedenceEnabled bool
@@ -176,6 +177,25 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 		}
 	}
 
+	if config.Datadog.GetInt("dogstatsd_tcp_port") > 0 {
+		tcpListener, err := listeners.NewTCPListener(packetsChannel, sharedPacketPool)
+		if err != nil {
+			log.Errorf(err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, tcpListener)
+		}
+	}
+
+	streamSocketPath := config.Datadog.GetString("dogstatsd_stream_socket")
+	if len(streamSocketPath) > 0 {
+		unixStreamListener, err := listeners.NewUDSStreamListener(packetsChannel, sharedPacketPool)
+		if err != nil {
+			log.Errorf(err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, unixStreamListener)
+		}
+	}
+
 	pipeName := config.Datadog.GetString("dogstatsd_pipe_name")
 	if len(pipeName) > 0 {
 		namedPipeListener, err := listeners.NewNamedPipeListener(pipeName, packetsChannel, sharedPacketPool)
@@ -187,7 +207,7 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 	}
 
 	if len(tmpListeners) == 0 {
-		return nil, fmt.Errorf("listening on neither udp nor socket, please check your configuration")
+		return nil, fmt.Errorf("listening on neither udp, tcp nor socket, please check your configuration")
 	}
 
 	// check configuration for custom namespace
@@ -241,6 +261,11 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 			keyGen: ckey.NewKeyGenerator(),
 		},
 		UdsListenerRunning: udsListenerRunning,
+		// limit the samples and contexts of each origin, set before the workers start as they read it without lock
+		originLimiter: newOriginLimiter(
+			config.Datadog.GetInt("dogstatsd_origin_max_samples_per_second"),
+			config.Datadog.GetInt("dogstatsd_origin_max_new_contexts_per_minute"),
+			time.Duration(config.Datadog.GetInt("dogstatsd_expiry_seconds"))*time.Second),
 	}
 
 	// packets forwarding
@@ -490,6 +515,17 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 		dogstatsdMetricPackets.Add(1)
 		tlmProcessed.IncWithTags(tlmProcessedOkTags)
 	}
+
+	if s.originLimiter != nil && len(metricSamples) > 0 {
+		sample := &metricSamples[0]
+		origin := sample.OriginID
+		if origin == "" {
+			origin = sample.K8sOriginID
+		}
+		if !s.originLimiter.allow(origin, sample.Name, sample.Host, sample.Tags, len(metricSamples)) {
+			return metricSamples[0:0], nil
+		}
+	}
 	return metricSamples, nil
 }
 
@@ -640,6 +676,26 @@ func (s *Server) GetJSONDebugStats() ([]byte, error) {
 	return json.Marshal(s.Debug.Stats)
 }
 
+// dropStats are the statistics about the samples dropped by the drop rules and the per-origin limits.
+// They are reported separately from the debug stats to keep the format of the latter unchanged.
+type dropStats struct {
+	DropRules []mapper.DropRuleStats `json:"drop_rules"`
+	// LimitedOrigins are the origins with the most samples dropped by the per-origin limits
+	LimitedOrigins []originLimitStat `json:"limited_origins"`
+}
+
+// GetJSONDropStats returns jsonified statistics about dropped samples.
+func (s *Server) GetJSONDropStats() ([]byte, error) {
+	var stats dropStats
+	if s.aggregator != nil {
+		stats.DropRules = s.aggregator.GetDogstatsdDropRuleStats()
+	}
+	if s.originLimiter != nil {
+		stats.LimitedOrigins = s.originLimiter.getTopOffenders()
+	}
+	return json.Marshal(stats)
+}
+
 // FormatDebugStats returns a printable version of debug stats.
 func FormatDebugStats(stats []byte) (string, error) {
 	var dogStats map[uint64]metricStat
@@ -677,3 +733,38 @@ func FormatDebugStats(stats []byte) (string, error) {
 
 	return buf.String(), nil
 }
+
+// FormatDropStats returns a printable version of the statistics about dropped samples.
+func FormatDropStats(stats []byte) (string, error) {
+	var dropStats dropStats
+	if err := json.Unmarshal(stats, &dropStats); err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if len(dropStats.DropRules) > 0 {
+		header := fmt.Sprintf("%-40s | %-15s | %-15s | %-15s\n", "Drop Rule", "Samples Matched", "Samples Dropped", "Tags Dropped")
+		buf.Write([]byte(header))
+		buf.Write([]byte(strings.Repeat("-", len(header)) + "\n"))
+
+		for _, rule := range dropStats.DropRules {
+			buf.Write([]byte(fmt.Sprintf("%-40s | %-15d | %-15d | %-15d\n", rule.Name, rule.SamplesMatched, rule.SamplesDropped, rule.TagsDropped)))
+		}
+	}
+
+	if len(dropStats.LimitedOrigins) > 0 {
+		if buf.Len() > 0 {
+			buf.Write([]byte("\n"))
+		}
+		header := fmt.Sprintf("%-60s | %-20s | %-20s | %-20s\n", "Rate Limited Origin", "Dropped (samples/s)", "Dropped (contexts)", "Last Seen")
+		buf.Write([]byte(header))
+		buf.Write([]byte(strings.Repeat("-", len(header)) + "\n"))
+
+		for _, origin := range dropStats.LimitedOrigins {
+			buf.Write([]byte(fmt.Sprintf("%-60s | %-20d | %-20d | %-20v\n", origin.Origin, origin.SamplesDroppedRate, origin.SamplesDroppedContext, origin.LastSeen)))
+		}
+	}
+
+	return buf.String(), nil
+}
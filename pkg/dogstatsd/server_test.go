@@ -21,6 +21,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -371,6 +372,40 @@ func TestUDPForward(t *testing.T) {
 	assert.Equal(t, message, buffer)
 }
 
+func TestTCPReceive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	config.Datadog.SetDefault("dogstatsd_tcp_port", port)
+	defer config.Datadog.SetDefault("dogstatsd_tcp_port", 0)
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	s, err := NewServer(agg, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	conn.Write([]byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2\n"))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, 1, len(res))
+		sample := res[0]
+		assert.NotNil(t, sample)
+		assert.Equal(t, sample.Name, "daemon")
+		assert.EqualValues(t, sample.Value, 666.0)
+		assert.Equal(t, sample.Mtype, metrics.GaugeType)
+		assert.ElementsMatch(t, sample.Tags, []string{"sometag1:somevalue1", "sometag2:somevalue2"})
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
 func TestHistToDist(t *testing.T) {
 	port, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -687,6 +722,81 @@ func TestNoMappingsConfig(t *testing.T) {
 	assert.Len(t, samples, 1)
 }
 
+func TestDropRuleStats(t *testing.T) {
+	datadogYaml := `
+dogstatsd_drop_rules:
+  - name: no_request_id
+    match: "test.*"
+    drop_tags:
+      - request_id
+`
+	config.Datadog.SetConfigType("yaml")
+	err := config.Datadog.ReadConfig(strings.NewReader(datadogYaml))
+	require.NoError(t, err)
+	defer config.Datadog.ReadConfig(strings.NewReader(``)) //nolint:errcheck
+
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	s, err := NewServer(mockAggregator(), nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	data, err := s.GetJSONDropStats()
+	require.NoError(t, err)
+	var stats dropStats
+	require.NoError(t, json.Unmarshal(data, &stats))
+	assert.Equal(t, []mapper.DropRuleStats{{Name: "no_request_id"}}, stats.DropRules)
+
+	formatted, err := FormatDropStats(data)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "no_request_id")
+}
+
+func TestOriginLimits(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	config.Datadog.Set("dogstatsd_origin_max_samples_per_second", 2)
+	defer config.Datadog.Set("dogstatsd_origin_max_samples_per_second", 0)
+
+	s, err := NewServer(mockAggregator(), nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+	require.NotNil(t, s.originLimiter)
+	now := time.Now()
+	s.originLimiter.now = func() time.Time { return now }
+
+	parser := newParser(newFloat64ListPool())
+	samples := []metrics.MetricSample{}
+
+	samples, err = s.parseMetricMessage(samples, parser, []byte("test.metric:1:2|d"), "container_id://abc")
+	assert.NoError(t, err)
+	assert.Len(t, samples, 2)
+
+	samples, err = s.parseMetricMessage(samples[0:0], parser, []byte("test.metric:1|g"), "container_id://abc")
+	assert.NoError(t, err)
+	assert.Len(t, samples, 0)
+
+	// samples without origin aren't limited
+	samples, err = s.parseMetricMessage(samples[0:0], parser, []byte("test.metric:1:2:3|d"), "")
+	assert.NoError(t, err)
+	assert.Len(t, samples, 3)
+
+	data, err := s.GetJSONDropStats()
+	require.NoError(t, err)
+	var stats dropStats
+	require.NoError(t, json.Unmarshal(data, &stats))
+	require.Len(t, stats.LimitedOrigins, 1)
+	assert.Equal(t, "container_id://abc", stats.LimitedOrigins[0].Origin)
+	assert.Equal(t, uint64(1), stats.LimitedOrigins[0].SamplesDroppedRate)
+
+	formatted, err := FormatDropStats(data)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "container_id://abc")
+}
+
 type MetricSample struct {
 	Name  string
 	Value float64
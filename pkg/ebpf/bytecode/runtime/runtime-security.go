@@ -7,4 +7,4 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 )
 
-var RuntimeSecurity = ebpf.NewRuntimeAsset("runtime-security.c", "5ac39a4d437faa3d983b245fea60200f229cf3a750e68c314bf265c07ceac6fb")
+var RuntimeSecurity = ebpf.NewRuntimeAsset("runtime-security.c", "810ef5b7333fd278ef08acf27cb332eb23e6f4c4fb3f1673b351ff40cd571b36")
@@ -7,4 +7,4 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 )
 
-var Tracer = ebpf.NewRuntimeAsset("tracer.c", "c5843dc37146036b21ffb3b7cc9bef4e901642e7cb1a1b10fa50883da70f8487")
+var Tracer = ebpf.NewRuntimeAsset("tracer.c", "faba15f3d2650fd0363c7b7314cad06935d8ebf5f7b3abeff860686f85b2197f")
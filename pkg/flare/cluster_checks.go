@@ -79,6 +79,16 @@ func GetClusterChecks(w io.Writer) error {
 		fmt.Fprintln(w, "")
 	}
 
+	// Print configs that can't be placed
+	if len(cr.Unplaceable) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("=== %s configurations ===", color.RedString("Unplaceable")))
+		for _, u := range cr.Unplaceable {
+			PrintConfig(w, u.Config)
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.RedString("Reason"), u.Reason))
+		}
+		fmt.Fprintln(w, "")
+	}
+
 	// Print summary of node-agents
 	if len(cr.Nodes) == 0 {
 		fmt.Fprintln(w, fmt.Sprintf("=== %s node-agent reporting ===", color.RedString("Zero")))
@@ -116,6 +116,9 @@ func PrintConfig(w io.Writer, c integration.Config) {
 	} else {
 		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Configuration source"), color.RedString("Unknown configuration source")))
 	}
+	if !c.Placement.IsEmpty() {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Placement constraints"), color.CyanString(c.Placement.String())))
+	}
 	for _, inst := range c.Instances {
 		ID := string(check.BuildID(c.Name, inst, c.InitConfig))
 		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Instance ID"), color.CyanString(ID)))
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+const (
+	minReplayBackoff = time.Second
+	maxReplayBackoff = time.Minute
+)
+
+// BufferedDestination spills the payloads that can not be sent to a DiskQueue
+// and replays them in order in the background once the destination recovers.
+// Send only returns once a payload is either sent or durably queued,
+// so that the auditor never advances past a payload that could be lost.
+type BufferedDestination struct {
+	destination         Destination
+	queue               *DiskQueue
+	destinationsContext *DestinationsContext
+	replaying           bool
+	reportedSize        int64
+	mu                  sync.Mutex
+	// room is notified every time a payload leaves the queue
+	room chan struct{}
+}
+
+// NewBufferedDestination returns a new BufferedDestination.
+func NewBufferedDestination(destination Destination, queue *DiskQueue, destinationsContext *DestinationsContext) *BufferedDestination {
+	return &BufferedDestination{
+		destination:         destination,
+		queue:               queue,
+		destinationsContext: destinationsContext,
+		room:                make(chan struct{}, 1),
+	}
+}
+
+// Start replays the payloads left in the queue by a previous run of the agent,
+// which would otherwise wait for a new payload to be pushed.
+func (d *BufferedDestination) Start() {
+	if !d.queue.IsEmpty() {
+		d.updateMetrics()
+		d.startReplay()
+	}
+}
+
+// Send sends the payload directly when no payload is waiting to be replayed,
+// otherwise, or when the destination is unreachable, it appends it to the queue.
+func (d *BufferedDestination) Send(payload []byte) error {
+	if d.queue.IsEmpty() {
+		err := d.destination.Send(payload)
+		if _, ok := err.(*RetryableError); !ok {
+			return err
+		}
+	}
+	return d.push(payload)
+}
+
+// SendAsync sends a payload in background.
+func (d *BufferedDestination) SendAsync(payload []byte) {
+	d.destination.SendAsync(payload)
+}
+
+// push waits until the payload fits in the queue and starts replaying the queue.
+func (d *BufferedDestination) push(payload []byte) error {
+	ctx := d.destinationsContext.Context()
+	for {
+		err := d.queue.Push(payload)
+		if err == nil {
+			d.updateMetrics()
+			d.startReplay()
+			return nil
+		}
+		if err != errDiskQueueFull {
+			// the payload can not be stored, let the sender retry to send it directly
+			log.Warnf("Could not buffer logs payload on disk: %v", err)
+			return NewRetryableError(err)
+		}
+		d.startReplay()
+		select {
+		case <-d.room:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// startReplay starts replaying the queue in background if it is not already the case.
+func (d *BufferedDestination) startReplay() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.replaying {
+		return
+	}
+	d.replaying = true
+	go d.replay()
+}
+
+// replay sends the queued payloads in order until the queue is empty,
+// a payload is only removed from the queue once it has been sent.
+func (d *BufferedDestination) replay() {
+	ctx := d.destinationsContext.Context()
+	backoff := minReplayBackoff
+
+	for {
+		d.mu.Lock()
+		if d.queue.IsEmpty() || ctx.Err() != nil {
+			// checked under lock so that a concurrent push always restarts the replay
+			d.replaying = false
+			d.mu.Unlock()
+			return
+		}
+		d.mu.Unlock()
+
+		payload, err := d.queue.Peek()
+		if err != nil {
+			log.Warnf("Dropping unreadable buffered logs payload: %v", err)
+			d.payloadRemoved()
+			continue
+		}
+
+		err = d.destination.Send(payload)
+		if _, ok := err.(*RetryableError); ok {
+			metrics.DestinationErrors.Add(1)
+			metrics.TlmDestinationErrors.Inc()
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+			if backoff *= 2; backoff > maxReplayBackoff {
+				backoff = maxReplayBackoff
+			}
+			continue
+		}
+		if err != nil && ctx.Err() != nil {
+			// the destination has been stopped, the payload stays in the queue
+			continue
+		}
+		if err != nil {
+			log.Warnf("Dropping buffered logs payload: %v", err)
+		}
+		backoff = minReplayBackoff
+		d.queue.Pop()
+		d.payloadRemoved()
+	}
+}
+
+// payloadRemoved notifies a pending push that some room was made in the queue.
+func (d *BufferedDestination) payloadRemoved() {
+	d.updateMetrics()
+	select {
+	case d.room <- struct{}{}:
+	default:
+	}
+}
+
+// updateMetrics reports the variation of the disk space used by the queue,
+// the metrics are shared by the queues of all pipelines.
+func (d *BufferedDestination) updateMetrics() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	size := d.queue.SizeInBytes()
+	metrics.DiskBufferSizeInBytes.Add(size - d.reportedSize)
+	metrics.TlmDiskBufferSizeInBytes.Add(float64(size - d.reportedSize))
+	d.reportedSize = size
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package client
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyDestination fails to send payloads until it is marked as up.
+type flakyDestination struct {
+	mu   sync.Mutex
+	up   bool
+	sent []string
+}
+
+func (d *flakyDestination) Send(payload []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.up {
+		return NewRetryableError(errors.New("intake unreachable"))
+	}
+	d.sent = append(d.sent, string(payload))
+	return nil
+}
+
+func (d *flakyDestination) SendAsync(payload []byte) {}
+
+func (d *flakyDestination) setUp(up bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.up = up
+}
+
+func (d *flakyDestination) getSent() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.sent...)
+}
+
+func TestBufferedDestinationReplaysInOrder(t *testing.T) {
+	destinationsCtx := NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	q, err := NewDiskQueue(newStoragePath(t), 100)
+	require.NoError(t, err)
+	main := &flakyDestination{up: true}
+	d := NewBufferedDestination(main, q, destinationsCtx)
+
+	assert.NoError(t, d.Send([]byte("1")))
+	assert.Equal(t, []string{"1"}, main.getSent())
+	assert.True(t, q.IsEmpty())
+
+	// payloads are queued while the intake is unreachable
+	main.setUp(false)
+	assert.NoError(t, d.Send([]byte("2")))
+	assert.NoError(t, d.Send([]byte("3")))
+	assert.Equal(t, []string{"1"}, main.getSent())
+	assert.False(t, q.IsEmpty())
+
+	// and replayed in order once it recovers
+	main.setUp(true)
+	assert.NoError(t, d.Send([]byte("4")))
+	assert.Eventually(t, q.IsEmpty, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, main.getSent())
+}
+
+func TestBufferedDestinationBlocksWhenFull(t *testing.T) {
+	destinationsCtx := NewDestinationsContext()
+	destinationsCtx.Start()
+
+	q, err := NewDiskQueue(newStoragePath(t), 2)
+	require.NoError(t, err)
+	d := NewBufferedDestination(&flakyDestination{}, q, destinationsCtx)
+
+	assert.NoError(t, d.Send([]byte("1")))
+	assert.NoError(t, d.Send([]byte("2")))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- d.Send([]byte("3"))
+	}()
+	select {
+	case <-errChan:
+		assert.Fail(t, "send should block while the queue is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	destinationsCtx.Stop()
+	assert.Error(t, <-errChan)
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestBufferedDestinationReplaysReloadedQueueOnStart(t *testing.T) {
+	destinationsCtx := NewDestinationsContext()
+	destinationsCtx.Start()
+	defer destinationsCtx.Stop()
+
+	// payloads left on disk by a previous run
+	storagePath := newStoragePath(t)
+	q, err := NewDiskQueue(storagePath, 100)
+	require.NoError(t, err)
+	require.NoError(t, q.Push([]byte("1")))
+	require.NoError(t, q.Push([]byte("2")))
+
+	q, err = NewDiskQueue(storagePath, 100)
+	require.NoError(t, err)
+	require.Equal(t, 2, q.Len())
+
+	main := &flakyDestination{up: true}
+	d := NewBufferedDestination(main, q, destinationsCtx)
+
+	// no new payload is sent, the backlog is still replayed
+	d.Start()
+	assert.Eventually(t, q.IsEmpty, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"1", "2"}, main.getSent())
+}
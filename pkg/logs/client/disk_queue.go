@@ -0,0 +1,205 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const diskQueueExtension = ".payload"
+
+// errDiskQueueFull is returned when a payload does not fit in the remaining disk space.
+var errDiskQueueFull = errors.New("disk queue is full")
+
+// DiskQueue is a bounded FIFO queue of payloads stored on disk,
+// one file per payload, so that payloads survive intake outages and agent restarts.
+type DiskQueue struct {
+	mu                 sync.Mutex
+	storagePath        string
+	maxSizeInBytes     int64
+	filenames          []string
+	currentSizeInBytes int64
+	nextID             uint64
+}
+
+// NewDiskQueue returns a new DiskQueue storing payloads in storagePath,
+// the payloads left by a previous run are reloaded in order.
+func NewDiskQueue(storagePath string, maxSizeInBytes int64) (*DiskQueue, error) {
+	if err := os.MkdirAll(storagePath, 0700); err != nil {
+		return nil, err
+	}
+	q := &DiskQueue{
+		storagePath:    storagePath,
+		maxSizeInBytes: maxSizeInBytes,
+	}
+	if err := q.reloadExistingFiles(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Push durably stores a payload at the end of the queue,
+// it returns errDiskQueueFull when there is not enough space left.
+func (q *DiskQueue) Push(payload []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	size := int64(len(payload))
+	if size > q.maxSizeInBytes {
+		return fmt.Errorf("the payload is too big. Current:%v Maximum:%v", size, q.maxSizeInBytes)
+	}
+	if q.currentSizeInBytes+size > q.maxSizeInBytes {
+		return errDiskQueueFull
+	}
+
+	filename := filepath.Join(q.storagePath, fmt.Sprintf("%020d%s", q.nextID, diskQueueExtension))
+	if err := writeFileSync(filename, payload); err != nil {
+		return err
+	}
+
+	q.nextID++
+	q.filenames = append(q.filenames, filename)
+	q.currentSizeInBytes += size
+	return nil
+}
+
+// Peek returns the oldest payload of the queue without removing it,
+// a payload that can not be read is removed from the queue.
+func (q *DiskQueue) Peek() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.filenames) == 0 {
+		return nil, nil
+	}
+	payload, err := ioutil.ReadFile(q.filenames[0])
+	if err != nil {
+		q.removeOldest()
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Pop removes the oldest payload of the queue.
+func (q *DiskQueue) Pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.filenames) > 0 {
+		q.removeOldest()
+	}
+}
+
+// IsEmpty returns true if the queue does not contain any payload.
+func (q *DiskQueue) IsEmpty() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.filenames) == 0
+}
+
+// Len returns the number of payloads in the queue.
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.filenames)
+}
+
+// SizeInBytes returns the disk space used by the queue.
+func (q *DiskQueue) SizeInBytes() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.currentSizeInBytes
+}
+
+func (q *DiskQueue) removeOldest() {
+	filename := q.filenames[0]
+	// remove the file from the queue even in case of error to not fail on the next call.
+	q.filenames = q.filenames[1:]
+
+	info, err := os.Stat(filename)
+	if err == nil {
+		q.currentSizeInBytes -= info.Size()
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove buffered logs payload %s: %v", filename, err)
+	}
+}
+
+func (q *DiskQueue) reloadExistingFiles() error {
+	entries, err := ioutil.ReadDir(q.storagePath)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		id       uint64
+		filename string
+	}
+	var files []entry
+	for _, info := range entries {
+		name := info.Name()
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		if strings.Contains(name, diskQueueExtension+".tmp") {
+			// the agent stopped while writing this payload
+			_ = os.Remove(filepath.Join(q.storagePath, name))
+			continue
+		}
+		if filepath.Ext(name) != diskQueueExtension {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, diskQueueExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, entry{id: id, filename: filepath.Join(q.storagePath, name)})
+		q.currentSizeInBytes += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].id < files[j].id
+	})
+	for _, file := range files {
+		q.filenames = append(q.filenames, file.filename)
+		q.nextID = file.id + 1
+	}
+	if len(files) > 0 {
+		log.Infof("Reloaded %d buffered logs payloads from %s", len(files), q.storagePath)
+	}
+	return nil
+}
+
+// writeFileSync writes data to a temporary file flushed to the disk
+// and renames it so that a payload is never partially written at filename.
+func writeFileSync(filename string, data []byte) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStoragePath(t *testing.T) string {
+	storagePath, err := ioutil.TempDir("", "logs-disk-queue")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(storagePath) })
+	return storagePath
+}
+
+func TestDiskQueuePushPeekPop(t *testing.T) {
+	q, err := NewDiskQueue(newStoragePath(t), 100)
+	require.NoError(t, err)
+	assert.True(t, q.IsEmpty())
+
+	assert.NoError(t, q.Push([]byte("first")))
+	assert.NoError(t, q.Push([]byte("second")))
+	assert.Equal(t, 2, q.Len())
+	assert.Equal(t, int64(11), q.SizeInBytes())
+
+	payload, err := q.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("first"), payload)
+
+	q.Pop()
+	payload, err = q.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("second"), payload)
+
+	q.Pop()
+	assert.True(t, q.IsEmpty())
+	assert.Equal(t, int64(0), q.SizeInBytes())
+}
+
+func TestDiskQueueIsBounded(t *testing.T) {
+	q, err := NewDiskQueue(newStoragePath(t), 10)
+	require.NoError(t, err)
+
+	assert.NoError(t, q.Push([]byte("12345")))
+	assert.Equal(t, errDiskQueueFull, q.Push([]byte("123456")))
+	assert.NoError(t, q.Push([]byte("12345")))
+	assert.Error(t, q.Push([]byte("12345678901")))
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestDiskQueueReloadsPayloadsInOrder(t *testing.T) {
+	storagePath := newStoragePath(t)
+	q, err := NewDiskQueue(storagePath, 100)
+	require.NoError(t, err)
+	for _, payload := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"} {
+		require.NoError(t, q.Push([]byte(payload)))
+	}
+	q.Pop()
+	// a payload partially written by a previous run is discarded
+	require.NoError(t, ioutil.WriteFile(filepath.Join(storagePath, "00000000000000000011.payload.tmp123"), []byte("partial"), 0600))
+
+	q, err = NewDiskQueue(storagePath, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 10, q.Len())
+	assert.Equal(t, int64(12), q.SizeInBytes())
+
+	var payloads []string
+	for !q.IsEmpty() {
+		payload, err := q.Peek()
+		require.NoError(t, err)
+		payloads = append(payloads, string(payload))
+		q.Pop()
+	}
+	assert.Equal(t, []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}, payloads)
+
+	require.NoError(t, q.Push([]byte("12")))
+	files, err := ioutil.ReadDir(storagePath)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "00000000000000000011.payload", files[0].Name())
+	_, err = os.Stat(filepath.Join(storagePath, "00000000000000000011.payload.tmp123"))
+	assert.True(t, os.IsNotExist(err))
+}
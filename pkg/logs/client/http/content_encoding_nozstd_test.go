@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !zstd
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestZstdContentEncodingFallsBackToGzip(t *testing.T) {
+	contentEncoding := buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.ZstdCompressionKind})
+	assert.Equal(t, "gzip", contentEncoding.name())
+}
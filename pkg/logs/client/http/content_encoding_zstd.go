@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build zstd
+
+package http
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+// ZstdContentEncoding encodes the payload using zstd algorithm
+type ZstdContentEncoding struct{}
+
+// NewZstdContentEncoding creates a new Zstd content type
+func NewZstdContentEncoding() *ZstdContentEncoding {
+	return &ZstdContentEncoding{}
+}
+
+func (c *ZstdContentEncoding) name() string {
+	return "zstd"
+}
+
+func (c *ZstdContentEncoding) encode(payload []byte) ([]byte, error) {
+	return compression.Compress(nil, payload)
+}
+
+// newZstdContentEncoding returns a zstd content type as the agent is built with zstd.
+func newZstdContentEncoding() (ContentEncoding, bool) {
+	return NewZstdContentEncoding(), true
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build zstd
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+func TestZstdContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	encodedPayload, err := NewZstdContentEncoding().encode(payload)
+	assert.Nil(t, err)
+
+	decompressedPayload, err := compression.Decompress(nil, encodedPayload)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, decompressedPayload)
+}
+
+func TestZstdContentEncodingName(t *testing.T) {
+	assert.Equal(t, NewZstdContentEncoding().name(), "zstd")
+}
+
+func TestDestinationSendZstd(t *testing.T) {
+	server, request, body := newRecordingHTTPServerTest(config.Endpoint{APIKey: "test", UseCompression: true, CompressionKind: config.ZstdCompressionKind})
+	defer server.stop()
+
+	err := server.destination.Send([]byte("yo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "zstd", request.Header.Get("Content-Encoding"))
+	decompressedBody, err := compression.Decompress(nil, *body)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("yo"), decompressedBody)
+}
@@ -165,10 +165,16 @@ func buildURL(endpoint config.Endpoint) string {
 }
 
 func buildContentEncoding(endpoint config.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
-		return NewGzipContentEncoding(endpoint.CompressionLevel)
+	if !endpoint.UseCompression {
+		return IdentityContentType
 	}
-	return IdentityContentType
+	if endpoint.CompressionKind == config.ZstdCompressionKind {
+		if contentEncoding, ok := newZstdContentEncoding(); ok {
+			return contentEncoding
+		}
+		log.Warnf("zstd compression is not supported by this build of the agent, using gzip instead")
+	}
+	return NewGzipContentEncoding(endpoint.CompressionLevel)
 }
 
 // CheckConnectivity check if sending logs through HTTP works
@@ -6,6 +6,7 @@
 package http
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -48,6 +49,28 @@ func NewHTTPServerTest(statusCode int) *HTTPServerTest {
 	}
 }
 
+// newRecordingHTTPServerTest returns a server that records the last request it received.
+func newRecordingHTTPServerTest(endpoint config.Endpoint) (*HTTPServerTest, *http.Request, *[]byte) {
+	var lastRequest http.Request
+	var lastBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = *r
+		lastBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(200)
+	}))
+	url := strings.Split(ts.URL, ":")
+	endpoint.Host = strings.Replace(url[1], "/", "", -1)
+	endpoint.Port, _ = strconv.Atoi(url[2])
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	return &HTTPServerTest{
+		httpServer:  ts,
+		destCtx:     destCtx,
+		destination: NewDestination(endpoint, JSONContentType, destCtx),
+		endpoint:    endpoint,
+	}, &lastRequest, &lastBody
+}
+
 func (s *HTTPServerTest) stop() {
 	s.destCtx.Start()
 	s.httpServer.Close()
@@ -108,6 +131,28 @@ func TestDestinationSend400(t *testing.T) {
 	server.stop()
 }
 
+func TestDestinationSendIdentity(t *testing.T) {
+	server, request, body := newRecordingHTTPServerTest(config.Endpoint{APIKey: "test"})
+	defer server.stop()
+
+	err := server.destination.Send([]byte("yo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "identity", request.Header.Get("Content-Encoding"))
+	assert.Equal(t, []byte("yo"), *body)
+}
+
+func TestDestinationSendGzip(t *testing.T) {
+	server, request, body := newRecordingHTTPServerTest(config.Endpoint{APIKey: "test", UseCompression: true, CompressionLevel: 6, CompressionKind: config.GzipCompressionKind})
+	defer server.stop()
+
+	err := server.destination.Send([]byte("yo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "gzip", request.Header.Get("Content-Encoding"))
+	decompressedBody, err := decompress(*body)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("yo"), decompressedBody)
+}
+
 func TestConnectivityCheck(t *testing.T) {
 	// Connectivity is ok when server return 200
 	server := NewHTTPServerTest(200)
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -115,6 +116,20 @@ func BuildEndpoints(httpConnectivity HTTPConnectivity) (*Endpoints, error) {
 	return buildTCPEndpoints()
 }
 
+// DiskBufferMaxSizeInBytes returns the maximum disk space used to buffer payloads
+// that can not be sent, 0 means that payloads are not buffered on disk.
+func DiskBufferMaxSizeInBytes() int64 {
+	return coreConfig.Datadog.GetInt64("logs_config.disk_buffer_max_size_in_bytes")
+}
+
+// DiskBufferPath returns the directory where payloads that can not be sent are buffered.
+func DiskBufferPath() string {
+	if path := coreConfig.Datadog.GetString("logs_config.disk_buffer_path"); path != "" {
+		return path
+	}
+	return filepath.Join(coreConfig.Datadog.GetString("logs_config.run_path"), "logs_to_retry")
+}
+
 // ExpectedTagsDuration returns a duration of the time expected tags will be submitted for.
 func ExpectedTagsDuration() time.Duration {
 	return coreConfig.Datadog.GetDuration("logs_config.expected_tags_duration")
@@ -190,6 +205,7 @@ func buildTCPEndpoints() (*Endpoints, error) {
 type LogsConfigKeys struct {
 	UseCompression          string
 	CompressionLevel        string
+	CompressionKind         string
 	ConnectionResetInterval string
 	LogsDDURL               string
 	LogsNoSSL               string
@@ -203,6 +219,7 @@ type LogsConfigKeys struct {
 var logsConfigDefaultKeys = LogsConfigKeys{
 	UseCompression:          "logs_config.use_compression",
 	CompressionLevel:        "logs_config.compression_level",
+	CompressionKind:         "logs_config.compression_kind",
 	ConnectionResetInterval: "logs_config.connection_reset_interval",
 	LogsDDURL:               "logs_config.logs_dd_url",
 	LogsNoSSL:               "logs_config.logs_no_ssl",
@@ -230,10 +247,16 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 		defaultUseCompression = coreConfig.Datadog.GetBool(logsConfig.UseCompression)
 	}
 
+	defaultCompressionKind := GzipCompressionKind
+	if len(logsConfig.CompressionKind) != 0 {
+		defaultCompressionKind = compressionKindFromKey(coreConfig.Datadog, logsConfig.CompressionKind)
+	}
+
 	main := Endpoint{
 		APIKey:                  getLogsAPIKey(coreConfig.Datadog),
 		UseCompression:          defaultUseCompression,
 		CompressionLevel:        coreConfig.Datadog.GetInt(logsConfig.CompressionLevel),
+		CompressionKind:         defaultCompressionKind,
 		ConnectionResetInterval: time.Duration(coreConfig.Datadog.GetInt(logsConfig.ConnectionResetInterval)) * time.Second,
 	}
 
@@ -255,6 +278,9 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 	for i := 0; i < len(additionals); i++ {
 		additionals[i].UseSSL = main.UseSSL
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
+		if additionals[i].CompressionKind == "" {
+			additionals[i].CompressionKind = main.CompressionKind
+		}
 	}
 
 	batchWait := batchWaitFromKey(coreConfig.Datadog, logsConfig.BatchWait)
@@ -284,6 +310,21 @@ func getAdditionalEndpointsFromKey(additionalEndpointsParameter string) []Endpoi
 	return endpoints
 }
 
+// compressionKindFromKey returns the compression kind set at key,
+// it falls back to gzip when the compression kind is not supported.
+func compressionKindFromKey(config coreConfig.Config, key string) string {
+	kind := config.GetString(key)
+	switch kind {
+	case GzipCompressionKind, ZstdCompressionKind:
+		return kind
+	case "":
+		return GzipCompressionKind
+	default:
+		log.Warnf("Invalid %s: %s, supported values are %s and %s, using %s", key, kind, GzipCompressionKind, ZstdCompressionKind, GzipCompressionKind)
+		return GzipCompressionKind
+	}
+}
+
 func isSetAndNotEmpty(config coreConfig.Config, key string) bool {
 	return config.IsSet(key) && len(config.GetString(key)) > 0
 }
@@ -162,21 +162,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  GzipCompressionKind}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  GzipCompressionKind}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  GzipCompressionKind}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -225,6 +228,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
 	suite.config.Set("logs_config.use_compression", true)
 	suite.config.Set("logs_config.compression_level", 6)
+	suite.config.Set("logs_config.compression_kind", "zstd")
 	suite.config.Set("logs_config.logs_no_ssl", false)
 	endpointsInConfig := []map[string]interface{}{
 		{
@@ -238,7 +242,8 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 			"host":              "additional.endpoint.2",
 			"port":              1234,
 			"use_compression":   true,
-			"compression_level": 2},
+			"compression_level": 2,
+			"compression_kind":  "gzip"},
 	}
 	suite.config.Set("logs_config.additional_endpoints", endpointsInConfig)
 
@@ -248,21 +253,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  ZstdCompressionKind}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  ZstdCompressionKind}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  GzipCompressionKind}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -271,6 +279,16 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 	suite.Equal(expectedEndpoints, endpoints)
 }
 
+func (suite *ConfigTestSuite) TestInvalidCompressionKind() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.compression_kind", "lz4")
+
+	endpoints, err := BuildHTTPEndpoints()
+
+	suite.Nil(err)
+	suite.Equal(GzipCompressionKind, endpoints.Main.CompressionKind)
+}
+
 func (suite *ConfigTestSuite) TestMultipleTCPEndpointsInConf() {
 	suite.config.Set("api_key", "123")
 	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
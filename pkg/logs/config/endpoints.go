@@ -9,14 +9,21 @@ import (
 	"time"
 )
 
+// Compression kinds supported by HTTP endpoints
+const (
+	GzipCompressionKind = "gzip"
+	ZstdCompressionKind = "zstd"
+)
+
 // Endpoint holds all the organization and network parameters to send logs to Datadog.
 type Endpoint struct {
 	APIKey                  string `mapstructure:"api_key" json:"api_key"`
 	Host                    string
 	Port                    int
 	UseSSL                  bool
-	UseCompression          bool `mapstructure:"use_compression" json:"use_compression"`
-	CompressionLevel        int  `mapstructure:"compression_level" json:"compression_level"`
+	UseCompression          bool   `mapstructure:"use_compression" json:"use_compression"`
+	CompressionLevel        int    `mapstructure:"compression_level" json:"compression_level"`
+	CompressionKind         string `mapstructure:"compression_kind" json:"compression_kind"`
 	ProxyAddress            string
 	ConnectionResetInterval time.Duration
 }
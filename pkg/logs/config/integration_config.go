@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
 )
 
@@ -66,6 +67,8 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+	// AutoMultiLine overrides logs_config.auto_multi_line_detection for this source when set
+	AutoMultiLine *bool `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`
 }
 
 // TailingMode type
@@ -137,6 +140,14 @@ func (c *LogsConfig) Validate() error {
 	return CompileProcessingRules(c.ProcessingRules)
 }
 
+// AutoMultiLineEnabled returns true if multi-line logs should be detected automatically for this source.
+func (c *LogsConfig) AutoMultiLineEnabled() bool {
+	if c.AutoMultiLine != nil {
+		return *c.AutoMultiLine
+	}
+	return coreConfig.Datadog.GetBool("logs_config.auto_multi_line_detection")
+}
+
 func (c *LogsConfig) validateTailingMode() error {
 	mode, found := TailingModeFromString(c.TailingMode)
 	if !found && c.TailingMode != "" {
@@ -21,6 +21,8 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: SnmpTrapsType},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONDropAttribute, Path: "user.email"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONRemapAttribute, Path: "lvl", Target: "level"}}},
 	}
 
 	for _, config := range validConfigs {
@@ -42,6 +44,9 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONDropAttribute}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONMaskAttribute, Path: "user..email"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONRemapAttribute, Path: "lvl"}}},
 	}
 
 	for _, config := range invalidConfigs {
@@ -8,6 +8,7 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // Processing rule types
@@ -16,6 +17,13 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+
+	// Structured rule types, applied on the attributes of JSON log lines
+	JSONRemapAttribute   = "json_remap_attribute"
+	JSONDropAttribute    = "json_drop_attribute"
+	JSONMaskAttribute    = "json_mask_attribute"
+	JSONPromoteToStatus  = "json_promote_to_status"
+	JSONPromoteToService = "json_promote_to_service"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,6 +33,10 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// Path is the dot-separated path of the JSON attribute a structured rule applies on,
+	// Target is the destination path used by json_remap_attribute.
+	Path   string
+	Target string
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -34,7 +46,7 @@ type ProcessingRule struct {
 // Each processing rule must have:
 // - a valid name
 // - a valid type
-// - a valid pattern that compiles
+// - a valid pattern that compiles, or a valid path for structured rules
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -44,6 +56,11 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
 			break
+		case JSONRemapAttribute, JSONDropAttribute, JSONMaskAttribute, JSONPromoteToStatus, JSONPromoteToService:
+			if err := validateStructuredRule(rule); err != nil {
+				return err
+			}
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -61,9 +78,47 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 	return nil
 }
 
+// validateStructuredRule validates a rule applied on the attributes of JSON log lines.
+func validateStructuredRule(rule *ProcessingRule) error {
+	if !isValidAttributePath(rule.Path) {
+		return fmt.Errorf("invalid path %s for processing rule: %s", rule.Path, rule.Name)
+	}
+	if rule.Type == JSONRemapAttribute && !isValidAttributePath(rule.Target) {
+		return fmt.Errorf("invalid target %s for processing rule: %s", rule.Target, rule.Name)
+	}
+	return nil
+}
+
+// isValidAttributePath returns true if path is a non-empty dot-separated
+// list of non-empty attribute names.
+func isValidAttributePath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsStructuredRule returns true if the rule applies on the attributes of JSON log lines.
+func (r *ProcessingRule) IsStructuredRule() bool {
+	switch r.Type {
+	case JSONRemapAttribute, JSONDropAttribute, JSONMaskAttribute, JSONPromoteToStatus, JSONPromoteToService:
+		return true
+	}
+	return false
+}
+
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
+		if rule.IsStructuredRule() {
+			rule.Placeholder = []byte(rule.ReplacePlaceholder)
+			continue
+		}
 		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return err
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package decoder
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// autoMultiLineInfoKey is the key used to report the result of the detection in the source info.
+const autoMultiLineInfoKey = "auto_multi_line"
+
+// autoMultiLineCandidates is the list of line-start formats the auto multi-line detection
+// picks from, the most specific formats come first so that they win ties.
+var autoMultiLineCandidates = []*regexp.Regexp{
+	// 2021-01-31T12:34:56, 2021-01-31 12:34:56,789
+	regexp.MustCompile(`^\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`),
+	// 2021/01/31 12:34:56
+	regexp.MustCompile(`^\[?\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`),
+	// 31/Jan/2021:12:34:56
+	regexp.MustCompile(`^\[?\d{2}/[A-Za-z]{3}/\d{4}:\d{2}:\d{2}:\d{2}`),
+	// Sun Jan 31 12:34:56
+	regexp.MustCompile(`^\[?[A-Za-z]{3} [A-Za-z]{3}\s+\d{1,2} \d{2}:\d{2}:\d{2}`),
+	// Jan 31 12:34:56
+	regexp.MustCompile(`^\[?[A-Za-z]{3}\s+\d{1,2} \d{2}:\d{2}:\d{2}`),
+	// 31-01-2021 12:34:56, 01/31/2021 12:34:56
+	regexp.MustCompile(`^\[?\d{2}[-/]\d{2}[-/]\d{4}[T ]\d{2}:\d{2}:\d{2}`),
+	// 12:34:56.789
+	regexp.MustCompile(`^\[?\d{2}:\d{2}:\d{2}[.,]\d+`),
+	// INFO, [ERROR], WARNING:root:
+	regexp.MustCompile(`^\[?(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|CRITICAL|FATAL)\b`),
+}
+
+// AutoMultiLineHandler forwards lines as single lines while sampling the first lines of a source,
+// once enough lines have been sampled, it detects if they start with a known format
+// and aggregates the following lines with a MultiLineHandler when one is found.
+type AutoMultiLineHandler struct {
+	inputChan        chan *Message
+	outputChan       chan *Message
+	source           *config.LogSource
+	singleLine       *SingleLineHandler
+	multiLine        *MultiLineHandler
+	lineLimit        int
+	sampleSize       int
+	matchThreshold   float64
+	detectionTimeout time.Duration
+	sampled          int
+	candidateLines   int
+	matches          []int
+}
+
+// NewAutoMultiLineHandler returns a new AutoMultiLineHandler.
+func NewAutoMultiLineHandler(outputChan chan *Message, source *config.LogSource, lineLimit int, sampleSize int, matchThreshold float64, detectionTimeout time.Duration) *AutoMultiLineHandler {
+	return &AutoMultiLineHandler{
+		inputChan:        make(chan *Message),
+		outputChan:       outputChan,
+		source:           source,
+		singleLine:       NewSingleLineHandler(outputChan, lineLimit),
+		lineLimit:        lineLimit,
+		sampleSize:       sampleSize,
+		matchThreshold:   matchThreshold,
+		detectionTimeout: detectionTimeout,
+		matches:          make([]int, len(autoMultiLineCandidates)),
+	}
+}
+
+// Handle forwards lines to inputChan to process them.
+func (h *AutoMultiLineHandler) Handle(input *Message) {
+	h.inputChan <- input
+}
+
+// Stop stops the handler.
+func (h *AutoMultiLineHandler) Stop() {
+	close(h.inputChan)
+}
+
+// Start starts the handler.
+func (h *AutoMultiLineHandler) Start() {
+	go h.run()
+}
+
+// run samples the first lines until the detection is complete,
+// then hands the remaining lines over to the selected handler.
+func (h *AutoMultiLineHandler) run() {
+	h.source.UpdateInfo(autoMultiLineInfoKey, "Auto multi-line detection: sampling lines")
+	detectionTimer := time.NewTimer(h.detectionTimeout)
+	defer detectionTimer.Stop()
+
+	detecting := true
+	for detecting {
+		select {
+		case message, isOpen := <-h.inputChan:
+			if !isOpen {
+				h.source.RemoveInfo(autoMultiLineInfoKey)
+				close(h.outputChan)
+				return
+			}
+			h.sample(message)
+			h.singleLine.process(message)
+			detecting = h.sampled < h.sampleSize
+		case <-detectionTimer.C:
+			// not enough lines have been collected during the detection window,
+			// decide with the lines sampled so far or keep waiting for the first one.
+			if h.sampled > 0 {
+				detecting = false
+			} else {
+				detectionTimer.Reset(h.detectionTimeout)
+			}
+		}
+	}
+
+	h.detect()
+
+	if h.multiLine == nil {
+		for message := range h.inputChan {
+			h.singleLine.process(message)
+		}
+		close(h.outputChan)
+		return
+	}
+
+	// the multi-line handler closes the output channel when stopped
+	h.multiLine.Start()
+	for message := range h.inputChan {
+		h.multiLine.Handle(message)
+	}
+	h.multiLine.Stop()
+}
+
+// sample counts the matches of the line with every candidate format.
+func (h *AutoMultiLineHandler) sample(message *Message) {
+	h.sampled++
+	if isContinuationLine(message.Content) {
+		return
+	}
+	h.candidateLines++
+	for i, re := range autoMultiLineCandidates {
+		if re.Match(message.Content) {
+			h.matches[i]++
+		}
+	}
+}
+
+// detect selects the candidate format matching the most lines, the format is only used
+// when it matches enough of the lines that do not look like the continuation of a previous line.
+func (h *AutoMultiLineHandler) detect() {
+	best := -1
+	for i, count := range h.matches {
+		if count > 0 && (best < 0 || count > h.matches[best]) {
+			best = i
+		}
+	}
+
+	if best < 0 || float64(h.matches[best])/float64(h.candidateLines) < h.matchThreshold {
+		log.Debugf("No multi-line pattern detected for source %s after sampling %d lines", h.source.Name, h.sampled)
+		h.source.UpdateInfo(autoMultiLineInfoKey, fmt.Sprintf("Auto multi-line detection: no pattern detected after sampling %d lines, using single lines", h.sampled))
+		return
+	}
+
+	pattern := autoMultiLineCandidates[best]
+	log.Infof("Detected multi-line pattern %s for source %s after sampling %d lines", pattern, h.source.Name, h.sampled)
+	h.source.UpdateInfo(autoMultiLineInfoKey, fmt.Sprintf("Auto multi-line detection: detected pattern %s after sampling %d lines", pattern, h.sampled))
+	h.multiLine = NewMultiLineHandler(h.outputChan, pattern, defaultFlushTimeout, h.lineLimit)
+}
+
+// isContinuationLine returns true if the line is empty or indented,
+// like the frames of most stack traces.
+func isContinuationLine(content []byte) bool {
+	return len(content) == 0 || content[0] == ' ' || content[0] == '\t'
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package decoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestAutoMultiLineHandlerDetectsPattern(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := config.NewLogSource("test", &config.LogsConfig{})
+	h := NewAutoMultiLineHandler(outputChan, source, 100, 3, 0.75, time.Minute)
+	h.Start()
+
+	var output *Message
+
+	// sampled lines are sent as single lines
+	h.Handle(getDummyMessageWithLF("2021-01-31 12:34:56 ERROR something went wrong"))
+	output = <-outputChan
+	assert.Equal(t, "2021-01-31 12:34:56 ERROR something went wrong", string(output.Content))
+	h.Handle(getDummyMessageWithLF("\tat com.example.Main.main(Main.java:12)"))
+	output = <-outputChan
+	assert.Equal(t, "at com.example.Main.main(Main.java:12)", string(output.Content))
+	h.Handle(getDummyMessageWithLF("2021-01-31 12:34:57 INFO recovered"))
+	output = <-outputChan
+	assert.Equal(t, "2021-01-31 12:34:57 INFO recovered", string(output.Content))
+
+	// following lines are aggregated with the detected pattern
+	h.Handle(getDummyMessageWithLF("2021-01-31 12:34:58 ERROR java.lang.NullPointerException"))
+	h.Handle(getDummyMessageWithLF("\tat com.example.Main.main(Main.java:12)"))
+	h.Handle(getDummyMessageWithLF("2021-01-31 12:34:59 INFO recovered"))
+	output = <-outputChan
+	assert.Equal(t, "2021-01-31 12:34:58 ERROR java.lang.NullPointerException"+`\n`+"\tat com.example.Main.main(Main.java:12)", string(output.Content))
+
+	h.Stop()
+	output = <-outputChan
+	assert.Equal(t, "2021-01-31 12:34:59 INFO recovered", string(output.Content))
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+	assert.Contains(t, source.GetInfo()[0], "detected pattern")
+}
+
+func TestAutoMultiLineHandlerFallsBackToSingleLine(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := config.NewLogSource("test", &config.LogsConfig{})
+	h := NewAutoMultiLineHandler(outputChan, source, 100, 2, 0.75, time.Minute)
+	h.Start()
+
+	h.Handle(getDummyMessageWithLF("hello world"))
+	h.Handle(getDummyMessageWithLF("2021-01-31 12:34:56 hello world"))
+	<-outputChan
+	<-outputChan
+
+	h.Handle(getDummyMessageWithLF("hello"))
+	assert.Equal(t, "hello", string((<-outputChan).Content))
+	h.Handle(getDummyMessageWithLF("\tworld"))
+	assert.Equal(t, "world", string((<-outputChan).Content))
+
+	h.Stop()
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+	assert.Contains(t, source.GetInfo()[0], "no pattern detected")
+}
+
+func TestAutoMultiLineHandlerDetectsAfterTimeout(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := config.NewLogSource("test", &config.LogsConfig{})
+	h := NewAutoMultiLineHandler(outputChan, source, 100, 500, 0.75, 10*time.Millisecond)
+	h.Start()
+
+	h.Handle(getDummyMessageWithLF("Jan 31 12:34:56 host app: starting"))
+	<-outputChan
+
+	assert.Eventually(t, func() bool {
+		info := source.GetInfo()
+		return len(info) == 1 && info[0] != "Auto multi-line detection: sampling lines"
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, source.GetInfo()[0], "detected pattern")
+
+	h.Handle(getDummyMessageWithLF("Jan 31 12:34:57 host app: panic"))
+	h.Handle(getDummyMessageWithLF("  goroutine 1 [running]"))
+	h.Stop()
+	assert.Equal(t, "Jan 31 12:34:57 host app: panic"+`\n`+"  goroutine 1 [running]", string((<-outputChan).Content))
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/parser"
 )
@@ -94,6 +95,16 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 			lineHandler = NewMultiLineHandler(outputChan, rule.Regex, defaultFlushTimeout, lineLimit)
 		}
 	}
+	if lineHandler == nil && source.Config.AutoMultiLineEnabled() {
+		lineHandler = NewAutoMultiLineHandler(
+			outputChan,
+			source,
+			lineLimit,
+			coreConfig.Datadog.GetInt("logs_config.auto_multi_line_sample_size"),
+			coreConfig.Datadog.GetFloat64("logs_config.auto_multi_line_match_threshold"),
+			coreConfig.Datadog.GetDuration("logs_config.auto_multi_line_detection_timeout")*time.Second,
+		)
+	}
 	if lineHandler == nil {
 		lineHandler = NewSingleLineHandler(outputChan, lineLimit)
 	}
@@ -19,6 +19,9 @@ type Origin struct {
 	service    string
 	source     string
 	tags       []string
+	// promotedService is the service read from the content of the message by a
+	// processing rule, it takes precedence over the service of the configuration
+	promotedService string
 }
 
 // NewOrigin returns a new Origin
@@ -107,9 +110,19 @@ func (o *Origin) SetService(service string) {
 	o.service = service
 }
 
-// Service returns the service of the configuration if set or the service of the message,
+// PromoteService sets the service read from the content of the message, which overrides
+// the service of the configuration.
+func (o *Origin) PromoteService(service string) {
+	o.promotedService = service
+}
+
+// Service returns the service promoted from the content of the message if set, else the
+// service of the configuration if set or the service of the message,
 // if none are defined, returns an empty string by default.
 func (o *Origin) Service() string {
+	if o.promotedService != "" {
+		return o.promotedService
+	}
 	if o.LogSource.Config.Service != "" {
 		return o.LogSource.Config.Service
 	}
@@ -97,6 +97,9 @@ func TestDefaultServiceValueIsServiceFromConfig(t *testing.T) {
 	origin.SetService("bar")
 	assert.Equal(t, "foo", origin.Service())
 
+	origin.PromoteService("baz")
+	assert.Equal(t, "baz", origin.Service())
+
 	cfg = &config.LogsConfig{}
 	source = config.NewLogSource("", cfg)
 	origin = NewOrigin(source)
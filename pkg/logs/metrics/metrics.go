@@ -51,6 +51,11 @@ var (
 	// TlmEncodedBytesSent is the total number of sent bytes after encoding if any
 	TlmEncodedBytesSent = telemetry.NewCounter("logs", "encoded_bytes_sent",
 		nil, "Total number of sent bytes after encoding if any")
+	// DiskBufferSizeInBytes is the disk space used by the payloads waiting to be replayed
+	DiskBufferSizeInBytes = expvar.Int{}
+	// TlmDiskBufferSizeInBytes is the disk space used by the payloads waiting to be replayed
+	TlmDiskBufferSizeInBytes = telemetry.NewGauge("logs", "disk_buffer_size_in_bytes",
+		nil, "Disk space used by the payloads waiting to be replayed")
 	// TODO: Add LogsCollected for the total number of collected logs.
 
 )
@@ -64,4 +69,5 @@ func init() {
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
 	LogsExpvars.Set("BytesSent", &BytesSent)
 	LogsExpvars.Set("EncodedBytesSent", &EncodedBytesSent)
+	LogsExpvars.Set("DiskBufferSizeInBytes", &DiskBufferSizeInBytes)
 }
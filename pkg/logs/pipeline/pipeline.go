@@ -21,13 +21,21 @@ type Pipeline struct {
 	InputChan chan *message.Message
 	processor *processor.Processor
 	sender    *sender.Sender
+	// buffered is nil when the payloads are not buffered on disk
+	buffered *client.BufferedDestination
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool) *Pipeline {
+// NewPipeline returns a new Pipeline,
+// when diskQueue is not nil, the payloads that can not be sent over HTTP are buffered in it.
+func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool, diskQueue *client.DiskQueue) *Pipeline {
 	var destinations *client.Destinations
+	var buffered *client.BufferedDestination
 	if endpoints.UseHTTP {
-		main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
+		var main client.Destination = http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
+		if diskQueue != nil {
+			buffered = client.NewBufferedDestination(main, diskQueue, destinationsContext)
+			main = buffered
+		}
 		additionals := []client.Destination{}
 		for _, endpoint := range endpoints.Additionals {
 			additionals = append(additionals, http.NewDestination(endpoint, http.JSONContentType, destinationsContext))
@@ -70,11 +78,15 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 		InputChan: inputChan,
 		processor: processor,
 		sender:    sender,
+		buffered:  buffered,
 	}
 }
 
 // Start launches the pipeline
 func (p *Pipeline) Start() {
+	if p.buffered != nil {
+		p.buffered.Start()
+	}
 	p.sender.Start()
 	p.processor.Start()
 }
@@ -6,8 +6,12 @@
 package pipeline
 
 import (
+	"fmt"
+	"path/filepath"
 	"sync/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
@@ -71,12 +75,28 @@ func (p *provider) Start() {
 	p.outputChan = p.auditor.Channel()
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless)
+		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless, p.buildDiskQueue(i))
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
 }
 
+// buildDiskQueue returns the disk queue of a pipeline when disk buffering is enabled,
+// the disk space is evenly shared by all pipelines.
+func (p *provider) buildDiskQueue(pipelineID int) *client.DiskQueue {
+	maxSizeInBytes := config.DiskBufferMaxSizeInBytes()
+	if maxSizeInBytes <= 0 || !p.endpoints.UseHTTP || p.serverless {
+		return nil
+	}
+	storagePath := filepath.Join(config.DiskBufferPath(), fmt.Sprintf("pipeline_%d", pipelineID))
+	diskQueue, err := client.NewDiskQueue(storagePath, maxSizeInBytes/int64(p.numberOfPipelines))
+	if err != nil {
+		log.Errorf("Could not buffer logs on disk in %s: %v", storagePath, err)
+		return nil
+	}
+	return diskQueue
+}
+
 // Stop stops all pipelines in parallel,
 // this call blocks until all pipelines are stopped
 func (p *provider) Stop() {
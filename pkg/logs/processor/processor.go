@@ -101,8 +101,15 @@ func (p *Processor) processMessage(msg *message.Message) {
 // and a copy of the message with some fields redacted, depending on config
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
+	structured := &structuredContent{}
 	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
 	for _, rule := range rules {
+		if rule.IsStructuredRule() {
+			structured.apply(rule, msg, content)
+			continue
+		}
+		// regex based rules always apply on the latest version of the content
+		content = structured.flush(content)
 		switch rule.Type {
 		case config.ExcludeAtMatch:
 			if rule.Regex.Match(content) {
@@ -114,7 +121,8 @@ func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 			}
 		case config.MaskSequences:
 			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+			structured.invalidate()
 		}
 	}
-	return true, content
+	return true, structured.flush(content)
 }
@@ -128,6 +128,76 @@ func TestMask(t *testing.T) {
 	assert.Equal(t, []byte("New data added to data_values= on prod"), redactedMessage)
 }
 
+func TestStructuredRules(t *testing.T) {
+	p := &Processor{}
+
+	var shouldProcess bool
+	var redactedMessage []byte
+
+	source := newStructuredSource(&config.ProcessingRule{Type: config.JSONDropAttribute, Path: "user.email"})
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte(`{"user":{"email":"bob@datadoghq.com","id":12345678901234567890},"msg":"<hello>"}`), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"msg":"<hello>","user":{"id":12345678901234567890}}`), redactedMessage)
+
+	// non JSON logs are left untouched
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("user.email=bob@datadoghq.com"), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("user.email=bob@datadoghq.com"), redactedMessage)
+
+	source = newStructuredSource(&config.ProcessingRule{Type: config.JSONMaskAttribute, Path: "card", ReplacePlaceholder: "[masked]"})
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte(`{"card":"4323124312341234","msg":"hello"}`), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"card":"[masked]","msg":"hello"}`), redactedMessage)
+
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte(`{"msg":"hello"}`), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"msg":"hello"}`), redactedMessage)
+
+	source = newStructuredSource(&config.ProcessingRule{Type: config.JSONRemapAttribute, Path: "lvl", Target: "log.level"})
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte(`{"lvl":"warn","msg":"hello"}`), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"log":{"level":"warn"},"msg":"hello"}`), redactedMessage)
+
+	// regex based rules apply on the content modified by the previous structured rules
+	source = newStructuredSource(
+		&config.ProcessingRule{Type: config.JSONDropAttribute, Path: "debug"},
+		newProcessingRule(config.ExcludeAtMatch, "", "debug"),
+	)
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte(`{"debug":true,"msg":"hello"}`), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"msg":"hello"}`), redactedMessage)
+}
+
+func TestStructuredRulesPromoteAttributes(t *testing.T) {
+	p := &Processor{}
+
+	source := newStructuredSource(
+		&config.ProcessingRule{Type: config.JSONPromoteToStatus, Path: "level"},
+		&config.ProcessingRule{Type: config.JSONPromoteToService, Path: "app.name"},
+	)
+	msg := newMessage([]byte(`{"level":"ERROR","app":{"name":"billing"}}`), &source, "")
+	shouldProcess, redactedMessage := p.applyRedactingRules(msg)
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte(`{"level":"ERROR","app":{"name":"billing"}}`), redactedMessage)
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "billing", msg.Origin.Service())
+
+	msg = newMessage([]byte(`{"level":42}`), &source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, "", msg.Origin.Service())
+
+	// the promoted service overrides the service of the configuration
+	source.Config.Service = "default"
+	msg = newMessage([]byte(`{"app":{"name":"billing"}}`), &source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, "billing", msg.Origin.Service())
+
+	msg = newMessage([]byte(`{"msg":"hello"}`), &source, "")
+	p.applyRedactingRules(msg)
+	assert.Equal(t, "default", msg.Origin.Service())
+}
+
 func TestTruncate(t *testing.T) {
 	p := &Processor{}
 
@@ -153,6 +223,13 @@ func newSource(ruleType, replacePlaceholder, pattern string) config.LogSource {
 	return config.LogSource{Config: &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{newProcessingRule(ruleType, replacePlaceholder, pattern)}}}
 }
 
+func newStructuredSource(rules ...*config.ProcessingRule) config.LogSource {
+	for _, rule := range rules {
+		rule.Name = "test"
+	}
+	return config.LogSource{Config: &config.LogsConfig{ProcessingRules: rules}}
+}
+
 func newMessage(content []byte, source *config.LogSource, status string) *message.Message {
 	return message.NewMessageWithSource(content, status, source, 0)
 }
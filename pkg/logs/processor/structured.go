@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// structuredContent lazily decodes the content of a message as a JSON object
+// so that consecutive structured rules only decode and encode it once.
+type structuredContent struct {
+	attributes map[string]interface{}
+	decoded    bool
+	modified   bool
+}
+
+// get returns the attributes of the content, or nil if the content is not a JSON object.
+func (s *structuredContent) get(content []byte) map[string]interface{} {
+	if !s.decoded {
+		s.decoded = true
+		s.attributes = nil
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		// keep numbers as is to not lose precision on large integers
+		decoder.UseNumber()
+		if err := decoder.Decode(&s.attributes); err != nil {
+			s.attributes = nil
+		}
+	}
+	return s.attributes
+}
+
+// flush returns the content encoded with its modified attributes,
+// or the original content when no attribute has changed.
+func (s *structuredContent) flush(content []byte) []byte {
+	if !s.modified {
+		return content
+	}
+	s.modified = false
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s.attributes); err != nil {
+		return content
+	}
+	// the encoder always appends a trailing newline
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+}
+
+// invalidate forces the content to be decoded again on the next call to get.
+func (s *structuredContent) invalidate() {
+	s.decoded = false
+	s.modified = false
+	s.attributes = nil
+}
+
+// apply applies a structured rule on the content of msg.
+func (s *structuredContent) apply(rule *config.ProcessingRule, msg *message.Message, content []byte) {
+	attributes := s.get(content)
+	if attributes == nil {
+		return
+	}
+	path := strings.Split(rule.Path, ".")
+	switch rule.Type {
+	case config.JSONRemapAttribute:
+		if value, exists := removeAttribute(attributes, path); exists {
+			setAttribute(attributes, strings.Split(rule.Target, "."), value)
+			s.modified = true
+		}
+	case config.JSONDropAttribute:
+		if _, exists := removeAttribute(attributes, path); exists {
+			s.modified = true
+		}
+	case config.JSONMaskAttribute:
+		if _, exists := lookupAttribute(attributes, path); exists {
+			setAttribute(attributes, path, rule.ReplacePlaceholder)
+			s.modified = true
+		}
+	case config.JSONPromoteToStatus:
+		if value, exists := lookupAttribute(attributes, path); exists {
+			if status, ok := value.(string); ok && status != "" {
+				msg.SetStatus(strings.ToLower(status))
+			}
+		}
+	case config.JSONPromoteToService:
+		if value, exists := lookupAttribute(attributes, path); exists {
+			if service, ok := value.(string); ok && service != "" {
+				msg.Origin.PromoteService(service)
+			}
+		}
+	}
+}
+
+// lookupAttribute returns the value at path if it exists.
+func lookupAttribute(attributes map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = attributes
+	for _, key := range path {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// removeAttribute removes the value at path and returns it if it existed.
+func removeAttribute(attributes map[string]interface{}, path []string) (interface{}, bool) {
+	parent, exists := lookupAttribute(attributes, path[:len(path)-1])
+	if !exists {
+		return nil, false
+	}
+	object, ok := parent.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	key := path[len(path)-1]
+	value, exists := object[key]
+	if exists {
+		delete(object, key)
+	}
+	return value, exists
+}
+
+// setAttribute sets the value at path, creating intermediate objects when missing
+// and overriding intermediate values that are not objects.
+func setAttribute(attributes map[string]interface{}, path []string, value interface{}) {
+	object := attributes
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			object[key] = child
+		}
+		object = child
+	}
+	object[path[len(path)-1]] = value
+}
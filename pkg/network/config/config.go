@@ -40,6 +40,19 @@ type Config struct {
 	// EnableHTTPMonitoring specifies whether the tracer should monitor HTTP traffic
 	EnableHTTPMonitoring bool
 
+	// EnableHTTPSMonitoring specifies whether the tracer should monitor the HTTP traffic encrypted with
+	// the OpenSSL and GnuTLS shared libraries. It requires EnableHTTPMonitoring.
+	EnableHTTPSMonitoring bool
+
+	// EnableProtocolClassification specifies whether the tracer should classify the application protocol
+	// of TCP connections from their payloads, and count the requests of the protocols supporting it
+	EnableProtocolClassification bool
+
+	// EnableTCPExtendedStats specifies whether the tracer should count the zero window probes, the segments
+	// received out of order, the resets and the SYN timeouts of TCP connections. It requires kprobes on
+	// kernel functions which may be inlined by the compiler, in which case the tracer can't start.
+	EnableTCPExtendedStats bool
+
 	// UDPConnTimeout determines the length of traffic inactivity between two (IP, port)-pairs before declaring a UDP
 	// connection as inactive.
 	// Note: As UDP traffic is technically "connection-less", for tracking, we consider a UDP connection to be traffic
@@ -120,6 +133,9 @@ func NewDefaultConfig() *Config {
 		CollectLocalDNS:              false,
 		DNSInspection:                true,
 		EnableHTTPMonitoring:         false,
+		EnableHTTPSMonitoring:        false,
+		EnableProtocolClassification: false,
+		EnableTCPExtendedStats:       false,
 		UDPConnTimeout:               30 * time.Second,
 		TCPConnTimeout:               2 * time.Minute,
 		TCPClosedTimeout:             time.Second,
@@ -25,6 +25,14 @@ func (c *Config) EnabledProbes(pre410Kernel bool) (map[probes.ProbeName]struct{}
 		enabled[probes.TCPv4DestroySock] = struct{}{}
 		enabled[probes.TCPSetState] = struct{}{}
 
+		if c.EnableTCPExtendedStats {
+			enabled[probes.TCPConnect] = struct{}{}
+			enabled[probes.TCPSendProbe0] = struct{}{}
+			enabled[probes.TCPDataQueueOfo] = struct{}{}
+			enabled[probes.TCPSendActiveReset] = struct{}{}
+			enabled[probes.TCPReset] = struct{}{}
+		}
+
 		if c.BPFDebug || c.EnableHTTPMonitoring {
 			enabled[probes.TCPSendMsgReturn] = struct{}{}
 		}
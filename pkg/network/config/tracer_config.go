@@ -63,6 +63,9 @@ func TracerConfigFromConfig(cfg *config.AgentConfig) *Config {
 	tracerConfig.EnableConntrackAllNamespaces = cfg.EnableConntrackAllNamespaces
 	tracerConfig.DebugPort = cfg.SystemProbeDebugPort
 	tracerConfig.EnableHTTPMonitoring = cfg.EnableHTTPMonitoring
+	tracerConfig.EnableHTTPSMonitoring = cfg.EnableHTTPSMonitoring
+	tracerConfig.EnableProtocolClassification = cfg.EnableProtocolClassification
+	tracerConfig.EnableTCPExtendedStats = cfg.EnableTCPExtendedStats
 
 	if mccb := cfg.MaxClosedConnectionsBuffered; mccb > 0 {
 		tracerConfig.MaxClosedConnectionsBuffered = mccb
@@ -5,7 +5,7 @@ import "github.com/DataDog/datadog-agent/pkg/process/util"
 // ReverseDNS translates IPs to names
 type ReverseDNS interface {
 	Resolve([]ConnectionStats) map[util.Address][]string
-	GetDNSStats() map[DNSKey]map[string]DNSStats
+	GetDNSStats() map[DNSKey]map[string]map[QueryType]DNSStats
 	GetStats() map[string]int64
 	Close()
 }
@@ -21,7 +21,7 @@ func (nullReverseDNS) Resolve(_ []ConnectionStats) map[util.Address][]string {
 	return nil
 }
 
-func (nullReverseDNS) GetDNSStats() map[DNSKey]map[string]DNSStats {
+func (nullReverseDNS) GetDNSStats() map[DNSKey]map[string]map[QueryType]DNSStats {
 	return nil
 }
 
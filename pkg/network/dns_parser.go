@@ -127,16 +127,23 @@ func (p *dnsParser) parseAnswerInto(
 	t *translation,
 	pktInfo *dnsPacketInfo,
 ) error {
-	// Only consider singleton, A-record questions
+	// Only consider singleton questions
 	if len(dns.Questions) != 1 {
 		return errSkippedPayload
 	}
 
 	question := dns.Questions[0]
-	if question.Type != layers.DNSTypeA || question.Class != layers.DNSClassIN {
+	if question.Class != layers.DNSClassIN {
 		return errSkippedPayload
 	}
 
+	// Only A-record questions are used to resolve the IPs of the connections,
+	// the other query types are only relevant for the DNS stats
+	if question.Type != layers.DNSTypeA && !p.collectDNSStats {
+		return errSkippedPayload
+	}
+	pktInfo.queryType = QueryType(question.Type)
+
 	// Only consider responses
 	if !dns.QR {
 		pktInfo.pktType = Query
@@ -152,6 +159,11 @@ func (p *dnsParser) parseAnswerInto(
 		return nil
 	}
 
+	pktInfo.pktType = SuccessfulResponse
+	if question.Type != layers.DNSTypeA {
+		return nil
+	}
+
 	var alias []byte
 	domainQueried := question.Name
 
@@ -166,7 +178,6 @@ func (p *dnsParser) parseAnswerInto(
 	p.extractIPsInto(alias, domainQueried, dns.Additionals, t)
 	t.dns = string(domainQueried)
 
-	pktInfo.pktType = SuccessfulResponse
 	return nil
 }
 
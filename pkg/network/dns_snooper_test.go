@@ -45,7 +45,7 @@ func getSnooper(
 		return nil, nil
 	}
 
-	mgr := netebpf.NewManager(ddebpf.NewPerfHandler(1), ddebpf.NewPerfHandler(1))
+	mgr := netebpf.NewManager(ddebpf.NewPerfHandler(1), ddebpf.NewPerfHandler(1), ddebpf.NewPerfHandler(1))
 	mgrOptions := manager.Options{
 		MapSpecEditors: map[string]manager.MapSpecEditor{
 			// These maps are unrelated to DNS but are getting set because the eBPF library loads all of them
@@ -54,6 +54,10 @@ func getSnooper(
 			string(probes.PortBindingsMap):    {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 			string(probes.UdpPortBindingsMap): {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 			string(probes.HttpInFlightMap):    {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLSockByCtxMap):    {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLCtxByTupleMap):   {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.Http2ConnsMap):      {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.ConnProtocolsMap):   {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 		},
 		RLimit: &unix.Rlimit{
 			Cur: math.MaxUint64,
@@ -271,7 +275,7 @@ func getKey(
 func getStats(
 	snooper *SocketFilterSnooper,
 	expectedCount int,
-) map[DNSKey]map[string]DNSStats {
+) map[DNSKey]map[string]map[QueryType]DNSStats {
 	// DNS timeout is set to 1 second for the tests.
 	// So a 3-second timeout here should provide enough time for an unanswered DNS query to be considered as a timeout.
 	timeout := time.After(3 * time.Second)
@@ -318,7 +322,7 @@ func TestDNSOverTCPSuccessfulResponseCountWithoutDomain(t *testing.T) {
 	require.Equal(t, 1, len(allStatsByDomain[key]))
 
 	// Exactly one rcode (0, success) is expected
-	stats := allStatsByDomain[key][""]
+	stats := allStatsByDomain[key][""][DNSTypeA]
 	require.Equal(t, 1, len(stats.DNSCountByRcode))
 	assert.Equal(t, uint32(3), stats.DNSCountByRcode[uint32(layers.DNSResponseCodeNoErr)])
 	assert.True(t, stats.DNSSuccessLatencySum >= uint64(1))
@@ -352,7 +356,7 @@ func TestDNSOverTCPSuccessfulResponseCount(t *testing.T) {
 
 	// Exactly one rcode (0, success) is expected
 	for _, d := range domains {
-		stats := allStatsByDomain[key][d]
+		stats := allStatsByDomain[key][d][DNSTypeA]
 		require.Equal(t, 1, len(stats.DNSCountByRcode))
 		assert.Equal(t, uint32(1), stats.DNSCountByRcode[uint32(layers.DNSResponseCodeNoErr)])
 		assert.True(t, stats.DNSSuccessLatencySum >= uint64(1))
@@ -410,16 +414,16 @@ func TestDNSFailedResponseCount(t *testing.T) {
 	// First check the one sent over TCP. Expected error type: NXDomain
 	assert.Equal(t, len(domains), len(allStats[key1]))
 	for _, d := range domains {
-		require.Equal(t, 1, len(allStats[key1][d].DNSCountByRcode))
-		assert.Equal(t, uint32(1), allStats[key1][d].DNSCountByRcode[uint32(layers.DNSResponseCodeNXDomain)])
+		require.Equal(t, 1, len(allStats[key1][d][DNSTypeA].DNSCountByRcode))
+		assert.Equal(t, uint32(1), allStats[key1][d][DNSTypeA].DNSCountByRcode[uint32(layers.DNSResponseCodeNXDomain)])
 	}
 
 	// Next check the one sent over UDP. Expected error type: ServFail
 	key2 := getKey(queryIP, queryPort, localhost, UDP)
 	assert.Equal(t, len(domains), len(allStats[key2]))
 	for _, d := range domains {
-		require.Equal(t, 1, len(allStats[key2][d].DNSCountByRcode))
-		assert.Equal(t, uint32(1), allStats[key2][d].DNSCountByRcode[uint32(layers.DNSResponseCodeServFail)])
+		require.Equal(t, 1, len(allStats[key2][d][DNSTypeA].DNSCountByRcode))
+		assert.Equal(t, uint32(1), allStats[key2][d][DNSTypeA].DNSCountByRcode[uint32(layers.DNSResponseCodeServFail)])
 	}
 }
 
@@ -438,10 +442,10 @@ func TestDNSOverUDPTimeoutCount(t *testing.T) {
 	allStats := getStats(reverseDNS, 1)
 	key := getKey(queryIP, queryPort, invalidServerIP, UDP)
 	require.Equal(t, 1, len(allStats))
-	assert.Equal(t, 0, len(allStats[key][domainQueried].DNSCountByRcode))
-	assert.Equal(t, uint32(1), allStats[key][domainQueried].DNSTimeouts)
-	assert.Equal(t, uint64(0), allStats[key][domainQueried].DNSSuccessLatencySum)
-	assert.Equal(t, uint64(0), allStats[key][domainQueried].DNSFailureLatencySum)
+	assert.Equal(t, 0, len(allStats[key][domainQueried][DNSTypeA].DNSCountByRcode))
+	assert.Equal(t, uint32(1), allStats[key][domainQueried][DNSTypeA].DNSTimeouts)
+	assert.Equal(t, uint64(0), allStats[key][domainQueried][DNSTypeA].DNSSuccessLatencySum)
+	assert.Equal(t, uint64(0), allStats[key][domainQueried][DNSTypeA].DNSFailureLatencySum)
 }
 
 func TestDNSOverUDPTimeoutCountWithoutDomain(t *testing.T) {
@@ -459,10 +463,10 @@ func TestDNSOverUDPTimeoutCountWithoutDomain(t *testing.T) {
 	allStats := getStats(reverseDNS, 1)
 	key := getKey(queryIP, queryPort, invalidServerIP, UDP)
 	require.Equal(t, 1, len(allStats))
-	assert.Equal(t, 0, len(allStats[key][""].DNSCountByRcode))
-	assert.Equal(t, uint32(1), allStats[key][""].DNSTimeouts)
-	assert.Equal(t, uint64(0), allStats[key][""].DNSSuccessLatencySum)
-	assert.Equal(t, uint64(0), allStats[key][""].DNSFailureLatencySum)
+	assert.Equal(t, 0, len(allStats[key][""][DNSTypeA].DNSCountByRcode))
+	assert.Equal(t, uint32(1), allStats[key][""][DNSTypeA].DNSTimeouts)
+	assert.Equal(t, uint64(0), allStats[key][""][DNSTypeA].DNSSuccessLatencySum)
+	assert.Equal(t, uint64(0), allStats[key][""][DNSTypeA].DNSFailureLatencySum)
 }
 
 func TestParsingError(t *testing.T) {
@@ -499,7 +503,7 @@ func TestDNSOverIPv6(t *testing.T) {
 	key := getKey(queryIP, queryPort, serverIP, UDP)
 	require.Contains(t, allStats, key)
 
-	stats := allStats[key]["nxdomain-123.com"]
+	stats := allStats[key]["nxdomain-123.com"][DNSTypeA]
 	assert.Equal(t, 1, len(stats.DNSCountByRcode))
 	assert.Equal(t, uint32(1), stats.DNSCountByRcode[uint32(layers.DNSResponseCodeNXDomain)])
 }
@@ -29,11 +29,13 @@ type dnsPacketInfo struct {
 	pktType       DNSPacketType
 	rCode         uint8  // responseCode
 	question      string // only relevant for query packets
+	queryType     QueryType
 }
 
 type stateKey struct {
-	key DNSKey
-	id  uint16
+	key       DNSKey
+	id        uint16
+	queryType QueryType
 }
 
 type stateValue struct {
@@ -43,7 +45,7 @@ type stateValue struct {
 
 type dnsStatKeeper struct {
 	mux              sync.Mutex
-	stats            map[DNSKey]map[string]DNSStats
+	stats            map[DNSKey]map[string]map[QueryType]DNSStats
 	state            map[stateKey]stateValue
 	expirationPeriod time.Duration
 	exit             chan struct{}
@@ -53,7 +55,7 @@ type dnsStatKeeper struct {
 
 func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
 	statsKeeper := &dnsStatKeeper{
-		stats:            make(map[DNSKey]map[string]DNSStats),
+		stats:            make(map[DNSKey]map[string]map[QueryType]DNSStats),
 		state:            make(map[stateKey]stateValue),
 		expirationPeriod: timeout,
 		exit:             make(chan struct{}),
@@ -82,7 +84,7 @@ func microSecs(t time.Time) uint64 {
 func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
-	sk := stateKey{key: info.key, id: info.transactionID}
+	sk := stateKey{key: info.key, id: info.transactionID, queryType: info.queryType}
 
 	if info.pktType == Query {
 		if len(d.state) == d.maxSize {
@@ -107,14 +109,7 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 
 	latency := microSecs(ts) - start.ts
 
-	allStats, ok := d.stats[info.key]
-	if !ok {
-		allStats = make(map[string]DNSStats)
-	}
-	stats, ok := allStats[start.question]
-	if !ok {
-		stats.DNSCountByRcode = make(map[uint32]uint32)
-	}
+	stats := d.getStats(info.key, start.question, info.queryType)
 
 	// Note: time.Duration in the agent version of go (1.12.9) does not have the Microseconds method.
 	if latency > uint64(d.expirationPeriod.Microseconds()) {
@@ -128,15 +123,34 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 	}
 
-	allStats[start.question] = stats
-	d.stats[info.key] = allStats
+	d.stats[info.key][start.question][info.queryType] = stats
+}
+
+// getStats returns the stats of a domain and query type for the given key, creating their maps if needed.
+// The returned stats must be stored back once updated. Must be called with the lock held.
+func (d *dnsStatKeeper) getStats(key DNSKey, question string, queryType QueryType) DNSStats {
+	allStats, ok := d.stats[key]
+	if !ok {
+		allStats = make(map[string]map[QueryType]DNSStats)
+		d.stats[key] = allStats
+	}
+	statsByType, ok := allStats[question]
+	if !ok {
+		statsByType = make(map[QueryType]DNSStats)
+		allStats[question] = statsByType
+	}
+	stats, ok := statsByType[queryType]
+	if !ok {
+		stats.DNSCountByRcode = make(map[uint32]uint32)
+	}
+	return stats
 }
 
-func (d *dnsStatKeeper) GetAndResetAllStats() map[DNSKey]map[string]DNSStats {
+func (d *dnsStatKeeper) GetAndResetAllStats() map[DNSKey]map[string]map[QueryType]DNSStats {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	ret := d.stats // No deep copy needed since `d.stats` gets reset
-	d.stats = make(map[DNSKey]map[string]DNSStats)
+	d.stats = make(map[DNSKey]map[string]map[QueryType]DNSStats)
 	return ret
 }
 
@@ -150,18 +164,10 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 		if v.ts < threshold {
 			delete(d.state, k)
 			d.deleteCount++
-			// When we expire a state, we need to increment timeout count for that key:domain
-			allStats, ok := d.stats[k.key]
-			if !ok {
-				allStats = make(map[string]DNSStats)
-			}
-			stats, ok := allStats[v.question]
-			if !ok {
-				stats.DNSCountByRcode = make(map[uint32]uint32)
-			}
+			// When we expire a state, we need to increment timeout count for that key:domain:query type
+			stats := d.getStats(k.key, v.question, k.queryType)
 			stats.DNSTimeouts++
-			allStats[v.question] = stats
-			d.stats[k.key] = allStats
+			d.stats[k.key][v.question][k.queryType] = stats
 		}
 	}
 
@@ -36,23 +36,24 @@ func testLatency(
 	var d = "abc.com"
 	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
 	key := getSampleDNSKey()
-	qPkt := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d}
+	qPkt := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d, queryType: DNSTypeA}
 	then := time.Now()
 	sk.ProcessPacketInfo(qPkt, then)
 	stats := sk.GetAndResetAllStats()
 	assert.NotContains(t, stats, key)
 
 	now := then.Add(delta)
-	rPkt := dnsPacketInfo{transactionID: 1, key: key, pktType: respType}
+	rPkt := dnsPacketInfo{transactionID: 1, key: key, pktType: respType, queryType: DNSTypeA}
 
 	sk.ProcessPacketInfo(rPkt, now)
 	stats = sk.GetAndResetAllStats()
 	require.Contains(t, stats, key)
 	require.Contains(t, stats[key], d)
+	require.Contains(t, stats[key][d], DNSTypeA)
 
-	assert.Equal(t, expectedSuccessLatency, stats[key][d].DNSSuccessLatencySum)
-	assert.Equal(t, expectedFailureLatency, stats[key][d].DNSFailureLatencySum)
-	assert.Equal(t, expectedTimeouts, stats[key][d].DNSTimeouts)
+	assert.Equal(t, expectedSuccessLatency, stats[key][d][DNSTypeA].DNSSuccessLatencySum)
+	assert.Equal(t, expectedFailureLatency, stats[key][d][DNSTypeA].DNSFailureLatencySum)
+	assert.Equal(t, expectedTimeouts, stats[key][d][DNSTypeA].DNSTimeouts)
 }
 
 func TestSuccessLatency(t *testing.T) {
@@ -74,11 +75,11 @@ func TestExpiredStateRemoval(t *testing.T) {
 	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
 	key := getSampleDNSKey()
 	var d = "abc.com"
-	qPkt1 := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d}
-	rPkt1 := dnsPacketInfo{transactionID: 1, key: key, pktType: SuccessfulResponse}
-	qPkt2 := dnsPacketInfo{transactionID: 2, pktType: Query, key: key, question: d}
-	qPkt3 := dnsPacketInfo{transactionID: 3, pktType: Query, key: key, question: d}
-	rPkt3 := dnsPacketInfo{transactionID: 3, key: key, pktType: SuccessfulResponse}
+	qPkt1 := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d, queryType: DNSTypeA}
+	rPkt1 := dnsPacketInfo{transactionID: 1, key: key, pktType: SuccessfulResponse, queryType: DNSTypeA}
+	qPkt2 := dnsPacketInfo{transactionID: 2, pktType: Query, key: key, question: d, queryType: DNSTypeA}
+	qPkt3 := dnsPacketInfo{transactionID: 3, pktType: Query, key: key, question: d, queryType: DNSTypeA}
+	rPkt3 := dnsPacketInfo{transactionID: 3, key: key, pktType: SuccessfulResponse, queryType: DNSTypeA}
 
 	sk.ProcessPacketInfo(qPkt1, time.Now())
 	sk.ProcessPacketInfo(rPkt1, time.Now())
@@ -92,10 +93,39 @@ func TestExpiredStateRemoval(t *testing.T) {
 	stats := sk.GetAndResetAllStats()
 	require.Contains(t, stats, key)
 	require.Contains(t, stats[key], d)
+	require.Contains(t, stats[key][d], DNSTypeA)
 
-	require.Contains(t, stats[key][d].DNSCountByRcode, uint32(0))
-	assert.Equal(t, uint32(2), stats[key][d].DNSCountByRcode[0])
-	assert.Equal(t, uint32(1), stats[key][d].DNSTimeouts)
+	require.Contains(t, stats[key][d][DNSTypeA].DNSCountByRcode, uint32(0))
+	assert.Equal(t, uint32(2), stats[key][d][DNSTypeA].DNSCountByRcode[0])
+	assert.Equal(t, uint32(1), stats[key][d][DNSTypeA].DNSTimeouts)
+}
+
+func TestStatsByQueryType(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	key := getSampleDNSKey()
+	var d = "abc.com"
+
+	// Queries of different types may share the same transaction ID
+	qPktA := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d, queryType: DNSTypeA}
+	qPktAAAA := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d, queryType: DNSTypeAAAA}
+	rPktA := dnsPacketInfo{transactionID: 1, key: key, pktType: SuccessfulResponse, queryType: DNSTypeA}
+	rPktAAAA := dnsPacketInfo{transactionID: 1, key: key, pktType: FailedResponse, rCode: 3, queryType: DNSTypeAAAA}
+
+	now := time.Now()
+	sk.ProcessPacketInfo(qPktA, now)
+	sk.ProcessPacketInfo(qPktAAAA, now)
+	sk.ProcessPacketInfo(rPktAAAA, now.Add(10*time.Microsecond))
+	sk.ProcessPacketInfo(rPktA, now.Add(20*time.Microsecond))
+
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	require.Contains(t, stats[key], d)
+	require.Len(t, stats[key][d], 2)
+
+	assert.Equal(t, map[uint32]uint32{0: 1}, stats[key][d][DNSTypeA].DNSCountByRcode)
+	assert.Equal(t, uint64(20), stats[key][d][DNSTypeA].DNSSuccessLatencySum)
+	assert.Equal(t, map[uint32]uint32{3: 1}, stats[key][d][DNSTypeAAAA].DNSCountByRcode)
+	assert.Equal(t, uint64(10), stats[key][d][DNSTypeAAAA].DNSFailureLatencySum)
 }
 
 func BenchmarkStats(b *testing.B) {
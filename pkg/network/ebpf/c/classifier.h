@@ -0,0 +1,210 @@
+#ifndef __CLASSIFIER_H
+#define __CLASSIFIER_H
+
+#include "tracer.h"
+#include "bpf_helpers.h"
+#include "tracer-maps.h"
+#include "ip.h"
+
+// The application protocol of a TCP connection is classified from the first bytes of a payload.
+// Only messages whose framing is specific enough are matched to keep the false positives low:
+// the startup messages of Postgres and AMQP, the greeting of MySQL, Redis commands and Kafka requests.
+//
+// Requests are counted for the protocols whose clients usually wait for the response to a query before
+// sending the next one (Postgres, MySQL and Redis): a query sent by the client starts a request, which is
+// completed by the next payload sent by the server. Pipelined queries are thus counted as a single request.
+
+#define POSTGRES_PROTOCOL_3_0 0x00030000
+#define POSTGRES_SSL_REQUEST 0x04d2162f
+#define POSTGRES_GSSENC_REQUEST 0x04d21630
+#define POSTGRES_QUERY 'Q'
+#define POSTGRES_PARSE 'P'
+
+#define MYSQL_HANDSHAKE_V10 0x0a
+#define MYSQL_COM_QUERY 0x03
+#define MYSQL_COM_STMT_PREPARE 0x16
+#define MYSQL_COM_STMT_EXECUTE 0x17
+
+#define KAFKA_MAX_API_KEY 67
+#define KAFKA_MAX_API_VERSION 12
+
+static __always_inline __u32 classifier_be32(const char* buf) {
+    return ((__u32)(__u8)buf[0] << 24) | ((__u32)(__u8)buf[1] << 16) | ((__u32)(__u8)buf[2] << 8) | (__u32)(__u8)buf[3];
+}
+
+static __always_inline __u16 classifier_be16(const char* buf) {
+    return ((__u16)(__u8)buf[0] << 8) | (__u16)(__u8)buf[1];
+}
+
+static __always_inline bool classifier_is_digit(char c) {
+    return c >= '0' && c <= '9';
+}
+
+static __always_inline void classifier_read_payload(struct __sk_buff* skb, skb_info_t* skb_info, char* buf, __u32 size) {
+#pragma unroll
+    for (int i = 0; i < CLASSIFICATION_BUFFER_SIZE; i++) {
+        if (i < size) {
+            buf[i] = load_byte(skb, skb_info->data_off + i);
+        }
+    }
+}
+
+// AMQP clients open connections with the protocol header: "AMQP" followed by the protocol version
+static __always_inline bool is_amqp(const char* buf, __u32 size) {
+    return size == 8 && buf[0] == 'A' && buf[1] == 'M' && buf[2] == 'Q' && buf[3] == 'P';
+}
+
+// Postgres clients open connections with a startup message, a SSL request or a GSSAPI encryption request.
+// None of them have a message type: they start with their length, followed by the protocol version or request code.
+static __always_inline bool is_postgres(const char* buf, __u32 size) {
+    if (size < 8 || classifier_be32(buf) != size) {
+        return false;
+    }
+
+    __u32 code = classifier_be32(buf + 4);
+    return code == POSTGRES_PROTOCOL_3_0 || code == POSTGRES_SSL_REQUEST || code == POSTGRES_GSSENC_REQUEST;
+}
+
+// MySQL servers open connections with a handshake packet: a 3-bytes little-endian length, a sequence id of 0,
+// the protocol version 10 and the server version string
+static __always_inline bool is_mysql_greeting(const char* buf, __u32 size) {
+    if (size < 6) {
+        return false;
+    }
+
+    __u32 length = (__u32)(__u8)buf[0] | ((__u32)(__u8)buf[1] << 8) | ((__u32)(__u8)buf[2] << 16);
+    return length + 4 == size && buf[3] == 0 && buf[4] == MYSQL_HANDSHAKE_V10 && classifier_is_digit(buf[5]);
+}
+
+// Redis commands are sent as arrays of bulk strings, such as "*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\n"
+static __always_inline bool is_redis(const char* buf, __u32 size) {
+    if (size < 6 || buf[0] != '*' || buf[1] < '1' || buf[1] > '9') {
+        return false;
+    }
+
+    if (buf[2] == '\r') {
+        return buf[3] == '\n' && buf[4] == '$';
+    }
+    return classifier_is_digit(buf[2]) && buf[3] == '\r' && buf[4] == '\n' && buf[5] == '$';
+}
+
+// Kafka requests start with their length, followed by the api key, the api version, the correlation id
+// and the nullable client id string
+static __always_inline bool is_kafka(const char* buf, __u32 size) {
+    if (size < 14 || classifier_be32(buf) + 4 != size) {
+        return false;
+    }
+
+    __u16 api_key = classifier_be16(buf + 4);
+    __u16 api_version = classifier_be16(buf + 6);
+    if (api_key > KAFKA_MAX_API_KEY || api_version > KAFKA_MAX_API_VERSION) {
+        return false;
+    }
+
+    // The correlation id is a positive int32
+    if (buf[8] & 0x80) {
+        return false;
+    }
+
+    // -1 stands for a null client id
+    __u16 client_id_size = classifier_be16(buf + 12);
+    return client_id_size == 0xffff || client_id_size <= size - 14;
+}
+
+// classify_payload returns the protocol of a payload, and sets from_client to false if it was sent by the server
+static __always_inline protocol_t classify_payload(const char* buf, __u32 size, bool* from_client) {
+    *from_client = true;
+    if (is_amqp(buf, size)) {
+        return PROTOCOL_AMQP;
+    }
+    if (is_postgres(buf, size)) {
+        return PROTOCOL_POSTGRES;
+    }
+    if (is_redis(buf, size)) {
+        return PROTOCOL_REDIS;
+    }
+    if (is_kafka(buf, size)) {
+        return PROTOCOL_KAFKA;
+    }
+    if (is_mysql_greeting(buf, size)) {
+        *from_client = false;
+        return PROTOCOL_MYSQL;
+    }
+    return PROTOCOL_UNKNOWN;
+}
+
+// is_request returns true if a payload sent by the client starts a request counted for its protocol
+static __always_inline bool is_request(__u8 protocol, const char* buf, __u32 size) {
+    switch (protocol) {
+    case PROTOCOL_POSTGRES:
+        return size >= 5 && (buf[0] == POSTGRES_QUERY || buf[0] == POSTGRES_PARSE);
+    case PROTOCOL_MYSQL:
+        // Commands are the first packet of their exchange, so their sequence id is 0
+        return size >= 5 && buf[3] == 0 && (buf[4] == MYSQL_COM_QUERY || buf[4] == MYSQL_COM_STMT_PREPARE || buf[4] == MYSQL_COM_STMT_EXECUTE);
+    case PROTOCOL_REDIS:
+        return buf[0] == '*';
+    default:
+        return false;
+    }
+}
+
+static __always_inline void classifier_process_packet(struct __sk_buff* skb, skb_info_t* skb_info) {
+    if (!(skb_info->tup.metadata & CONN_TYPE_TCP) || skb->len <= skb_info->data_off) {
+        return;
+    }
+
+    __u32 size = skb->len - skb_info->data_off;
+    char buf[CLASSIFICATION_BUFFER_SIZE] = {};
+    classifier_read_payload(skb, skb_info, buf, size);
+
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, &skb_info->tup, sizeof(conn_tuple_t));
+    bool from_client = true;
+    protocol_stats_t* stats = bpf_map_lookup_elem(&conn_protocols, &tup);
+    if (stats == NULL) {
+        flip_tuple(&tup);
+        from_client = false;
+        stats = bpf_map_lookup_elem(&conn_protocols, &tup);
+    }
+
+    if (stats == NULL) {
+        protocol_t protocol = classify_payload(buf, size, &from_client);
+        if (protocol == PROTOCOL_UNKNOWN) {
+            return;
+        }
+
+        // The tuple is normalized so the client is the source
+        __builtin_memcpy(&tup, &skb_info->tup, sizeof(conn_tuple_t));
+        if (!from_client) {
+            flip_tuple(&tup);
+        }
+
+        protocol_stats_t new_stats = {};
+        new_stats.protocol = protocol;
+        bpf_map_update_elem(&conn_protocols, &tup, &new_stats, BPF_NOEXIST);
+        stats = bpf_map_lookup_elem(&conn_protocols, &tup);
+        if (stats == NULL) {
+            return;
+        }
+    }
+
+    __u64 now = bpf_ktime_get_ns();
+    stats->last_seen = now;
+
+    if (!from_client) {
+        if (stats->request_started != 0) {
+            __sync_fetch_and_add(&stats->requests, 1);
+            __sync_fetch_and_add(&stats->latency_sum, now - stats->request_started);
+            stats->request_started = 0;
+        }
+        return;
+    }
+
+    // Retransmitted requests are ignored
+    if (stats->request_started == 0 && stats->last_request_seq != skb_info->tcp_seq && is_request(stats->protocol, buf, size)) {
+        stats->request_started = now;
+        stats->last_request_seq = skb_info->tcp_seq;
+    }
+}
+
+#endif
@@ -0,0 +1,68 @@
+#ifndef __HTTP2_H
+#define __HTTP2_H
+
+#include "tracer.h"
+#include "bpf_helpers.h"
+#include "tracer-maps.h"
+#include "ip.h"
+
+// HTTP/2 headers are compressed with HPACK, whose dynamic table depends on every header block
+// previously sent on the connection: they can't be decoded from a single packet. Instead of parsing
+// the frames, the socket filter passes the packets of the HTTP/2 connections to userspace, which
+// reassembles and decodes them. The connections are recognized from the preface sent by the client,
+// so only HTTP/2 with prior knowledge (as used by gRPC) is supported, and HTTP/2 over TLS isn't covered.
+// Userspace deletes the connections it doesn't track from http2_conns, so their packets aren't passed anymore.
+
+#define HTTP2_PREFACE "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+#define HTTP2_PREFACE_SIZE 24
+
+static __always_inline bool http2_is_preface(struct __sk_buff* skb, skb_info_t* skb_info) {
+    if (skb->len - skb_info->data_off < HTTP2_PREFACE_SIZE) {
+        return false;
+    }
+
+#pragma unroll
+    for (int i = 0; i < HTTP2_PREFACE_SIZE; i++) {
+        if (load_byte(skb, skb_info->data_off + i) != HTTP2_PREFACE[i]) {
+            return false;
+        }
+    }
+
+    return true;
+}
+
+// http2_filter_packet returns -1 if the packet belongs to a HTTP/2 connection, so it's passed to userspace
+static __always_inline int http2_filter_packet(struct __sk_buff* skb, skb_info_t* skb_info) {
+    if (!(skb_info->tup.metadata & CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, &skb_info->tup, sizeof(conn_tuple_t));
+    __u8* tracked = bpf_map_lookup_elem(&http2_conns, &tup);
+    if (tracked == NULL) {
+        // The packet may be sent by the server
+        flip_tuple(&tup);
+        tracked = bpf_map_lookup_elem(&http2_conns, &tup);
+    }
+
+    if (tracked == NULL) {
+        if (!http2_is_preface(skb, skb_info)) {
+            return 0;
+        }
+
+        // The preface is sent by the client, which is the source of this packet
+        __u8 seen = 1;
+        bpf_map_update_elem(&http2_conns, &skb_info->tup, &seen, BPF_ANY);
+        return -1;
+    }
+
+    if (skb_info->tcp_flags & (TCPHDR_FIN | TCPHDR_RST)) {
+        // The packet is still passed to userspace so the connection state can be released there as well
+        bpf_map_delete_elem(&http2_conns, &tup);
+    }
+
+    return -1;
+}
+
+#endif
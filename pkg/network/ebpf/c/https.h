@@ -0,0 +1,178 @@
+#ifndef __HTTPS_H
+#define __HTTPS_H
+
+#include "tracer.h"
+#include "bpf_helpers.h"
+#include "tracer-maps.h"
+#include "http.h"
+
+// HTTPS monitoring relies on uprobes attached to the functions of the TLS libraries handling
+// plaintext payloads (SSL_read/SSL_write for OpenSSL, gnutls_record_recv/gnutls_record_send
+// for GnuTLS). These functions don't give us access to the underlying socket, so the TCP
+// connection of a TLS session is learned from the TCP kprobes triggered while a call is in flight
+// (see https_bind_sock). The payload is processed when the function returns, and goes through
+// the same code path as the plaintext traffic captured by the socket filter.
+
+static __always_inline void https_save_args(void* ssl_ctx, void* buf) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    ssl_args_t args = {};
+    args.ctx = ssl_ctx;
+    args.buf = buf;
+    bpf_map_update_elem(&ssl_args, &pid_tgid, &args, BPF_ANY);
+}
+
+// https_bind_sock is called from the TCP send/receive kprobes: if the current thread is
+// in the middle of a SSL_read/SSL_write call, the connection is associated to its TLS session
+static __always_inline void https_bind_sock(u64 pid_tgid, conn_tuple_t* t) {
+    ssl_args_t* args = bpf_map_lookup_elem(&ssl_args, &pid_tgid);
+    if (args == NULL) {
+        return;
+    }
+
+    void* ssl_ctx = args->ctx;
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, t, sizeof(conn_tuple_t));
+
+    // Like the tuples read by the socket filter, the HTTP tuples don't hold the PID nor the network namespace
+    tup.pid = 0;
+    tup.netns = 0;
+    bpf_map_update_elem(&ssl_sock_by_ctx, &ssl_ctx, &tup, BPF_ANY);
+    bpf_map_update_elem(&ssl_ctx_by_tuple, &tup, &ssl_ctx, BPF_ANY);
+}
+
+static __always_inline void https_process(struct pt_regs* ctx, int len) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    ssl_args_t* args = bpf_map_lookup_elem(&ssl_args, &pid_tgid);
+    if (args == NULL) {
+        return;
+    }
+
+    void* ssl_ctx = args->ctx;
+    void* buf = args->buf;
+    bpf_map_delete_elem(&ssl_args, &pid_tgid);
+    if (len <= 0) {
+        return;
+    }
+
+    conn_tuple_t* t = bpf_map_lookup_elem(&ssl_sock_by_ctx, &ssl_ctx);
+    if (t == NULL) {
+        log_debug("https_process: no connection found for TLS session: pid_tgid: %d\n", pid_tgid);
+        return;
+    }
+
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, t, sizeof(conn_tuple_t));
+
+    char buffer[HTTP_BUFFER_SIZE];
+    __builtin_memset(&buffer, '\0', sizeof(buffer));
+
+    http_packet_t packet_type = HTTP_PACKET_UNKNOWN;
+    http_method_t method = HTTP_METHOD_UNKNOWN;
+    if (len >= HTTP_BUFFER_SIZE) {
+        bpf_probe_read(&buffer, sizeof(buffer), buf);
+        http_parse_data(buffer, &packet_type, &method);
+    }
+
+    http_transaction_t* http = http_process(&tup, buffer, packet_type, method);
+    if (http != NULL && http_responding(http)) {
+        http->response_last_seen = bpf_ktime_get_ns();
+    }
+
+    // Unlike socket filters, uprobes can flush the completed batches themselves
+    http_notify_batch(ctx);
+}
+
+// https_finish is called when a TLS session is shut down or freed: there is no FIN flag to
+// look at, so this is when the last in-flight HTTP response ends.
+static __always_inline void https_finish(struct pt_regs* ctx, void* ssl_ctx) {
+    conn_tuple_t* t = bpf_map_lookup_elem(&ssl_sock_by_ctx, &ssl_ctx);
+    if (t == NULL) {
+        return;
+    }
+
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, t, sizeof(conn_tuple_t));
+    bpf_map_delete_elem(&ssl_sock_by_ctx, &ssl_ctx);
+    bpf_map_delete_elem(&ssl_ctx_by_tuple, &tup);
+
+    http_transaction_t* http = bpf_map_lookup_elem(&http_in_flight, &tup);
+    if (http == NULL) {
+        return;
+    }
+
+    http_end_response(http);
+    bpf_map_delete_elem(&http_in_flight, &tup);
+    http_notify_batch(ctx);
+}
+
+// https_sock_close is called from tcp_close, so that the TLS sessions whose end we missed
+// (e.g. a process exiting without shutting them down) don't outlive their TCP connection.
+static __always_inline void https_sock_close(conn_tuple_t* t) {
+    conn_tuple_t tup = {};
+    __builtin_memcpy(&tup, t, sizeof(conn_tuple_t));
+    tup.pid = 0;
+    tup.netns = 0;
+
+    void** ssl_ctx = bpf_map_lookup_elem(&ssl_ctx_by_tuple, &tup);
+    if (ssl_ctx == NULL) {
+        return;
+    }
+
+    // If the session was bound to another connection since, it is bound again by its next SSL_read/SSL_write call
+    void* ssl_ctx_copy = *ssl_ctx;
+    bpf_map_delete_elem(&ssl_sock_by_ctx, &ssl_ctx_copy);
+    bpf_map_delete_elem(&ssl_ctx_by_tuple, &tup);
+}
+
+// https_open_enter and https_open_exit report the shared libraries opened by processes
+// to userspace, so the TLS libraries can be instrumented before they are used.
+static __always_inline void https_open_enter(struct pt_regs* ctx) {
+    char* path_argument = (char*)PT_REGS_PARM2(ctx);
+    lib_path_t path = {};
+    if (bpf_probe_read_str(path.buf, sizeof(path.buf), path_argument) < 0) {
+        return;
+    }
+
+    // Find the end of the path, clean up the garbage following it and make sure it's a shared library
+    bool shared_library = false;
+#pragma unroll
+    for (int i = 0; i < LIB_PATH_MAX_SIZE; i++) {
+        if (path.len) {
+            path.buf[i] = 0;
+        } else if (path.buf[i] == 0) {
+            path.len = i;
+        } else if (i >= 2 && path.buf[i - 2] == '.' && path.buf[i - 1] == 's' && path.buf[i] == 'o') {
+            shared_library = true;
+        }
+    }
+
+    // Bail out if the path is larger than our buffer
+    if (!path.len || !shared_library) {
+        return;
+    }
+
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    path.pid = pid_tgid >> 32;
+    bpf_map_update_elem(&open_at_args, &pid_tgid, &path, BPF_ANY);
+}
+
+static __always_inline void https_open_exit(struct pt_regs* ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    lib_path_t* path = bpf_map_lookup_elem(&open_at_args, &pid_tgid);
+    if (path == NULL) {
+        return;
+    }
+
+    // Only report the libraries that could be opened
+    if ((long)PT_REGS_RC(ctx) >= 0) {
+        // Copy the path on the stack since older kernels can't write map values to the perf buffer
+        lib_path_t path_copy = {};
+        __builtin_memcpy(&path_copy, path, sizeof(lib_path_t));
+        u32 cpu = bpf_get_smp_processor_id();
+        bpf_perf_event_output(ctx, &shared_libraries, cpu, &path_copy, sizeof(lib_path_t));
+    }
+
+    bpf_map_delete_elem(&open_at_args, &pid_tgid);
+}
+
+#endif
@@ -6,6 +6,9 @@
 #include "ip.h"
 #include "ipv6.h"
 #include "http.h"
+#include "https.h"
+#include "http2.h"
+#include "classifier.h"
 #include <linux/kconfig.h>
 #include <net/inet_sock.h>
 #include <net/net_namespace.h>
@@ -234,6 +237,12 @@ static __always_inline void update_conn_stats(conn_tuple_t* t, size_t sent_bytes
     }
 }
 
+// saturating_add adds a value to one of the small counters of the tcp stats, which saturate instead of wrapping
+// around since a counter going back to zero would be reported as a huge delta
+static __always_inline __u32 saturating_add(__u32 counter, __u32 value, __u32 max) {
+    return counter + value > max ? max : counter + value;
+}
+
 static __always_inline void update_tcp_stats(conn_tuple_t* t, tcp_stats_t stats) {
     // query stats without the PID from the tuple
     __u32 pid = t->pid;
@@ -251,8 +260,23 @@ static __always_inline void update_tcp_stats(conn_tuple_t* t, tcp_stats_t stats)
 
     if (stats.retransmits > 0) {
         __sync_fetch_and_add(&val->retransmits, stats.retransmits);
+
+        // A segment retransmitted after the SYN was sent, but before the connection got established, is the SYN itself
+        if ((val->state_transitions & (1 << TCP_SYN_SENT)) && !(val->state_transitions & (1 << TCP_ESTABLISHED))) {
+            val->syn_timeouts = saturating_add(val->syn_timeouts, 1, 0xff);
+        }
     }
 
+    if (stats.ooo_packets > 0) {
+        __sync_fetch_and_add(&val->ooo_packets, stats.ooo_packets);
+    }
+
+    // Atomic operations are only supported for 32 and 64 bits values, and these counters are rarely
+    // updated concurrently: they are incremented non-atomically
+    val->zero_window_probes = saturating_add(val->zero_window_probes, stats.zero_window_probes, 0xffff);
+    val->rst_sent = saturating_add(val->rst_sent, stats.rst_sent, 0xff);
+    val->rst_received = saturating_add(val->rst_received, stats.rst_received, 0xff);
+
     if (stats.rtt > 0) {
         // For more information on the bit shift operations see:
         // https://elixir.bootlin.com/linux/v4.6/source/net/ipv4/tcp.c#L2686
@@ -380,6 +404,60 @@ static __always_inline int handle_retransmit(struct sock* sk) {
     return 0;
 }
 
+// get_closing_tcp_conn returns the connection last added to the batch of closed connections of the current CPU if it
+// matches the given tuple, which is used for the events happening within tcp_close once the connection was batched
+static __always_inline tcp_conn_t* get_closing_tcp_conn(conn_tuple_t* t) {
+    u32 cpu = bpf_get_smp_processor_id();
+    batch_t* batch_ptr = bpf_map_lookup_elem(&tcp_close_batch, &cpu);
+    if (batch_ptr == NULL) {
+        return NULL;
+    }
+
+    tcp_conn_t* conn;
+    switch (batch_ptr->pos) {
+    case 1:
+        conn = &batch_ptr->c0;
+        break;
+    case 2:
+        conn = &batch_ptr->c1;
+        break;
+    case 3:
+        conn = &batch_ptr->c2;
+        break;
+    case 4:
+        conn = &batch_ptr->c3;
+        break;
+    case 5:
+        conn = &batch_ptr->c4;
+        break;
+    default:
+        return NULL;
+    }
+
+    // The PID is ignored since the tuple of the event may not have it
+    if (conn->tup.saddr_h != t->saddr_h || conn->tup.saddr_l != t->saddr_l || conn->tup.daddr_h != t->daddr_h ||
+        conn->tup.daddr_l != t->daddr_l || conn->tup.sport != t->sport || conn->tup.dport != t->dport ||
+        conn->tup.netns != t->netns || conn->tup.metadata != t->metadata) {
+        return NULL;
+    }
+
+    return conn;
+}
+
+// handle_tcp_event updates the tcp stats of a socket from a probe which may run outside of the process context
+static __always_inline int handle_tcp_event(struct sock* sk, tcp_stats_t stats) {
+    conn_tuple_t t = {};
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    update_tcp_stats(&t, stats);
+
+    return 0;
+}
+
 static __always_inline void handle_tcp_stats(conn_tuple_t* t, struct sock* sk) {
     u32 rtt = 0, rtt_var = 0;
     bpf_probe_read(&rtt, sizeof(rtt), ((char*)sk) + offset_rtt());
@@ -401,6 +479,7 @@ int kprobe__tcp_sendmsg(struct pt_regs* ctx) {
         return 0;
     }
 
+    https_bind_sock(pid_tgid, &t);
     handle_tcp_stats(&t, sk);
     return handle_message(&t, size, 0);
 }
@@ -454,6 +533,7 @@ int kprobe__tcp_cleanup_rbuf(struct pt_regs* ctx) {
         return 0;
     }
 
+    https_bind_sock(pid_tgid, &t);
     return handle_message(&t, 0, copied);
 }
 
@@ -472,6 +552,7 @@ int kprobe__tcp_close(struct pt_regs* ctx) {
     }
 
     cleanup_tcp_conn(ctx, &t);
+    https_sock_close(&t);
     return 0;
 }
 
@@ -634,6 +715,59 @@ int kprobe__tcp_retransmit_skb(struct pt_regs* ctx) {
     return handle_retransmit(sk);
 }
 
+SEC("kprobe/tcp_connect")
+int kprobe__tcp_connect(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+
+    // tcp_set_state(TCP_SYN_SENT) is called before the source port is assigned, so the transition is recorded here
+    tcp_stats_t stats = { .state_transitions = (1 << TCP_SYN_SENT) };
+    return handle_tcp_event(sk, stats);
+}
+
+SEC("kprobe/tcp_send_probe0")
+int kprobe__tcp_send_probe0(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    tcp_stats_t stats = { .zero_window_probes = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
+SEC("kprobe/tcp_data_queue_ofo")
+int kprobe__tcp_data_queue_ofo(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    tcp_stats_t stats = { .ooo_packets = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
+SEC("kprobe/tcp_send_active_reset")
+int kprobe__tcp_send_active_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    conn_tuple_t t = {};
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    // Sockets closed with unread data or a zero linger time are reset by tcp_close, after kprobe/tcp_close
+    // moved their stats to the batch of closed connections
+    tcp_conn_t* conn = get_closing_tcp_conn(&t);
+    if (conn != NULL) {
+        conn->tcp_stats.rst_sent = saturating_add(conn->tcp_stats.rst_sent, 1, 0xff);
+        return 0;
+    }
+
+    tcp_stats_t stats = { .rst_sent = 1 };
+    update_tcp_stats(&t, stats);
+    return 0;
+}
+
+SEC("kprobe/tcp_reset")
+int kprobe__tcp_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    tcp_stats_t stats = { .rst_received = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
 SEC("kprobe/tcp_set_state")
 int kprobe__tcp_set_state(struct pt_regs* ctx) {
     u8 state = (u8)PT_REGS_PARM2(ctx);
@@ -908,6 +1042,113 @@ int socket__http_filter(struct __sk_buff* skb) {
     return 0;
 }
 
+SEC("socket/http2_filter")
+int socket__http2_filter(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+
+    if (!read_conn_tuple_skb(skb, &skb_info)) {
+        return 0;
+    }
+
+    return http2_filter_packet(skb, &skb_info);
+}
+
+SEC("socket/classifier_filter")
+int socket__classifier_filter(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+
+    if (!read_conn_tuple_skb(skb, &skb_info)) {
+        return 0;
+    }
+
+    classifier_process_packet(skb, &skb_info);
+    return 0;
+}
+
+SEC("uprobe/SSL_read")
+int uprobe__SSL_read(struct pt_regs* ctx) {
+    https_save_args((void*)PT_REGS_PARM1(ctx), (void*)PT_REGS_PARM2(ctx));
+    return 0;
+}
+
+SEC("uretprobe/SSL_read")
+int uretprobe__SSL_read(struct pt_regs* ctx) {
+    https_process(ctx, (int)PT_REGS_RC(ctx));
+    return 0;
+}
+
+SEC("uprobe/SSL_write")
+int uprobe__SSL_write(struct pt_regs* ctx) {
+    https_save_args((void*)PT_REGS_PARM1(ctx), (void*)PT_REGS_PARM2(ctx));
+    return 0;
+}
+
+SEC("uretprobe/SSL_write")
+int uretprobe__SSL_write(struct pt_regs* ctx) {
+    https_process(ctx, (int)PT_REGS_RC(ctx));
+    return 0;
+}
+
+SEC("uprobe/SSL_shutdown")
+int uprobe__SSL_shutdown(struct pt_regs* ctx) {
+    https_finish(ctx, (void*)PT_REGS_PARM1(ctx));
+    return 0;
+}
+
+SEC("uprobe/SSL_free")
+int uprobe__SSL_free(struct pt_regs* ctx) {
+    https_finish(ctx, (void*)PT_REGS_PARM1(ctx));
+    return 0;
+}
+
+SEC("uprobe/gnutls_record_recv")
+int uprobe__gnutls_record_recv(struct pt_regs* ctx) {
+    https_save_args((void*)PT_REGS_PARM1(ctx), (void*)PT_REGS_PARM2(ctx));
+    return 0;
+}
+
+SEC("uretprobe/gnutls_record_recv")
+int uretprobe__gnutls_record_recv(struct pt_regs* ctx) {
+    https_process(ctx, (int)PT_REGS_RC(ctx));
+    return 0;
+}
+
+SEC("uprobe/gnutls_record_send")
+int uprobe__gnutls_record_send(struct pt_regs* ctx) {
+    https_save_args((void*)PT_REGS_PARM1(ctx), (void*)PT_REGS_PARM2(ctx));
+    return 0;
+}
+
+SEC("uretprobe/gnutls_record_send")
+int uretprobe__gnutls_record_send(struct pt_regs* ctx) {
+    https_process(ctx, (int)PT_REGS_RC(ctx));
+    return 0;
+}
+
+SEC("uprobe/gnutls_bye")
+int uprobe__gnutls_bye(struct pt_regs* ctx) {
+    https_finish(ctx, (void*)PT_REGS_PARM1(ctx));
+    return 0;
+}
+
+SEC("uprobe/gnutls_deinit")
+int uprobe__gnutls_deinit(struct pt_regs* ctx) {
+    https_finish(ctx, (void*)PT_REGS_PARM1(ctx));
+    return 0;
+}
+
+SEC("kprobe/do_sys_open")
+int kprobe__do_sys_open(struct pt_regs* ctx) {
+    https_open_enter(ctx);
+    return 0;
+}
+
+SEC("kretprobe/do_sys_open")
+int kretprobe__do_sys_open(struct pt_regs* ctx) {
+    https_open_exit(ctx);
+    return 0;
+}
+
 // This number will be interpreted by elf-loader to set the current running kernel version
 __u32 _version SEC("version") = 0xFFFFFFFE; // NOLINT(bugprone-reserved-identifier)
 
@@ -151,6 +151,94 @@ struct bpf_map_def SEC("maps/http_batch_state") http_batch_state = {
     .namespace = "",
 };
 
+/* This map associates a TLS session (the SSL* or gnutls_session_t pointer) to the TCP connection it uses.
+ * It's populated by the TCP kprobes triggered while a SSL_read/SSL_write call is in flight.
+ * Entries are removed when the session is shut down or freed, or when its TCP connection is closed.
+ * This is a LRU map so that the sessions we miss the end of can't fill it up.
+ */
+struct bpf_map_def SEC("maps/ssl_sock_by_ctx") ssl_sock_by_ctx = {
+    .type = BPF_MAP_TYPE_LRU_HASH,
+    .key_size = sizeof(void *),
+    .value_size = sizeof(conn_tuple_t),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map is the reverse of ssl_sock_by_ctx, so the TLS session of a TCP connection can be forgotten in tcp_close */
+struct bpf_map_def SEC("maps/ssl_ctx_by_tuple") ssl_ctx_by_tuple = {
+    .type = BPF_MAP_TYPE_LRU_HASH,
+    .key_size = sizeof(conn_tuple_t),
+    .value_size = sizeof(void *),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map holds the arguments of the in-flight SSL_read/SSL_write calls
+ *
+ * Keys: the PID returned by bpf_get_current_pid_tgid()
+ * Values: the TLS session and plaintext buffer of the call
+ */
+struct bpf_map_def SEC("maps/ssl_args") ssl_args = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(__u64),
+    .value_size = sizeof(ssl_args_t),
+    .max_entries = 1024,
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map holds the path of the shared libraries being opened between the call and the return of do_sys_open
+ *
+ * Keys: the PID returned by bpf_get_current_pid_tgid()
+ * Values: the path of the library
+ */
+struct bpf_map_def SEC("maps/open_at_args") open_at_args = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(__u64),
+    .value_size = sizeof(lib_path_t),
+    .max_entries = 1024,
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map is used for notifying userspace that a shared library was opened */
+struct bpf_map_def SEC("maps/shared_libraries") shared_libraries = {
+    .type = BPF_MAP_TYPE_PERF_EVENT_ARRAY,
+    .key_size = sizeof(__u32),
+    .value_size = sizeof(__u32),
+    .max_entries = 0, // This will get overridden at runtime
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map holds the HTTP/2 connections whose packets are passed to userspace by the socket filter.
+ * The tuples are normalized so the client (the peer that sent the connection preface) is the source.
+ * This is a LRU map, since the connections whose FIN or RST was missed are never deleted otherwise.
+ */
+struct bpf_map_def SEC("maps/http2_conns") http2_conns = {
+    .type = BPF_MAP_TYPE_LRU_HASH,
+    .key_size = sizeof(conn_tuple_t),
+    .value_size = sizeof(__u8),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map holds the application protocol classified for the TCP connections by the classifier socket filter.
+ * The tuples are normalized so the client is the source. The entries are removed by userspace once the
+ * connection is closed or expired.
+ */
+struct bpf_map_def SEC("maps/conn_protocols") conn_protocols = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(conn_tuple_t),
+    .value_size = sizeof(protocol_stats_t),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
 /* This map is used for telemetry in kernelspace
  * only key 0 is used
  * value is a telemetry object
@@ -41,13 +41,24 @@ typedef struct {
     __u32 metadata; // This is that big because it seems that we atleast need a 32-bit aligned struct
 } conn_tuple_t;
 
+// The counters which can't grow large over the lifetime of a connection are kept small, since the tcp stats
+// are part of the batches of closed connections, which must fit in the 512 bytes of the eBPF stack
 typedef struct {
     __u32 retransmits;
     __u32 rtt;
     __u32 rtt_var;
+    // Number of segments received out of order
+    __u32 ooo_packets;
+    // Number of zero window probes sent, while the peer advertised a zero receive window
+    __u16 zero_window_probes;
 
     // Bit mask containing all TCP state transitions tracked by our tracer
     __u16 state_transitions;
+
+    __u8 rst_sent;
+    __u8 rst_received;
+    // Number of times the SYN timed out and was retransmitted
+    __u8 syn_timeouts;
 } tcp_stats_t;
 
 // Full data for a tcp connection
@@ -61,6 +72,7 @@ typedef struct {
 // tcp_flag_byte(th) (((u_int8_t *)th)[13])
 #define TCP_FLAGS_OFFSET 13
 #define TCPHDR_FIN 0x01
+#define TCPHDR_RST 0x04
 
 // skb_info_t embeds a conn_tuple_t extracted from the skb object as well as
 // some ancillary data such as the data offset (the byte offset pointing to
@@ -70,9 +82,36 @@ typedef struct {
 typedef struct {
     conn_tuple_t tup;
     __u32 data_off;
+    __u32 tcp_seq;
     __u8 tcp_flags;
 } skb_info_t;
 
+// This determines the number of payload bytes read to classify the application protocol of a connection
+#define CLASSIFICATION_BUFFER_SIZE 16
+
+typedef enum {
+    PROTOCOL_UNKNOWN,
+    PROTOCOL_POSTGRES,
+    PROTOCOL_MYSQL,
+    PROTOCOL_REDIS,
+    PROTOCOL_KAFKA,
+    PROTOCOL_AMQP
+} protocol_t;
+
+// Application protocol of a connection, along with the requests counted for the protocols
+// whose request/response exchanges can be told apart from a single packet
+typedef struct {
+    __u64 requests;
+    // Sum of the latencies of the counted requests, in nanoseconds
+    __u64 latency_sum;
+    // Timestamp of the request waiting for its response, 0 if none
+    __u64 request_started;
+    __u64 last_seen;
+    // TCP sequence number of the last request, used to ignore its retransmissions
+    __u32 last_request_seq;
+    __u8 protocol;
+} protocol_stats_t;
+
 // This determines the size of the payload fragment that is captured for each HTTP request
 #define HTTP_BUFFER_SIZE 25
 // This controls the number of HTTP transactions read from userspace at a time
@@ -145,6 +184,25 @@ typedef struct {
     __u64 batch_idx;
 } http_batch_notification_t;
 
+// ssl_args_t holds the arguments of an in-flight SSL_read/SSL_write call (or of
+// their GnuTLS counterparts) until the function returns, since the plaintext
+// buffer of a read is only populated at that point.
+typedef struct {
+    void *ctx;
+    void *buf;
+} ssl_args_t;
+
+// This determines the maximum size of a shared library path sent to userspace
+#define LIB_PATH_MAX_SIZE 120
+
+// lib_path_t is sent to userspace every time a process opens a shared library
+// so the TLS libraries can be instrumented as soon as they are loaded.
+typedef struct {
+    __u32 pid;
+    __u32 len;
+    char buf[LIB_PATH_MAX_SIZE];
+} lib_path_t;
+
 // Must match the number of tcp_conn_t objects embedded in the batch_t struct
 #ifndef TCP_CLOSED_BATCH_SIZE
 #define TCP_CLOSED_BATCH_SIZE 5
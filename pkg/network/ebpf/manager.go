@@ -32,8 +32,8 @@ func NewOffsetManager() *manager.Manager {
 	}
 }
 
-func NewManager(closedHandler, httpHandler *ebpf.PerfHandler) *manager.Manager {
-	return &manager.Manager{
+func NewManager(closedHandler, httpHandler, sharedLibrariesHandler *ebpf.PerfHandler) *manager.Manager {
+	m := &manager.Manager{
 		Maps: []*manager.Map{
 			{Name: string(probes.ConnMap)},
 			{Name: string(probes.TcpStatsMap)},
@@ -46,6 +46,12 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler) *manager.Manager {
 			{Name: string(probes.HttpInFlightMap)},
 			{Name: string(probes.HttpBatchesMap)},
 			{Name: string(probes.HttpBatchStateMap)},
+			{Name: string(probes.SSLSockByCtxMap)},
+			{Name: string(probes.SSLCtxByTupleMap)},
+			{Name: string(probes.SSLArgsMap)},
+			{Name: string(probes.OpenAtArgsMap)},
+			{Name: string(probes.Http2ConnsMap)},
+			{Name: string(probes.ConnProtocolsMap)},
 		},
 		PerfMaps: []*manager.PerfMap{
 			{
@@ -66,6 +72,15 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler) *manager.Manager {
 					LostHandler:        httpHandler.LostHandler,
 				},
 			},
+			{
+				Map: manager.Map{Name: string(probes.SharedLibrariesMap)},
+				PerfMapOptions: manager.PerfMapOptions{
+					PerfRingBufferSize: 8 * os.Getpagesize(),
+					Watermark:          1,
+					DataHandler:        sharedLibrariesHandler.DataHandler,
+					LostHandler:        sharedLibrariesHandler.LostHandler,
+				},
+			},
 		},
 		Probes: []*manager.Probe{
 			{Section: string(probes.TCPSendMsg)},
@@ -81,6 +96,12 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler) *manager.Manager {
 			{Section: string(probes.UDPRecvMsgPre410), MatchFuncName: "^udp_recvmsg$"},
 			{Section: string(probes.UDPRecvMsgReturn), KProbeMaxActive: maxActive},
 			{Section: string(probes.TCPRetransmit)},
+			{Section: string(probes.TCPConnect)},
+			{Section: string(probes.TCPSendProbe0)},
+			// tcp_data_queue_ofo is a static function, which can get renamed by compiler optimizations
+			{Section: string(probes.TCPDataQueueOfo), MatchFuncName: `^tcp_data_queue_ofo(\.(isra|constprop)\.\d+)?$`},
+			{Section: string(probes.TCPSendActiveReset)},
+			{Section: string(probes.TCPReset)},
 			{Section: string(probes.InetCskAcceptReturn), KProbeMaxActive: maxActive},
 			{Section: string(probes.TCPv4DestroySock)},
 			{Section: string(probes.UDPDestroySock)},
@@ -90,6 +111,15 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler) *manager.Manager {
 			{Section: string(probes.Inet6BindRet), KProbeMaxActive: maxActive},
 			{Section: string(probes.SocketDnsFilter)},
 			{Section: string(probes.SocketHTTPFilter)},
+			{Section: string(probes.SocketHTTP2Filter)},
+			{Section: string(probes.SocketClassifierFilter)},
+			{Section: string(probes.DoSysOpen)},
+			{Section: string(probes.DoSysOpenReturn), KProbeMaxActive: maxActive},
 		},
 	}
+	// The TLS library probes are only loaded here: they get attached to the libraries as processes load them
+	for _, p := range append(probes.OpenSSLProbes, probes.GnuTLSProbes...) {
+		m.Probes = append(m.Probes, &manager.Probe{Section: string(p)})
+	}
+	return m
 }
@@ -59,6 +59,17 @@ const (
 	// TCPRetransmit traces the return value for the tcp_retransmit_skb() system call
 	TCPRetransmit ProbeName = "kprobe/tcp_retransmit_skb"
 
+	// TCPConnect traces the tcp_connect() kernel function, sending the SYN of outgoing connections
+	TCPConnect ProbeName = "kprobe/tcp_connect"
+	// TCPSendProbe0 traces the tcp_send_probe0() kernel function, probing a zero receive window of the peer
+	TCPSendProbe0 ProbeName = "kprobe/tcp_send_probe0"
+	// TCPDataQueueOfo traces the tcp_data_queue_ofo() kernel function, queuing the segments received out of order
+	TCPDataQueueOfo ProbeName = "kprobe/tcp_data_queue_ofo"
+	// TCPSendActiveReset traces the tcp_send_active_reset() kernel function, sending a RST to abort a connection
+	TCPSendActiveReset ProbeName = "kprobe/tcp_send_active_reset"
+	// TCPReset traces the tcp_reset() kernel function, handling the RST received on a connection
+	TCPReset ProbeName = "kprobe/tcp_reset"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn ProbeName = "kretprobe/inet_csk_accept"
 
@@ -77,8 +88,54 @@ const (
 
 	// SocketHTTPFilter is the socket probe for HTTP
 	SocketHTTPFilter ProbeName = "socket/http_filter"
+
+	// SocketHTTP2Filter is the socket probe passing the packets of HTTP/2 connections to userspace
+	SocketHTTP2Filter ProbeName = "socket/http2_filter"
+
+	// SocketClassifierFilter is the socket probe classifying the application protocol of TCP connections
+	SocketClassifierFilter ProbeName = "socket/classifier_filter"
+
+	// DoSysOpen traces the do_sys_open() kernel function to detect the shared libraries opened by processes
+	DoSysOpen ProbeName = "kprobe/do_sys_open"
+	// DoSysOpenReturn traces the return value for the do_sys_open() kernel function
+	DoSysOpenReturn ProbeName = "kretprobe/do_sys_open"
+
+	// The following uprobes are attached to the TLS libraries to capture HTTPS payloads in plaintext.
+	// They are only hooked when a process loads libssl or libgnutls.
+
+	// SSLRead traces the SSL_read() function of OpenSSL
+	SSLRead ProbeName = "uprobe/SSL_read"
+	// SSLReadReturn traces the return value for the SSL_read() function of OpenSSL
+	SSLReadReturn ProbeName = "uretprobe/SSL_read"
+	// SSLWrite traces the SSL_write() function of OpenSSL
+	SSLWrite ProbeName = "uprobe/SSL_write"
+	// SSLWriteReturn traces the return value for the SSL_write() function of OpenSSL
+	SSLWriteReturn ProbeName = "uretprobe/SSL_write"
+	// SSLShutdown traces the SSL_shutdown() function of OpenSSL
+	SSLShutdown ProbeName = "uprobe/SSL_shutdown"
+	// SSLFree traces the SSL_free() function of OpenSSL
+	SSLFree ProbeName = "uprobe/SSL_free"
+
+	// GnuTLSRecordRecv traces the gnutls_record_recv() function of GnuTLS
+	GnuTLSRecordRecv ProbeName = "uprobe/gnutls_record_recv"
+	// GnuTLSRecordRecvReturn traces the return value for the gnutls_record_recv() function of GnuTLS
+	GnuTLSRecordRecvReturn ProbeName = "uretprobe/gnutls_record_recv"
+	// GnuTLSRecordSend traces the gnutls_record_send() function of GnuTLS
+	GnuTLSRecordSend ProbeName = "uprobe/gnutls_record_send"
+	// GnuTLSRecordSendReturn traces the return value for the gnutls_record_send() function of GnuTLS
+	GnuTLSRecordSendReturn ProbeName = "uretprobe/gnutls_record_send"
+	// GnuTLSBye traces the gnutls_bye() function of GnuTLS
+	GnuTLSBye ProbeName = "uprobe/gnutls_bye"
+	// GnuTLSDeinit traces the gnutls_deinit() function of GnuTLS
+	GnuTLSDeinit ProbeName = "uprobe/gnutls_deinit"
 )
 
+// OpenSSLProbes are the probes hooked on libssl
+var OpenSSLProbes = []ProbeName{SSLRead, SSLReadReturn, SSLWrite, SSLWriteReturn, SSLShutdown, SSLFree}
+
+// GnuTLSProbes are the probes hooked on libgnutls
+var GnuTLSProbes = []ProbeName{GnuTLSRecordRecv, GnuTLSRecordRecvReturn, GnuTLSRecordSend, GnuTLSRecordSendReturn, GnuTLSBye, GnuTLSDeinit}
+
 // BPFMapName stores the name of the BPF maps storing statistics and other info
 type BPFMapName string
 
@@ -95,6 +152,13 @@ const (
 	HttpBatchesMap       BPFMapName = "http_batches"
 	HttpBatchStateMap    BPFMapName = "http_batch_state"
 	HttpNotificationsMap BPFMapName = "http_notifications"
+	SSLSockByCtxMap      BPFMapName = "ssl_sock_by_ctx"
+	SSLCtxByTupleMap     BPFMapName = "ssl_ctx_by_tuple"
+	SSLArgsMap           BPFMapName = "ssl_args"
+	OpenAtArgsMap        BPFMapName = "open_at_args"
+	SharedLibrariesMap   BPFMapName = "shared_libraries"
+	Http2ConnsMap        BPFMapName = "http2_conns"
+	ConnProtocolsMap     BPFMapName = "conn_protocols"
 )
 
 // SectionName returns the SectionName for the given BPF map
@@ -35,6 +35,18 @@ func TestSerialization(t *testing.T) {
 				NetNS:                7,
 				SPort:                1000,
 				DPort:                9000,
+
+				MonotonicZeroWindowProbes:  4,
+				LastZeroWindowProbes:       2,
+				MonotonicOutOfOrderPackets: 12,
+				LastOutOfOrderPackets:      5,
+				MonotonicResetsSent:        1,
+				LastResetsSent:             1,
+				MonotonicResetsReceived:    1,
+				LastResetsReceived:         0,
+				MonotonicSYNTimeouts:       3,
+				LastSYNTimeouts:            3,
+
 				IPTranslation: &network.IPTranslation{
 					ReplSrcIP:   util.AddressFromString("20.1.1.1"),
 					ReplDstIP:   util.AddressFromString("20.1.1.1"),
@@ -45,14 +57,28 @@ func TestSerialization(t *testing.T) {
 				Type:      network.UDP,
 				Family:    network.AFINET6,
 				Direction: network.LOCAL,
+				Protocol:  network.ProtocolRedis,
+
+				MonotonicProtocolRequests:   30,
+				LastProtocolRequests:        3,
+				MonotonicProtocolLatencySum: 3000,
+				LastProtocolLatencySum:      300,
 
 				DNSCountByRcode: map[uint32]uint32{0: 1},
-				DNSStatsByDomain: map[string]network.DNSStats{
+				DNSStatsByDomain: map[string]map[network.QueryType]network.DNSStats{
 					"foo.com": {
-						DNSTimeouts:          0,
-						DNSSuccessLatencySum: 0,
-						DNSFailureLatencySum: 0,
-						DNSCountByRcode:      map[uint32]uint32{0: 1},
+						network.DNSTypeA: {
+							DNSTimeouts:          0,
+							DNSSuccessLatencySum: 0,
+							DNSFailureLatencySum: 0,
+							DNSCountByRcode:      map[uint32]uint32{0: 1},
+						},
+						network.DNSTypeAAAA: {
+							DNSTimeouts:          1,
+							DNSSuccessLatencySum: 0,
+							DNSFailureLatencySum: 0,
+							DNSCountByRcode:      map[uint32]uint32{0: 2},
+						},
 					},
 				},
 
@@ -78,6 +104,13 @@ func TestSerialization(t *testing.T) {
 				LastTcpClosed:      1,
 				Pid:                int32(6000),
 				NetNS:              7,
+
+				LastZeroWindowProbes:  2,
+				LastOutOfOrderPackets: 5,
+				LastResetsSent:        1,
+				LastResetsReceived:    0,
+				LastSynTimeouts:       3,
+
 				IpTranslation: &model.IPTranslation{
 					ReplSrcIP:   "20.1.1.1",
 					ReplDstIP:   "20.1.1.1",
@@ -88,14 +121,36 @@ func TestSerialization(t *testing.T) {
 				Type:      model.ConnectionType_udp,
 				Family:    model.ConnectionFamily_v6,
 				Direction: model.ConnectionDirection_local,
+				Protocol:  model.ConnectionProtocol_redis,
+
+				LastProtocolRequests:   3,
+				LastProtocolLatencySum: 300,
 
 				DnsCountByRcode: map[uint32]uint32{0: 1},
 				DnsStatsByDomain: map[int32]*model.DNSStats{
 					0: {
-						DnsTimeouts:          0,
+						DnsTimeouts:          1,
 						DnsSuccessLatencySum: 0,
 						DnsFailureLatencySum: 0,
-						DnsCountByRcode:      map[uint32]uint32{0: 1},
+						DnsCountByRcode:      map[uint32]uint32{0: 3},
+					},
+				},
+				DnsStatsByDomainByQueryType: map[int32]*model.DNSStatsByQueryType{
+					0: {
+						DnsStatsByQueryType: map[int32]*model.DNSStats{
+							int32(network.DNSTypeA): {
+								DnsTimeouts:          0,
+								DnsSuccessLatencySum: 0,
+								DnsFailureLatencySum: 0,
+								DnsCountByRcode:      map[uint32]uint32{0: 1},
+							},
+							int32(network.DNSTypeAAAA): {
+								DnsTimeouts:          1,
+								DnsSuccessLatencySum: 0,
+								DnsFailureLatencySum: 0,
+								DnsCountByRcode:      map[uint32]uint32{0: 2},
+							},
+						},
 					},
 				},
 
@@ -35,6 +35,9 @@ func FormatConnection(conn network.ConnectionStats, domainSet map[string]int) *m
 	c.LastBytesReceived = conn.LastRecvBytes
 	c.LastRetransmits = conn.LastRetransmits
 	c.Direction = formatDirection(conn.Direction)
+	c.Protocol = formatProtocol(conn.Protocol)
+	c.LastProtocolRequests = conn.LastProtocolRequests
+	c.LastProtocolLatencySum = conn.LastProtocolLatencySum
 	c.NetNS = conn.NetNS
 	c.RemoteNetworkId = ""
 	c.IpTranslation = formatIPTranslation(conn.IPTranslation)
@@ -49,7 +52,13 @@ func FormatConnection(conn network.ConnectionStats, domainSet map[string]int) *m
 	c.DnsCountByRcode = conn.DNSCountByRcode
 	c.LastTcpEstablished = conn.LastTCPEstablished
 	c.LastTcpClosed = conn.LastTCPClosed
+	c.LastZeroWindowProbes = conn.LastZeroWindowProbes
+	c.LastOutOfOrderPackets = conn.LastOutOfOrderPackets
+	c.LastResetsSent = conn.LastResetsSent
+	c.LastResetsReceived = conn.LastResetsReceived
+	c.LastSynTimeouts = conn.LastSYNTimeouts
 	c.DnsStatsByDomain = formatDNSStatsByDomain(conn.DNSStatsByDomain, domainSet)
+	c.DnsStatsByDomainByQueryType = formatDNSStatsByDomainByQueryType(conn.DNSStatsByDomain, domainSet)
 	c.HttpStatsByPath = formatHTTPStatsByPath(conn.HTTPStatsByPath)
 	return c
 }
@@ -162,24 +171,69 @@ func formatDirection(d network.ConnectionDirection) model.ConnectionDirection {
 	}
 }
 
-func formatDNSStatsByDomain(stats map[string]network.DNSStats, domainSet map[string]int) map[int32]*model.DNSStats {
+func formatProtocol(p network.ProtocolType) model.ConnectionProtocol {
+	switch p {
+	case network.ProtocolPostgres:
+		return model.ConnectionProtocol_postgres
+	case network.ProtocolMySQL:
+		return model.ConnectionProtocol_mysql
+	case network.ProtocolRedis:
+		return model.ConnectionProtocol_redis
+	case network.ProtocolKafka:
+		return model.ConnectionProtocol_kafka
+	case network.ProtocolAMQP:
+		return model.ConnectionProtocol_amqp
+	default:
+		return model.ConnectionProtocol_protocolUnknown
+	}
+}
+
+// formatDNSStatsByDomain aggregates the stats of all the query types of each domain,
+// for the clients which don't read the stats by query type
+func formatDNSStatsByDomain(stats map[string]map[network.QueryType]network.DNSStats, domainSet map[string]int) map[int32]*model.DNSStats {
 	m := make(map[int32]*model.DNSStats)
-	for d, s := range stats {
-		var ms model.DNSStats
-		ms.DnsCountByRcode = s.DNSCountByRcode
-		ms.DnsFailureLatencySum = s.DNSFailureLatencySum
-		ms.DnsSuccessLatencySum = s.DNSSuccessLatencySum
-		ms.DnsTimeouts = s.DNSTimeouts
-		pos, ok := domainSet[d]
-		if !ok {
-			pos = len(domainSet)
-			domainSet[d] = pos
+	for d, statsByType := range stats {
+		ms := model.DNSStats{DnsCountByRcode: make(map[uint32]uint32)}
+		for _, s := range statsByType {
+			for rcode, count := range s.DNSCountByRcode {
+				ms.DnsCountByRcode[rcode] += count
+			}
+			ms.DnsFailureLatencySum += s.DNSFailureLatencySum
+			ms.DnsSuccessLatencySum += s.DNSSuccessLatencySum
+			ms.DnsTimeouts += s.DNSTimeouts
+		}
+		m[domainIndex(d, domainSet)] = &ms
+	}
+	return m
+}
+
+func formatDNSStatsByDomainByQueryType(stats map[string]map[network.QueryType]network.DNSStats, domainSet map[string]int) map[int32]*model.DNSStatsByQueryType {
+	m := make(map[int32]*model.DNSStatsByQueryType)
+	for d, statsByType := range stats {
+		ms := model.DNSStatsByQueryType{DnsStatsByQueryType: make(map[int32]*model.DNSStats)}
+		for qtype, s := range statsByType {
+			ms.DnsStatsByQueryType[int32(qtype)] = &model.DNSStats{
+				DnsCountByRcode:      s.DNSCountByRcode,
+				DnsFailureLatencySum: s.DNSFailureLatencySum,
+				DnsSuccessLatencySum: s.DNSSuccessLatencySum,
+				DnsTimeouts:          s.DNSTimeouts,
+			}
 		}
-		m[int32(pos)] = &ms
+		m[domainIndex(d, domainSet)] = &ms
 	}
 	return m
 }
 
+// domainIndex returns the index of a domain in the domains of the payload, adding it if needed
+func domainIndex(domain string, domainSet map[string]int) int32 {
+	pos, ok := domainSet[domain]
+	if !ok {
+		pos = len(domainSet)
+		domainSet[domain] = pos
+	}
+	return int32(pos)
+}
+
 func formatIPTranslation(ct *network.IPTranslation) *model.IPTranslation {
 	if ct == nil {
 		return nil
@@ -77,6 +77,46 @@ func (d ConnectionDirection) String() string {
 	}
 }
 
+// ProtocolType is the application protocol of a connection, as classified from its payloads
+type ProtocolType uint8
+
+const (
+	// ProtocolUnknown is used for the connections whose protocol wasn't classified
+	ProtocolUnknown ProtocolType = 0
+
+	// ProtocolPostgres represents PostgreSQL connections
+	ProtocolPostgres ProtocolType = 1
+
+	// ProtocolMySQL represents MySQL connections
+	ProtocolMySQL ProtocolType = 2
+
+	// ProtocolRedis represents Redis connections
+	ProtocolRedis ProtocolType = 3
+
+	// ProtocolKafka represents Kafka connections
+	ProtocolKafka ProtocolType = 4
+
+	// ProtocolAMQP represents AMQP connections
+	ProtocolAMQP ProtocolType = 5
+)
+
+func (p ProtocolType) String() string {
+	switch p {
+	case ProtocolPostgres:
+		return "postgres"
+	case ProtocolMySQL:
+		return "mysql"
+	case ProtocolRedis:
+		return "redis"
+	case ProtocolKafka:
+		return "kafka"
+	case ProtocolAMQP:
+		return "amqp"
+	default:
+		return "unknown"
+	}
+}
+
 // Connections wraps a collection of ConnectionStats
 type Connections struct {
 	DNS       map[util.Address][]string
@@ -130,6 +170,33 @@ type ConnectionStats struct {
 	MonotonicTCPClosed uint32
 	LastTCPClosed      uint32
 
+	// MonotonicZeroWindowProbes counts the zero window probes sent while the peer advertised a zero window
+	MonotonicZeroWindowProbes uint32
+	LastZeroWindowProbes      uint32
+
+	// MonotonicOutOfOrderPackets counts the segments received out of order
+	MonotonicOutOfOrderPackets uint32
+	LastOutOfOrderPackets      uint32
+
+	// MonotonicResetsSent and MonotonicResetsReceived count the TCP resets sent and received on the connection
+	MonotonicResetsSent     uint32
+	LastResetsSent          uint32
+	MonotonicResetsReceived uint32
+	LastResetsReceived      uint32
+
+	// MonotonicSYNTimeouts counts the times the SYN of the connection timed out and was retransmitted
+	MonotonicSYNTimeouts uint32
+	LastSYNTimeouts      uint32
+
+	// MonotonicProtocolRequests counts the requests completed on the connection, for the protocols
+	// whose requests are tracked (Postgres, MySQL and Redis)
+	MonotonicProtocolRequests uint64
+	LastProtocolRequests      uint64
+
+	// MonotonicProtocolLatencySum is the sum of the latencies of these requests, in nanoseconds
+	MonotonicProtocolLatencySum uint64
+	LastProtocolLatencySum      uint64
+
 	Pid   uint32
 	NetNS uint32
 
@@ -138,6 +205,7 @@ type ConnectionStats struct {
 	Type                   ConnectionType
 	Family                 ConnectionFamily
 	Direction              ConnectionDirection
+	Protocol               ProtocolType
 	IPTranslation          *IPTranslation
 	IntraHost              bool
 	DNSSuccessfulResponses uint32
@@ -146,7 +214,7 @@ type ConnectionStats struct {
 	DNSSuccessLatencySum   uint64
 	DNSFailureLatencySum   uint64
 	DNSCountByRcode        map[uint32]uint32
-	DNSStatsByDomain       map[string]DNSStats
+	DNSStatsByDomain       map[string]map[QueryType]DNSStats
 	HTTPStatsByPath        map[string]http.RequestStats
 }
 
@@ -246,6 +314,10 @@ func ConnectionSummary(c *ConnectionStats, names map[util.Address][]string) stri
 		)
 	}
 
+	if c.Protocol != ProtocolUnknown {
+		str += fmt.Sprintf(", %s, %d requests (+%d)", c.Protocol, c.MonotonicProtocolRequests, c.LastProtocolRequests)
+	}
+
 	return str
 }
 
@@ -266,10 +338,26 @@ type DNSKey struct {
 	protocol ConnectionType
 }
 
-// DNSStats holds statistics corresponding to a particular domain
+// DNSStats holds statistics corresponding to a particular domain and query type
 type DNSStats struct {
 	DNSTimeouts          uint32
 	DNSSuccessLatencySum uint64
 	DNSFailureLatencySum uint64
 	DNSCountByRcode      map[uint32]uint32
 }
+
+// QueryType is the type of the question of a DNS query, such as A or AAAA
+type QueryType uint16
+
+// We could have used the DNS types of gopacket here, but importing the library only for these constants is overkill.
+const (
+	DNSTypeA     QueryType = 1
+	DNSTypeNS    QueryType = 2
+	DNSTypeCNAME QueryType = 5
+	DNSTypeSOA   QueryType = 6
+	DNSTypePTR   QueryType = 12
+	DNSTypeMX    QueryType = 15
+	DNSTypeTXT   QueryType = 16
+	DNSTypeAAAA  QueryType = 28
+	DNSTypeSRV   QueryType = 33
+)
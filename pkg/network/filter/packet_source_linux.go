@@ -11,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/ebpf/manager"
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/afpacket"
 )
 
@@ -34,6 +35,7 @@ func NewPacketSource(filter *manager.Probe) (*AFPacketSource, error) {
 		afpacket.OptPollTimeout(1*time.Second),
 		// This setup will require ~4Mb that is mmap'd into the process virtual space
 		// More information here: https://www.kernel.org/doc/Documentation/networking/packet_mmap.txt
+		// Packets larger than a frame are truncated, their original length is part of the capture info
 		afpacket.OptFrameSize(4096),
 		afpacket.OptBlockSize(4096*128),
 		afpacket.OptNumBlocks(8),
@@ -72,6 +74,14 @@ func (p *AFPacketSource) Stats() map[string]int64 {
 }
 
 func (p *AFPacketSource) VisitPackets(exit <-chan struct{}, visit func([]byte, time.Time) error) error {
+	return p.VisitPacketsWithInfo(exit, func(data []byte, info gopacket.CaptureInfo) error {
+		return visit(data, info.Timestamp)
+	})
+}
+
+// VisitPacketsWithInfo is like VisitPackets, but the visitor gets the capture info of the packets.
+// Packets larger than the frame size are truncated, in which case info.Length is larger than len(data).
+func (p *AFPacketSource) VisitPacketsWithInfo(exit <-chan struct{}, visit func([]byte, gopacket.CaptureInfo) error) error {
 	for {
 		// allow the read loop to be prematurely interrupted
 		select {
@@ -95,7 +105,7 @@ func (p *AFPacketSource) VisitPackets(exit <-chan struct{}, visit func([]byte, t
 			return err
 		}
 
-		if err := visit(data, stats.Timestamp); err != nil {
+		if err := visit(data, stats); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,573 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2ClientPreface is the first bytes sent by a HTTP/2 client on a connection
+const http2ClientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+const (
+	http2FrameHeaderSize = 9
+
+	// The default size of the HPACK dynamic table, until a SETTINGS frame says otherwise
+	http2DefaultHeaderTableSize = 4096
+
+	// Frames larger than this are not buffered: they are skipped like the DATA frames.
+	http2MaxBufferedFrameSize = 1 << 20
+
+	// The maximum number of in-flight streams tracked per connection
+	http2MaxStreams = 1024
+)
+
+// HTTP/2 frame types (RFC 7540 section 6)
+const (
+	http2FrameData         = 0x0
+	http2FrameHeaders      = 0x1
+	http2FramePriority     = 0x2
+	http2FrameRSTStream    = 0x3
+	http2FrameSettings     = 0x4
+	http2FramePushPromise  = 0x5
+	http2FramePing         = 0x6
+	http2FrameGoAway       = 0x7
+	http2FrameContinuation = 0x9
+)
+
+// HTTP/2 frame flags
+const (
+	http2FlagEndStream  = 0x1
+	http2FlagAck        = 0x1
+	http2FlagEndHeaders = 0x4
+	http2FlagPadded     = 0x8
+	http2FlagPriority   = 0x20
+)
+
+const http2SettingHeaderTableSize = 0x1
+
+// grpcStatusUnknown is used when a response carries no gRPC status
+const grpcStatusUnknown = -1
+
+// grpcToHTTPStatus maps the gRPC status codes to the HTTP status codes conventionally associated to them.
+// gRPC responses always have a 200 status, so this is what tells successful and failed calls apart.
+var grpcToHTTPStatus = map[int]int{
+	0:  200, // OK
+	1:  499, // CANCELLED
+	2:  500, // UNKNOWN
+	3:  400, // INVALID_ARGUMENT
+	4:  504, // DEADLINE_EXCEEDED
+	5:  404, // NOT_FOUND
+	6:  409, // ALREADY_EXISTS
+	7:  403, // PERMISSION_DENIED
+	8:  429, // RESOURCE_EXHAUSTED
+	9:  400, // FAILED_PRECONDITION
+	10: 409, // ABORTED
+	11: 400, // OUT_OF_RANGE
+	12: 501, // UNIMPLEMENTED
+	13: 500, // INTERNAL
+	14: 503, // UNAVAILABLE
+	15: 500, // DATA_LOSS
+	16: 401, // UNAUTHENTICATED
+}
+
+// http2Transaction is a HTTP/2 request and its response, exchanged on a stream
+type http2Transaction struct {
+	key        Key
+	method     string
+	path       string
+	statusCode int
+	grpcStatus int
+	latency    time.Duration
+}
+
+// Method returns the HTTP method of the request
+func (tx *http2Transaction) Method() string {
+	return tx.method
+}
+
+// Path returns the path of the request
+func (tx *http2Transaction) Path() string {
+	return tx.path
+}
+
+// StatusCode returns the status code of the response. For gRPC calls, this is
+// the HTTP status code associated to the gRPC status found in the trailers.
+func (tx *http2Transaction) StatusCode() int {
+	if code, ok := grpcToHTTPStatus[tx.grpcStatus]; ok {
+		return code
+	}
+	return tx.statusCode
+}
+
+// StatusClass returns an integer representing the status code class
+// Example: a 404 would return 400
+func (tx *http2Transaction) StatusClass() int {
+	return (tx.StatusCode() / 100) * 100
+}
+
+// RequestLatency returns the latency of the request in ms
+func (tx *http2Transaction) RequestLatency() float64 {
+	return float64(tx.latency / time.Millisecond)
+}
+
+// http2Stream holds the state of an in-flight request
+type http2Stream struct {
+	method     string
+	path       string
+	started    time.Time
+	statusCode int
+	grpcStatus int
+}
+
+// http2Direction holds the state of one direction of a HTTP/2 connection
+type http2Direction struct {
+	fromClient bool
+
+	// nextSeq is the TCP sequence number of the next expected payload byte
+	nextSeq  uint32
+	seqKnown bool
+
+	// prefaceLeft is the number of bytes of the client preface that remain to be read
+	prefaceLeft int
+	// buf holds the bytes of the frame being read
+	buf []byte
+	// skip is the number of bytes of a skipped frame payload, such as a DATA one, that remain to be skipped
+	skip int
+	// synced is false once the frame boundaries are lost, until a segment starting with a frame is seen
+	synced bool
+	// desyncs is the number of times the frame boundaries were lost
+	desyncs int
+
+	decoder *hpack.Decoder
+	// tableSize is the maximum size of the dynamic table allowed by the peer, used when the decoder is reset
+	tableSize uint32
+	// The header block being read, which can be split across HEADERS (or PUSH_PROMISE) and CONTINUATION frames
+	headerBlock     []byte
+	headerStream    uint32
+	headerEndStream bool
+	headerPromise   bool
+}
+
+// http2Conn reassembles the frames exchanged on a HTTP/2 connection from its TCP segments
+// and reports the completed transactions.
+// When bytes are missed, the frames they belong to are lost. If they end within a frame whose length
+// is known, the next frames are read as usual, otherwise they are looked for at the start of the next
+// segments. The HPACK dynamic table depends on all the headers exchanged so far, so once a header
+// block is lost the decoder starts over with an empty table: the header blocks that reference the
+// entries added before can't be decoded, but the ones added afterwards are numbered the same way.
+// The connection is only given up if it doesn't start with the client preface.
+type http2Conn struct {
+	key     Key
+	client  *http2Direction
+	server  *http2Direction
+	streams map[uint32]*http2Stream
+
+	broken   bool
+	lastSeen time.Time
+}
+
+// newHTTP2Conn returns a new HTTP/2 connection, where the client is the source of the key
+func newHTTP2Conn(key Key) *http2Conn {
+	return &http2Conn{
+		key: key,
+		client: &http2Direction{
+			fromClient:  true,
+			prefaceLeft: len(http2ClientPreface),
+			synced:      true,
+			decoder:     hpack.NewDecoder(http2DefaultHeaderTableSize, nil),
+			tableSize:   http2DefaultHeaderTableSize,
+		},
+		// The first segment seen from the server may not be the first one it sent
+		server: &http2Direction{
+			decoder:   hpack.NewDecoder(http2DefaultHeaderTableSize, nil),
+			tableSize: http2DefaultHeaderTableSize,
+		},
+		streams: make(map[uint32]*http2Stream),
+	}
+}
+
+// feed processes a TCP segment of the connection and returns the transactions it completed.
+// length is the length of the segment payload, which is larger than the captured payload
+// when the packet was truncated.
+func (c *http2Conn) feed(fromClient bool, seq uint32, payload []byte, length int, now time.Time) []http2Transaction {
+	c.lastSeen = now
+	if c.broken || length == 0 {
+		return nil
+	}
+
+	d := c.server
+	if fromClient {
+		d = c.client
+	}
+
+	newBytes, missed, uncaptured := d.sequence(seq, payload, length)
+	d.lose(missed)
+
+	if !d.synced {
+		// The frames are looked for again at the start of the next segments
+		if len(newBytes)+uncaptured != length || !isFrameStart(newBytes) {
+			return nil
+		}
+		d.synced = true
+	}
+
+	transactions := c.read(d, newBytes, now)
+	d.lose(uncaptured)
+	return transactions
+}
+
+// read processes the bytes of a direction, which follow the ones read so far
+func (c *http2Conn) read(d *http2Direction, payload []byte, now time.Time) []http2Transaction {
+	if d.prefaceLeft > 0 {
+		n := d.prefaceLeft
+		if n > len(payload) {
+			n = len(payload)
+		}
+		offset := len(http2ClientPreface) - d.prefaceLeft
+		if string(payload[:n]) != http2ClientPreface[offset:offset+n] {
+			c.broken = true
+			return nil
+		}
+		d.prefaceLeft -= n
+		payload = payload[n:]
+	}
+
+	d.buf = append(d.buf, payload...)
+
+	var transactions []http2Transaction
+	read := 0
+	for {
+		if d.skip > 0 {
+			n := d.skip
+			if n > len(d.buf)-read {
+				n = len(d.buf) - read
+			}
+			d.skip -= n
+			read += n
+			if d.skip > 0 {
+				break
+			}
+		}
+
+		frame := d.buf[read:]
+		if len(frame) < http2FrameHeaderSize {
+			break
+		}
+
+		length := int(frame[0])<<16 | int(frame[1])<<8 | int(frame[2])
+		frameType := frame[3]
+		flags := frame[4]
+		streamID := binary.BigEndian.Uint32(frame[5:9]) & 0x7fffffff
+
+		// Only the END_STREAM flag of the DATA frames matters, their payload is skipped
+		if frameType == http2FrameData {
+			read += http2FrameHeaderSize
+			d.skip = length
+			if flags&http2FlagEndStream != 0 && !d.fromClient {
+				transactions = c.endStream(streamID, transactions, now)
+			}
+			continue
+		}
+
+		if length > http2MaxBufferedFrameSize {
+			read += http2FrameHeaderSize
+			d.skip = length
+			if isHeaderFrame(frameType) {
+				d.loseHeaders()
+			}
+			continue
+		}
+
+		if len(frame) < http2FrameHeaderSize+length {
+			break
+		}
+
+		read += http2FrameHeaderSize + length
+		transactions = c.handleFrame(d, frameType, flags, streamID, frame[http2FrameHeaderSize:http2FrameHeaderSize+length], transactions, now)
+	}
+
+	// Move the bytes of the incomplete frame to the beginning of the buffer
+	d.buf = append(d.buf[:0], d.buf[read:]...)
+	return transactions
+}
+
+func (c *http2Conn) handleFrame(d *http2Direction, frameType, flags uint8, streamID uint32, payload []byte, transactions []http2Transaction, now time.Time) []http2Transaction {
+	switch frameType {
+	case http2FrameHeaders, http2FramePushPromise:
+		fragment, ok := headerBlockFragment(frameType, flags, payload)
+		if !ok {
+			d.loseHeaders()
+			return transactions
+		}
+
+		d.headerBlock = append(d.headerBlock[:0], fragment...)
+		d.headerStream = streamID
+		d.headerEndStream = frameType == http2FrameHeaders && flags&http2FlagEndStream != 0
+		d.headerPromise = frameType == http2FramePushPromise
+	case http2FrameContinuation:
+		if streamID != d.headerStream {
+			// The frame starting the header block was lost
+			d.loseHeaders()
+			return transactions
+		}
+		d.headerBlock = append(d.headerBlock, payload...)
+	case http2FrameRSTStream:
+		delete(c.streams, streamID)
+		return transactions
+	case http2FrameSettings:
+		if flags&http2FlagAck == 0 {
+			c.applySettings(d, payload)
+		}
+		return transactions
+	default:
+		return transactions
+	}
+
+	if flags&http2FlagEndHeaders == 0 {
+		return transactions
+	}
+
+	// The header block is decoded even when it's not needed, to keep the dynamic table up to date
+	fields, err := d.decoder.DecodeFull(d.headerBlock)
+	if err != nil {
+		d.loseHeaders()
+		return transactions
+	}
+	if d.headerPromise {
+		return transactions
+	}
+
+	if d.fromClient {
+		c.handleRequestHeaders(d.headerStream, fields, now)
+		return transactions
+	}
+
+	c.handleResponseHeaders(d.headerStream, fields)
+	if d.headerEndStream {
+		transactions = c.endStream(d.headerStream, transactions, now)
+	}
+	return transactions
+}
+
+func (c *http2Conn) handleRequestHeaders(streamID uint32, fields []hpack.HeaderField, now time.Time) {
+	// The request trailers, if any, don't matter
+	if _, found := c.streams[streamID]; found {
+		return
+	}
+	if len(c.streams) >= http2MaxStreams {
+		// The end of some streams may have been lost
+		c.expireStreams(now)
+		if len(c.streams) >= http2MaxStreams {
+			return
+		}
+	}
+
+	stream := &http2Stream{started: now, grpcStatus: grpcStatusUnknown}
+	for _, f := range fields {
+		switch f.Name {
+		case ":method":
+			stream.method = f.Value
+		case ":path":
+			stream.path = f.Value
+		}
+	}
+	c.streams[streamID] = stream
+}
+
+// handleResponseHeaders reads the status from the headers of a response. The gRPC status comes
+// from the trailers, which are sent as a second header block at the end of the stream, or along
+// with the headers if the response has no body ("Trailers-Only" responses).
+func (c *http2Conn) handleResponseHeaders(streamID uint32, fields []hpack.HeaderField) {
+	stream, found := c.streams[streamID]
+	if !found {
+		return
+	}
+
+	for _, f := range fields {
+		switch f.Name {
+		case ":status":
+			if code, err := strconv.Atoi(f.Value); err == nil {
+				stream.statusCode = code
+			}
+		case "grpc-status":
+			if code, err := strconv.Atoi(f.Value); err == nil {
+				stream.grpcStatus = code
+			}
+		}
+	}
+}
+
+// expireStreams forgets the streams that have been in flight for too long
+func (c *http2Conn) expireStreams(now time.Time) {
+	for streamID, stream := range c.streams {
+		if now.Sub(stream.started) > http2ConnTimeout {
+			delete(c.streams, streamID)
+		}
+	}
+}
+
+// endStream completes the transaction of a stream once the server is done responding
+func (c *http2Conn) endStream(streamID uint32, transactions []http2Transaction, now time.Time) []http2Transaction {
+	stream, found := c.streams[streamID]
+	if !found {
+		return transactions
+	}
+	delete(c.streams, streamID)
+
+	// Skip the streams whose response status is unknown or invalid
+	if stream.statusCode < 100 || stream.statusCode > 599 {
+		return transactions
+	}
+
+	return append(transactions, http2Transaction{
+		key:        c.key,
+		method:     stream.method,
+		path:       stream.path,
+		statusCode: stream.statusCode,
+		grpcStatus: stream.grpcStatus,
+		latency:    now.Sub(stream.started),
+	})
+}
+
+// applySettings handles the SETTINGS frames sent on a direction. The header table size
+// announced by a peer is the one its decoder uses, so it applies to the other direction.
+func (c *http2Conn) applySettings(d *http2Direction, payload []byte) {
+	peer := c.client
+	if d.fromClient {
+		peer = c.server
+	}
+
+	for ; len(payload) >= 6; payload = payload[6:] {
+		if binary.BigEndian.Uint16(payload) == http2SettingHeaderTableSize {
+			peer.tableSize = binary.BigEndian.Uint32(payload[2:])
+			peer.decoder.SetAllowedMaxDynamicTableSize(peer.tableSize)
+		}
+	}
+}
+
+// sequence trims the bytes of a TCP segment that were already seen, such as retransmissions.
+// length is the length of the segment payload, of which payload holds the captured bytes.
+// It returns the new captured bytes, the number of bytes missed before them, and the number
+// of new bytes that follow them but weren't captured.
+func (d *http2Direction) sequence(seq uint32, payload []byte, length int) (newBytes []byte, missed int, uncaptured int) {
+	if !d.seqKnown {
+		d.seqKnown = true
+		d.nextSeq = seq + uint32(length)
+		return payload, 0, length - len(payload)
+	}
+
+	offset := int32(d.nextSeq - seq)
+	if offset < 0 {
+		missed = int(-offset)
+		offset = 0
+	}
+	if int(offset) >= length {
+		return nil, 0, 0
+	}
+
+	d.nextSeq = seq + uint32(length)
+	if int(offset) > len(payload) {
+		return nil, missed, length - int(offset)
+	}
+	return payload[offset:], missed, length - len(payload)
+}
+
+// lose accounts for n bytes of the direction that weren't seen. The frame they belong to is lost,
+// and the frame boundaries too, unless the bytes end within a frame whose length is known.
+func (d *http2Direction) lose(n int) {
+	if n == 0 || !d.synced {
+		return
+	}
+
+	if d.prefaceLeft > 0 || (len(d.buf) > 0 && len(d.buf) < http2FrameHeaderSize) {
+		d.desync()
+		return
+	}
+
+	// Skip the rest of the frame being buffered, whose header tells its length
+	if len(d.buf) > 0 {
+		length := int(d.buf[0])<<16 | int(d.buf[1])<<8 | int(d.buf[2])
+		d.skip = http2FrameHeaderSize + length - len(d.buf)
+		if isHeaderFrame(d.buf[3]) {
+			d.loseHeaders()
+		}
+		d.buf = d.buf[:0]
+	}
+
+	if n > d.skip {
+		d.desync()
+		return
+	}
+	d.skip -= n
+}
+
+// desync drops the state of the frame being read once the frame boundaries are lost
+func (d *http2Direction) desync() {
+	d.synced = false
+	d.desyncs++
+	d.prefaceLeft = 0
+	d.buf = d.buf[:0]
+	d.skip = 0
+	d.loseHeaders()
+}
+
+// loseHeaders drops the header block being read, and starts the decoder over since the entries
+// the lost headers added to the dynamic table are unknown
+func (d *http2Direction) loseHeaders() {
+	d.headerBlock = d.headerBlock[:0]
+	d.headerStream = 0
+	d.decoder = hpack.NewDecoder(d.tableSize, nil)
+}
+
+// isHeaderFrame returns true for the frames carrying a header block fragment
+func isHeaderFrame(frameType uint8) bool {
+	return frameType == http2FrameHeaders || frameType == http2FramePushPromise || frameType == http2FrameContinuation
+}
+
+// isFrameStart returns true if the payload plausibly starts with a frame header
+func isFrameStart(payload []byte) bool {
+	if len(payload) < http2FrameHeaderSize || payload[5]&0x80 != 0 {
+		return false
+	}
+
+	streamID := binary.BigEndian.Uint32(payload[5:9])
+	switch payload[3] {
+	case http2FrameSettings, http2FramePing, http2FrameGoAway:
+		return streamID == 0
+	case http2FrameData, http2FrameHeaders, http2FramePriority, http2FrameRSTStream, http2FramePushPromise:
+		return streamID != 0
+	default:
+		// CONTINUATION frames can't be read without the frame starting their header block
+		return false
+	}
+}
+
+// headerBlockFragment returns the header block fragment of a HEADERS or PUSH_PROMISE frame
+func headerBlockFragment(frameType, flags uint8, payload []byte) ([]byte, bool) {
+	padding := 0
+	if flags&http2FlagPadded != 0 {
+		if len(payload) < 1 {
+			return nil, false
+		}
+		padding = int(payload[0])
+		payload = payload[1:]
+	}
+
+	// PUSH_PROMISE frames start with the promised stream ID, HEADERS frames may start with the stream priority
+	skip := 0
+	if frameType == http2FramePushPromise {
+		skip = 4
+	} else if flags&http2FlagPriority != 0 {
+		skip = 5
+	}
+
+	if len(payload) < skip+padding {
+		return nil, false
+	}
+	return payload[skip : len(payload)-padding], true
+}
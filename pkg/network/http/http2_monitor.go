@@ -0,0 +1,233 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/ebpf"
+	"github.com/DataDog/ebpf/manager"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	// http2ConnTimeout is the duration after which an idle HTTP/2 connection is forgotten
+	http2ConnTimeout = 2 * time.Minute
+	// http2ExpirationPeriod is the period at which idle connections are looked for
+	http2ExpirationPeriod = 30 * time.Second
+)
+
+// http2Monitor reads the packets of the HTTP/2 connections, which the socket filter passes to
+// userspace, and reports the transactions reassembled from their frames
+type http2Monitor struct {
+	source  *filterpkg.AFPacketSource
+	handler func([]http2Transaction)
+	// filterConns holds the connections whose packets are passed to userspace by the socket filter
+	filterConns *ebpf.Map
+
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    *layers.IPv4
+	ipv6    *layers.IPv6
+	tcp     *layers.TCP
+
+	conns       map[Key]*http2Conn
+	lastExpired time.Time
+
+	// telemetry
+	transactions   int64
+	brokenConns    int64
+	desyncs        int64
+	decodingErrors int64
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newHTTP2Monitor(procRoot string, mgr *manager.Manager, handler func([]http2Transaction)) (*http2Monitor, error) {
+	filter, _ := mgr.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketHTTP2Filter)})
+	if filter == nil {
+		return nil, fmt.Errorf("error retrieving HTTP/2 socket filter")
+	}
+
+	filterConns, _, err := mgr.GetMap(string(probes.Http2ConnsMap))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving %s map: %s", probes.Http2ConnsMap, err)
+	}
+
+	var (
+		source *filterpkg.AFPacketSource
+		srcErr error
+	)
+	err = util.WithRootNS(procRoot, func() error {
+		source, srcErr = filterpkg.NewPacketSource(filter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ipv4 := &layers.IPv4{}
+	ipv6 := &layers.IPv6{}
+	tcp := &layers.TCP{}
+	return &http2Monitor{
+		source:      source,
+		handler:     handler,
+		filterConns: filterConns,
+		decoder:     gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &layers.Ethernet{}, ipv4, ipv6, tcp),
+		ipv4:        ipv4,
+		ipv6:        ipv6,
+		tcp:         tcp,
+		conns:       make(map[Key]*http2Conn),
+		lastExpired: time.Now(),
+		exit:        make(chan struct{}),
+	}, nil
+}
+
+// Start reading packets
+func (m *http2Monitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			err := m.source.VisitPacketsWithInfo(m.exit, m.processPacket)
+			if err != nil {
+				log.Warnf("error reading HTTP/2 packet: %s", err)
+			}
+
+			select {
+			case <-m.exit:
+				return
+			default:
+			}
+
+			// VisitPackets returns when no packet was read for a while
+			m.expireConns(time.Now())
+		}
+	}()
+}
+
+// Stop reading packets
+func (m *http2Monitor) Stop() {
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+// GetStats returns the telemetry of the HTTP/2 monitor and its packet source
+func (m *http2Monitor) GetStats() map[string]int64 {
+	stats := m.source.Stats()
+	stats["transactions"] = atomic.LoadInt64(&m.transactions)
+	stats["broken_connections"] = atomic.LoadInt64(&m.brokenConns)
+	stats["desyncs"] = atomic.LoadInt64(&m.desyncs)
+	stats["decoding_errors"] = atomic.LoadInt64(&m.decodingErrors)
+	return stats
+}
+
+// processPacket feeds the TCP payload of a packet to its HTTP/2 connection.
+// The packet data can't be referenced after this call, since it's reused by the packet source.
+func (m *http2Monitor) processPacket(data []byte, info gopacket.CaptureInfo) error {
+	ts := info.Timestamp
+	m.expireConns(ts)
+
+	// Decoding stops after the TCP layer, since its payload isn't a layer gopacket can decode
+	err := m.decoder.DecodeLayers(data, &m.layers)
+	if _, unsupported := err.(gopacket.UnsupportedLayerType); err != nil && !unsupported {
+		atomic.AddInt64(&m.decodingErrors, 1)
+		log.Tracef("error decoding HTTP/2 packet: %s", err)
+		return nil
+	}
+
+	var src, dst util.Address
+	isTCP := false
+	for _, layer := range m.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			src, dst = util.AddressFromNetIP(m.ipv4.SrcIP), util.AddressFromNetIP(m.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			src, dst = util.AddressFromNetIP(m.ipv6.SrcIP), util.AddressFromNetIP(m.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isTCP || src == nil {
+		return nil
+	}
+
+	// The connections are keyed by their tuple normalized so the client is the source
+	fromClient := true
+	key := Key{SourceIP: src, DestIP: dst, SourcePort: uint16(m.tcp.SrcPort), DestPort: uint16(m.tcp.DstPort)}
+	conn, found := m.conns[key]
+	if !found {
+		serverKey := Key{SourceIP: dst, DestIP: src, SourcePort: uint16(m.tcp.DstPort), DestPort: uint16(m.tcp.SrcPort)}
+		if conn, found = m.conns[serverKey]; found {
+			key = serverKey
+			fromClient = false
+		}
+	}
+
+	if !found {
+		// We only learn about the connections from their preface. The socket filter passes
+		// the packets of connections unknown here, such as the expired ones, until told otherwise.
+		if !bytes.HasPrefix(m.tcp.Payload, []byte(http2ClientPreface)) {
+			m.unfilterConn(key)
+			m.unfilterConn(Key{SourceIP: dst, DestIP: src, SourcePort: uint16(m.tcp.DstPort), DestPort: uint16(m.tcp.SrcPort)})
+			return nil
+		}
+		conn = newHTTP2Conn(key)
+		m.conns[key] = conn
+	}
+
+	// The packets larger than the frames of the packet source are truncated
+	length := len(m.tcp.Payload) + info.Length - info.CaptureLength
+	desyncs := conn.client.desyncs + conn.server.desyncs
+	transactions := conn.feed(fromClient, m.tcp.Seq, m.tcp.Payload, length, ts)
+	atomic.AddInt64(&m.desyncs, int64(conn.client.desyncs+conn.server.desyncs-desyncs))
+
+	if conn.broken {
+		atomic.AddInt64(&m.brokenConns, 1)
+		delete(m.conns, key)
+		m.unfilterConn(key)
+	} else if m.tcp.FIN || m.tcp.RST {
+		delete(m.conns, key)
+	}
+
+	if len(transactions) > 0 {
+		atomic.AddInt64(&m.transactions, int64(len(transactions)))
+		m.handler(transactions)
+	}
+
+	return nil
+}
+
+// expireConns forgets the connections that have been idle for too long, such as the ones whose FIN was missed
+func (m *http2Monitor) expireConns(now time.Time) {
+	if now.Sub(m.lastExpired) < http2ExpirationPeriod {
+		return
+	}
+	m.lastExpired = now
+
+	for key, conn := range m.conns {
+		if now.Sub(conn.lastSeen) > http2ConnTimeout {
+			delete(m.conns, key)
+			m.unfilterConn(key)
+		}
+	}
+}
+
+// unfilterConn stops the socket filter from passing the packets of a connection to userspace
+func (m *http2Monitor) unfilterConn(key Key) {
+	// The connection may not be tracked by the socket filter, or under the other tuple
+	tuple := newConnTuple(key)
+	_ = m.filterConns.Delete(unsafe.Pointer(&tuple))
+}
@@ -0,0 +1,321 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestHTTP2Request(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	start := time.Now()
+
+	client.writePreface()
+	client.writeHeaders(1, true, ":method", "GET", ":path", "/foo?bar=1", ":scheme", "http", ":authority", "example.com")
+	assert.Empty(t, client.send(conn, start))
+
+	server.framer.WriteSettings()
+	server.writeHeaders(1, false, ":status", "404", "content-type", "text/plain")
+	server.framer.WriteData(1, true, []byte("not found"))
+	txs := server.send(conn, start.Add(15*time.Millisecond))
+
+	require.Len(t, txs, 1)
+	assert.Equal(t, "GET", txs[0].Method())
+	assert.Equal(t, "/foo?bar=1", txs[0].Path())
+	assert.Equal(t, 404, txs[0].StatusCode())
+	assert.Equal(t, 400, txs[0].StatusClass())
+	assert.Equal(t, float64(15), txs[0].RequestLatency())
+	assert.Equal(t, conn.key, txs[0].key)
+	assert.Empty(t, conn.streams)
+}
+
+func TestHTTP2GRPCStatus(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	for _, id := range []uint32{1, 3, 5} {
+		client.writeHeaders(id, false, ":method", "POST", ":path", "/helloworld.Greeter/SayHello", "content-type", "application/grpc")
+		client.framer.WriteData(id, true, []byte("request"))
+	}
+	assert.Empty(t, client.send(conn, now))
+
+	// Successful call, with the status in the trailers
+	server.writeHeaders(1, false, ":status", "200", "content-type", "application/grpc")
+	server.framer.WriteData(1, false, []byte("response"))
+	server.writeHeaders(1, true, "grpc-status", "0")
+	// Failed call, with the status in the trailers
+	server.writeHeaders(3, false, ":status", "200", "content-type", "application/grpc")
+	server.writeHeaders(3, true, "grpc-status", "5", "grpc-message", "not found")
+	// "Trailers-Only" response
+	server.writeHeaders(5, true, ":status", "200", "content-type", "application/grpc", "grpc-status", "14")
+	txs := server.send(conn, now)
+
+	require.Len(t, txs, 3)
+	statuses := make([]int, 0, len(txs))
+	for _, tx := range txs {
+		assert.Equal(t, "/helloworld.Greeter/SayHello", tx.Path())
+		statuses = append(statuses, tx.StatusCode())
+	}
+	assert.Equal(t, []int{200, 404, 503}, statuses)
+}
+
+func TestHTTP2DynamicTable(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	client.send(conn, now)
+
+	// The headers repeated across requests are indexed by the encoders
+	for id := uint32(1); id < 10; id += 2 {
+		client.writeHeaders(id, true, ":method", "GET", ":path", "/indexed", "user-agent", "test")
+		client.send(conn, now)
+		server.writeHeaders(id, true, ":status", "200", "server", "test")
+		txs := server.send(conn, now)
+
+		require.Len(t, txs, 1)
+		assert.Equal(t, "/indexed", txs[0].Path())
+		assert.Equal(t, 200, txs[0].StatusCode())
+	}
+	assert.False(t, conn.broken)
+}
+
+func TestHTTP2Continuation(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	block := client.encode(":method", "GET", ":path", "/continued", ":authority", "example.com")
+	client.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: block[:3],
+		EndStream:     true,
+		PadLength:     4,
+		Priority:      http2.PriorityParam{StreamDep: 0, Weight: 15},
+	})
+	client.framer.WriteContinuation(1, false, block[3:5])
+	client.framer.WriteContinuation(1, true, block[5:])
+	client.send(conn, now)
+
+	// A promised request is decoded to keep the dynamic table in sync, but doesn't create a transaction
+	server.framer.WritePushPromise(http2.PushPromiseParam{
+		StreamID:      1,
+		PromiseID:     2,
+		BlockFragment: server.encode(":method", "GET", ":path", "/pushed", "x-push", "yes"),
+		EndHeaders:    true,
+	})
+	server.writeHeaders(1, true, ":status", "204", "x-push", "yes")
+	txs := server.send(conn, now)
+
+	require.Len(t, txs, 1)
+	assert.Equal(t, "/continued", txs[0].Path())
+	assert.Equal(t, 204, txs[0].StatusCode())
+	assert.False(t, conn.broken)
+}
+
+func TestHTTP2Segments(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	client.writeHeaders(1, true, ":method", "DELETE", ":path", "/segmented")
+	seq, data := client.flush()
+	// Frames can be split across segments, and segments can be retransmitted
+	for i := 0; i < len(data); i += 5 {
+		end := i + 5
+		if end > len(data) {
+			end = len(data)
+		}
+		assert.Empty(t, conn.feed(true, seq+uint32(i), data[i:end], end-i, now))
+		assert.Empty(t, conn.feed(true, seq+uint32(i), data[i:end], end-i, now))
+	}
+
+	server.writeHeaders(1, false, ":status", "500")
+	server.framer.WriteData(1, true, make([]byte, 4000))
+	seq, data = server.flush()
+	var txs []http2Transaction
+	for i := 0; i < len(data); i += 1000 {
+		end := i + 1000
+		if end > len(data) {
+			end = len(data)
+		}
+		txs = append(txs, conn.feed(false, seq+uint32(i), data[i:end], end-i, now)...)
+	}
+
+	require.Len(t, txs, 1)
+	assert.Equal(t, "DELETE", txs[0].Method())
+	assert.Equal(t, 500, txs[0].StatusClass())
+	assert.Empty(t, conn.server.buf)
+}
+
+func TestHTTP2RSTStream(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	client.writeHeaders(1, true, ":method", "GET", ":path", "/reset")
+	client.framer.WriteRSTStream(1, http2.ErrCodeCancel)
+	client.send(conn, now)
+	assert.Empty(t, conn.streams)
+
+	server.writeHeaders(1, true, ":status", "200")
+	assert.Empty(t, server.send(conn, now))
+}
+
+func TestHTTP2MissedSegment(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	for _, id := range []uint32{1, 3, 5} {
+		client.writeHeaders(id, true, ":method", "GET", ":path", "/lost")
+	}
+	client.send(conn, now)
+	server.framer.WriteSettings()
+	server.send(conn, now)
+
+	// The frames are looked for again in the next segment, whose header block can be decoded
+	// since it doesn't reference the entries the missed one added to the dynamic table
+	server.writeHeaders(1, true, ":status", "200", "x-request-id", "1")
+	server.flush()
+	server.writeHeaders(3, true, ":status", "500")
+	txs := server.send(conn, now)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 500, txs[0].StatusCode())
+	assert.Equal(t, 1, conn.server.desyncs)
+
+	// A header block referencing the entries added by the missed one is lost
+	server.writeHeaders(5, true, ":status", "200", "x-request-id", "1")
+	assert.Empty(t, server.send(conn, now))
+	assert.False(t, conn.broken)
+}
+
+func TestHTTP2ResyncOnFrameStart(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	client.writeHeaders(1, true, ":method", "GET", ":path", "/resync")
+	client.send(conn, now)
+	server.framer.WriteSettings()
+	server.send(conn, now)
+
+	// The segments following the missed one don't start with a frame
+	server.writeHeaders(1, false, ":status", "200")
+	server.framer.WriteData(1, false, make([]byte, 100))
+	server.flush()
+	server.framer.WriteData(1, false, make([]byte, 100))
+	seq, data := server.flush()
+	assert.Empty(t, conn.feed(false, seq+20, data[20:], len(data)-20, now))
+	assert.False(t, conn.server.synced)
+
+	server.framer.WriteData(1, true, nil)
+	txs := server.send(conn, now)
+	assert.True(t, conn.server.synced)
+	// The stream is completed, but its status was missed
+	assert.Empty(t, txs)
+	assert.Empty(t, conn.streams)
+}
+
+func TestHTTP2TruncatedSegment(t *testing.T) {
+	conn, client, server := newHTTP2TestConn()
+	now := time.Now()
+
+	client.writePreface()
+	for _, id := range []uint32{1, 3, 5} {
+		client.writeHeaders(id, true, ":method", "GET", ":path", "/truncated")
+	}
+	client.send(conn, now)
+
+	// The bytes which weren't captured are part of a DATA frame, whose payload is skipped anyway
+	server.writeHeaders(1, false, ":status", "200")
+	server.framer.WriteData(1, true, make([]byte, 8000))
+	seq, data := server.flush()
+	txs := conn.feed(false, seq, data[:4000], len(data), now)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 200, txs[0].StatusCode())
+
+	// The bytes which weren't captured end within a header block, which is lost
+	server.writeHeaders(3, true, ":status", "404", "x-padding", string(make([]byte, 500)))
+	seq, data = server.flush()
+	assert.Empty(t, conn.feed(false, seq, data[:100], len(data), now))
+
+	server.writeHeaders(5, true, ":status", "204")
+	txs = server.send(conn, now)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 204, txs[0].StatusCode())
+	assert.Equal(t, 0, conn.server.desyncs)
+}
+
+func TestHTTP2InvalidPreface(t *testing.T) {
+	conn, client, _ := newHTTP2TestConn()
+
+	client.buf.WriteString("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	assert.Empty(t, client.send(conn, time.Now()))
+	assert.True(t, conn.broken)
+}
+
+// http2TestPeer writes the frames sent by one side of a HTTP/2 connection
+type http2TestPeer struct {
+	fromClient bool
+	seq        uint32
+	buf        bytes.Buffer
+	framer     *http2.Framer
+	headerBuf  bytes.Buffer
+	encoder    *hpack.Encoder
+}
+
+func newHTTP2TestConn() (*http2Conn, *http2TestPeer, *http2TestPeer) {
+	return newHTTP2Conn(Key{SourcePort: 43210, DestPort: 50051}), newHTTP2TestPeer(true, 1000), newHTTP2TestPeer(false, 5000)
+}
+
+func newHTTP2TestPeer(fromClient bool, seq uint32) *http2TestPeer {
+	p := &http2TestPeer{fromClient: fromClient, seq: seq}
+	p.framer = http2.NewFramer(&p.buf, nil)
+	p.encoder = hpack.NewEncoder(&p.headerBuf)
+	return p
+}
+
+func (p *http2TestPeer) writePreface() {
+	p.buf.WriteString(http2.ClientPreface)
+	p.framer.WriteSettings(http2.Setting{ID: http2.SettingHeaderTableSize, Val: 8192})
+}
+
+func (p *http2TestPeer) encode(fields ...string) []byte {
+	p.headerBuf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		p.encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte(nil), p.headerBuf.Bytes()...)
+}
+
+func (p *http2TestPeer) writeHeaders(streamID uint32, endStream bool, fields ...string) {
+	p.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: p.encode(fields...),
+		EndStream:     endStream,
+		EndHeaders:    true,
+	})
+}
+
+// flush returns the bytes written since the last call along with their sequence number
+func (p *http2TestPeer) flush() (uint32, []byte) {
+	data := append([]byte(nil), p.buf.Bytes()...)
+	p.buf.Reset()
+	seq := p.seq
+	p.seq += uint32(len(data))
+	return seq, data
+}
+
+func (p *http2TestPeer) send(conn *http2Conn, now time.Time) []http2Transaction {
+	seq, data := p.flush()
+	return conn.feed(p.fromClient, seq, data, len(data), now)
+}
@@ -3,6 +3,7 @@
 package http
 
 import (
+	"encoding/binary"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -22,16 +23,47 @@ type httpTX C.http_transaction_t
 type httpNotification C.http_batch_notification_t
 type httpBatch C.http_batch_t
 type httpBatchKey C.http_batch_key_t
+type libPath C.lib_path_t
+type connTuple C.conn_tuple_t
 
 const (
 	CONN_V4 uint = 0 << 0
 	CONN_V6 uint = 1 << 1
 )
 
+// newConnTuple returns the tuple of a TCP connection as read by the socket filter
+func newConnTuple(key Key) connTuple {
+	t := connTuple{
+		sport:    C.__u16(key.SourcePort),
+		dport:    C.__u16(key.DestPort),
+		metadata: C.CONN_TYPE_TCP,
+	}
+
+	src, dst := key.SourceIP.Bytes(), key.DestIP.Bytes()
+	if len(src) == 16 {
+		t.metadata |= C.CONN_V6
+		t.saddr_h, t.saddr_l = C.__u64(binary.LittleEndian.Uint64(src[:8])), C.__u64(binary.LittleEndian.Uint64(src[8:]))
+		t.daddr_h, t.daddr_l = C.__u64(binary.LittleEndian.Uint64(dst[:8])), C.__u64(binary.LittleEndian.Uint64(dst[8:]))
+	} else {
+		t.saddr_l = C.__u64(binary.LittleEndian.Uint32(src))
+		t.daddr_l = C.__u64(binary.LittleEndian.Uint32(dst))
+	}
+	return t
+}
+
 func toHTTPNotification(data []byte) httpNotification {
 	return *(*httpNotification)(unsafe.Pointer(&data[0]))
 }
 
+func toLibPath(data []byte) libPath {
+	return *(*libPath)(unsafe.Pointer(&data[0]))
+}
+
+// Path returns the path of the shared library opened by the process
+func (l *libPath) Path() string {
+	return C.GoStringN(&l.buf[0], C.int(l.len))
+}
+
 // Prepare the httpBatchKey for a map lookup
 func (k *httpBatchKey) Prepare(n httpNotification) {
 	k.cpu = n.cpu
@@ -21,8 +21,11 @@ import (
 // * Polling a perf buffer that contains notifications about HTTP transaction batches ready to be read;
 // * Querying these batches by doing a map lookup;
 // * Aggregating and emitting metrics based on the received HTTP transactions;
+// * Optionally, hooking the TLS libraries loaded by processes to capture HTTPS transactions;
+// * Reassembling the HTTP/2 transactions from the packets of the HTTP/2 connections;
 type Monitor struct {
-	handler func([]httpTX)
+	handler      func([]httpTX)
+	http2Handler func([]http2Transaction)
 
 	batchManager *batchManager
 	perfMap      *manager.PerfMap
@@ -30,6 +33,8 @@ type Monitor struct {
 	telemetry    *telemetry
 	pollRequests chan chan struct{}
 	statkeeper   *httpStatKeeper
+	sslWatcher   *soWatcher
+	http2Monitor *http2Monitor
 
 	// termination
 	mux           sync.Mutex
@@ -38,8 +43,9 @@ type Monitor struct {
 	stopped       bool
 }
 
-// NewMonitor returns a new Monitor instance
-func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*Monitor, error) {
+// NewMonitor returns a new Monitor instance.
+// HTTPS monitoring is enabled if sharedLibrariesHandler, receiving the shared libraries opened by processes, isn't nil.
+func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler, sharedLibrariesHandler *ddebpf.PerfHandler) (*Monitor, error) {
 	filter, _ := mgr.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketHTTPFilter)})
 	if filter == nil {
 		return nil, fmt.Errorf("error retrieving socket filter")
@@ -68,6 +74,14 @@ func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*
 		return nil, fmt.Errorf("unable to find perf map %s", probes.HttpNotificationsMap)
 	}
 
+	var sslWatcher *soWatcher
+	if sharedLibrariesHandler != nil {
+		sslWatcher, err = newSSLWatcher(procRoot, mgr, sharedLibrariesHandler)
+		if err != nil {
+			return nil, fmt.Errorf("error enabling HTTPS traffic inspection: %s", err)
+		}
+	}
+
 	statkeeper := newHTTPStatkeeper()
 
 	handler := func(transactions []httpTX) {
@@ -76,7 +90,7 @@ func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*
 		}
 	}
 
-	return &Monitor{
+	m := &Monitor{
 		handler:       handler,
 		batchManager:  newBatchManager(batchMap, batchStateMap, numCPUs),
 		perfMap:       pm,
@@ -85,7 +99,24 @@ func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*
 		pollRequests:  make(chan chan struct{}),
 		closeFilterFn: closeFilterFn,
 		statkeeper:    statkeeper,
-	}, nil
+		sslWatcher:    sslWatcher,
+	}
+
+	// The HTTP/2 transactions are reported from the goroutine reading the packets
+	m.http2Handler = func(transactions []http2Transaction) {
+		if statkeeper != nil {
+			statkeeper.ProcessHTTP2(transactions)
+		}
+	}
+	m.http2Monitor, err = newHTTP2Monitor(procRoot, mgr, func(transactions []http2Transaction) {
+		m.http2Handler(transactions)
+	})
+	if err != nil {
+		closeFilterFn()
+		return nil, fmt.Errorf("error enabling HTTP/2 traffic inspection: %s", err)
+	}
+
+	return m, nil
 }
 
 // Start consuming HTTP events
@@ -98,6 +129,14 @@ func (m *Monitor) Start() error {
 		return fmt.Errorf("error starting perf map: %s", err)
 	}
 
+	if m.sslWatcher != nil {
+		if err := m.sslWatcher.Start(); err != nil {
+			return fmt.Errorf("error watching shared libraries: %s", err)
+		}
+	}
+
+	m.http2Monitor.Start()
+
 	m.eventLoopWG.Add(1)
 	go func() {
 		defer m.eventLoopWG.Done()
@@ -172,6 +211,7 @@ func (m *Monitor) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"current_time": currentTime,
 		"telemetry":    telemetryData,
+		"http2":        m.http2Monitor.GetStats(),
 	}
 }
 
@@ -188,6 +228,10 @@ func (m *Monitor) Stop() {
 	}
 
 	m.closeFilterFn()
+	m.http2Monitor.Stop()
+	if m.sslWatcher != nil {
+		m.sslWatcher.Stop()
+	}
 	_ = m.perfMap.Stop(manager.CleanAll)
 	m.perfHandler.Stop()
 	close(m.pollRequests)
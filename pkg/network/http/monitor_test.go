@@ -4,14 +4,20 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
 	"math/rand"
+	"net"
 	nethttp "net/http"
+	"net/http/httptest"
+	"os/exec"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,7 +27,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/kernel"
 	"github.com/DataDog/ebpf"
 	"github.com/DataDog/ebpf/manager"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/sys/unix"
 )
 
@@ -40,7 +49,7 @@ func TestHTTPMonitorIntegration(t *testing.T) {
 	handlerFn := func(transactions []httpTX) {
 		buffer = append(buffer, transactions...)
 	}
-	monitor, doneFn := monitorSetup(t, handlerFn)
+	monitor, doneFn := monitorSetup(t, handlerFn, nil)
 	defer doneFn()
 
 	// Perform a number of random requests
@@ -60,6 +69,121 @@ func TestHTTPMonitorIntegration(t *testing.T) {
 	}
 }
 
+func TestHTTPSMonitorIntegration(t *testing.T) {
+	currKernelVersion, err := kernel.HostVersion()
+	require.NoError(t, err)
+	if currKernelVersion < kernel.VersionCode(4, 1, 0) {
+		t.Skip("HTTPS feature not available on pre 4.1.0 kernels")
+	}
+
+	// curl is dynamically linked against either OpenSSL or GnuTLS
+	curlPath, err := exec.LookPath("curl")
+	if err != nil {
+		t.Skip("curl is required to run this test")
+	}
+
+	srv := httptest.NewUnstartedServer(nethttp.HandlerFunc(statusHandler))
+	srv.Config.SetKeepAlivesEnabled(false)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// Create a monitor that simply buffers all HTTP requests
+	var buffer []httpTX
+	handlerFn := func(transactions []httpTX) {
+		buffer = append(buffer, transactions...)
+	}
+	monitor, doneFn := monitorSetup(t, handlerFn, nil)
+	defer doneFn()
+
+	// Each curl process is started after the monitor, which has to hook the TLS library as it gets loaded
+	requestFn := curlRequestGenerator(t, curlPath, srv.URL)
+	var requests []*nethttp.Request
+	for i := 0; i < 10; i++ {
+		requests = append(requests, requestFn())
+	}
+
+	// Ensure all captured transactions get sent to user-space
+	time.Sleep(10 * time.Millisecond)
+	monitor.Sync()
+
+	// Assert all requests made were correctly captured by the monitor
+	for _, req := range requests {
+		hasMatchingTX(t, req, buffer)
+	}
+}
+
+func TestHTTP2MonitorIntegration(t *testing.T) {
+	currKernelVersion, err := kernel.HostVersion()
+	require.NoError(t, err)
+	if currKernelVersion < kernel.VersionCode(4, 1, 0) {
+		t.Skip("HTTP/2 feature not available on pre 4.1.0 kernels")
+	}
+
+	// The server accepts HTTP/2 connections without TLS ("h2c"), like gRPC servers usually do
+	srv := httptest.NewServer(h2c.NewHandler(nethttp.HandlerFunc(grpcStatusHandler), &http2.Server{}))
+	defer srv.Close()
+
+	// Create a monitor that simply buffers all HTTP/2 requests
+	var (
+		mux    sync.Mutex
+		buffer []http2Transaction
+	)
+	http2HandlerFn := func(transactions []http2Transaction) {
+		mux.Lock()
+		defer mux.Unlock()
+		buffer = append(buffer, transactions...)
+	}
+	_, doneFn := monitorSetup(t, nil, http2HandlerFn)
+	defer doneFn()
+
+	// The client sends the HTTP/2 connection preface right away
+	client := &nethttp.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+
+	var requests []*nethttp.Request
+	for i, path := range []string{"/200/request-1", "/404/request-2", "/500/request-3", "/grpc/0/call-4", "/grpc/5/call-5", "/grpc/14/call-6"} {
+		method := "GET"
+		if i%2 == 1 {
+			method = "POST"
+		}
+		req, err := nethttp.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		requests = append(requests, req)
+	}
+
+	// Ensure the last packets get processed
+	time.Sleep(100 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	for _, req := range requests {
+		hasMatchingHTTP2TX(t, req, buffer)
+	}
+}
+
+func TestParseMapsFile(t *testing.T) {
+	maps := `55d2d6e35000-55d2d6e3d000 r--p 00000000 fd:01 1576541                    /usr/bin/curl
+7f1c5f2a1000-7f1c5f2c3000 r--p 00000000 fd:01 1578963                    /usr/lib/x86_64-linux-gnu/libssl.so.1.1
+7f1c5f2c3000-7f1c5f312000 r-xp 00022000 fd:01 1578963                    /usr/lib/x86_64-linux-gnu/libssl.so.1.1
+7f1c5f313000-7f1c5f314000 rw-p 00000000 00:00 0
+7f1c5f314000-7f1c5f315000 r--p 00000000 fd:01 1579011                    /opt/some dir/libgnutls.so.30
+7ffd3e5d1000-7ffd3e5f2000 rw-p 00000000 00:00 0                          [stack]
+`
+	assert.Equal(t, []string{
+		"/usr/bin/curl",
+		"/usr/lib/x86_64-linux-gnu/libssl.so.1.1",
+		"/opt/some dir/libgnutls.so.30",
+	}, parseMapsFile(strings.NewReader(maps)))
+}
+
 func hasMatchingTX(t *testing.T, req *nethttp.Request, transactions []httpTX) {
 	expectedStatus := statusFromPath(req.URL.Path)
 	for _, tx := range transactions {
@@ -76,20 +200,34 @@ func hasMatchingTX(t *testing.T, req *nethttp.Request, transactions []httpTX) {
 	)
 }
 
+func hasMatchingHTTP2TX(t *testing.T, req *nethttp.Request, transactions []http2Transaction) {
+	expectedStatus := statusFromPath(req.URL.Path)
+	if code, ok := grpcToHTTPStatus[grpcStatusFromPath(req.URL.Path)]; ok {
+		expectedStatus = code
+	}
+
+	for _, tx := range transactions {
+		if tx.Path() == req.URL.Path && tx.StatusCode() == expectedStatus && tx.Method() == req.Method {
+			return
+		}
+	}
+
+	t.Errorf(
+		"could not find HTTP/2 transaction matching the following criteria:\n path=%s method=%s status=%d",
+		req.URL.Path,
+		req.Method,
+		expectedStatus,
+	)
+}
+
 // serverSetup spins up a HTTP test server that returns the status code included in the URL
 // Example:
 // * GET /200/foo returns a 200 status code;
 // * PUT /404/bar returns a 404 status code;
 func serverSetup(t *testing.T) func() {
-	handler := func(w nethttp.ResponseWriter, req *nethttp.Request) {
-		statusCode := statusFromPath(req.URL.Path)
-		io.Copy(ioutil.Discard, req.Body)
-		w.WriteHeader(statusCode)
-	}
-
 	srv := &nethttp.Server{
 		Addr:         "localhost:8080",
-		Handler:      nethttp.HandlerFunc(handler),
+		Handler:      nethttp.HandlerFunc(statusHandler),
 		ReadTimeout:  time.Second,
 		WriteTimeout: time.Second,
 	}
@@ -103,11 +241,36 @@ func serverSetup(t *testing.T) func() {
 	return func() { srv.Shutdown(context.Background()) }
 }
 
-func monitorSetup(t *testing.T, handlerFn func([]httpTX)) (*Monitor, func()) {
-	mgr, perfHandler := eBPFSetup(t)
-	monitor, err := NewMonitor("/proc", mgr, perfHandler)
+func statusHandler(w nethttp.ResponseWriter, req *nethttp.Request) {
+	statusCode := statusFromPath(req.URL.Path)
+	io.Copy(ioutil.Discard, req.Body)
+	w.WriteHeader(statusCode)
+}
+
+// grpcStatusHandler responds like a gRPC server to the paths including a gRPC status code,
+// which is sent in the trailers, and like statusHandler otherwise
+// Example:
+// * POST /grpc/5/foo returns a 200 status code and a NOT_FOUND gRPC status;
+func grpcStatusHandler(w nethttp.ResponseWriter, req *nethttp.Request) {
+	if !strings.HasPrefix(req.URL.Path, "/grpc/") {
+		statusHandler(w, req)
+		return
+	}
+
+	io.Copy(ioutil.Discard, req.Body)
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(nethttp.StatusOK)
+	w.Header().Set(nethttp.TrailerPrefix+"Grpc-Status", strconv.Itoa(grpcStatusFromPath(req.URL.Path)))
+}
+
+func monitorSetup(t *testing.T, handlerFn func([]httpTX), http2HandlerFn func([]http2Transaction)) (*Monitor, func()) {
+	mgr, perfHandler, sharedLibrariesHandler := eBPFSetup(t)
+	monitor, err := NewMonitor("/proc", mgr, perfHandler, sharedLibrariesHandler)
 	require.NoError(t, err)
 	monitor.handler = handlerFn
+	if http2HandlerFn != nil {
+		monitor.http2Handler = http2HandlerFn
+	}
 
 	// Start HTTP monitor
 	err = monitor.Start()
@@ -124,47 +287,66 @@ func monitorSetup(t *testing.T, handlerFn func([]httpTX)) (*Monitor, func()) {
 	return monitor, doneFn
 }
 
-func eBPFSetup(t *testing.T) (*manager.Manager, *ddebpf.PerfHandler) {
+func eBPFSetup(t *testing.T) (*manager.Manager, *ddebpf.PerfHandler, *ddebpf.PerfHandler) {
 	currKernelVersion, err := kernel.HostVersion()
 	require.NoError(t, err)
 	pre410Kernel := currKernelVersion < kernel.VersionCode(4, 1, 0)
 	if pre410Kernel {
 		t.Skip("HTTP feature not available on pre 4.1.0 kernels")
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	httpPerfHandler := ddebpf.NewPerfHandler(10)
-	mgr := netebpf.NewManager(ddebpf.NewPerfHandler(1), httpPerfHandler)
+	sharedLibrariesHandler := ddebpf.NewPerfHandler(10)
+	mgr := netebpf.NewManager(ddebpf.NewPerfHandler(1), httpPerfHandler, sharedLibrariesHandler)
 	mgrOptions := manager.Options{
 		MapSpecEditors: map[string]manager.MapSpecEditor{
-			string(probes.HttpInFlightMap): {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.HttpInFlightMap):  {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLSockByCtxMap):  {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLCtxByTupleMap): {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.Http2ConnsMap):    {Type: ebpf.LRUHash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 
 			// These maps are unrelated to HTTP but need to have their `MaxEntries` set because the eBPF library loads all of them
 			string(probes.ConnMap):            {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 			string(probes.TcpStatsMap):        {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 			string(probes.PortBindingsMap):    {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 			string(probes.UdpPortBindingsMap): {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
+			string(probes.ConnProtocolsMap):   {Type: ebpf.Hash, MaxEntries: 1024, EditorFlag: manager.EditMaxEntries},
 		},
 		RLimit: &unix.Rlimit{
 			Cur: math.MaxUint64,
 			Max: math.MaxUint64,
 		},
-		ActivatedProbes: []manager.ProbesSelector{
-			&manager.ProbeSelector{
-				ProbeIdentificationPair: manager.ProbeIdentificationPair{
-					Section: string(probes.SocketHTTPFilter),
-				},
-			},
-			&manager.ProbeSelector{
-				ProbeIdentificationPair: manager.ProbeIdentificationPair{
-					Section: string(probes.TCPSendMsgReturn),
-				},
+	}
+
+	// The TCP send/receive probes bind the TLS sessions to their connection
+	activated := map[probes.ProbeName]struct{}{
+		probes.SocketHTTPFilter:  {},
+		probes.SocketHTTP2Filter: {},
+		probes.TCPSendMsg:        {},
+		probes.TCPSendMsgReturn:  {},
+		probes.TCPCleanupRBuf:    {},
+		probes.DoSysOpen:         {},
+		probes.DoSysOpenReturn:   {},
+	}
+	for probeName := range activated {
+		mgrOptions.ActivatedProbes = append(mgrOptions.ActivatedProbes, &manager.ProbeSelector{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				Section: string(probeName),
 			},
-		},
+		})
+	}
+
+	// The TLS library probes are loaded but only attached by the monitor
+	loaded := map[probes.ProbeName]struct{}{}
+	for _, p := range append(probes.OpenSSLProbes, probes.GnuTLSProbes...) {
+		loaded[p] = struct{}{}
 	}
 
 	for _, p := range mgr.Probes {
-		if p.Section != string(probes.SocketHTTPFilter) && p.Section != string(probes.TCPSendMsgReturn) {
+		_, isActivated := activated[probes.ProbeName(p.Section)]
+		_, isLoaded := loaded[probes.ProbeName(p.Section)]
+		if !isActivated && !isLoaded {
 			mgrOptions.ExcludedSections = append(mgrOptions.ExcludedSections, p.Section)
 		}
 	}
@@ -173,7 +355,7 @@ func eBPFSetup(t *testing.T) (*manager.Manager, *ddebpf.PerfHandler) {
 	require.NoError(t, err)
 	err = mgr.InitWithOptions(elf, mgrOptions)
 	require.NoError(t, err)
-	return mgr, httpPerfHandler
+	return mgr, httpPerfHandler, sharedLibrariesHandler
 }
 
 func requestGenerator(t *testing.T) func() *nethttp.Request {
@@ -200,7 +382,35 @@ func requestGenerator(t *testing.T) func() *nethttp.Request {
 	}
 }
 
-var pathParser = regexp.MustCompile(`/(\d{3})/.+`)
+// curlRequestGenerator returns a function performing HTTPS requests with curl, which
+// is started in a new process for each request
+func curlRequestGenerator(t *testing.T, curlPath string, baseURL string) func() *nethttp.Request {
+	var (
+		methods     = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+		statusCodes = []int{200, 300, 400, 500}
+		random      = rand.New(rand.NewSource(time.Now().Unix()))
+		idx         = 0
+	)
+
+	return func() *nethttp.Request {
+		idx++
+		method := methods[random.Intn(len(methods))]
+		status := statusCodes[random.Intn(len(statusCodes))]
+		url := fmt.Sprintf("%s/%d/request-%d", baseURL, status, idx)
+		req, err := nethttp.NewRequest(method, url, nil)
+		require.NoError(t, err)
+
+		// the test server certificate is self-signed
+		out, err := exec.Command(curlPath, "--silent", "--insecure", "--http1.1", "--request", method, url).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return req
+	}
+}
+
+var (
+	pathParser     = regexp.MustCompile(`/(\d{3})/.+`)
+	grpcPathParser = regexp.MustCompile(`/grpc/(\d+)/.+`)
+)
 
 func statusFromPath(path string) (status int) {
 	matches := pathParser.FindStringSubmatch(path)
@@ -210,3 +420,13 @@ func statusFromPath(path string) (status int) {
 
 	return
 }
+
+func grpcStatusFromPath(path string) int {
+	matches := grpcPathParser.FindStringSubmatch(path)
+	if len(matches) != 2 {
+		return grpcStatusUnknown
+	}
+
+	status, _ := strconv.Atoi(matches[1])
+	return status
+}
@@ -0,0 +1,190 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/ebpf/manager"
+)
+
+// soRule associates a pattern matching the path of shared libraries with the callback
+// registering the hooks of these libraries
+type soRule struct {
+	re         *regexp.Regexp
+	registerCB func(path string) error
+}
+
+// soKey identifies a shared library file. Since the same library can be reached from different
+// paths (for instance from the host and from a container root) it is used to hook each file once.
+type soKey struct {
+	dev   uint64
+	inode uint64
+}
+
+// soWatcher is responsible for finding the shared libraries loaded by processes:
+// * the libraries mapped by the processes running when it starts are read from procfs;
+// * the libraries opened later on are reported by the do_sys_open kprobe through a perf buffer;
+type soWatcher struct {
+	procRoot    string
+	perfMap     *manager.PerfMap
+	perfHandler *ddebpf.PerfHandler
+	rules       []soRule
+
+	// registered holds the libraries whose hooks were registered, or failed to
+	registered map[soKey]struct{}
+	wg         sync.WaitGroup
+}
+
+func newSOWatcher(procRoot string, perfMap *manager.PerfMap, perfHandler *ddebpf.PerfHandler, rules ...soRule) *soWatcher {
+	return &soWatcher{
+		procRoot:    procRoot,
+		perfMap:     perfMap,
+		perfHandler: perfHandler,
+		rules:       rules,
+		registered:  make(map[soKey]struct{}),
+	}
+}
+
+// Start registers the libraries loaded by the running processes and starts watching for new ones
+func (w *soWatcher) Start() error {
+	if err := w.perfMap.Start(); err != nil {
+		return fmt.Errorf("error starting perf map: %s", err)
+	}
+
+	_ = util.WithAllProcs(w.procRoot, func(pid int) error {
+		for _, lib := range w.getSharedLibraries(pid) {
+			w.register(pid, lib)
+		}
+		return nil
+	})
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case data, ok := <-w.perfHandler.DataChannel:
+				if !ok {
+					return
+				}
+
+				lib := toLibPath(data)
+				path := lib.Path()
+				if w.matches(path) {
+					w.register(int(lib.pid), path)
+				}
+			case lost, ok := <-w.perfHandler.LostChannel:
+				if !ok {
+					return
+				}
+
+				log.Debugf("lost %d shared library notifications", lost)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop watching for new shared libraries
+func (w *soWatcher) Stop() {
+	_ = w.perfMap.Stop(manager.CleanAll)
+	w.perfHandler.Stop()
+	w.wg.Wait()
+}
+
+// getSharedLibraries returns the paths of the libraries matching a rule mapped by a process
+func (w *soWatcher) getSharedLibraries(pid int) []string {
+	f, err := os.Open(filepath.Join(w.procRoot, strconv.Itoa(pid), "maps"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var libs []string
+	for _, path := range parseMapsFile(f) {
+		if w.matches(path) {
+			libs = append(libs, path)
+		}
+	}
+	return libs
+}
+
+func (w *soWatcher) matches(path string) bool {
+	for _, r := range w.rules {
+		if r.re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// register calls the callback of the rules matching a library opened by a process.
+// The path is resolved from the root directory of the process, so the libraries of
+// containerized processes are found as well.
+func (w *soWatcher) register(pid int, path string) {
+	hostPath := filepath.Join(w.procRoot, strconv.Itoa(pid), "root", path)
+	info, err := os.Stat(hostPath)
+	if err != nil {
+		log.Debugf("could not stat shared library %s: %s", hostPath, err)
+		return
+	}
+
+	key := soKey{}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		key = soKey{dev: uint64(stat.Dev), inode: stat.Ino}
+	}
+	if _, found := w.registered[key]; found {
+		return
+	}
+	w.registered[key] = struct{}{}
+
+	for _, r := range w.rules {
+		if !r.re.MatchString(path) {
+			continue
+		}
+
+		if err := r.registerCB(hostPath); err != nil {
+			log.Errorf("error registering shared library %s: %s", path, err)
+			continue
+		}
+		log.Debugf("registered shared library %s (pid %d)", path, pid)
+	}
+}
+
+// parseMapsFile returns the distinct paths of the files mapped in memory from
+// the content of a /proc/<pid>/maps file
+func parseMapsFile(r io.Reader) []string {
+	var paths []string
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// address perms offset dev inode pathname
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || !strings.HasPrefix(fields[5], "/") {
+			continue
+		}
+
+		path := strings.Join(fields[5:], " ")
+		if _, found := seen[path]; found {
+			continue
+		}
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,62 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
+	"github.com/DataDog/ebpf/manager"
+)
+
+// newSSLWatcher returns a soWatcher hooking the OpenSSL and GnuTLS probes on the libraries loaded by processes.
+// The decrypted payloads captured by these probes are enqueued in the same batches as the plaintext traffic.
+func newSSLWatcher(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*soWatcher, error) {
+	pm, found := mgr.GetPerfMap(string(probes.SharedLibrariesMap))
+	if !found {
+		return nil, fmt.Errorf("unable to find perf map %s", probes.SharedLibrariesMap)
+	}
+
+	return newSOWatcher(procRoot, pm, h,
+		soRule{
+			re:         regexp.MustCompile(`libssl\.so`),
+			registerCB: addHooks(mgr, probes.OpenSSLProbes),
+		},
+		soRule{
+			re:         regexp.MustCompile(`libgnutls\.so`),
+			registerCB: addHooks(mgr, probes.GnuTLSProbes),
+		},
+	), nil
+}
+
+// addHooks returns a callback attaching a copy of each of the given uprobes to a library
+func addHooks(mgr *manager.Manager, probeNames []probes.ProbeName) func(string) error {
+	return func(libPath string) error {
+		uid := getUID(libPath)
+		for _, name := range probeNames {
+			newProbe := manager.Probe{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					UID:     uid,
+					Section: string(name),
+				},
+				BinaryPath: libPath,
+			}
+
+			// the probes declared by the manager without UID serve as templates
+			if err := mgr.AddHook("", newProbe); err != nil {
+				return fmt.Errorf("error hooking %s: %s", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// getUID returns a short identifier for the probes of a library, unique per library path
+func getUID(libPath string) string {
+	sum := sha256.Sum256([]byte(libPath))
+	return hex.EncodeToString(sum[:8])
+}
@@ -35,7 +35,7 @@ type State interface {
 		clientID string,
 		latestTime uint64,
 		latestConns []ConnectionStats,
-		dns map[DNSKey]map[string]DNSStats,
+		dns map[DNSKey]map[string]map[QueryType]DNSStats,
 		http map[http.Key]map[string]http.RequestStats,
 	) []ConnectionStats
 
@@ -75,6 +75,14 @@ type stats struct {
 	totalRetransmits    uint32
 	totalTCPEstablished uint32
 	totalTCPClosed      uint32
+
+	totalZeroWindowProbes   uint32
+	totalOutOfOrderPackets  uint32
+	totalResetsSent         uint32
+	totalResetsReceived     uint32
+	totalSYNTimeouts        uint32
+	totalProtocolRequests   uint64
+	totalProtocolLatencySum uint64
 }
 
 type client struct {
@@ -82,7 +90,7 @@ type client struct {
 
 	closedConnections map[string]ConnectionStats
 	stats             map[string]*stats
-	dnsStats          map[DNSKey]map[string]DNSStats
+	dnsStats          map[DNSKey]map[string]map[QueryType]DNSStats
 	httpStatsDelta    map[http.Key]map[string]http.RequestStats
 }
 
@@ -138,7 +146,7 @@ func (ns *networkState) Connections(
 	id string,
 	latestTime uint64,
 	latestConns []ConnectionStats,
-	dnsStats map[DNSKey]map[string]DNSStats,
+	dnsStats map[DNSKey]map[string]map[QueryType]DNSStats,
 	httpStats map[http.Key]map[string]http.RequestStats,
 ) []ConnectionStats {
 	ns.Lock()
@@ -162,6 +170,13 @@ func (ns *networkState) Connections(
 			c.LastRetransmits = 0
 			c.LastTCPEstablished = 0
 			c.LastTCPClosed = 0
+			c.LastZeroWindowProbes = 0
+			c.LastOutOfOrderPackets = 0
+			c.LastResetsSent = 0
+			c.LastResetsReceived = 0
+			c.LastSYNTimeouts = 0
+			c.LastProtocolRequests = 0
+			c.LastProtocolLatencySum = 0
 		}
 
 		ns.determineConnectionIntraHost(latestConns)
@@ -230,30 +245,35 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 
 		if dnsStatsByDomain, ok := ns.clients[id].dnsStats[key]; ok {
 			if ns.collectDNSDomains {
-				conn.DNSStatsByDomain = make(map[string]DNSStats)
+				conn.DNSStatsByDomain = make(map[string]map[QueryType]DNSStats)
 			} else {
 				conn.DNSCountByRcode = make(map[uint32]uint32)
 			}
 			var total uint32
-			for domain, dnsStats := range dnsStatsByDomain {
+			for domain, statsByType := range dnsStatsByDomain {
 				if ns.collectDNSDomains {
-					var ds DNSStats
-					ds.DNSTimeouts = dnsStats.DNSTimeouts
-					ds.DNSSuccessLatencySum = dnsStats.DNSSuccessLatencySum
-					ds.DNSFailureLatencySum = dnsStats.DNSFailureLatencySum
-					ds.DNSCountByRcode = make(map[uint32]uint32)
-					for rcode, count := range dnsStats.DNSCountByRcode {
-						ds.DNSCountByRcode[rcode] = count
-					}
-					conn.DNSStatsByDomain[domain] = ds
-				} else {
-					conn.DNSSuccessfulResponses += dnsStats.DNSCountByRcode[DNSResponseCodeNoError]
-					conn.DNSTimeouts += dnsStats.DNSTimeouts
-					conn.DNSSuccessLatencySum += dnsStats.DNSSuccessLatencySum
-					conn.DNSFailureLatencySum += dnsStats.DNSFailureLatencySum
-					for rcode, count := range dnsStats.DNSCountByRcode {
-						conn.DNSCountByRcode[rcode] += count
-						total += count
+					conn.DNSStatsByDomain[domain] = make(map[QueryType]DNSStats)
+				}
+				for queryType, dnsStats := range statsByType {
+					if ns.collectDNSDomains {
+						var ds DNSStats
+						ds.DNSTimeouts = dnsStats.DNSTimeouts
+						ds.DNSSuccessLatencySum = dnsStats.DNSSuccessLatencySum
+						ds.DNSFailureLatencySum = dnsStats.DNSFailureLatencySum
+						ds.DNSCountByRcode = make(map[uint32]uint32)
+						for rcode, count := range dnsStats.DNSCountByRcode {
+							ds.DNSCountByRcode[rcode] = count
+						}
+						conn.DNSStatsByDomain[domain][queryType] = ds
+					} else {
+						conn.DNSSuccessfulResponses += dnsStats.DNSCountByRcode[DNSResponseCodeNoError]
+						conn.DNSTimeouts += dnsStats.DNSTimeouts
+						conn.DNSSuccessLatencySum += dnsStats.DNSSuccessLatencySum
+						conn.DNSFailureLatencySum += dnsStats.DNSFailureLatencySum
+						for rcode, count := range dnsStats.DNSCountByRcode {
+							conn.DNSCountByRcode[rcode] += count
+							total += count
+						}
 					}
 				}
 			}
@@ -265,7 +285,7 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 	}
 
 	// flush the DNS stats
-	ns.clients[id].dnsStats = make(map[DNSKey]map[string]DNSStats)
+	ns.clients[id].dnsStats = make(map[DNSKey]map[string]map[QueryType]DNSStats)
 }
 
 // addHTTPStats fills in the HTTP stats for each connection
@@ -328,6 +348,16 @@ func (ns *networkState) StoreClosedConnection(conn *ConnectionStats) {
 			prev.MonotonicRetransmits += conn.MonotonicRetransmits
 			prev.MonotonicTCPEstablished += conn.MonotonicTCPEstablished
 			prev.MonotonicTCPClosed += conn.MonotonicTCPClosed
+			prev.MonotonicZeroWindowProbes += conn.MonotonicZeroWindowProbes
+			prev.MonotonicOutOfOrderPackets += conn.MonotonicOutOfOrderPackets
+			prev.MonotonicResetsSent += conn.MonotonicResetsSent
+			prev.MonotonicResetsReceived += conn.MonotonicResetsReceived
+			prev.MonotonicSYNTimeouts += conn.MonotonicSYNTimeouts
+			prev.MonotonicProtocolRequests += conn.MonotonicProtocolRequests
+			prev.MonotonicProtocolLatencySum += conn.MonotonicProtocolLatencySum
+			if conn.Protocol != ProtocolUnknown {
+				prev.Protocol = conn.Protocol
+			}
 			// Also update the timestamp
 			prev.LastUpdateEpoch = conn.LastUpdateEpoch
 			client.closedConnections[string(key)] = prev
@@ -341,24 +371,31 @@ func (ns *networkState) StoreClosedConnection(conn *ConnectionStats) {
 }
 
 // storeDNSStats stores latest DNS stats for all clients
-func (ns *networkState) storeDNSStats(stats map[DNSKey]map[string]DNSStats) {
+func (ns *networkState) storeDNSStats(stats map[DNSKey]map[string]map[QueryType]DNSStats) {
 	for key, statsByDomain := range stats {
 		for _, client := range ns.clients {
 			// If we've seen DNS stats for this key already, let's combine the two
 			if prevByDomain, ok := client.dnsStats[key]; ok {
-				for domain, dns := range statsByDomain {
-					if prev, ok := prevByDomain[domain]; ok {
-						prev.DNSTimeouts += dns.DNSTimeouts
-						prev.DNSSuccessLatencySum += dns.DNSSuccessLatencySum
-						prev.DNSFailureLatencySum += dns.DNSFailureLatencySum
-						for rcode, count := range dns.DNSCountByRcode {
-							prev.DNSCountByRcode[rcode] += count
-						}
-						prevByDomain[domain] = prev
-					} else {
-						prevByDomain[domain] = dns
+				for domain, statsByType := range statsByDomain {
+					prevByType, ok := prevByDomain[domain]
+					if !ok {
+						prevByDomain[domain] = statsByType
+						continue
 					}
 
+					for queryType, dns := range statsByType {
+						if prev, ok := prevByType[queryType]; ok {
+							prev.DNSTimeouts += dns.DNSTimeouts
+							prev.DNSSuccessLatencySum += dns.DNSSuccessLatencySum
+							prev.DNSFailureLatencySum += dns.DNSFailureLatencySum
+							for rcode, count := range dns.DNSCountByRcode {
+								prev.DNSCountByRcode[rcode] += count
+							}
+							prevByType[queryType] = prev
+						} else {
+							prevByType[queryType] = dns
+						}
+					}
 				}
 				client.dnsStats[key] = prevByDomain
 			} else if len(client.dnsStats) >= ns.maxDNSStats {
@@ -411,7 +448,7 @@ func (ns *networkState) newClient(clientID string) (*client, bool) {
 		lastFetch:         time.Now(),
 		stats:             map[string]*stats{},
 		closedConnections: map[string]ConnectionStats{},
-		dnsStats:          map[DNSKey]map[string]DNSStats{},
+		dnsStats:          map[DNSKey]map[string]map[QueryType]DNSStats{},
 		httpStatsDelta:    map[http.Key]map[string]http.RequestStats{},
 	}
 	ns.clients[clientID] = c
@@ -442,6 +479,16 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 				closedConn.MonotonicRetransmits += activeConn.MonotonicRetransmits
 				closedConn.MonotonicTCPEstablished += activeConn.MonotonicTCPEstablished
 				closedConn.MonotonicTCPClosed += activeConn.MonotonicTCPClosed
+				closedConn.MonotonicZeroWindowProbes += activeConn.MonotonicZeroWindowProbes
+				closedConn.MonotonicOutOfOrderPackets += activeConn.MonotonicOutOfOrderPackets
+				closedConn.MonotonicResetsSent += activeConn.MonotonicResetsSent
+				closedConn.MonotonicResetsReceived += activeConn.MonotonicResetsReceived
+				closedConn.MonotonicSYNTimeouts += activeConn.MonotonicSYNTimeouts
+				closedConn.MonotonicProtocolRequests += activeConn.MonotonicProtocolRequests
+				closedConn.MonotonicProtocolLatencySum += activeConn.MonotonicProtocolLatencySum
+				if activeConn.Protocol != ProtocolUnknown {
+					closedConn.Protocol = activeConn.Protocol
+				}
 
 				ns.createStatsForKey(client, key)
 				ns.updateConnWithStatWithActiveConn(client, key, *activeConn, &closedConn)
@@ -497,6 +544,13 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		closed.LastRetransmits = closed.MonotonicRetransmits - st.totalRetransmits
 		closed.LastTCPEstablished = closed.LastTCPEstablished - st.totalTCPEstablished
 		closed.LastTCPClosed = closed.LastTCPClosed - st.totalTCPClosed
+		closed.LastZeroWindowProbes = closed.MonotonicZeroWindowProbes - st.totalZeroWindowProbes
+		closed.LastOutOfOrderPackets = closed.MonotonicOutOfOrderPackets - st.totalOutOfOrderPackets
+		closed.LastResetsSent = closed.MonotonicResetsSent - st.totalResetsSent
+		closed.LastResetsReceived = closed.MonotonicResetsReceived - st.totalResetsReceived
+		closed.LastSYNTimeouts = closed.MonotonicSYNTimeouts - st.totalSYNTimeouts
+		closed.LastProtocolRequests = closed.MonotonicProtocolRequests - st.totalProtocolRequests
+		closed.LastProtocolLatencySum = closed.MonotonicProtocolLatencySum - st.totalProtocolLatencySum
 
 		// Update stats object with latest values
 		st.totalSent = active.MonotonicSentBytes
@@ -504,12 +558,26 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		st.totalRetransmits = active.MonotonicRetransmits
 		st.totalTCPEstablished = active.MonotonicTCPEstablished
 		st.totalTCPClosed = active.MonotonicTCPClosed
+		st.totalZeroWindowProbes = active.MonotonicZeroWindowProbes
+		st.totalOutOfOrderPackets = active.MonotonicOutOfOrderPackets
+		st.totalResetsSent = active.MonotonicResetsSent
+		st.totalResetsReceived = active.MonotonicResetsReceived
+		st.totalSYNTimeouts = active.MonotonicSYNTimeouts
+		st.totalProtocolRequests = active.MonotonicProtocolRequests
+		st.totalProtocolLatencySum = active.MonotonicProtocolLatencySum
 	} else {
 		closed.LastSentBytes = closed.MonotonicSentBytes
 		closed.LastRecvBytes = closed.MonotonicRecvBytes
 		closed.LastRetransmits = closed.MonotonicRetransmits
 		closed.LastTCPEstablished = closed.MonotonicTCPEstablished
 		closed.LastTCPClosed = closed.MonotonicTCPClosed
+		closed.LastZeroWindowProbes = closed.MonotonicZeroWindowProbes
+		closed.LastOutOfOrderPackets = closed.MonotonicOutOfOrderPackets
+		closed.LastResetsSent = closed.MonotonicResetsSent
+		closed.LastResetsReceived = closed.MonotonicResetsReceived
+		closed.LastSYNTimeouts = closed.MonotonicSYNTimeouts
+		closed.LastProtocolRequests = closed.MonotonicProtocolRequests
+		closed.LastProtocolLatencySum = closed.MonotonicProtocolLatencySum
 	}
 }
 
@@ -523,6 +591,13 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		c.LastRetransmits = c.MonotonicRetransmits - st.totalRetransmits
 		c.LastTCPEstablished = c.MonotonicTCPEstablished - st.totalTCPEstablished
 		c.LastTCPClosed = c.MonotonicTCPClosed - st.totalTCPClosed
+		c.LastZeroWindowProbes = c.MonotonicZeroWindowProbes - st.totalZeroWindowProbes
+		c.LastOutOfOrderPackets = c.MonotonicOutOfOrderPackets - st.totalOutOfOrderPackets
+		c.LastResetsSent = c.MonotonicResetsSent - st.totalResetsSent
+		c.LastResetsReceived = c.MonotonicResetsReceived - st.totalResetsReceived
+		c.LastSYNTimeouts = c.MonotonicSYNTimeouts - st.totalSYNTimeouts
+		c.LastProtocolRequests = c.MonotonicProtocolRequests - st.totalProtocolRequests
+		c.LastProtocolLatencySum = c.MonotonicProtocolLatencySum - st.totalProtocolLatencySum
 
 		// Update stats object with latest values
 		st.totalSent = c.MonotonicSentBytes
@@ -530,23 +605,47 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		st.totalRetransmits = c.MonotonicRetransmits
 		st.totalTCPEstablished = c.MonotonicTCPEstablished
 		st.totalTCPClosed = c.MonotonicTCPClosed
+		st.totalZeroWindowProbes = c.MonotonicZeroWindowProbes
+		st.totalOutOfOrderPackets = c.MonotonicOutOfOrderPackets
+		st.totalResetsSent = c.MonotonicResetsSent
+		st.totalResetsReceived = c.MonotonicResetsReceived
+		st.totalSYNTimeouts = c.MonotonicSYNTimeouts
+		st.totalProtocolRequests = c.MonotonicProtocolRequests
+		st.totalProtocolLatencySum = c.MonotonicProtocolLatencySum
 	} else {
 		c.LastSentBytes = c.MonotonicSentBytes
 		c.LastRecvBytes = c.MonotonicRecvBytes
 		c.LastRetransmits = c.MonotonicRetransmits
 		c.LastTCPEstablished = c.MonotonicTCPEstablished
 		c.LastTCPClosed = c.MonotonicTCPClosed
+		c.LastZeroWindowProbes = c.MonotonicZeroWindowProbes
+		c.LastOutOfOrderPackets = c.MonotonicOutOfOrderPackets
+		c.LastResetsSent = c.MonotonicResetsSent
+		c.LastResetsReceived = c.MonotonicResetsReceived
+		c.LastSYNTimeouts = c.MonotonicSYNTimeouts
+		c.LastProtocolRequests = c.MonotonicProtocolRequests
+		c.LastProtocolLatencySum = c.MonotonicProtocolLatencySum
 	}
 }
 
 // handleStatsUnderflow checks if we are going to have an underflow when computing last stats and if it's the case it resets the stats to avoid it
 func (ns *networkState) handleStatsUnderflow(key string, st *stats, c *ConnectionStats) {
-	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits {
+	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits ||
+		c.MonotonicZeroWindowProbes < st.totalZeroWindowProbes || c.MonotonicOutOfOrderPackets < st.totalOutOfOrderPackets ||
+		c.MonotonicResetsSent < st.totalResetsSent || c.MonotonicResetsReceived < st.totalResetsReceived || c.MonotonicSYNTimeouts < st.totalSYNTimeouts ||
+		c.MonotonicProtocolRequests < st.totalProtocolRequests || c.MonotonicProtocolLatencySum < st.totalProtocolLatencySum {
 		ns.telemetry.statsResets++
 		log.Debugf("Stats reset triggered for key:%s, stats:%+v, connection:%+v", BeautifyKey(key), *st, *c)
 		st.totalSent = 0
 		st.totalRecv = 0
 		st.totalRetransmits = 0
+		st.totalZeroWindowProbes = 0
+		st.totalOutOfOrderPackets = 0
+		st.totalResetsSent = 0
+		st.totalResetsReceived = 0
+		st.totalSYNTimeouts = 0
+		st.totalProtocolRequests = 0
+		st.totalProtocolLatencySum = 0
 	}
 }
 
@@ -654,11 +753,18 @@ func (ns *networkState) DumpState(clientID string) map[string]interface{} {
 	if client, ok := ns.clients[clientID]; ok {
 		for connKey, s := range client.stats {
 			data[BeautifyKey(connKey)] = map[string]uint64{
-				"total_sent":            s.totalSent,
-				"total_recv":            s.totalRecv,
-				"total_retransmits":     uint64(s.totalRetransmits),
-				"total_tcp_established": uint64(s.totalTCPEstablished),
-				"total_tcp_closed":      uint64(s.totalTCPClosed),
+				"total_sent":                 s.totalSent,
+				"total_recv":                 s.totalRecv,
+				"total_retransmits":          uint64(s.totalRetransmits),
+				"total_tcp_established":      uint64(s.totalTCPEstablished),
+				"total_tcp_closed":           uint64(s.totalTCPClosed),
+				"total_zero_window_probes":   uint64(s.totalZeroWindowProbes),
+				"total_out_of_order_packets": uint64(s.totalOutOfOrderPackets),
+				"total_resets_sent":          uint64(s.totalResetsSent),
+				"total_resets_received":      uint64(s.totalResetsReceived),
+				"total_syn_timeouts":         uint64(s.totalSYNTimeouts),
+				"total_protocol_requests":    s.totalProtocolRequests,
+				"total_protocol_latency_sum": s.totalProtocolLatencySum,
 			}
 		}
 	}
@@ -374,6 +374,127 @@ func TestLastStatsForClosedConnection(t *testing.T) {
 	assert.Equal(t, conn2.MonotonicRetransmits, conns[0].MonotonicRetransmits)
 }
 
+func TestLastProtocolStats(t *testing.T) {
+	clientID := "1"
+	state := newDefaultState()
+
+	dRequests := uint64(3)
+	dLatency := uint64(4500)
+
+	conn := ConnectionStats{
+		Pid:                         123,
+		Type:                        TCP,
+		Family:                      AFINET,
+		Source:                      util.AddressFromString("127.0.0.1"),
+		Dest:                        util.AddressFromString("127.0.0.1"),
+		SPort:                       31890,
+		DPort:                       5432,
+		Protocol:                    ProtocolPostgres,
+		MonotonicProtocolRequests:   5,
+		MonotonicProtocolLatencySum: 7000,
+		LastUpdateEpoch:             latestEpochTime(),
+	}
+
+	conn2 := conn
+	conn2.MonotonicProtocolRequests += dRequests
+	conn2.MonotonicProtocolLatencySum += dLatency
+	conn2.LastUpdateEpoch = latestEpochTime()
+
+	// The same tuple is reused by a connection which wasn't classified
+	conn3 := conn
+	conn3.Protocol = ProtocolUnknown
+	conn3.MonotonicProtocolRequests = 0
+	conn3.MonotonicProtocolLatencySum = 0
+	conn3.LastUpdateEpoch = latestEpochTime()
+
+	// First get, we should not have any connections stored
+	conns := state.Connections(clientID, latestEpochTime(), nil, nil, nil)
+	assert.Equal(t, 0, len(conns))
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil)
+	require.Equal(t, 1, len(conns))
+	assert.Equal(t, ProtocolPostgres, conns[0].Protocol)
+	assert.Equal(t, conn.MonotonicProtocolRequests, conns[0].LastProtocolRequests)
+	assert.Equal(t, conn.MonotonicProtocolLatencySum, conns[0].LastProtocolLatencySum)
+
+	state.StoreClosedConnection(&conn2)
+	state.StoreClosedConnection(&conn3)
+
+	conns = state.Connections(clientID, latestEpochTime(), nil, nil, nil)
+	require.Equal(t, 1, len(conns))
+	assert.Equal(t, ProtocolPostgres, conns[0].Protocol)
+	assert.Equal(t, dRequests, conns[0].LastProtocolRequests)
+	assert.Equal(t, dLatency, conns[0].LastProtocolLatencySum)
+	assert.Equal(t, conn2.MonotonicProtocolRequests, conns[0].MonotonicProtocolRequests)
+}
+
+func TestLastTCPEventCounters(t *testing.T) {
+	clientID := "1"
+	state := newDefaultState()
+
+	conn := ConnectionStats{
+		Pid:                        123,
+		Type:                       TCP,
+		Family:                     AFINET,
+		Source:                     util.AddressFromString("127.0.0.1"),
+		Dest:                       util.AddressFromString("127.0.0.1"),
+		SPort:                      31890,
+		DPort:                      80,
+		MonotonicZeroWindowProbes:  2,
+		MonotonicOutOfOrderPackets: 10,
+		MonotonicResetsSent:        0,
+		MonotonicResetsReceived:    0,
+		MonotonicSYNTimeouts:       1,
+		LastUpdateEpoch:            latestEpochTime(),
+	}
+
+	// The connection is still active and received more segments out of order
+	conn2 := conn
+	conn2.MonotonicZeroWindowProbes += 3
+	conn2.MonotonicOutOfOrderPackets += 5
+	conn2.LastUpdateEpoch = latestEpochTime()
+
+	// The connection is then reset by its peer
+	conn3 := conn2
+	conn3.MonotonicResetsReceived = 1
+	conn3.LastUpdateEpoch = latestEpochTime()
+
+	// The same tuple is reused by another connection which is still active
+	conn4 := conn
+	conn4.MonotonicZeroWindowProbes = 0
+	conn4.MonotonicOutOfOrderPackets = 4
+	conn4.MonotonicResetsSent = 1
+	conn4.MonotonicSYNTimeouts = 2
+	conn4.LastUpdateEpoch = latestEpochTime()
+
+	// First get, we should not have any connections stored
+	conns := state.Connections(clientID, latestEpochTime(), nil, nil, nil)
+	assert.Equal(t, 0, len(conns))
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil)
+	require.Equal(t, 1, len(conns))
+	assert.Equal(t, uint32(2), conns[0].LastZeroWindowProbes)
+	assert.Equal(t, uint32(10), conns[0].LastOutOfOrderPackets)
+	assert.Equal(t, uint32(1), conns[0].LastSYNTimeouts)
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn2}, nil, nil)
+	require.Equal(t, 1, len(conns))
+	assert.Equal(t, uint32(3), conns[0].LastZeroWindowProbes)
+	assert.Equal(t, uint32(5), conns[0].LastOutOfOrderPackets)
+	assert.Equal(t, uint32(0), conns[0].LastResetsReceived)
+	assert.Equal(t, uint32(0), conns[0].LastSYNTimeouts)
+
+	state.StoreClosedConnection(&conn3)
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn4}, nil, nil)
+	require.Equal(t, 1, len(conns))
+	assert.Equal(t, uint32(0), conns[0].LastZeroWindowProbes)
+	assert.Equal(t, uint32(4), conns[0].LastOutOfOrderPackets)
+	assert.Equal(t, uint32(1), conns[0].LastResetsSent)
+	assert.Equal(t, uint32(1), conns[0].LastResetsReceived)
+	assert.Equal(t, uint32(2), conns[0].LastSYNTimeouts)
+}
+
 func TestRaceConditions(t *testing.T) {
 	nClients := 10
 
@@ -1147,13 +1268,13 @@ func TestDNSStatsWithMultipleClients(t *testing.T) {
 
 	dKey := DNSKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
 
-	getStats := func() map[DNSKey]map[string]DNSStats {
+	getStats := func() map[DNSKey]map[string]map[QueryType]DNSStats {
 		var d = "foo.com"
-		statsByDomain := make(map[DNSKey]map[string]DNSStats)
-		stats := make(map[string]DNSStats)
+		statsByDomain := make(map[DNSKey]map[string]map[QueryType]DNSStats)
+		stats := make(map[string]map[QueryType]DNSStats)
 		countByRcode := make(map[uint32]uint32)
 		countByRcode[uint32(DNSResponseCodeNoError)] = 1
-		stats[d] = DNSStats{DNSCountByRcode: countByRcode}
+		stats[d] = map[QueryType]DNSStats{DNSTypeA: {DNSCountByRcode: countByRcode}}
 		statsByDomain[dKey] = stats
 		return statsByDomain
 	}
@@ -1199,12 +1320,12 @@ func TestDNSStatsWithMultipleClientsWithDomainCollectionEnabled(t *testing.T) {
 
 	dKey := DNSKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
 	var d = "foo.com"
-	getStats := func() map[DNSKey]map[string]DNSStats {
-		statsByDomain := make(map[DNSKey]map[string]DNSStats)
-		stats := make(map[string]DNSStats)
+	getStats := func() map[DNSKey]map[string]map[QueryType]DNSStats {
+		statsByDomain := make(map[DNSKey]map[string]map[QueryType]DNSStats)
+		stats := make(map[string]map[QueryType]DNSStats)
 		countByRcode := make(map[uint32]uint32)
 		countByRcode[uint32(DNSResponseCodeNoError)] = 1
-		stats[d] = DNSStats{DNSCountByRcode: countByRcode}
+		stats[d] = map[QueryType]DNSStats{DNSTypeA: {DNSCountByRcode: countByRcode}}
 		statsByDomain[dKey] = stats
 		return statsByDomain
 	}
@@ -1223,7 +1344,7 @@ func TestDNSStatsWithMultipleClientsWithDomainCollectionEnabled(t *testing.T) {
 
 	conns := state.Connections(client1, latestEpochTime(), nil, getStats(), nil)
 	require.Len(t, conns, 1)
-	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d].DNSCountByRcode[DNSResponseCodeNoError])
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d][DNSTypeA].DNSCountByRcode[DNSResponseCodeNoError])
 	// domain agnostic stats should be 0
 	assert.EqualValues(t, 0, conns[0].DNSSuccessfulResponses)
 
@@ -1231,18 +1352,65 @@ func TestDNSStatsWithMultipleClientsWithDomainCollectionEnabled(t *testing.T) {
 	conns = state.Connections(client3, latestEpochTime(), []ConnectionStats{c}, getStats(), nil)
 	require.Len(t, conns, 1)
 	// DNS stats should be available for the new client
-	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d].DNSCountByRcode[DNSResponseCodeNoError])
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d][DNSTypeA].DNSCountByRcode[DNSResponseCodeNoError])
 	// domain agnostic stats should be 0
 	assert.EqualValues(t, 0, conns[0].DNSSuccessfulResponses)
 
 	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, getStats(), nil)
 	require.Len(t, conns, 1)
 	// 2nd client should get accumulated stats
-	assert.EqualValues(t, 3, conns[0].DNSStatsByDomain[d].DNSCountByRcode[DNSResponseCodeNoError])
+	assert.EqualValues(t, 3, conns[0].DNSStatsByDomain[d][DNSTypeA].DNSCountByRcode[DNSResponseCodeNoError])
 	// domain agnostic stats should be 0
 	assert.EqualValues(t, 0, conns[0].DNSSuccessfulResponses)
 }
 
+func TestDNSStatsByQueryType(t *testing.T) {
+	c := ConnectionStats{
+		Pid:    123,
+		Type:   UDP,
+		Family: AFINET,
+		Source: util.AddressFromString("127.0.0.1"),
+		Dest:   util.AddressFromString("127.0.0.1"),
+		SPort:  1000,
+		DPort:  53,
+	}
+
+	dKey := DNSKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
+	var d = "foo.com"
+	getStats := func(queryType QueryType, rcode uint32) map[DNSKey]map[string]map[QueryType]DNSStats {
+		return map[DNSKey]map[string]map[QueryType]DNSStats{
+			dKey: {
+				d: {
+					queryType: {DNSCountByRcode: map[uint32]uint32{rcode: 1}},
+				},
+			},
+		}
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := NewState(2*time.Minute, 50000, 75000, 75000, 7500, true)
+
+	// Register both clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil, nil), 0)
+
+	c.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(&c)
+
+	conns := state.Connections(client1, latestEpochTime(), nil, getStats(DNSTypeA, DNSResponseCodeNoError), nil)
+	require.Len(t, conns, 1)
+	require.Len(t, conns[0].DNSStatsByDomain[d], 1)
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d][DNSTypeA].DNSCountByRcode[DNSResponseCodeNoError])
+
+	// The 2nd client should get the stats of both query types, kept apart
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, getStats(DNSTypeAAAA, 3), nil)
+	require.Len(t, conns, 1)
+	require.Len(t, conns[0].DNSStatsByDomain[d], 2)
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d][DNSTypeA].DNSCountByRcode[DNSResponseCodeNoError])
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain[d][DNSTypeAAAA].DNSCountByRcode[3])
+}
+
 func TestDNSStatsPIDCollisions(t *testing.T) {
 	c := ConnectionStats{
 		Pid:    123,
@@ -1256,11 +1424,11 @@ func TestDNSStatsPIDCollisions(t *testing.T) {
 
 	var d = "foo.com"
 	dKey := DNSKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
-	statsByDomain := make(map[DNSKey]map[string]DNSStats)
-	stats := make(map[string]DNSStats)
+	statsByDomain := make(map[DNSKey]map[string]map[QueryType]DNSStats)
+	stats := make(map[string]map[QueryType]DNSStats)
 	countByRcode := make(map[uint32]uint32)
 	countByRcode[DNSResponseCodeNoError] = 1
-	stats[d] = DNSStats{DNSCountByRcode: countByRcode}
+	stats[d] = map[QueryType]DNSStats{DNSTypeA: {DNSCountByRcode: countByRcode}}
 	statsByDomain[dKey] = stats
 
 	client := "client"
@@ -231,9 +231,25 @@ type ConnStatsWithTimestamp C.conn_stats_ts_t
 __u32 retransmits;
 __u32 rtt;
 __u32 rtt_var;
+__u32 ooo_packets;
+__u16 zero_window_probes;
+__u16 state_transitions;
+__u8 rst_sent;
+__u8 rst_received;
+__u8 syn_timeouts;
 */
 type TCPStats C.tcp_stats_t
 
+/* protocol_stats_t
+__u64 requests;
+__u64 latency_sum;
+__u64 request_started;
+__u64 last_seen;
+__u32 last_request_seq;
+__u8 protocol;
+*/
+type protocolStats C.protocol_stats_t
+
 /*
 __u32 tcp_sent_miscounts;
 */
@@ -285,22 +301,27 @@ func connStats(t *ConnTuple, s *ConnStatsWithTimestamp, tcpStats *TCPStats) netw
 	}
 
 	return network.ConnectionStats{
-		Pid:                     uint32(t.pid),
-		Type:                    connType(metadata),
-		Family:                  family,
-		NetNS:                   uint32(t.netns),
-		Source:                  source,
-		Dest:                    dest,
-		SPort:                   uint16(t.sport),
-		DPort:                   uint16(t.dport),
-		MonotonicSentBytes:      uint64(s.sent_bytes),
-		MonotonicRecvBytes:      uint64(s.recv_bytes),
-		MonotonicRetransmits:    uint32(tcpStats.retransmits),
-		MonotonicTCPEstablished: uint32(tcpStats.state_transitions >> C.TCP_ESTABLISHED & 1),
-		MonotonicTCPClosed:      uint32(tcpStats.state_transitions >> C.TCP_CLOSE & 1),
-		RTT:                     uint32(tcpStats.rtt),
-		RTTVar:                  uint32(tcpStats.rtt_var),
-		LastUpdateEpoch:         uint64(s.timestamp),
+		Pid:                        uint32(t.pid),
+		Type:                       connType(metadata),
+		Family:                     family,
+		NetNS:                      uint32(t.netns),
+		Source:                     source,
+		Dest:                       dest,
+		SPort:                      uint16(t.sport),
+		DPort:                      uint16(t.dport),
+		MonotonicSentBytes:         uint64(s.sent_bytes),
+		MonotonicRecvBytes:         uint64(s.recv_bytes),
+		MonotonicRetransmits:       uint32(tcpStats.retransmits),
+		MonotonicTCPEstablished:    uint32(tcpStats.state_transitions >> C.TCP_ESTABLISHED & 1),
+		MonotonicTCPClosed:         uint32(tcpStats.state_transitions >> C.TCP_CLOSE & 1),
+		MonotonicZeroWindowProbes:  uint32(tcpStats.zero_window_probes),
+		MonotonicOutOfOrderPackets: uint32(tcpStats.ooo_packets),
+		MonotonicResetsSent:        uint32(tcpStats.rst_sent),
+		MonotonicResetsReceived:    uint32(tcpStats.rst_received),
+		MonotonicSYNTimeouts:       uint32(tcpStats.syn_timeouts),
+		RTT:                        uint32(tcpStats.rtt),
+		RTTVar:                     uint32(tcpStats.rtt_var),
+		LastUpdateEpoch:            uint64(s.timestamp),
 	}
 }
 
@@ -0,0 +1,125 @@
+// +build linux_bpf
+
+package tracer
+
+import (
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/ebpf"
+	"github.com/DataDog/ebpf/manager"
+)
+
+// protocolClassifier reads the application protocols that the classifier socket filter assigns to
+// the TCP connections, along with their request counters.
+// The socket filter doesn't know the PID and network namespace of the connections: its entries are
+// keyed by their tuple only, normalized so the client is the source.
+type protocolClassifier struct {
+	protocols     *ebpf.Map
+	closeFilterFn func()
+	// timeout is the duration, in nanoseconds, after which the entry of an idle connection is removed
+	timeout uint64
+}
+
+func newProtocolClassifier(supported bool, c *config.Config, m *manager.Manager) *protocolClassifier {
+	if !c.EnableProtocolClassification {
+		return nil
+	}
+
+	if !supported {
+		log.Warnf("protocol classification is not supported by this kernel version. please refer to system-probe's documentation")
+		return nil
+	}
+
+	filter, _ := m.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketClassifierFilter)})
+	if filter == nil {
+		log.Errorf("could not enable protocol classification: error retrieving socket filter")
+		return nil
+	}
+
+	protocols, _, err := m.GetMap(string(probes.ConnProtocolsMap))
+	if err != nil {
+		log.Errorf("could not enable protocol classification: %s", err)
+		return nil
+	}
+
+	closeFilterFn, err := filterpkg.HeadlessSocketFilter(c.ProcRoot, filter)
+	if err != nil {
+		log.Errorf("could not enable protocol classification: %s", err)
+		return nil
+	}
+
+	log.Info("protocol classification enabled")
+	return &protocolClassifier{
+		protocols:     protocols,
+		closeFilterFn: closeFilterFn,
+		timeout:       uint64(c.TCPConnTimeout.Nanoseconds()),
+	}
+}
+
+// getProtocolStats sets the protocol classified for a TCP connection along with its request counters.
+// The entry of the connection is removed if remove is true, which is used once the connection is closed.
+func (c *protocolClassifier) getProtocolStats(conn *network.ConnectionStats, remove bool) {
+	if c == nil || conn.Type != network.TCP {
+		return
+	}
+
+	stats := new(protocolStats)
+	// The connection can be either outgoing (its source is the client) or incoming
+	tuple := newConnTuple(0, 0, conn.Source, conn.Dest, conn.SPort, conn.DPort, conn.Type)
+	if tuple == nil {
+		return
+	}
+	if err := c.protocols.Lookup(unsafe.Pointer(tuple), unsafe.Pointer(stats)); err != nil {
+		tuple = newConnTuple(0, 0, conn.Dest, conn.Source, conn.DPort, conn.SPort, conn.Type)
+		if err := c.protocols.Lookup(unsafe.Pointer(tuple), unsafe.Pointer(stats)); err != nil {
+			return
+		}
+	}
+
+	conn.Protocol = network.ProtocolType(stats.protocol)
+	conn.MonotonicProtocolRequests = uint64(stats.requests)
+	conn.MonotonicProtocolLatencySum = uint64(stats.latency_sum)
+
+	if remove {
+		_ = c.protocols.Delete(unsafe.Pointer(tuple))
+	}
+}
+
+// removeExpired removes the entries of the connections that have been idle for too long, such as
+// the ones whose close was missed, or the ones classified from the packets of another network namespace
+func (c *protocolClassifier) removeExpired(latestTime uint64) {
+	if c == nil {
+		return
+	}
+
+	key, stats := &ConnTuple{}, &protocolStats{}
+	var expired []*ConnTuple
+	entries := c.protocols.IterateFrom(unsafe.Pointer(&ConnTuple{}))
+	for entries.Next(unsafe.Pointer(key), unsafe.Pointer(stats)) {
+		if latestTime > c.timeout+uint64(stats.last_seen) {
+			expired = append(expired, key.copy())
+		}
+	}
+
+	if err := entries.Err(); err != nil {
+		log.Warnf("unable to iterate connection protocols map: %s", err)
+	}
+
+	for _, key := range expired {
+		_ = c.protocols.Delete(unsafe.Pointer(key))
+	}
+}
+
+// Close detaches the classifier socket filter
+func (c *protocolClassifier) Close() {
+	if c == nil {
+		return
+	}
+
+	c.closeFilterFn()
+}
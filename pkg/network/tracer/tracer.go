@@ -55,6 +55,8 @@ type Tracer struct {
 
 	httpMonitor *http.Monitor
 
+	classifier *protocolClassifier
+
 	perfMap      *manager.PerfMap
 	perfHandler  *ddebpf.PerfHandler
 	batchManager *PerfBatchManager
@@ -128,6 +130,22 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 
 	if config.EnableHTTPMonitoring && !pre410Kernel {
 		enabledProbes[probes.SocketHTTPFilter] = struct{}{}
+		enabledProbes[probes.SocketHTTP2Filter] = struct{}{}
+	}
+
+	if config.EnableProtocolClassification && !pre410Kernel {
+		enabledProbes[probes.SocketClassifierFilter] = struct{}{}
+	}
+
+	// The TLS sessions are bound to their connection by the TCP send/receive probes
+	enableHTTPS := config.EnableHTTPMonitoring && config.EnableHTTPSMonitoring && !pre410Kernel
+	if enableHTTPS && !config.CollectTCPConns {
+		log.Warn("https monitoring requires TCP connections to be collected, disabling it")
+		enableHTTPS = false
+	}
+	if enableHTTPS {
+		enabledProbes[probes.DoSysOpen] = struct{}{}
+		enabledProbes[probes.DoSysOpenReturn] = struct{}{}
 	}
 
 	mgrOptions := manager.Options{
@@ -147,9 +165,23 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 			string(probes.PortBindingsMap):    {Type: ebpf.Hash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
 			string(probes.UdpPortBindingsMap): {Type: ebpf.Hash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
 			string(probes.HttpInFlightMap):    {Type: ebpf.Hash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLSockByCtxMap):    {Type: ebpf.LRUHash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
+			string(probes.SSLCtxByTupleMap):   {Type: ebpf.LRUHash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
+			string(probes.Http2ConnsMap):      {Type: ebpf.LRUHash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
+			string(probes.ConnProtocolsMap):   {Type: ebpf.Hash, MaxEntries: uint32(config.MaxTrackedConnections), EditorFlag: manager.EditMaxEntries},
 		},
 	}
 
+	// LRU maps are only available from kernel 4.10, older kernels fall back to regular hash maps
+	if currKernelVersion < kernel.VersionCode(4, 10, 0) {
+		for _, m := range []probes.BPFMapName{probes.SSLSockByCtxMap, probes.SSLCtxByTupleMap, probes.Http2ConnsMap} {
+			editor := mgrOptions.MapSpecEditors[string(m)]
+			editor.Type = ebpf.Hash
+			editor.EditorFlag |= manager.EditType
+			mgrOptions.MapSpecEditors[string(m)] = editor
+		}
+	}
+
 	var buf bytecode.AssetReader
 	if config.EnableRuntimeCompiler {
 		buf, err = getRuntimeCompiledTracer(config)
@@ -195,10 +227,24 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 	}
 	perfHandlerTCP := ddebpf.NewPerfHandler(closedChannelSize)
 	perfHandlerHTTP := ddebpf.NewPerfHandler(closedChannelSize)
-	m := netebpf.NewManager(perfHandlerTCP, perfHandlerHTTP)
+	perfHandlerSharedLibraries := ddebpf.NewPerfHandler(closedChannelSize)
+	m := netebpf.NewManager(perfHandlerTCP, perfHandlerHTTP, perfHandlerSharedLibraries)
+
+	// The TLS library probes are loaded without being activated, they are attached by the HTTP monitor
+	loadedProbes := make(map[probes.ProbeName]struct{})
+	if enableHTTPS {
+		for _, p := range append(probes.OpenSSLProbes, probes.GnuTLSProbes...) {
+			loadedProbes[p] = struct{}{}
+		}
+	} else {
+		perfHandlerSharedLibraries = nil
+	}
 
 	// exclude all non-enabled probes to ensure we don't run into problems with unsupported probe types
 	for _, p := range m.Probes {
+		if _, loaded := loadedProbes[probes.ProbeName(p.Section)]; loaded {
+			continue
+		}
 		if _, enabled := enabledProbes[probes.ProbeName(p.Section)]; !enabled {
 			mgrOptions.ExcludedSections = append(mgrOptions.ExcludedSections, p.Section)
 		}
@@ -256,7 +302,8 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		portMapping:    portMapping,
 		udpPortMapping: udpPortMapping,
 		reverseDNS:     reverseDNS,
-		httpMonitor:    newHTTPMonitor(!pre410Kernel, config, m, perfHandlerHTTP),
+		httpMonitor:    newHTTPMonitor(!pre410Kernel, config, m, perfHandlerHTTP, perfHandlerSharedLibraries),
+		classifier:     newProtocolClassifier(!pre410Kernel, config, m),
 		buffer:         make([]network.ConnectionStats, 0, 512),
 		conntracker:    conntracker,
 		sourceExcludes: network.ParseConnectionFilters(config.ExcludedSourceConnections),
@@ -480,6 +527,7 @@ func (t *Tracer) storeClosedConn(cs *network.ConnectionStats) {
 
 	atomic.AddInt64(&t.closedConns, 1)
 	cs.IPTranslation = t.conntracker.GetTranslationForConn(*cs)
+	t.classifier.getProtocolStats(cs, true)
 	t.state.StoreClosedConnection(cs)
 	if cs.IPTranslation != nil {
 		t.conntracker.DeleteTranslation(*cs)
@@ -493,6 +541,7 @@ func (t *Tracer) Stop() {
 	_ = t.perfMap.Stop(manager.CleanAll)
 	t.perfHandler.Stop()
 	t.httpMonitor.Stop()
+	t.classifier.Close()
 	close(t.flushIdle)
 	t.conntracker.Close()
 }
@@ -632,6 +681,7 @@ func (t *Tracer) getConnections(active []network.ConnectionStats) ([]network.Con
 			} else {
 				// lookup conntrack in for active
 				conn.IPTranslation = t.conntracker.GetTranslationForConn(conn)
+				t.classifier.getProtocolStats(&conn, false)
 				active = append(active, conn)
 			}
 		}
@@ -643,6 +693,7 @@ func (t *Tracer) getConnections(active []network.ConnectionStats) ([]network.Con
 
 	// Remove expired entries
 	t.removeEntries(mp, tcpMp, expired)
+	t.classifier.removeExpired(latestTime)
 
 	// check for expired clients in the state
 	t.state.RemoveExpiredClients(time.Now())
@@ -724,10 +775,15 @@ func (t *Tracer) getTCPStats(mp *ebpf.Map, tuple *ConnTuple, seen map[ConnTuple]
 
 	_ = mp.Lookup(unsafe.Pointer(tuple), unsafe.Pointer(stats))
 
-	// This is required to avoid (over)reporting retransmits for connections sharing the same socket.
+	// This is required to avoid (over)reporting retransmits and the other TCP events for connections sharing the same socket.
 	if _, reported := seen[*tuple]; reported {
 		atomic.AddInt64(&t.pidCollisions, 1)
 		stats.retransmits = 0
+		stats.zero_window_probes = 0
+		stats.ooo_packets = 0
+		stats.rst_sent = 0
+		stats.rst_received = 0
+		stats.syn_timeouts = 0
 	} else {
 		seen[*tuple] = struct{}{}
 	}
@@ -919,7 +975,7 @@ func (t *Tracer) conntrackExists(ctr *cachedConntrack, conn *ConnTuple) bool {
 	return ok
 }
 
-func newHTTPMonitor(supported bool, c *config.Config, m *manager.Manager, h *ddebpf.PerfHandler) *http.Monitor {
+func newHTTPMonitor(supported bool, c *config.Config, m *manager.Manager, h *ddebpf.PerfHandler, sharedLibrariesHandler *ddebpf.PerfHandler) *http.Monitor {
 	if !c.EnableHTTPMonitoring {
 		return nil
 	}
@@ -929,12 +985,15 @@ func newHTTPMonitor(supported bool, c *config.Config, m *manager.Manager, h *dde
 		return nil
 	}
 
-	monitor, err := http.NewMonitor(c.ProcRoot, m, h)
+	monitor, err := http.NewMonitor(c.ProcRoot, m, h, sharedLibrariesHandler)
 	if err != nil {
 		log.Errorf("could not enable http monitoring: %s", err)
 		return nil
 	}
 
 	log.Info("http monitoring enabled")
+	if sharedLibrariesHandler != nil {
+		log.Info("https monitoring enabled")
+	}
 	return monitor
 }
@@ -498,6 +498,53 @@ func TestTCPRetransmit(t *testing.T) {
 	assert.Equal(t, addrPort(server.address), int(conn.DPort))
 }
 
+func TestTCPResets(t *testing.T) {
+	// Enable BPF-based system probe with the probes counting the resets
+	config := testConfig()
+	config.EnableTCPExtendedStats = true
+	tr, err := NewTracer(config)
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	// Create TCP Server which resets the connection once it reads the client message
+	server := NewTCPServer(func(c net.Conn) {
+		r := bufio.NewReader(c)
+		r.ReadBytes(byte('\n'))
+		c.(*net.TCPConn).SetLinger(0)
+		c.Close()
+	})
+	doneChan := make(chan struct{})
+	err = server.Run(doneChan)
+	require.NoError(t, err)
+	defer close(doneChan)
+
+	// Connect to server
+	c, err := net.DialTimeout("tcp", server.address, time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// Write clientMessageSize to server, and wait for the reset
+	_, err = c.Write(genPayload(clientMessageSize))
+	require.NoError(t, err)
+	r := bufio.NewReader(c)
+	_, err = r.ReadBytes(byte('\n'))
+	require.Error(t, err)
+
+	// Iterate through the connections until we find the client side of the connection, and confirm it received the reset
+	connections := getConnections(t, tr)
+
+	conn, ok := findConnection(c.LocalAddr(), c.RemoteAddr(), connections)
+	require.True(t, ok)
+	assert.Equal(t, 1, int(conn.MonotonicResetsReceived))
+	assert.Equal(t, 0, int(conn.MonotonicResetsSent))
+	assert.Equal(t, 0, int(conn.MonotonicSYNTimeouts))
+
+	// The server side of the connection sent it
+	conn, ok = findConnection(c.RemoteAddr(), c.LocalAddr(), connections)
+	require.True(t, ok)
+	assert.Equal(t, 1, int(conn.MonotonicResetsSent))
+}
+
 func TestTCPRetransmitSharedSocket(t *testing.T) {
 	// Enable BPF-based system probe
 	tr, err := NewTracer(testConfig())
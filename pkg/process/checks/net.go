@@ -202,9 +202,13 @@ func batchConnections(
 			for d := range c.DnsStatsByDomain {
 				domainIndices[d] = struct{}{}
 			}
+			for d := range c.DnsStatsByDomainByQueryType {
+				domainIndices[d] = struct{}{}
+			}
 		}
 
-		// We want to keep the length of the domains array same so that the pointers in DnsStatsByDomain remain valid
+		// We want to keep the length of the domains array same so that the pointers in DnsStatsByDomain and
+		// DnsStatsByDomainByQueryType remain valid
 		// For absent entries, we simply use an empty string to cut down on storage.
 		batchDomains := make([]string, len(domains))
 		for i, domain := range domains {
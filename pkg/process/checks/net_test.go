@@ -216,3 +216,29 @@ func TestNetworkConnectionBatchingWithDomains(t *testing.T) {
 	}
 	assert.Equal(t, 4, total)
 }
+
+func TestNetworkConnectionBatchingWithDomainsByQueryType(t *testing.T) {
+	conns := makeConnections(2)
+
+	domains := []string{"foo.com", "bar.com"}
+	conns[1].DnsStatsByDomainByQueryType = map[int32]*model.DNSStatsByQueryType{
+		1: {DnsStatsByQueryType: map[int32]*model.DNSStats{28: {DnsTimeouts: 1}}},
+	}
+	dns := map[string]*model.DNSEntry{}
+
+	cfg := config.NewDefaultAgentConfig(false)
+	cfg.MaxConnsPerMessage = 1
+
+	chunks := batchConnections(cfg, 0, conns, dns, "nid", nil, domains)
+
+	assert.Len(t, chunks, 2)
+	for i, c := range chunks {
+		connections := c.(*model.CollectorConnections)
+		switch i {
+		case 0:
+			assert.Equal(t, []string{"", ""}, connections.Domains)
+		case 1:
+			assert.Equal(t, []string{"", "bar.com"}, connections.Domains)
+		}
+	}
+}
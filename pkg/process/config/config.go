@@ -95,6 +95,9 @@ type AgentConfig struct {
 	DisableDNSInspection           bool
 	CollectLocalDNS                bool
 	EnableHTTPMonitoring           bool
+	EnableHTTPSMonitoring          bool
+	EnableProtocolClassification   bool
+	EnableTCPExtendedStats         bool
 	SystemProbeAddress             string
 	SystemProbeLogFile             string
 	SystemProbeBPFDir              string
@@ -219,6 +222,9 @@ func NewDefaultAgentConfig(canAccessContainers bool) *AgentConfig {
 		DisableIPv6Tracing:           false,
 		DisableDNSInspection:         false,
 		EnableHTTPMonitoring:         false,
+		EnableHTTPSMonitoring:        false,
+		EnableProtocolClassification: false,
+		EnableTCPExtendedStats:       false,
 		SystemProbeAddress:           defaultSystemProbeAddress,
 		SystemProbeLogFile:           defaultSystemProbeLogFilePath,
 		SystemProbeBPFDir:            defaultSystemProbeBPFDir,
@@ -505,6 +511,9 @@ func loadSysProbeEnvVariables() {
 		{"DD_SYSTEM_PROBE_ENABLED", "system_probe_config.enabled"},
 		{"DD_SYSTEM_PROBE_NETWORK_ENABLED", "network_config.enabled"},
 		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP_MONITORING", "network_config.enable_http_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTPS_MONITORING", "network_config.enable_https_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_PROTOCOL_CLASSIFICATION", "network_config.enable_protocol_classification"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_TCP_EXTENDED_STATS", "network_config.enable_tcp_extended_stats"},
 		{"DD_SYSPROBE_SOCKET", "system_probe_config.sysprobe_socket"},
 		{"DD_SYSTEM_PROBE_CONNTRACK_IGNORE_ENOBUFS", "system_probe_config.conntrack_ignore_enobufs"},
 		{"DD_SYSTEM_PROBE_ENABLE_CONNTRACK_ALL_NAMESPACES", "system_probe_config.enable_conntrack_all_namespaces"},
@@ -62,6 +62,18 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.EnableHTTPMonitoring = config.Datadog.GetBool("network_config.enable_http_monitoring")
 	}
 
+	if config.Datadog.IsSet("network_config.enable_https_monitoring") {
+		a.EnableHTTPSMonitoring = config.Datadog.GetBool("network_config.enable_https_monitoring")
+	}
+
+	if config.Datadog.IsSet("network_config.enable_protocol_classification") {
+		a.EnableProtocolClassification = config.Datadog.GetBool("network_config.enable_protocol_classification")
+	}
+
+	if config.Datadog.IsSet("network_config.enable_tcp_extended_stats") {
+		a.EnableTCPExtendedStats = config.Datadog.GetBool("network_config.enable_tcp_extended_stats")
+	}
+
 	if config.Datadog.GetBool(key(spNS, "enabled")) {
 		a.EnableSystemProbe = true
 	}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// commandBackendName is the backend reported for the handles resolved by the secret_backend_command
+const commandBackendName = "command"
+
+// builtinBackendSeparator separates the backend name from the secret reference in a handle
+const builtinBackendSeparator = "@"
+
+var (
+	// builtinBackends resolve the handles of the form `<name>@<reference>` within the agent,
+	// without executing the secret_backend_command. They are only used once enabled by
+	// secret_backend_builtin_backends.
+	builtinBackends = map[string]func(reference string) (string, error){
+		"file":  fetchFileSecret,
+		"env":   fetchEnvSecret,
+		"vault": fetchVaultSecret,
+	}
+
+	// enabledBuiltinBackends is the set of built-in backends listed in secret_backend_builtin_backends,
+	// the handles of the other backends are resolved by the secret_backend_command as any handle
+	enabledBuiltinBackends = map[string]bool{}
+
+	vaultAddress   string
+	vaultTokenFile string
+)
+
+// RegisterBackend registers a built-in backend resolving the handles of the form
+// `<name>@<reference>`. It allows packages that can't be imported by the secrets
+// package, such as the Kubernetes apiserver client, to provide a backend.
+// It must be called before any secret is decrypted, usually from an init function, and
+// the backend is only used once enabled by InitBuiltinBackends.
+func RegisterBackend(name string, fetch func(reference string) (string, error)) {
+	builtinBackends[name] = fetch
+}
+
+// InitBuiltinBackends enables the given built-in backends. They are all disabled by default,
+// since they give access to the files, environment and secrets readable by the agent.
+func InitBuiltinBackends(names []string) {
+	enabledBuiltinBackends = map[string]bool{}
+	for _, name := range names {
+		if _, found := builtinBackends[name]; !found {
+			log.Warnf("Unknown built-in secret backend '%s', it is ignored", name)
+			continue
+		}
+		enabledBuiltinBackends[name] = true
+	}
+}
+
+// InitVault sets the address of the Vault server and the file containing the token
+// used by the `vault` backend. The VAULT_ADDR and VAULT_TOKEN environment variables
+// are used when they are empty.
+func InitVault(address string, tokenFile string) {
+	vaultAddress = address
+	vaultTokenFile = tokenFile
+}
+
+// getBuiltinBackend returns the name of the built-in backend resolving a handle and the
+// reference of the secret for this backend. It returns false if no enabled built-in backend
+// resolves the handle.
+func getBuiltinBackend(handle string) (string, string, bool) {
+	parts := strings.SplitN(handle, builtinBackendSeparator, 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	if _, found := builtinBackends[parts[0]]; !found || !enabledBuiltinBackends[parts[0]] {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// fetchBuiltinSecret resolves a handle with a built-in backend and adds it to the cache.
+// Origin should be the name of the configuration where the secret was referenced.
+func fetchBuiltinSecret(handle string, backend string, reference string, origin string) (string, error) {
+	value, err := builtinBackends[backend](reference)
+	if err != nil {
+		return "", fmt.Errorf("an error occurred while decrypting '%s' with the %s backend: %s", handle, backend, err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("decrypted secret for '%s' is empty", handle)
+	}
+
+	// add it to the cache
+	secretCache[handle] = value
+	// keep track of place where a handle was found
+	secretOrigin[handle] = common.NewStringSet(origin)
+	secretBackend[handle] = backend
+	return value, nil
+}
+
+// fetchFileSecret returns the content of a file, without its trailing newlines,
+// such as a secret mounted in a container. The reference is the absolute path of the file.
+func fetchFileSecret(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("'%s' is not an absolute path", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(f, int64(SecretBackendOutputMaxSize)+1))
+	if err != nil {
+		return "", err
+	}
+	if len(content) > SecretBackendOutputMaxSize {
+		return "", fmt.Errorf("the file is too large: exceeded %d bytes", SecretBackendOutputMaxSize)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// fetchEnvSecret returns the value of an environment variable. The reference is the variable name.
+func fetchEnvSecret(name string) (string, error) {
+	value, found := os.LookupEnv(name)
+	if !found {
+		return "", fmt.Errorf("environment variable '%s' is not set", name)
+	}
+	return value, nil
+}
+
+// vaultResponse is the response of the Vault API when reading a secret
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// fetchVaultSecret reads a key of a secret stored in a Vault KV secrets engine, using
+// token authentication. The reference is `<path>#<key>`, e.g. `secret/data/db#password`.
+// Both versions of the KV engine are supported: with the version 2, the path must
+// include the `data/` prefix, as in the Vault HTTP API.
+func fetchVaultSecret(reference string) (string, error) {
+	parts := strings.SplitN(reference, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid reference '%s', must be '<path>#<key>'", reference)
+	}
+	path, key := strings.Trim(parts[0], "/"), parts[1]
+
+	address := vaultAddress
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", fmt.Errorf("no Vault address set")
+	}
+	token, err := getVaultToken()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(secretBackendTimeout)*time.Second)
+	defer cancel()
+	req, err := http.NewRequest("GET", strings.TrimRight(address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", token)
+
+	log.Debugf("reading secret '%s' from Vault at %s", path, address)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response vaultResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, int64(SecretBackendOutputMaxSize))).Decode(&response)
+	if resp.StatusCode != http.StatusOK {
+		if len(response.Errors) > 0 {
+			return "", fmt.Errorf("the Vault server returned status %d: %s", resp.StatusCode, strings.Join(response.Errors, ", "))
+		}
+		return "", fmt.Errorf("the Vault server returned status %d", resp.StatusCode)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not decode the Vault response: %s", err)
+	}
+
+	data := response.Data
+	// the KV version 2 engine nests the secret in data.data, next to its metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, found := data[key]
+	if !found {
+		return "", fmt.Errorf("key '%s' not found in secret '%s'", key, path)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key '%s' of secret '%s' is not a string", key, path)
+	}
+	return str, nil
+}
+
+func getVaultToken() (string, error) {
+	if vaultTokenFile != "" {
+		token, err := fetchFileSecret(vaultTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("could not read the Vault token: %s", err)
+		}
+		return token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no Vault token set")
+}
@@ -0,0 +1,289 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+)
+
+func resetSecrets() {
+	secretBackendCommand = ""
+	secretCache = map[string]string{}
+	secretOrigin = map[string]common.StringSet{}
+	secretBackend = map[string]string{}
+	secretFetcher = fetchSecret
+	secretValuesFetcher = fetchSecretValues
+	secretChanges = nil
+	enabledBuiltinBackends = map[string]bool{}
+}
+
+func TestGetBuiltinBackend(t *testing.T) {
+	defer resetSecrets()
+
+	// the built-in backends are disabled by default
+	_, _, ok := getBuiltinBackend("file@/etc/secret")
+	assert.False(t, ok)
+
+	InitBuiltinBackends([]string{"file", "vault", "unknown"})
+	assert.Equal(t, map[string]bool{"file": true, "vault": true}, enabledBuiltinBackends)
+
+	backend, reference, ok := getBuiltinBackend("file@/etc/secret")
+	assert.True(t, ok)
+	assert.Equal(t, "file", backend)
+	assert.Equal(t, "/etc/secret", reference)
+
+	backend, reference, ok = getBuiltinBackend("vault@secret/data/db#pass@word")
+	assert.True(t, ok)
+	assert.Equal(t, "vault", backend)
+	assert.Equal(t, "secret/data/db#pass@word", reference)
+
+	_, _, ok = getBuiltinBackend("pass1")
+	assert.False(t, ok)
+	_, _, ok = getBuiltinBackend("user@example.com")
+	assert.False(t, ok)
+	_, _, ok = getBuiltinBackend("env@HOME")
+	assert.False(t, ok)
+}
+
+func TestFetchFileSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(path, []byte("password1\n"), 0600))
+
+	value, err := fetchFileSecret(path)
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	_, err = fetchFileSecret("password")
+	assert.EqualError(t, err, "'password' is not an absolute path")
+
+	_, err = fetchFileSecret(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	defer func(max int) { SecretBackendOutputMaxSize = max }(SecretBackendOutputMaxSize)
+	SecretBackendOutputMaxSize = 4
+	_, err = fetchFileSecret(path)
+	assert.EqualError(t, err, "the file is too large: exceeded 4 bytes")
+}
+
+func TestFetchEnvSecret(t *testing.T) {
+	os.Setenv("TEST_SECRET_PASSWORD", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	value, err := fetchEnvSecret("TEST_SECRET_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	_, err = fetchEnvSecret("TEST_SECRET_MISSING")
+	assert.EqualError(t, err, "environment variable 'TEST_SECRET_MISSING' is not set")
+}
+
+// newVaultStub starts a server answering like the Vault KV engines
+func newVaultStub(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			fmt.Fprint(w, `{"data":{"data":{"password":"password2","port":5432},"metadata":{"version":3}}}`)
+		case "/v1/kv/db":
+			fmt.Fprint(w, `{"data":{"password":"password1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+}
+
+func TestFetchVaultSecret(t *testing.T) {
+	server := newVaultStub(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("root-token\n"), 0600))
+
+	InitVault(server.URL, tokenFile)
+	defer InitVault("", "")
+
+	value, err := fetchVaultSecret("kv/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	value, err = fetchVaultSecret("/secret/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "password2", value)
+
+	_, err = fetchVaultSecret("secret/data/db#user")
+	assert.EqualError(t, err, "key 'user' not found in secret 'secret/data/db'")
+
+	_, err = fetchVaultSecret("secret/data/db#port")
+	assert.EqualError(t, err, "key 'port' of secret 'secret/data/db' is not a string")
+
+	_, err = fetchVaultSecret("secret/data/missing#password")
+	assert.EqualError(t, err, "the Vault server returned status 404")
+
+	_, err = fetchVaultSecret("secret/data/db")
+	assert.EqualError(t, err, "invalid reference 'secret/data/db', must be '<path>#<key>'")
+
+	// the token and address can be set through the environment
+	InitVault("", "")
+	os.Setenv("VAULT_ADDR", server.URL)
+	defer os.Unsetenv("VAULT_ADDR")
+	os.Setenv("VAULT_TOKEN", "wrong-token")
+	defer os.Unsetenv("VAULT_TOKEN")
+	_, err = fetchVaultSecret("kv/db#password")
+	assert.EqualError(t, err, "the Vault server returned status 403: permission denied")
+}
+
+func TestDecryptBuiltinBackends(t *testing.T) {
+	defer resetSecrets()
+	InitBuiltinBackends([]string{"env"})
+	os.Setenv("TEST_SECRET_PASSWORD", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	conf := []byte(`---
+instances:
+- password: ENC[env@TEST_SECRET_PASSWORD]
+  user: test
+- password: ENC[pass2]
+  user: test2
+`)
+
+	// without secret_backend_command, only the built-in backends are used
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, `instances:
+- password: password1
+  user: test
+- password: ENC[pass2]
+  user: test2
+`, string(newConf))
+
+	secretBackendCommand = "some_command"
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		assert.Equal(t, []string{"pass2"}, secrets)
+		secretCache["pass2"] = "password2"
+		secretOrigin["pass2"] = common.NewStringSet(origin)
+		secretBackend["pass2"] = commandBackendName
+		return map[string]string{"pass2": "password2"}, nil
+	}
+	newConf, err = Decrypt(conf, "test2")
+	require.NoError(t, err)
+	assert.Equal(t, string(testConfDecrypted), string(newConf))
+
+	info, err := GetDebugInfo()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"env@TEST_SECRET_PASSWORD": "env",
+		"pass2":                    commandBackendName,
+	}, info.SecretsBackends)
+}
+
+func TestDecryptBuiltinBackendError(t *testing.T) {
+	defer resetSecrets()
+	InitBuiltinBackends([]string{"env"})
+
+	_, err := Decrypt([]byte("password: ENC[env@TEST_SECRET_MISSING]"), "test")
+	assert.EqualError(t, err, "an error occurred while decrypting 'env@TEST_SECRET_MISSING' with the env backend: environment variable 'TEST_SECRET_MISSING' is not set")
+}
+
+func TestDecryptNoSecret(t *testing.T) {
+	defer resetSecrets()
+
+	// data without secrets is returned unchanged
+	conf := []byte("# comment\nuser:   test\n")
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, conf, newConf)
+
+	_, err = GetDebugInfo()
+	assert.Error(t, err)
+}
+
+func TestRegisterBackend(t *testing.T) {
+	defer resetSecrets()
+	defer delete(builtinBackends, "test_backend")
+
+	RegisterBackend("test_backend", func(reference string) (string, error) {
+		return "value_of_" + reference, nil
+	})
+	InitBuiltinBackends([]string{"test_backend"})
+
+	newConf, err := Decrypt([]byte("password: ENC[test_backend@pass1]"), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "password: value_of_pass1\n", string(newConf))
+}
+
+func TestDecryptBuiltinBackendDisabled(t *testing.T) {
+	defer resetSecrets()
+	os.Setenv("TEST_SECRET_PASSWORD", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	// without secret_backend_command, the handles of disabled backends are left untouched
+	conf := []byte("password: ENC[env@TEST_SECRET_PASSWORD]")
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, conf, newConf)
+
+	// otherwise they are resolved by the command as any other handle
+	secretBackendCommand = "some_command"
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		assert.Equal(t, []string{"env@TEST_SECRET_PASSWORD"}, secrets)
+		return map[string]string{"env@TEST_SECRET_PASSWORD": "from_command"}, nil
+	}
+	newConf, err = Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "password: from_command\n", string(newConf))
+}
+
+func TestDecryptUntrusted(t *testing.T) {
+	defer resetSecrets()
+	InitBuiltinBackends([]string{"env"})
+	os.Setenv("TEST_SECRET_PASSWORD", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	conf := []byte("password: ENC[env@TEST_SECRET_PASSWORD]")
+	_, err := DecryptUntrusted(conf, "test")
+	assert.EqualError(t, err, "the env secret backend is not allowed for 'env@TEST_SECRET_PASSWORD' in this configuration")
+
+	// the value in the cache isn't used either
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "password: password1\n", string(newConf))
+	_, err = DecryptUntrusted(conf, "test")
+	assert.Error(t, err)
+
+	// the handles of the secret_backend_command are still resolved
+	secretBackendCommand = "some_command"
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		return map[string]string{"pass1": "password1"}, nil
+	}
+	newConf, err = DecryptUntrusted([]byte("password: ENC[pass1]"), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "password: password1\n", string(newConf))
+}
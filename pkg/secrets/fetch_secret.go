@@ -104,6 +104,24 @@ var runCommand = execCommand
 // executable to fetch the actual secrets and returns them. Origin should be
 // the name of the configuration where the secret was referenced.
 func fetchSecret(secretsHandle []string, origin string) (map[string]string, error) {
+	res, err := fetchSecretValues(secretsHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	for sec, value := range res {
+		// add it to the cache
+		secretCache[sec] = value
+		// keep track of place where a handle was found
+		secretOrigin[sec] = common.NewStringSet(origin)
+		secretBackend[sec] = commandBackendName
+	}
+	return res, nil
+}
+
+// fetchSecretValues execs the secret_backend_command to fetch a list of secrets
+// and returns their values, without updating the cache.
+func fetchSecretValues(secretsHandle []string) (map[string]string, error) {
 	payload := map[string]interface{}{
 		"version": PayloadVersion,
 		"secrets": secretsHandle,
@@ -137,11 +155,6 @@ func fetchSecret(secretsHandle []string, origin string) (map[string]string, erro
 		if v.Value == "" {
 			return nil, fmt.Errorf("decrypted secret for '%s' is empty", sec)
 		}
-
-		// add it to the cache
-		secretCache[sec] = v.Value
-		// keep track of place where a handle was found
-		secretOrigin[sec] = common.NewStringSet(origin)
 		res[sec] = v.Value
 	}
 	return res, nil
@@ -10,8 +10,16 @@ import (
 	"io"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// SecretChange records a change of the value of a handle detected by a refresh
+type SecretChange struct {
+	Handle  string
+	Time    time.Time
+	Origins []string
+}
+
 // SecretInfo export troubleshooting information about the decrypted secrets
 type SecretInfo struct {
 	ExecutablePath string
@@ -20,27 +28,42 @@ type SecretInfo struct {
 	UnixOwner      string
 	UnixGroup      string
 	SecretsHandles map[string][]string
+	// SecretsBackends is the backend that resolved each handle: "command" or a built-in backend
+	SecretsBackends map[string]string
+	// SecretsChanges are the latest changes of values detected by the periodic refresh
+	SecretsChanges []SecretChange
 }
 
 // Print output a SecretInfo to a io.Writer
 func (si *SecretInfo) Print(w io.Writer) {
 	fmt.Fprintf(w, "=== Checking executable rights ===\n")
-	fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
+	if si.ExecutablePath == "" {
+		fmt.Fprintf(w, "No secret_backend_command set, only built-in backends are used\n")
+	} else {
+		fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
 
-	fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
+		fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
 
-	fmt.Fprintf(w, "\nRights Detail:\n")
-	fmt.Fprintf(w, "%s\n", si.RightDetails)
+		fmt.Fprintf(w, "\nRights Detail:\n")
+		fmt.Fprintf(w, "%s\n", si.RightDetails)
 
-	if runtime.GOOS != "windows" {
-		fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
-		fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		if runtime.GOOS != "windows" {
+			fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
+			fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		}
 	}
 
 	fmt.Fprintf(w, "\n=== Secrets stats ===\n")
 	fmt.Fprintf(w, "Number of secrets decrypted: %d\n", len(si.SecretsHandles))
 	fmt.Fprintf(w, "Secrets handle decrypted:\n")
 	for handle, origins := range si.SecretsHandles {
-		fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(origins, ", "))
+		fmt.Fprintf(w, "- %s: from %s, using the %s backend\n", handle, strings.Join(origins, ", "), si.SecretsBackends[handle])
+	}
+
+	if len(si.SecretsChanges) > 0 {
+		fmt.Fprintf(w, "\n=== Secrets changes ===\n")
+		for _, change := range si.SecretsChanges {
+			fmt.Fprintf(w, "- %s: %s changed, used by %s\n", change.Time.Format(time.RFC3339), change.Handle, strings.Join(change.Origins, ", "))
+		}
 	}
 }
@@ -19,11 +19,30 @@ var SecretBackendOutputMaxSize = 1024 * 1024
 // Init placeholder when compiled without the 'secrets' build tag
 func Init(command string, arguments []string, timeout int, maxSize int, groupExecPerm bool) {}
 
+// RegisterBackend placeholder when compiled without the 'secrets' build tag
+func RegisterBackend(name string, fetch func(reference string) (string, error)) {}
+
+// InitBuiltinBackends placeholder when compiled without the 'secrets' build tag
+func InitBuiltinBackends(names []string) {}
+
+// InitVault placeholder when compiled without the 'secrets' build tag
+func InitVault(address string, tokenFile string) {}
+
 // Decrypt encrypted secrets are not available on windows
 func Decrypt(data []byte, origin string) ([]byte, error) {
 	return data, nil
 }
 
+// DecryptUntrusted encrypted secrets are not available on windows
+func DecryptUntrusted(data []byte, origin string) ([]byte, error) {
+	return data, nil
+}
+
+// Refresh placeholder when compiled without the 'secrets' build tag
+func Refresh() ([]string, error) {
+	return nil, nil
+}
+
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
 	return nil, fmt.Errorf("Secret feature is not available in this version of the agent")
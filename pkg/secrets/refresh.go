@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxSecretChanges is the number of changes kept for the `secret` command
+const maxSecretChanges = 50
+
+var (
+	// latest changes detected by Refresh, oldest first
+	secretChanges []SecretChange
+
+	// testing purpose
+	secretValuesFetcher = fetchSecretValues
+	timeNow             = time.Now
+)
+
+// Refresh fetches again every handle in the cache, updates the cache with the new
+// values and returns the handles whose value changed. The handles that could not
+// be fetched keep their previous value and are reported in the returned error.
+func Refresh() ([]string, error) {
+	secretLock.Lock()
+	defer secretLock.Unlock()
+
+	values := map[string]string{}
+	errs := []string{}
+	commandHandles := []string{}
+	for handle := range secretCache {
+		if backend, reference, ok := getBuiltinBackend(handle); ok {
+			value, err := builtinBackends[backend](reference)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("'%s' with the %s backend: %s", handle, backend, err))
+				continue
+			}
+			if value == "" {
+				errs = append(errs, fmt.Sprintf("'%s' is empty", handle))
+				continue
+			}
+			values[handle] = value
+		} else if secretBackendCommand != "" {
+			commandHandles = append(commandHandles, handle)
+		}
+	}
+
+	// the secret_backend_command is executed once for all its handles
+	if len(commandHandles) != 0 {
+		sort.Strings(commandHandles)
+		res, err := secretValuesFetcher(commandHandles)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		for handle, value := range res {
+			values[handle] = value
+		}
+	}
+
+	changed := []string{}
+	for handle, value := range values {
+		if secretCache[handle] == value {
+			continue
+		}
+		log.Infof("Secret '%s' has a new value", handle)
+		secretCache[handle] = value
+		changed = append(changed, handle)
+	}
+	sort.Strings(changed)
+	recordChanges(changed)
+
+	if len(errs) != 0 {
+		sort.Strings(errs)
+		return changed, fmt.Errorf("could not refresh some secrets: %s", strings.Join(errs, "; "))
+	}
+	return changed, nil
+}
+
+// recordChanges keeps the latest changes of handles, to be displayed by the `secret` command
+func recordChanges(handles []string) {
+	now := timeNow()
+	for _, handle := range handles {
+		change := SecretChange{Handle: handle, Time: now}
+		if origins, ok := secretOrigin[handle]; ok {
+			change.Origins = origins.GetAll()
+			sort.Strings(change.Origins)
+		}
+		secretChanges = append(secretChanges, change)
+	}
+	if len(secretChanges) > maxSecretChanges {
+		secretChanges = append([]SecretChange(nil), secretChanges[len(secretChanges)-maxSecretChanges:]...)
+	}
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresh(t *testing.T) {
+	defer resetSecrets()
+	defer func() { timeNow = time.Now }()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	InitBuiltinBackends([]string{"env"})
+	os.Setenv("TEST_SECRET_PASSWORD", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	secretBackendCommand = "some_command"
+	commandValues := map[string]string{"pass2": "password2", "pass3": "password3"}
+	secretValuesFetcher = func(handles []string) (map[string]string, error) {
+		res := map[string]string{}
+		for _, handle := range handles {
+			res[handle] = commandValues[handle]
+		}
+		return res, nil
+	}
+	runCommand = func(string) ([]byte, error) {
+		return []byte(`{"pass2":{"value":"password2"},"pass3":{"value":"password3"}}`), nil
+	}
+	defer func() { runCommand = execCommand }()
+
+	_, err := Decrypt([]byte("password: ENC[env@TEST_SECRET_PASSWORD]\nother: ENC[pass2]\n"), "check1")
+	require.NoError(t, err)
+	_, err = Decrypt([]byte("password: ENC[pass3]\nother: ENC[pass2]\n"), "check2")
+	require.NoError(t, err)
+
+	// nothing changed
+	changed, err := Refresh()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	os.Setenv("TEST_SECRET_PASSWORD", "new_password1")
+	commandValues["pass2"] = "new_password2"
+	changed, err = Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env@TEST_SECRET_PASSWORD", "pass2"}, changed)
+
+	// the new values are used from the cache
+	newConf, err := Decrypt([]byte("password: ENC[env@TEST_SECRET_PASSWORD]\nother: ENC[pass2]\n"), "check1")
+	require.NoError(t, err)
+	assert.Equal(t, "other: new_password2\npassword: new_password1\n", string(newConf))
+
+	info, err := GetDebugInfo()
+	require.NoError(t, err)
+	assert.Equal(t, []SecretChange{
+		{Handle: "env@TEST_SECRET_PASSWORD", Time: now, Origins: []string{"check1"}},
+		{Handle: "pass2", Time: now, Origins: []string{"check1", "check2"}},
+	}, info.SecretsChanges)
+
+	var buffer bytes.Buffer
+	info.Print(&buffer)
+	assert.Contains(t, buffer.String(), "=== Secrets changes ===\n- 2020-01-01T00:00:00Z: env@TEST_SECRET_PASSWORD changed, used by check1\n")
+}
+
+func TestRefreshError(t *testing.T) {
+	defer resetSecrets()
+	InitBuiltinBackends([]string{"env"})
+
+	secretBackendCommand = "some_command"
+	secretCache["pass1"] = "password1"
+	secretCache["env@TEST_SECRET_MISSING"] = "password2"
+	secretValuesFetcher = func(handles []string) (map[string]string, error) {
+		return nil, fmt.Errorf("some error")
+	}
+
+	// values that can't be fetched are kept
+	changed, err := Refresh()
+	assert.EqualError(t, err, "could not refresh some secrets: 'env@TEST_SECRET_MISSING' with the env backend: environment variable 'TEST_SECRET_MISSING' is not set; some error")
+	assert.Empty(t, changed)
+	assert.Equal(t, "password1", secretCache["pass1"])
+	assert.Equal(t, "password2", secretCache["env@TEST_SECRET_MISSING"])
+}
+
+func TestRecordChangesLimit(t *testing.T) {
+	defer resetSecrets()
+
+	for i := 0; i < maxSecretChanges+5; i++ {
+		recordChanges([]string{fmt.Sprintf("pass%d", i)})
+	}
+	require.Len(t, secretChanges, maxSecretChanges)
+	assert.Equal(t, "pass5", secretChanges[0].Handle)
+	assert.Equal(t, fmt.Sprintf("pass%d", maxSecretChanges+4), secretChanges[maxSecretChanges-1].Handle)
+}
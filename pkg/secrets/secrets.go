@@ -8,8 +8,10 @@
 package secrets
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"sync"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -18,9 +20,14 @@ import (
 )
 
 var (
+	// secretLock protects the cache, origins and backends of the handles, as they're
+	// updated by the periodic refresh of the secrets
+	secretLock  sync.Mutex
 	secretCache map[string]string
 	// list of handles and where they were found
 	secretOrigin map[string]common.StringSet
+	// backend that resolved each handle
+	secretBackend map[string]string
 
 	secretBackendCommand               string
 	secretBackendArguments             []string
@@ -34,6 +41,7 @@ var (
 func init() {
 	secretCache = make(map[string]string)
 	secretOrigin = make(map[string]common.StringSet)
+	secretBackend = make(map[string]string)
 }
 
 // Init initializes the command and other options of the secrets package. Since
@@ -126,33 +134,66 @@ func isEnc(str string) (bool, string) {
 // testing purpose
 var secretFetcher = fetchSecret
 
-// Decrypt replaces all encrypted secrets in data by executing
-// "secret_backend_command" once if all secrets aren't present in the cache.
+// Decrypt replaces all encrypted secrets in data. Handles of the form
+// `<backend>@<reference>` are resolved by the matching built-in backend, the others
+// by executing "secret_backend_command" once if all secrets aren't present in the cache.
 func Decrypt(data []byte, origin string) ([]byte, error) {
-	if data == nil || secretBackendCommand == "" {
+	return decrypt(data, origin, true)
+}
+
+// DecryptUntrusted replaces all encrypted secrets in data, as Decrypt, but returns an error
+// for the handles of the built-in backends. It must be used for the configurations that
+// can be written by the monitored workloads, such as container labels or pod annotations,
+// which would otherwise be able to read any secret available to the agent.
+func DecryptUntrusted(data []byte, origin string) ([]byte, error) {
+	return decrypt(data, origin, false)
+}
+
+func decrypt(data []byte, origin string, allowBuiltinBackends bool) ([]byte, error) {
+	if data == nil || !bytes.Contains(data, []byte("ENC[")) {
 		return data, nil
 	}
 
+	secretLock.Lock()
+	defer secretLock.Unlock()
+
 	var config interface{}
 	err := yaml.Unmarshal(data, &config)
 	if err != nil {
 		return nil, fmt.Errorf("could not Unmarshal config: %s", err)
 	}
 
-	// First we collect all new handles in the config
+	// First we collect all new handles in the config, built-in backends are called right away
 	newHandles := []string{}
 	haveSecret := false
 	err = walk(&config, func(str string) (string, error) {
 		if ok, handle := isEnc(str); ok {
-			haveSecret = true
+			// checked before the cache, which may hold the value resolved for a trusted configuration
+			if backend, _, ok := getBuiltinBackend(handle); ok && !allowBuiltinBackends {
+				return str, fmt.Errorf("the %s secret backend is not allowed for '%s' in this configuration", backend, handle)
+			}
 			// Check if we already know this secret
 			if secret, ok := secretCache[handle]; ok {
 				log.Debugf("Secret '%s' was retrieved from cache", handle)
+				haveSecret = true
 				// keep track of place where a handle was found
 				secretOrigin[handle].Add(origin)
 				return secret, nil
 			}
-			newHandles = append(newHandles, handle)
+			if backend, reference, ok := getBuiltinBackend(handle); ok {
+				secret, err := fetchBuiltinSecret(handle, backend, reference, origin)
+				if err != nil {
+					return str, err
+				}
+				log.Debugf("Secret '%s' was retrieved from the %s backend", handle, backend)
+				haveSecret = true
+				return secret, nil
+			}
+			// without secret_backend_command, other handles are left untouched
+			if secretBackendCommand != "" {
+				haveSecret = true
+				newHandles = append(newHandles, handle)
+			}
 		}
 		return str, nil
 	})
@@ -199,15 +240,23 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
-	if secretBackendCommand == "" {
-		return nil, fmt.Errorf("No secret_backend_command set: secrets feature is not enabled")
+	secretLock.Lock()
+	defer secretLock.Unlock()
+
+	if secretBackendCommand == "" && len(secretBackend) == 0 {
+		return nil, fmt.Errorf("No secret_backend_command set and no secret decrypted by a built-in backend: secrets feature is not enabled")
 	}
 	info := &SecretInfo{ExecutablePath: secretBackendCommand}
-	info.populateRights()
+	if secretBackendCommand != "" {
+		info.populateRights()
+	}
 
 	info.SecretsHandles = map[string][]string{}
+	info.SecretsBackends = map[string]string{}
 	for handle, originNames := range secretOrigin {
 		info.SecretsHandles[handle] = originNames.GetAll()
+		info.SecretsBackends[handle] = secretBackend[handle]
 	}
+	info.SecretsChanges = append([]SecretChange(nil), secretChanges...)
 	return info, nil
 }
@@ -7,7 +7,7 @@ package config
 
 // DefaultPolicy holds the default runtime security agent rules
 var DefaultPolicy = `---
-version: 1.0.1
+version: 1.0.2
 rules:
   - id: credential_accessed
     description: Sensitive credential files were accessed using a non-standard tool
@@ -112,4 +112,36 @@ rules:
       open.filename =~ "/opt/*") && 
       open.flags & (O_RDWR | O_WRONLY) > 0 &&
       process.name not in ["agent", "security-agent", "system-probe", "process-agent"]
+  - id: kernel_module_loaded
+    description: A kernel module was loaded by a non-standard tool
+    expression: >-
+      load_module.name != "" &&
+      process.name not in ["modprobe", "insmod", "kmod", "systemd-modules-load", "systemd-udevd"]
+    tags:
+      technique: T1215
+  - id: ptrace_injection
+    description: The memory of another process was modified using ptrace
+    expression: >-
+      ptrace.request == PTRACE_POKETEXT || ptrace.request == PTRACE_POKEDATA || ptrace.request == PTRACE_POKEUSR
+    tags:
+      technique: T1055
+  - id: ptrace_attach
+    description: A process was attached to by a non-standard debugger
+    expression: >-
+      (ptrace.request == PTRACE_ATTACH || ptrace.request == PTRACE_SEIZE) &&
+      process.name not in ["gdb", "strace", "ltrace", "lldb-server"]
+    tags:
+      technique: T1055
+  - id: executable_anonymous_memory
+    description: Anonymous memory was mapped both writable and executable
+    expression: >-
+      mmap.flags & MAP_ANONYMOUS > 0 && mmap.protection & PROT_WRITE > 0 && mmap.protection & PROT_EXEC > 0
+    tags:
+      technique: T1055
+  - id: executable_anonymous_memory_protection
+    description: Anonymous memory was made both writable and executable
+    expression: >-
+      mprotect.anonymous == true && mprotect.protection & PROT_WRITE > 0 && mprotect.protection & PROT_EXEC > 0
+    tags:
+      technique: T1055
 `
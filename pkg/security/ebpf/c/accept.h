@@ -0,0 +1,24 @@
+#ifndef _ACCEPT_H_
+#define _ACCEPT_H_
+
+#include "network.h"
+
+// the peer address is written by the kernel, it can only be read when the syscall returns
+
+SYSCALL_KPROBE3(accept, int, fd, struct sockaddr*, upeer_sockaddr, int*, upeer_addrlen) {
+    return trace__sys_sock_addr(SYSCALL_ACCEPT, EVENT_ACCEPT, upeer_sockaddr, 0);
+}
+
+SYSCALL_KPROBE4(accept4, int, fd, struct sockaddr*, upeer_sockaddr, int*, upeer_addrlen, int, flags) {
+    return trace__sys_sock_addr(SYSCALL_ACCEPT, EVENT_ACCEPT, upeer_sockaddr, 0);
+}
+
+SYSCALL_KRETPROBE(accept) {
+    return trace__sys_sock_addr_ret(ctx, SYSCALL_ACCEPT, EVENT_ACCEPT, 1);
+}
+
+SYSCALL_KRETPROBE(accept4) {
+    return trace__sys_sock_addr_ret(ctx, SYSCALL_ACCEPT, EVENT_ACCEPT, 1);
+}
+
+#endif
@@ -0,0 +1,14 @@
+#ifndef _BIND_H_
+#define _BIND_H_
+
+#include "network.h"
+
+SYSCALL_KPROBE3(bind, int, fd, struct sockaddr*, uaddr, int, addrlen) {
+    return trace__sys_sock_addr(SYSCALL_BIND, EVENT_BIND, uaddr, 1);
+}
+
+SYSCALL_KRETPROBE(bind) {
+    return trace__sys_sock_addr_ret(ctx, SYSCALL_BIND, EVENT_BIND, 0);
+}
+
+#endif
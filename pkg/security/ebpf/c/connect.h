@@ -0,0 +1,14 @@
+#ifndef _CONNECT_H_
+#define _CONNECT_H_
+
+#include "network.h"
+
+SYSCALL_KPROBE3(connect, int, fd, struct sockaddr*, uaddr, int, addrlen) {
+    return trace__sys_sock_addr(SYSCALL_CONNECT, EVENT_CONNECT, uaddr, 1);
+}
+
+SYSCALL_KRETPROBE(connect) {
+    return trace__sys_sock_addr_ret(ctx, SYSCALL_CONNECT, EVENT_CONNECT, 0);
+}
+
+#endif
@@ -198,6 +198,14 @@ enum event_type
     EVENT_EXEC,
     EVENT_EXIT,
     EVENT_INVALIDATE_DENTRY,
+    EVENT_SOCKET,
+    EVENT_CONNECT,
+    EVENT_BIND,
+    EVENT_ACCEPT,
+    EVENT_LOAD_MODULE,
+    EVENT_PTRACE,
+    EVENT_MMAP,
+    EVENT_MPROTECT,
     EVENT_MAX, // has to be the last one
     EVENT_MAX_ROUNDED_UP = 32, // closest power of 2 that is bigger than EVENT_MAX
 };
@@ -219,6 +227,14 @@ enum syscall_type
     SYSCALL_REMOVEXATTR = 1 << EVENT_REMOVEXATTR,
     SYSCALL_EXEC        = 1 << EVENT_EXEC,
     SYSCALL_FORK        = 1 << EVENT_FORK,
+    SYSCALL_SOCKET      = 1 << EVENT_SOCKET,
+    SYSCALL_CONNECT     = 1 << EVENT_CONNECT,
+    SYSCALL_BIND        = 1 << EVENT_BIND,
+    SYSCALL_ACCEPT      = 1 << EVENT_ACCEPT,
+    SYSCALL_LOAD_MODULE = 1 << EVENT_LOAD_MODULE,
+    SYSCALL_PTRACE      = 1 << EVENT_PTRACE,
+    SYSCALL_MMAP        = 1 << EVENT_MMAP,
+    SYSCALL_MPROTECT    = 1 << EVENT_MPROTECT,
 };
 
 struct kevent_t {
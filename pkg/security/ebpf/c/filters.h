@@ -16,6 +16,9 @@ enum policy_flags
     FLAGS = 2,
     MODE = 4,
     PARENT_NAME = 8,
+    FAMILY = 16,
+    PORT = 32,
+    ADDR = 64,
 };
 
 struct policy_t {
@@ -0,0 +1,127 @@
+#ifndef _MEMORY_H_
+#define _MEMORY_H_
+
+#include <linux/mm_types.h>
+#include <uapi/linux/mman.h>
+
+#include "syscalls.h"
+
+struct bpf_map_def SEC("maps/mmap_protection_approvers") mmap_protection_approvers = {
+    .type = BPF_MAP_TYPE_ARRAY,
+    .key_size = sizeof(u32),
+    .value_size = sizeof(u32),
+    .max_entries = 1,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct bpf_map_def SEC("maps/mprotect_protection_approvers") mprotect_protection_approvers = {
+    .type = BPF_MAP_TYPE_ARRAY,
+    .key_size = sizeof(u32),
+    .value_size = sizeof(u32),
+    .max_entries = 1,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct memory_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u32 protection;
+    u32 flags;
+    u32 anonymous;
+    u32 padding;
+};
+
+int __attribute__((always_inline)) trace__sys_memory(u64 type, u64 event_type, u32 protection, u32 flags) {
+    struct syscall_cache_t syscall = {
+        .type = type,
+        .memory = {
+            .protection = protection,
+            .flags = flags,
+            .anonymous = (flags & MAP_ANONYMOUS) > 0,
+        },
+    };
+
+    cache_syscall(&syscall, event_type);
+
+    if (discarded_by_process(syscall.policy.mode, event_type)) {
+        pop_syscall(type);
+    }
+
+    return 0;
+}
+
+SYSCALL_KPROBE4(mmap, unsigned long, addr, unsigned long, len, unsigned long, prot, unsigned long, flags) {
+    return trace__sys_memory(SYSCALL_MMAP, EVENT_MMAP, prot, flags);
+}
+
+SYSCALL_KPROBE3(mprotect, unsigned long, start, size_t, len, unsigned long, prot) {
+    return trace__sys_memory(SYSCALL_MPROTECT, EVENT_MPROTECT, prot, 0);
+}
+
+// security_file_mprotect is called for each vma of the range, an anonymous vma flags the whole mprotect call
+SEC("kprobe/security_file_mprotect")
+int kprobe__security_file_mprotect(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(SYSCALL_MPROTECT);
+    if (!syscall)
+        return 0;
+
+    struct vm_area_struct *vma = (struct vm_area_struct *)PT_REGS_PARM1(ctx);
+    struct file *vm_file = NULL;
+    bpf_probe_read(&vm_file, sizeof(vm_file), &vma->vm_file);
+    if (!vm_file) {
+        syscall->memory.anonymous = 1;
+    }
+
+    return 0;
+}
+
+// approve_by_protection returns 1 if the requested protection has all the approved protection flags
+int __attribute__((always_inline)) approve_by_protection(struct syscall_cache_t *syscall, struct bpf_map_def *approvers) {
+    if ((syscall->policy.flags & FLAGS) == 0)
+        return 0;
+
+    u32 key = 0;
+    u32 *protection = bpf_map_lookup_elem(approvers, &key);
+    return protection != NULL && *protection != 0 && (syscall->memory.protection & *protection) == *protection;
+}
+
+int __attribute__((always_inline)) trace__sys_memory_ret(struct pt_regs *ctx, u64 type, u64 event_type, struct bpf_map_def *approvers) {
+    struct syscall_cache_t *syscall = pop_syscall(type);
+    if (!syscall)
+        return 0;
+
+    long retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    if (syscall->policy.mode == DENY && !approve_by_protection(syscall, approvers))
+        return 0;
+
+    struct memory_event_t event = {
+        .syscall.retval = retval,
+        .protection = syscall->memory.protection,
+        .flags = syscall->memory.flags,
+        .anonymous = syscall->memory.anonymous,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, event_type, event);
+
+    return 0;
+}
+
+SYSCALL_KRETPROBE(mmap) {
+    return trace__sys_memory_ret(ctx, SYSCALL_MMAP, EVENT_MMAP, &mmap_protection_approvers);
+}
+
+SYSCALL_KRETPROBE(mprotect) {
+    return trace__sys_memory_ret(ctx, SYSCALL_MPROTECT, EVENT_MPROTECT, &mprotect_protection_approvers);
+}
+
+#endif
@@ -0,0 +1,86 @@
+#ifndef _MODULE_H_
+#define _MODULE_H_
+
+#include <linux/module.h>
+
+#include "syscalls.h"
+
+struct load_module_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    char name[MAX_MODULE_NAME_LEN];
+    u32 loaded_from_memory;
+    u32 padding;
+};
+
+int __attribute__((always_inline)) trace__sys_load_module(u32 loaded_from_memory) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_LOAD_MODULE,
+        .load_module = {
+            .loaded_from_memory = loaded_from_memory,
+        },
+    };
+
+    cache_syscall(&syscall, EVENT_LOAD_MODULE);
+
+    if (discarded_by_process(syscall.policy.mode, EVENT_LOAD_MODULE)) {
+        pop_syscall(SYSCALL_LOAD_MODULE);
+    }
+
+    return 0;
+}
+
+SYSCALL_KPROBE0(init_module) {
+    return trace__sys_load_module(1);
+}
+
+SYSCALL_KPROBE0(finit_module) {
+    return trace__sys_load_module(0);
+}
+
+SEC("kprobe/do_init_module")
+int kprobe__do_init_module(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(SYSCALL_LOAD_MODULE);
+    if (!syscall)
+        return 0;
+
+    struct module *mod = (struct module *)PT_REGS_PARM1(ctx);
+    bpf_probe_read_str(&syscall->load_module.name, sizeof(syscall->load_module.name), &mod->name);
+
+    return 0;
+}
+
+int __attribute__((always_inline)) trace__sys_load_module_ret(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_LOAD_MODULE);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    struct load_module_event_t event = {
+        .syscall.retval = retval,
+        .loaded_from_memory = syscall->load_module.loaded_from_memory,
+    };
+    bpf_probe_read(&event.name, sizeof(event.name), syscall->load_module.name);
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_LOAD_MODULE, event);
+
+    return 0;
+}
+
+SYSCALL_KRETPROBE(init_module) {
+    return trace__sys_load_module_ret(ctx);
+}
+
+SYSCALL_KRETPROBE(finit_module) {
+    return trace__sys_load_module_ret(ctx);
+}
+
+#endif
@@ -0,0 +1,178 @@
+#ifndef _NETWORK_H_
+#define _NETWORK_H_
+
+#include <linux/in.h>
+#include <linux/in6.h>
+#include <linux/socket.h>
+
+#include "bpf_endian.h"
+#include "defs.h"
+#include "filters.h"
+#include "syscalls.h"
+#include "process.h"
+
+enum net_filter_kind
+{
+    NET_FILTER_FAMILY = 1,
+    NET_FILTER_PORT,
+    NET_FILTER_ADDR,
+};
+
+struct net_filter_t {
+    u32 event_type;
+    u16 kind;
+    u16 value;
+    u8 addr[16];
+};
+
+struct bpf_map_def SEC("maps/net_approvers") net_approvers = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(struct net_filter_t),
+    .value_size = sizeof(u8),
+    .max_entries = 256,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct bpf_map_def SEC("maps/net_discarders") net_discarders = {
+    .type = BPF_MAP_TYPE_LRU_HASH,
+    .key_size = sizeof(struct net_filter_t),
+    .value_size = sizeof(u8),
+    .max_entries = 1024,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct sock_addr_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    struct sock_addr_t addr;
+};
+
+// read_sock_addr copies the family, port and address of a user space sockaddr. The port is returned in host byte order.
+void __attribute__((always_inline)) read_sock_addr(struct sockaddr *uaddr, struct sock_addr_t *addr) {
+    if (!uaddr)
+        return;
+
+    bpf_probe_read(&addr->family, sizeof(addr->family), &uaddr->sa_family);
+
+    u16 port = 0;
+    switch (addr->family) {
+        case AF_INET: {
+            struct sockaddr_in *sin = (struct sockaddr_in *)uaddr;
+            bpf_probe_read(&port, sizeof(port), &sin->sin_port);
+            bpf_probe_read(&addr->addr, sizeof(sin->sin_addr), &sin->sin_addr);
+            break;
+        }
+        case AF_INET6: {
+            struct sockaddr_in6 *sin6 = (struct sockaddr_in6 *)uaddr;
+            bpf_probe_read(&port, sizeof(port), &sin6->sin6_port);
+            bpf_probe_read(&addr->addr, sizeof(sin6->sin6_addr), &sin6->sin6_addr);
+            break;
+        }
+    }
+    addr->port = bpf_ntohs(port);
+}
+
+int __attribute__((always_inline)) net_filter_match(struct bpf_map_def *map, u64 event_type, u16 kind, u16 value, u8 *addr) {
+    struct net_filter_t key = {
+        .event_type = event_type,
+        .kind = kind,
+        .value = value,
+    };
+    if (addr) {
+        __builtin_memcpy(&key.addr, addr, sizeof(key.addr));
+    }
+
+    return bpf_map_lookup_elem(map, &key) != NULL;
+}
+
+int __attribute__((always_inline)) discarded_by_addr(u64 event_type, struct sock_addr_t *addr) {
+    return net_filter_match(&net_discarders, event_type, NET_FILTER_FAMILY, addr->family, NULL) ||
+        net_filter_match(&net_discarders, event_type, NET_FILTER_PORT, addr->port, NULL) ||
+        net_filter_match(&net_discarders, event_type, NET_FILTER_ADDR, addr->family, addr->addr);
+}
+
+int __attribute__((always_inline)) approve_by_addr(struct syscall_cache_t *syscall, u64 event_type, struct sock_addr_t *addr) {
+    if ((syscall->policy.flags & FAMILY) > 0 && net_filter_match(&net_approvers, event_type, NET_FILTER_FAMILY, addr->family, NULL)) {
+        return 1;
+    }
+
+    if ((syscall->policy.flags & PORT) > 0 && net_filter_match(&net_approvers, event_type, NET_FILTER_PORT, addr->port, NULL)) {
+        return 1;
+    }
+
+    if ((syscall->policy.flags & ADDR) > 0 && net_filter_match(&net_approvers, event_type, NET_FILTER_ADDR, addr->family, addr->addr)) {
+        return 1;
+    }
+
+    return 0;
+}
+
+// filter_net returns 1 if the event on the given address has to be sent to user space
+int __attribute__((always_inline)) filter_net(struct syscall_cache_t *syscall, u64 event_type, struct sock_addr_t *addr) {
+    if (syscall->policy.mode == NO_FILTER)
+        return 1;
+
+    if (discarded_by_addr(event_type, addr))
+        return 0;
+
+    if (syscall->policy.mode == ACCEPT)
+        return 1;
+
+    return approve_by_addr(syscall, event_type, addr);
+}
+
+int __attribute__((always_inline)) trace__sys_sock_addr(u64 type, u64 event_type, struct sockaddr *uaddr, int read_at_entry) {
+    struct syscall_cache_t syscall = {
+        .type = type,
+        .net = {
+            .uaddr = uaddr,
+        },
+    };
+
+    if (read_at_entry) {
+        read_sock_addr(uaddr, &syscall.net.addr);
+    }
+
+    cache_syscall(&syscall, event_type);
+
+    if (discarded_by_process(syscall.policy.mode, event_type)) {
+        pop_syscall(type);
+    }
+
+    return 0;
+}
+
+int __attribute__((always_inline)) trace__sys_sock_addr_ret(struct pt_regs *ctx, u64 type, u64 event_type, int read_at_exit) {
+    struct syscall_cache_t *syscall = pop_syscall(type);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval) && retval != -EINPROGRESS)
+        return 0;
+
+    struct sock_addr_event_t event = {
+        .syscall.retval = retval,
+    };
+
+    if (read_at_exit) {
+        read_sock_addr(syscall->net.uaddr, &syscall->net.addr);
+    }
+    event.addr = syscall->net.addr;
+
+    if (!filter_net(syscall, event_type, &event.addr))
+        return 0;
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, event_type, event);
+
+    return 0;
+}
+
+#endif
@@ -29,6 +29,14 @@
 #include "raw_syscalls.h"
 #include "procfs.h"
 #include "setxattr.h"
+#include "network.h"
+#include "socket.h"
+#include "connect.h"
+#include "bind.h"
+#include "accept.h"
+#include "module.h"
+#include "ptrace.h"
+#include "memory.h"
 
 struct invalidate_dentry_event_t {
     struct kevent_t event;
@@ -0,0 +1,77 @@
+#ifndef _PTRACE_H_
+#define _PTRACE_H_
+
+#include "syscalls.h"
+
+struct bpf_map_def SEC("maps/ptrace_request_approvers") ptrace_request_approvers = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(u32),
+    .value_size = sizeof(u8),
+    .max_entries = 32,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct ptrace_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u32 request;
+    u32 pid;
+};
+
+SYSCALL_KPROBE2(ptrace, long, request, pid_t, pid) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_PTRACE,
+        .ptrace = {
+            .request = (u32)request,
+            .pid = (u32)pid,
+        },
+    };
+
+    cache_syscall(&syscall, EVENT_PTRACE);
+
+    if (discarded_by_process(syscall.policy.mode, EVENT_PTRACE)) {
+        pop_syscall(SYSCALL_PTRACE);
+    }
+
+    return 0;
+}
+
+// approve_by_request returns 1 if the ptrace request matches an approver
+int __attribute__((always_inline)) approve_by_request(struct syscall_cache_t *syscall) {
+    if ((syscall->policy.flags & FLAGS) == 0)
+        return 0;
+
+    u32 request = syscall->ptrace.request;
+    return bpf_map_lookup_elem(&ptrace_request_approvers, &request) != NULL;
+}
+
+SYSCALL_KRETPROBE(ptrace) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_PTRACE);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    if (syscall->policy.mode == DENY && !approve_by_request(syscall))
+        return 0;
+
+    struct ptrace_event_t event = {
+        .syscall.retval = retval,
+        .request = syscall->ptrace.request,
+        .pid = syscall->ptrace.pid,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_PTRACE, event);
+
+    return 0;
+}
+
+#endif
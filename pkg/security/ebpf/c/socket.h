@@ -0,0 +1,69 @@
+#ifndef _SOCKET_H_
+#define _SOCKET_H_
+
+#include "network.h"
+
+struct socket_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u16 family;
+    u16 type;
+    u16 protocol;
+    u16 padding;
+};
+
+SYSCALL_KPROBE3(socket, int, family, int, type, int, protocol) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_SOCKET,
+        .socket = {
+            .family = family,
+            .type = type & 0xf, // SOCK_TYPE_MASK, strips SOCK_NONBLOCK and SOCK_CLOEXEC
+            .protocol = protocol,
+        },
+    };
+
+    cache_syscall(&syscall, EVENT_SOCKET);
+
+    if (discarded_by_process(syscall.policy.mode, EVENT_SOCKET)) {
+        pop_syscall(SYSCALL_SOCKET);
+    }
+
+    return 0;
+}
+
+SYSCALL_KRETPROBE(socket) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_SOCKET);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    if (syscall->policy.mode != NO_FILTER) {
+        if (net_filter_match(&net_discarders, EVENT_SOCKET, NET_FILTER_FAMILY, syscall->socket.family, NULL))
+            return 0;
+
+        if (syscall->policy.mode == DENY && !((syscall->policy.flags & FAMILY) > 0 &&
+            net_filter_match(&net_approvers, EVENT_SOCKET, NET_FILTER_FAMILY, syscall->socket.family, NULL)))
+            return 0;
+    }
+
+    struct socket_event_t event = {
+        .syscall.retval = retval,
+        .family = syscall->socket.family,
+        .type = syscall->socket.type,
+        .protocol = syscall->socket.protocol,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_SOCKET, event);
+
+    return 0;
+}
+
+#endif
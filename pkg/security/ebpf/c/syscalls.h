@@ -5,12 +5,20 @@
 #include "process.h"
 
 #define FSTYPE_LEN 16
+#define MAX_MODULE_NAME_LEN 56 // MODULE_NAME_LEN, (64 - sizeof(unsigned long))
 
 struct ktimeval {
     long tv_sec;
     long tv_nsec;
 };
 
+struct sock_addr_t {
+    u8 addr[16];
+    u16 family;
+    u16 port;
+    u32 padding;
+};
+
 struct syscall_cache_t {
     struct policy_t policy;
 
@@ -96,6 +104,33 @@ struct syscall_cache_t {
         struct {
             u8 is_thread;
         } clone;
+
+        struct {
+            u16 family;
+            u16 type;
+            u16 protocol;
+        } socket;
+
+        struct {
+            struct sockaddr *uaddr;
+            struct sock_addr_t addr;
+        } net;
+
+        struct {
+            char name[MAX_MODULE_NAME_LEN];
+            u32 loaded_from_memory;
+        } load_module;
+
+        struct {
+            u32 request;
+            u32 pid;
+        } ptrace;
+
+        struct {
+            u32 protection;
+            u32 flags;
+            u32 anonymous;
+        } memory;
     };
 };
 
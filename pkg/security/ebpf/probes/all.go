@@ -21,9 +21,13 @@ func AllProbes() []*manager.Probe {
 	allProbes = append(allProbes, getAttrProbes()...)
 	allProbes = append(allProbes, getExecProbes()...)
 	allProbes = append(allProbes, getLinkProbe()...)
+	allProbes = append(allProbes, getMemoryProbes()...)
 	allProbes = append(allProbes, getMkdirProbes()...)
+	allProbes = append(allProbes, getModuleProbes()...)
 	allProbes = append(allProbes, getMountProbes()...)
+	allProbes = append(allProbes, getNetworkProbes()...)
 	allProbes = append(allProbes, getOpenProbes()...)
+	allProbes = append(allProbes, getPtraceProbes()...)
 	allProbes = append(allProbes, getRenameProbes()...)
 	allProbes = append(allProbes, getRmdirProbe()...)
 	allProbes = append(allProbes, sharedProbes...)
@@ -69,6 +73,14 @@ func AllMaps() []*manager.Map {
 		// Open tables
 		{Name: "open_basename_approvers"},
 		{Name: "open_flags_approvers"},
+		// Network tables
+		{Name: "net_approvers"},
+		{Name: "net_discarders"},
+		// Ptrace tables
+		{Name: "ptrace_request_approvers"},
+		// Memory tables
+		{Name: "mmap_protection_approvers"},
+		{Name: "mprotect_protection_approvers"},
 		// Exec tables
 		{Name: "proc_cache"},
 		{Name: "pid_cache"},
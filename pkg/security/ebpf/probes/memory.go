@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probes
+
+import "github.com/DataDog/ebpf/manager"
+
+// memoryProbes holds the list of probes used to track mmap and mprotect events
+var memoryProbes = []*manager.Probe{
+	{
+		UID:     SecurityAgentUID,
+		Section: "kprobe/security_file_mprotect",
+	},
+}
+
+func getMemoryProbes() []*manager.Probe {
+	memoryProbes = append(memoryProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "mmap",
+	}, EntryAndExit)...)
+	memoryProbes = append(memoryProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "mprotect",
+	}, EntryAndExit)...)
+	return memoryProbes
+}
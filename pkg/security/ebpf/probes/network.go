@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probes
+
+import "github.com/DataDog/ebpf/manager"
+
+// networkProbes holds the list of probes used to track network events
+var networkProbes []*manager.Probe
+
+func getNetworkProbes() []*manager.Probe {
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "socket",
+	}, EntryAndExit)...)
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "connect",
+	}, EntryAndExit)...)
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "bind",
+	}, EntryAndExit)...)
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "accept",
+	}, EntryAndExit)...)
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "accept4",
+	}, EntryAndExit)...)
+	return networkProbes
+}
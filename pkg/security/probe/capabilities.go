@@ -59,4 +59,11 @@ func (caps Capabilities) GetFieldCapabilities() rules.FieldCapabilities {
 
 func init() {
 	allCapabilities["open"] = openCapabilities
+	allCapabilities["socket"] = socketCapabilities
+	allCapabilities["connect"] = sockAddrCapabilities("connect")
+	allCapabilities["bind"] = sockAddrCapabilities("bind")
+	allCapabilities["accept"] = sockAddrCapabilities("accept")
+	allCapabilities["ptrace"] = ptraceCapabilities
+	allCapabilities["mmap"] = protectionCapabilities("mmap")
+	allCapabilities["mprotect"] = protectionCapabilities("mprotect")
 }
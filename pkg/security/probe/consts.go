@@ -65,6 +65,22 @@ const (
 	ExitEventType
 	// InvalidateDentryEventType Dentry invalidated event
 	InvalidateDentryEventType
+	// SocketEventType Socket creation event
+	SocketEventType
+	// ConnectEventType Socket connect event
+	ConnectEventType
+	// BindEventType Socket bind event
+	BindEventType
+	// AcceptEventType Socket accept event
+	AcceptEventType
+	// LoadModuleEventType Kernel module load event
+	LoadModuleEventType
+	// PtraceEventType Ptrace event
+	PtraceEventType
+	// MMapEventType Memory map event
+	MMapEventType
+	// MProtectEventType Memory protection change event
+	MProtectEventType
 	// maxEventType is used internally to get the maximum number of kernel events.
 	maxEventType
 
@@ -123,6 +139,22 @@ func (t EventType) String() string {
 		return "exit"
 	case InvalidateDentryEventType:
 		return "invalidate_dentry"
+	case SocketEventType:
+		return "socket"
+	case ConnectEventType:
+		return "connect"
+	case BindEventType:
+		return "bind"
+	case AcceptEventType:
+		return "accept"
+	case LoadModuleEventType:
+		return "load_module"
+	case PtraceEventType:
+		return "ptrace"
+	case MMapEventType:
+		return "mmap"
+	case MProtectEventType:
+		return "mprotect"
 
 	case CustomLostReadEventType:
 		return "lost_events_read"
@@ -145,6 +177,7 @@ func (t EventType) String() string {
 
 // parseEvalEventType convert a eval.EventType (string) to its uint64 representation
 // the current algorithm is not efficient but allows us to reduce the number of conversion functions
+//
 //nolint:deadcode,unused
 func parseEvalEventType(eventType eval.EventType) EventType {
 	for i := uint64(0); i != uint64(maxEventType); i++ {
@@ -357,6 +390,77 @@ var (
 		"AT_REMOVEDIR": unix.AT_REMOVEDIR,
 	}
 
+	addressFamilyConstants = map[string]int{
+		"AF_UNSPEC":  unix.AF_UNSPEC,
+		"AF_UNIX":    unix.AF_UNIX,
+		"AF_INET":    unix.AF_INET,
+		"AF_INET6":   unix.AF_INET6,
+		"AF_NETLINK": unix.AF_NETLINK,
+		"AF_PACKET":  unix.AF_PACKET,
+		"AF_VSOCK":   unix.AF_VSOCK,
+	}
+
+	socketTypeConstants = map[string]int{
+		"SOCK_STREAM":    unix.SOCK_STREAM,
+		"SOCK_DGRAM":     unix.SOCK_DGRAM,
+		"SOCK_RAW":       unix.SOCK_RAW,
+		"SOCK_RDM":       unix.SOCK_RDM,
+		"SOCK_SEQPACKET": unix.SOCK_SEQPACKET,
+		"SOCK_PACKET":    unix.SOCK_PACKET,
+	}
+
+	ptraceRequestConstants = map[string]int{
+		"PTRACE_TRACEME":     unix.PTRACE_TRACEME,
+		"PTRACE_PEEKTEXT":    unix.PTRACE_PEEKTEXT,
+		"PTRACE_PEEKDATA":    unix.PTRACE_PEEKDATA,
+		"PTRACE_PEEKUSR":     unix.PTRACE_PEEKUSR,
+		"PTRACE_POKETEXT":    unix.PTRACE_POKETEXT,
+		"PTRACE_POKEDATA":    unix.PTRACE_POKEDATA,
+		"PTRACE_POKEUSR":     unix.PTRACE_POKEUSR,
+		"PTRACE_CONT":        unix.PTRACE_CONT,
+		"PTRACE_KILL":        unix.PTRACE_KILL,
+		"PTRACE_SINGLESTEP":  unix.PTRACE_SINGLESTEP,
+		"PTRACE_ATTACH":      unix.PTRACE_ATTACH,
+		"PTRACE_DETACH":      unix.PTRACE_DETACH,
+		"PTRACE_SYSCALL":     unix.PTRACE_SYSCALL,
+		"PTRACE_SETOPTIONS":  unix.PTRACE_SETOPTIONS,
+		"PTRACE_GETEVENTMSG": unix.PTRACE_GETEVENTMSG,
+		"PTRACE_GETSIGINFO":  unix.PTRACE_GETSIGINFO,
+		"PTRACE_SETSIGINFO":  unix.PTRACE_SETSIGINFO,
+		"PTRACE_GETREGSET":   unix.PTRACE_GETREGSET,
+		"PTRACE_SETREGSET":   unix.PTRACE_SETREGSET,
+		"PTRACE_SEIZE":       unix.PTRACE_SEIZE,
+		"PTRACE_INTERRUPT":   unix.PTRACE_INTERRUPT,
+		"PTRACE_LISTEN":      unix.PTRACE_LISTEN,
+	}
+
+	protectionConstants = map[string]int{
+		"PROT_NONE":      unix.PROT_NONE,
+		"PROT_READ":      unix.PROT_READ,
+		"PROT_WRITE":     unix.PROT_WRITE,
+		"PROT_EXEC":      unix.PROT_EXEC,
+		"PROT_GROWSDOWN": unix.PROT_GROWSDOWN,
+		"PROT_GROWSUP":   unix.PROT_GROWSUP,
+	}
+
+	mmapFlagsConstants = map[string]int{
+		"MAP_SHARED":          unix.MAP_SHARED,
+		"MAP_PRIVATE":         unix.MAP_PRIVATE,
+		"MAP_SHARED_VALIDATE": unix.MAP_SHARED_VALIDATE,
+		"MAP_FIXED":           unix.MAP_FIXED,
+		"MAP_ANONYMOUS":       unix.MAP_ANONYMOUS,
+		"MAP_GROWSDOWN":       unix.MAP_GROWSDOWN,
+		"MAP_DENYWRITE":       unix.MAP_DENYWRITE,
+		"MAP_EXECUTABLE":      unix.MAP_EXECUTABLE,
+		"MAP_LOCKED":          unix.MAP_LOCKED,
+		"MAP_NORESERVE":       unix.MAP_NORESERVE,
+		"MAP_POPULATE":        unix.MAP_POPULATE,
+		"MAP_NONBLOCK":        unix.MAP_NONBLOCK,
+		"MAP_STACK":           unix.MAP_STACK,
+		"MAP_HUGETLB":         unix.MAP_HUGETLB,
+		"MAP_FIXED_NOREPLACE": unix.MAP_FIXED_NOREPLACE,
+	}
+
 	// SECLConstants are constants available in runtime security agent rules
 	SECLConstants = map[string]interface{}{
 		// boolean
@@ -366,9 +470,14 @@ var (
 )
 
 var (
-	openFlagsStrings   = map[int]string{}
-	chmodModeStrings   = map[int]string{}
-	unlinkFlagsStrings = map[int]string{}
+	openFlagsStrings     = map[int]string{}
+	chmodModeStrings     = map[int]string{}
+	unlinkFlagsStrings   = map[int]string{}
+	addressFamilyStrings = map[int]string{}
+	socketTypeStrings    = map[int]string{}
+	ptraceRequestStrings = map[int]string{}
+	protectionStrings    = map[int]string{}
+	mmapFlagsStrings     = map[int]string{}
 )
 
 func initOpenConstants() {
@@ -401,6 +510,37 @@ func initUnlinkConstanst() {
 	}
 }
 
+func initNetworkConstants() {
+	for k, v := range addressFamilyConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		addressFamilyStrings[v] = k
+	}
+
+	for k, v := range socketTypeConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		socketTypeStrings[v] = k
+	}
+}
+
+func initPtraceConstants() {
+	for k, v := range ptraceRequestConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		ptraceRequestStrings[v] = k
+	}
+}
+
+func initMemoryConstants() {
+	for k, v := range protectionConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		protectionStrings[v] = k
+	}
+
+	for k, v := range mmapFlagsConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		mmapFlagsStrings[v] = k
+	}
+}
+
 func initErrorConstants() {
 	for k, v := range errorConstants {
 		SECLConstants[k] = &eval.IntEvaluator{Value: v}
@@ -412,6 +552,9 @@ func initConstants() {
 	initOpenConstants()
 	initChmodConstants()
 	initUnlinkConstanst()
+	initNetworkConstants()
+	initPtraceConstants()
+	initMemoryConstants()
 }
 
 func bitmaskToStringArray(bitmask int, intToStrMap map[int]string) []string {
@@ -478,6 +621,63 @@ func (f UnlinkFlags) StringArray() []string {
 	return bitmaskToStringArray(int(f), unlinkFlagsStrings)
 }
 
+// AddressFamily represents a socket address family
+type AddressFamily int
+
+func (f AddressFamily) String() string {
+	if s, ok := addressFamilyStrings[int(f)]; ok {
+		return s
+	}
+	return fmt.Sprintf("%d", int(f))
+}
+
+// SocketType represents a socket type
+type SocketType int
+
+func (t SocketType) String() string {
+	if s, ok := socketTypeStrings[int(t)]; ok {
+		return s
+	}
+	return fmt.Sprintf("%d", int(t))
+}
+
+// PtraceRequest represents a ptrace request
+type PtraceRequest int
+
+func (r PtraceRequest) String() string {
+	if s, ok := ptraceRequestStrings[int(r)]; ok {
+		return s
+	}
+	return fmt.Sprintf("%d", int(r))
+}
+
+// Protection represents a memory protection bitmask value
+type Protection int
+
+func (p Protection) String() string {
+	return strings.Join(p.StringArray(), " | ")
+}
+
+// StringArray returns the memory protection as an array of string
+func (p Protection) StringArray() []string {
+	if int(p) == unix.PROT_NONE {
+		return []string{protectionStrings[unix.PROT_NONE]}
+	}
+	return bitmaskToStringArray(int(p), protectionStrings)
+}
+
+// MMapFlags represents a mmap flags bitmask value
+type MMapFlags int
+
+func (f MMapFlags) String() string {
+	return bitmaskToString(int(f), mmapFlagsStrings)
+}
+
+// StringArray returns the mmap flags as an array of string
+func (f MMapFlags) StringArray() []string {
+	return bitmaskToStringArray(int(f), mmapFlagsStrings)
+}
+
 // RetValError represents a syscall return error value
 type RetValError int
 
@@ -33,4 +33,14 @@ func TestFlagsToString(t *testing.T) {
 	if str != "O_RDONLY" {
 		t.Errorf("expexted flags not found, got: %s", str)
 	}
+
+	str = Protection(syscall.PROT_READ | syscall.PROT_EXEC).String()
+	if str != "PROT_EXEC | PROT_READ" {
+		t.Errorf("expexted flags not found, got: %s", str)
+	}
+
+	str = Protection(syscall.PROT_NONE).String()
+	if str != "PROT_NONE" {
+		t.Errorf("expexted flags not found, got: %s", str)
+	}
 }
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// protectionCapabilities returns the capabilities of the events reporting a memory protection
+func protectionCapabilities(eventType eval.EventType) Capabilities {
+	return Capabilities{
+		eventType + ".protection": {
+			PolicyFlags:     PolicyFlagFlags,
+			FieldValueTypes: eval.ScalarValueType | eval.BitmaskValueType,
+		},
+	}
+}
+
+// protectionOnNewApprovers returns the approvers handler of the mmap and mprotect events
+func protectionOnNewApprovers(eventType EventType) onApproverHandler {
+	return func(probe *Probe, approvers rules.Approvers) (activeApprovers, error) {
+		var protectionApprovers []activeApprover
+		for field, values := range approvers {
+			if field != eventType.String()+".protection" {
+				return nil, errors.New("field unknown")
+			}
+
+			// the kernel approves the events having all the approved flags. An event matching
+			// any of the approvers has at least the flags common to all of them, so these are used.
+			protection := -1
+			for _, value := range values {
+				protection &= value.Value.(int)
+			}
+			if protection == 0 {
+				return nil, errors.New("no protection flag common to all the approvers")
+			}
+
+			activeApprover, err := approveFlags(eventType.String()+"_protection_approvers", protection)
+			if err != nil {
+				return nil, err
+			}
+			protectionApprovers = append(protectionApprovers, activeApprover)
+		}
+
+		return newActiveKFilters(protectionApprovers...), nil
+	}
+}
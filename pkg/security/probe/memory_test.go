@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestProtectionApprovers(t *testing.T) {
+	handler := protectionOnNewApprovers(MMapEventType)
+
+	// the kernel approves the events with all the flags common to the approvers
+	approvers, err := handler(nil, rules.Approvers{
+		"mmap.protection": rules.FilterValues{
+			{Field: "mmap.protection", Value: unix.PROT_WRITE | unix.PROT_EXEC},
+			{Field: "mmap.protection", Value: unix.PROT_READ | unix.PROT_EXEC},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(approvers) != 1 {
+		t.Fatalf("expected 1 approver, got %d", len(approvers))
+	}
+	for _, approver := range approvers {
+		if value := approver.(*arrayEntry).value; value != ebpf.Uint32MapItem(unix.PROT_EXEC) {
+			t.Errorf("expected the PROT_EXEC approver, got %v", value)
+		}
+	}
+
+	// an approver without flag would approve every event
+	_, err = handler(nil, rules.Approvers{
+		"mmap.protection": rules.FilterValues{
+			{Field: "mmap.protection", Value: unix.PROT_WRITE},
+			{Field: "mmap.protection", Value: unix.PROT_EXEC},
+		},
+	})
+	if err == nil {
+		t.Error("expected an error for approvers without common flags")
+	}
+}
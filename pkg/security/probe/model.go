@@ -13,6 +13,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
 	"path"
 	"regexp"
 	"strings"
@@ -94,6 +95,13 @@ func (m *Model) ValidateField(key string, field eval.FieldValue) error {
 		}
 	}
 
+	// check that IP addresses are valid
+	if strings.HasSuffix(key, ".addr.ip") && field.Type == eval.ScalarValueType {
+		if value, ok := field.Value.(string); ok && net.ParseIP(value) == nil {
+			return fmt.Errorf("invalid IP address `%s`", value)
+		}
+	}
+
 	switch key {
 
 	case "event.retval":
@@ -619,6 +627,184 @@ func (e *UmountEvent) UnmarshalBinary(data []byte) (int, error) {
 	return 8, nil
 }
 
+// SocketAddr represents a socket address
+type SocketAddr struct {
+	Family uint16   `field:"family"`
+	Port   uint16   `field:"port"`
+	IPRaw  [16]byte `field:"-"`
+	IP     string   `field:"ip" handler:"ResolveIP,string"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (a *SocketAddr) UnmarshalBinary(data []byte) (int, error) {
+	if len(data) < 24 {
+		return 0, ErrNotEnoughData
+	}
+
+	utils.SliceToArray(data[0:16], unsafe.Pointer(&a.IPRaw))
+	a.Family = ebpf.ByteOrder.Uint16(data[16:18])
+	a.Port = ebpf.ByteOrder.Uint16(data[18:20])
+
+	return 24, nil
+}
+
+// ResolveIP resolves the textual representation of the IP address
+func (a *SocketAddr) ResolveIP(event *Event) string {
+	if len(a.IP) == 0 {
+		switch a.Family {
+		case syscall.AF_INET:
+			a.IP = net.IP(a.IPRaw[0:4]).String()
+		case syscall.AF_INET6:
+			a.IP = net.IP(a.IPRaw[:]).String()
+		}
+	}
+	return a.IP
+}
+
+// SocketEvent represents a socket event
+type SocketEvent struct {
+	SyscallEvent
+	Family   uint16 `field:"family"`
+	Type     uint16 `field:"type"`
+	Protocol uint16 `field:"protocol"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *SocketEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := unmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 8 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Family = ebpf.ByteOrder.Uint16(data[0:2])
+	e.Type = ebpf.ByteOrder.Uint16(data[2:4])
+	e.Protocol = ebpf.ByteOrder.Uint16(data[4:6])
+	return n + 8, nil
+}
+
+// SocketAddrEvent represents a connect, bind or accept event
+type SocketAddrEvent struct {
+	SyscallEvent
+	Addr SocketAddr `field:"addr"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *SocketAddrEvent) UnmarshalBinary(data []byte) (int, error) {
+	return unmarshalBinary(data, &e.SyscallEvent, &e.Addr)
+}
+
+// LoadModuleEvent represents a kernel module load event
+type LoadModuleEvent struct {
+	SyscallEvent
+	Name             string   `field:"name" handler:"ResolveName,string"`
+	NameRaw          [56]byte `field:"-"`
+	LoadedFromMemory bool     `field:"loaded_from_memory"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *LoadModuleEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := unmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 64 {
+		return n, ErrNotEnoughData
+	}
+
+	utils.SliceToArray(data[0:56], unsafe.Pointer(&e.NameRaw))
+	e.LoadedFromMemory = ebpf.ByteOrder.Uint32(data[56:60]) == 1
+	return n + 64, nil
+}
+
+// ResolveName resolves the name of the loaded module
+func (e *LoadModuleEvent) ResolveName(event *Event) string {
+	if len(e.Name) == 0 {
+		e.Name = string(bytes.Trim(e.NameRaw[:], "\x00"))
+	}
+	return e.Name
+}
+
+// PtraceEvent represents a ptrace event
+type PtraceEvent struct {
+	SyscallEvent
+	Request uint32 `field:"request"`
+	PID     uint32 `field:"pid"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *PtraceEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := unmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 8 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Request = ebpf.ByteOrder.Uint32(data[0:4])
+	e.PID = ebpf.ByteOrder.Uint32(data[4:8])
+	return n + 8, nil
+}
+
+// MMapEvent represents a mmap event
+type MMapEvent struct {
+	SyscallEvent
+	Protection uint32 `field:"protection"`
+	Flags      uint32 `field:"flags"`
+	Anonymous  bool   `field:"anonymous"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *MMapEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := unmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 16 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Protection = ebpf.ByteOrder.Uint32(data[0:4])
+	e.Flags = ebpf.ByteOrder.Uint32(data[4:8])
+	e.Anonymous = ebpf.ByteOrder.Uint32(data[8:12]) == 1
+	return n + 16, nil
+}
+
+// MProtectEvent represents a mprotect event
+type MProtectEvent struct {
+	SyscallEvent
+	Protection uint32 `field:"protection"`
+	Anonymous  bool   `field:"anonymous"`
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *MProtectEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := unmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 16 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Protection = ebpf.ByteOrder.Uint32(data[0:4])
+	e.Anonymous = ebpf.ByteOrder.Uint32(data[8:12]) == 1
+	return n + 16, nil
+}
+
 // ContainerContext holds the container context of an event
 type ContainerContext struct {
 	ID string `field:"id" handler:"ResolveContainerID,string"`
@@ -1004,18 +1190,26 @@ type Event struct {
 	Process   ProcessContext   `field:"process" event:"*"`
 	Container ContainerContext `field:"container"`
 
-	Chmod       ChmodEvent    `field:"chmod" event:"chmod"`
-	Chown       ChownEvent    `field:"chown" event:"chown"`
-	Open        OpenEvent     `field:"open" event:"open"`
-	Mkdir       MkdirEvent    `field:"mkdir" event:"mkdir"`
-	Rmdir       RmdirEvent    `field:"rmdir" event:"rmdir"`
-	Rename      RenameEvent   `field:"rename" event:"rename"`
-	Unlink      UnlinkEvent   `field:"unlink" event:"unlink"`
-	Utimes      UtimesEvent   `field:"utimes" event:"utimes"`
-	Link        LinkEvent     `field:"link" event:"link"`
-	SetXAttr    SetXAttrEvent `field:"setxattr" event:"setxattr"`
-	RemoveXAttr SetXAttrEvent `field:"removexattr" event:"removexattr"`
-	Exec        ExecEvent     `field:"exec" event:"exec"`
+	Chmod       ChmodEvent      `field:"chmod" event:"chmod"`
+	Chown       ChownEvent      `field:"chown" event:"chown"`
+	Open        OpenEvent       `field:"open" event:"open"`
+	Mkdir       MkdirEvent      `field:"mkdir" event:"mkdir"`
+	Rmdir       RmdirEvent      `field:"rmdir" event:"rmdir"`
+	Rename      RenameEvent     `field:"rename" event:"rename"`
+	Unlink      UnlinkEvent     `field:"unlink" event:"unlink"`
+	Utimes      UtimesEvent     `field:"utimes" event:"utimes"`
+	Link        LinkEvent       `field:"link" event:"link"`
+	SetXAttr    SetXAttrEvent   `field:"setxattr" event:"setxattr"`
+	RemoveXAttr SetXAttrEvent   `field:"removexattr" event:"removexattr"`
+	Exec        ExecEvent       `field:"exec" event:"exec"`
+	Socket      SocketEvent     `field:"socket" event:"socket"`
+	Connect     SocketAddrEvent `field:"connect" event:"connect"`
+	Bind        SocketAddrEvent `field:"bind" event:"bind"`
+	Accept      SocketAddrEvent `field:"accept" event:"accept"`
+	LoadModule  LoadModuleEvent `field:"load_module" event:"load_module"`
+	Ptrace      PtraceEvent     `field:"ptrace" event:"ptrace"`
+	MMap        MMapEvent       `field:"mmap" event:"mmap"`
+	MProtect    MProtectEvent   `field:"mprotect" event:"mprotect"`
 
 	Mount            MountEvent            `field:"-"`
 	Umount           UmountEvent           `field:"-"`
@@ -25,6 +25,102 @@ func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
 func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Evaluator, error) {
 	switch field {
 
+	case "accept.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.Addr.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.addr.ip":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).Accept.Addr.ResolveIP((*Event)(ctx.Object))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "accept.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.Addr.Port)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Addr.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.ip":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).Bind.Addr.ResolveIP((*Event)(ctx.Object))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "bind.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Addr.Port)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "chmod.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -205,6 +301,54 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "connect.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Addr.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.addr.ip":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).Connect.Addr.ResolveIP((*Event)(ctx.Object))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "connect.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Addr.Port)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "container.id":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -517,6 +661,42 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "load_module.loaded_from_memory":
+		return &eval.BoolEvaluator{
+			EvalFnc: func(ctx *eval.Context) bool {
+
+				return (*Event)(ctx.Object).LoadModule.LoadedFromMemory
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "load_module.name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).LoadModule.ResolveName((*Event)(ctx.Object))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "load_module.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).LoadModule.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "mkdir.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -601,6 +781,90 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "mmap.anonymous":
+		return &eval.BoolEvaluator{
+			EvalFnc: func(ctx *eval.Context) bool {
+
+				return (*Event)(ctx.Object).MMap.Anonymous
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mmap.flags":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).MMap.Flags)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mmap.protection":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).MMap.Protection)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mmap.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).MMap.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mprotect.anonymous":
+		return &eval.BoolEvaluator{
+			EvalFnc: func(ctx *eval.Context) bool {
+
+				return (*Event)(ctx.Object).MProtect.Anonymous
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mprotect.protection":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).MProtect.Protection)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "mprotect.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).MProtect.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "open.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1263,6 +1527,42 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "ptrace.pid":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Ptrace.PID)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "ptrace.request":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Ptrace.Request)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "ptrace.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Ptrace.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "removexattr.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1659,6 +1959,54 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "socket.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Socket.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "socket.protocol":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Socket.Protocol)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "socket.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Socket.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "socket.type":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Socket.Type)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "unlink.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1823,6 +2171,38 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 	switch field {
 
+	case "accept.addr.family":
+
+		return int(e.Accept.Addr.Family), nil
+
+	case "accept.addr.ip":
+
+		return e.Accept.Addr.ResolveIP(e), nil
+
+	case "accept.addr.port":
+
+		return int(e.Accept.Addr.Port), nil
+
+	case "accept.retval":
+
+		return int(e.Accept.Retval), nil
+
+	case "bind.addr.family":
+
+		return int(e.Bind.Addr.Family), nil
+
+	case "bind.addr.ip":
+
+		return e.Bind.Addr.ResolveIP(e), nil
+
+	case "bind.addr.port":
+
+		return int(e.Bind.Addr.Port), nil
+
+	case "bind.retval":
+
+		return int(e.Bind.Retval), nil
+
 	case "chmod.basename":
 
 		return e.Chmod.ResolveBasename(e), nil
@@ -1883,6 +2263,22 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Chown.UID), nil
 
+	case "connect.addr.family":
+
+		return int(e.Connect.Addr.Family), nil
+
+	case "connect.addr.ip":
+
+		return e.Connect.Addr.ResolveIP(e), nil
+
+	case "connect.addr.port":
+
+		return int(e.Connect.Addr.Port), nil
+
+	case "connect.retval":
+
+		return int(e.Connect.Retval), nil
+
 	case "container.id":
 
 		return e.Container.ResolveContainerID(e), nil
@@ -1987,6 +2383,18 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Link.Target.OverlayNumLower), nil
 
+	case "load_module.loaded_from_memory":
+
+		return e.LoadModule.LoadedFromMemory, nil
+
+	case "load_module.name":
+
+		return e.LoadModule.ResolveName(e), nil
+
+	case "load_module.retval":
+
+		return int(e.LoadModule.Retval), nil
+
 	case "mkdir.basename":
 
 		return e.Mkdir.ResolveBasename(e), nil
@@ -2015,6 +2423,34 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Mkdir.Retval), nil
 
+	case "mmap.anonymous":
+
+		return e.MMap.Anonymous, nil
+
+	case "mmap.flags":
+
+		return int(e.MMap.Flags), nil
+
+	case "mmap.protection":
+
+		return int(e.MMap.Protection), nil
+
+	case "mmap.retval":
+
+		return int(e.MMap.Retval), nil
+
+	case "mprotect.anonymous":
+
+		return e.MProtect.Anonymous, nil
+
+	case "mprotect.protection":
+
+		return int(e.MProtect.Protection), nil
+
+	case "mprotect.retval":
+
+		return int(e.MProtect.Retval), nil
+
 	case "open.basename":
 
 		return e.Open.ResolveBasename(e), nil
@@ -2485,6 +2921,18 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.Process.ResolveUser(e), nil
 
+	case "ptrace.pid":
+
+		return int(e.Ptrace.PID), nil
+
+	case "ptrace.request":
+
+		return int(e.Ptrace.Request), nil
+
+	case "ptrace.retval":
+
+		return int(e.Ptrace.Retval), nil
+
 	case "removexattr.basename":
 
 		return e.RemoveXAttr.ResolveBasename(e), nil
@@ -2617,6 +3065,22 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.SetXAttr.Retval), nil
 
+	case "socket.family":
+
+		return int(e.Socket.Family), nil
+
+	case "socket.protocol":
+
+		return int(e.Socket.Protocol), nil
+
+	case "socket.retval":
+
+		return int(e.Socket.Retval), nil
+
+	case "socket.type":
+
+		return int(e.Socket.Type), nil
+
 	case "unlink.basename":
 
 		return e.Unlink.ResolveBasename(e), nil
@@ -2677,6 +3141,30 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	switch field {
 
+	case "accept.addr.family":
+		return "accept", nil
+
+	case "accept.addr.ip":
+		return "accept", nil
+
+	case "accept.addr.port":
+		return "accept", nil
+
+	case "accept.retval":
+		return "accept", nil
+
+	case "bind.addr.family":
+		return "bind", nil
+
+	case "bind.addr.ip":
+		return "bind", nil
+
+	case "bind.addr.port":
+		return "bind", nil
+
+	case "bind.retval":
+		return "bind", nil
+
 	case "chmod.basename":
 		return "chmod", nil
 
@@ -2722,6 +3210,18 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "chown.uid":
 		return "chown", nil
 
+	case "connect.addr.family":
+		return "connect", nil
+
+	case "connect.addr.ip":
+		return "connect", nil
+
+	case "connect.addr.port":
+		return "connect", nil
+
+	case "connect.retval":
+		return "connect", nil
+
 	case "container.id":
 		return "*", nil
 
@@ -2800,6 +3300,15 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "link.target.overlay_numlower":
 		return "link", nil
 
+	case "load_module.loaded_from_memory":
+		return "load_module", nil
+
+	case "load_module.name":
+		return "load_module", nil
+
+	case "load_module.retval":
+		return "load_module", nil
+
 	case "mkdir.basename":
 		return "mkdir", nil
 
@@ -2815,11 +3324,32 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "mkdir.mode":
 		return "mkdir", nil
 
-	case "mkdir.overlay_numlower":
-		return "mkdir", nil
+	case "mkdir.overlay_numlower":
+		return "mkdir", nil
+
+	case "mkdir.retval":
+		return "mkdir", nil
+
+	case "mmap.anonymous":
+		return "mmap", nil
+
+	case "mmap.flags":
+		return "mmap", nil
+
+	case "mmap.protection":
+		return "mmap", nil
+
+	case "mmap.retval":
+		return "mmap", nil
+
+	case "mprotect.anonymous":
+		return "mprotect", nil
 
-	case "mkdir.retval":
-		return "mkdir", nil
+	case "mprotect.protection":
+		return "mprotect", nil
+
+	case "mprotect.retval":
+		return "mprotect", nil
 
 	case "open.basename":
 		return "open", nil
@@ -2944,6 +3474,15 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "process.user":
 		return "*", nil
 
+	case "ptrace.pid":
+		return "ptrace", nil
+
+	case "ptrace.request":
+		return "ptrace", nil
+
+	case "ptrace.retval":
+		return "ptrace", nil
+
 	case "removexattr.basename":
 		return "removexattr", nil
 
@@ -3043,6 +3582,18 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "setxattr.retval":
 		return "setxattr", nil
 
+	case "socket.family":
+		return "socket", nil
+
+	case "socket.protocol":
+		return "socket", nil
+
+	case "socket.retval":
+		return "socket", nil
+
+	case "socket.type":
+		return "socket", nil
+
 	case "unlink.basename":
 		return "unlink", nil
 
@@ -3090,6 +3641,38 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 	switch field {
 
+	case "accept.addr.family":
+
+		return reflect.Int, nil
+
+	case "accept.addr.ip":
+
+		return reflect.String, nil
+
+	case "accept.addr.port":
+
+		return reflect.Int, nil
+
+	case "accept.retval":
+
+		return reflect.Int, nil
+
+	case "bind.addr.family":
+
+		return reflect.Int, nil
+
+	case "bind.addr.ip":
+
+		return reflect.String, nil
+
+	case "bind.addr.port":
+
+		return reflect.Int, nil
+
+	case "bind.retval":
+
+		return reflect.Int, nil
+
 	case "chmod.basename":
 
 		return reflect.String, nil
@@ -3150,6 +3733,22 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "connect.addr.family":
+
+		return reflect.Int, nil
+
+	case "connect.addr.ip":
+
+		return reflect.String, nil
+
+	case "connect.addr.port":
+
+		return reflect.Int, nil
+
+	case "connect.retval":
+
+		return reflect.Int, nil
+
 	case "container.id":
 
 		return reflect.String, nil
@@ -3254,6 +3853,18 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "load_module.loaded_from_memory":
+
+		return reflect.Bool, nil
+
+	case "load_module.name":
+
+		return reflect.String, nil
+
+	case "load_module.retval":
+
+		return reflect.Int, nil
+
 	case "mkdir.basename":
 
 		return reflect.String, nil
@@ -3282,6 +3893,34 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "mmap.anonymous":
+
+		return reflect.Bool, nil
+
+	case "mmap.flags":
+
+		return reflect.Int, nil
+
+	case "mmap.protection":
+
+		return reflect.Int, nil
+
+	case "mmap.retval":
+
+		return reflect.Int, nil
+
+	case "mprotect.anonymous":
+
+		return reflect.Bool, nil
+
+	case "mprotect.protection":
+
+		return reflect.Int, nil
+
+	case "mprotect.retval":
+
+		return reflect.Int, nil
+
 	case "open.basename":
 
 		return reflect.String, nil
@@ -3446,6 +4085,18 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "ptrace.pid":
+
+		return reflect.Int, nil
+
+	case "ptrace.request":
+
+		return reflect.Int, nil
+
+	case "ptrace.retval":
+
+		return reflect.Int, nil
+
 	case "removexattr.basename":
 
 		return reflect.String, nil
@@ -3578,6 +4229,22 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "socket.family":
+
+		return reflect.Int, nil
+
+	case "socket.protocol":
+
+		return reflect.Int, nil
+
+	case "socket.retval":
+
+		return reflect.Int, nil
+
+	case "socket.type":
+
+		return reflect.Int, nil
+
 	case "unlink.basename":
 
 		return reflect.String, nil
@@ -3639,6 +4306,74 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 	var ok bool
 	switch field {
 
+	case "accept.addr.family":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Addr.Family"}
+		}
+		e.Accept.Addr.Family = uint16(v)
+		return nil
+
+	case "accept.addr.ip":
+
+		if e.Accept.Addr.IP, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Addr.IP"}
+		}
+		return nil
+
+	case "accept.addr.port":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Addr.Port"}
+		}
+		e.Accept.Addr.Port = uint16(v)
+		return nil
+
+	case "accept.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Retval"}
+		}
+		e.Accept.Retval = int64(v)
+		return nil
+
+	case "bind.addr.family":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.Family"}
+		}
+		e.Bind.Addr.Family = uint16(v)
+		return nil
+
+	case "bind.addr.ip":
+
+		if e.Bind.Addr.IP, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.IP"}
+		}
+		return nil
+
+	case "bind.addr.port":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.Port"}
+		}
+		e.Bind.Addr.Port = uint16(v)
+		return nil
+
+	case "bind.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Retval"}
+		}
+		e.Bind.Retval = int64(v)
+		return nil
+
 	case "chmod.basename":
 
 		if e.Chmod.BasenameStr, ok = value.(string); !ok {
@@ -3762,6 +4497,40 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Chown.UID = int32(v)
 		return nil
 
+	case "connect.addr.family":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.Family"}
+		}
+		e.Connect.Addr.Family = uint16(v)
+		return nil
+
+	case "connect.addr.ip":
+
+		if e.Connect.Addr.IP, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.IP"}
+		}
+		return nil
+
+	case "connect.addr.port":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.Port"}
+		}
+		e.Connect.Addr.Port = uint16(v)
+		return nil
+
+	case "connect.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Retval"}
+		}
+		e.Connect.Retval = int64(v)
+		return nil
+
 	case "container.id":
 
 		if e.Container.ID, ok = value.(string); !ok {
@@ -3966,6 +4735,29 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Link.Target.OverlayNumLower = int32(v)
 		return nil
 
+	case "load_module.loaded_from_memory":
+
+		if e.LoadModule.LoadedFromMemory, ok = value.(bool); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.LoadedFromMemory"}
+		}
+		return nil
+
+	case "load_module.name":
+
+		if e.LoadModule.Name, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.Name"}
+		}
+		return nil
+
+	case "load_module.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.Retval"}
+		}
+		e.LoadModule.Retval = int64(v)
+		return nil
+
 	case "mkdir.basename":
 
 		if e.Mkdir.BasenameStr, ok = value.(string); !ok {
@@ -4023,6 +4815,65 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Mkdir.Retval = int64(v)
 		return nil
 
+	case "mmap.anonymous":
+
+		if e.MMap.Anonymous, ok = value.(bool); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MMap.Anonymous"}
+		}
+		return nil
+
+	case "mmap.flags":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MMap.Flags"}
+		}
+		e.MMap.Flags = uint32(v)
+		return nil
+
+	case "mmap.protection":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MMap.Protection"}
+		}
+		e.MMap.Protection = uint32(v)
+		return nil
+
+	case "mmap.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MMap.Retval"}
+		}
+		e.MMap.Retval = int64(v)
+		return nil
+
+	case "mprotect.anonymous":
+
+		if e.MProtect.Anonymous, ok = value.(bool); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MProtect.Anonymous"}
+		}
+		return nil
+
+	case "mprotect.protection":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MProtect.Protection"}
+		}
+		e.MProtect.Protection = uint32(v)
+		return nil
+
+	case "mprotect.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "MProtect.Retval"}
+		}
+		e.MProtect.Retval = int64(v)
+		return nil
+
 	case "open.basename":
 
 		if e.Open.BasenameStr, ok = value.(string); !ok {
@@ -4217,6 +5068,33 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		return nil
 
+	case "ptrace.pid":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Ptrace.PID"}
+		}
+		e.Ptrace.PID = uint32(v)
+		return nil
+
+	case "ptrace.request":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Ptrace.Request"}
+		}
+		e.Ptrace.Request = uint32(v)
+		return nil
+
+	case "ptrace.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Ptrace.Retval"}
+		}
+		e.Ptrace.Retval = int64(v)
+		return nil
+
 	case "removexattr.basename":
 
 		if e.RemoveXAttr.BasenameStr, ok = value.(string); !ok {
@@ -4476,6 +5354,42 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.SetXAttr.Retval = int64(v)
 		return nil
 
+	case "socket.family":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Socket.Family"}
+		}
+		e.Socket.Family = uint16(v)
+		return nil
+
+	case "socket.protocol":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Socket.Protocol"}
+		}
+		e.Socket.Protocol = uint16(v)
+		return nil
+
+	case "socket.retval":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Socket.Retval"}
+		}
+		e.Socket.Retval = int64(v)
+		return nil
+
+	case "socket.type":
+
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Socket.Type"}
+		}
+		e.Socket.Type = uint16(v)
+		return nil
+
 	case "unlink.basename":
 
 		if e.Unlink.BasenameStr, ok = value.(string); !ok {
@@ -8,8 +8,10 @@
 package probe
 
 import (
+	"syscall"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
 )
 
@@ -37,3 +39,50 @@ func TestAbsolutePath(t *testing.T) {
 		t.Fatal("should return an error")
 	}
 }
+
+func TestSocketAddr(t *testing.T) {
+	data := make([]byte, 24)
+	copy(data[0:4], []byte{10, 0, 0, 1})
+	ebpf.ByteOrder.PutUint16(data[16:18], syscall.AF_INET)
+	ebpf.ByteOrder.PutUint16(data[18:20], 443)
+
+	var addr SocketAddr
+	if _, err := addr.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if addr.Port != 443 {
+		t.Errorf("expected port 443, got %d", addr.Port)
+	}
+	if ip := addr.ResolveIP(nil); ip != "10.0.0.1" {
+		t.Errorf("expected IP 10.0.0.1, got %s", ip)
+	}
+
+	model := &Model{}
+	if err := model.ValidateField("connect.addr.ip", eval.FieldValue{Value: "::1", Type: eval.ScalarValueType}); err != nil {
+		t.Fatalf("shouldn't return an error: %s", err)
+	}
+	if err := model.ValidateField("connect.addr.ip", eval.FieldValue{Value: "10.0.0.256", Type: eval.ScalarValueType}); err == nil {
+		t.Fatal("should return an error")
+	}
+}
+
+func TestLoadModuleEvent(t *testing.T) {
+	data := make([]byte, 72)
+	copy(data[8:], "nf_conntrack")
+	ebpf.ByteOrder.PutUint32(data[64:68], 1)
+
+	var event LoadModuleEvent
+	n, err := event.UnmarshalBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 72 {
+		t.Errorf("expected 72 bytes read, got %d", n)
+	}
+	if name := event.ResolveName(nil); name != "nf_conntrack" {
+		t.Errorf("expected module nf_conntrack, got %s", name)
+	}
+	if !event.LoadedFromMemory {
+		t.Error("expected a module loaded from memory")
+	}
+}
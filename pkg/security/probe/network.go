@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// netFilterKind describes the socket address part a network approver or discarder applies to
+type netFilterKind uint16
+
+// need to be aligned with the kernel net_filter_kind enum
+const (
+	netFilterFamily netFilterKind = iota + 1
+	netFilterPort
+	netFilterAddr
+)
+
+// netFilterKey is the key of the net_approvers and net_discarders tables
+type netFilterKey struct {
+	EventType EventType
+	Kind      netFilterKind
+	Value     uint16
+	Addr      [16]byte
+}
+
+// MarshalBinary returns the binary representation of a netFilterKey
+func (k netFilterKey) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 24)
+	ebpf.ByteOrder.PutUint32(b[0:4], uint32(k.EventType))
+	ebpf.ByteOrder.PutUint16(b[4:6], uint16(k.Kind))
+	ebpf.ByteOrder.PutUint16(b[6:8], k.Value)
+	copy(b[8:24], k.Addr[:])
+	return b, nil
+}
+
+// newNetAddrFilterKey returns the filter key matching the given IP address
+func newNetAddrFilterKey(eventType EventType, value string) (netFilterKey, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return netFilterKey{}, fmt.Errorf("invalid IP address `%s`", value)
+	}
+
+	key := netFilterKey{EventType: eventType, Kind: netFilterAddr}
+	if ip4 := ip.To4(); ip4 != nil {
+		key.Value = syscall.AF_INET
+		copy(key.Addr[:], ip4)
+	} else {
+		key.Value = syscall.AF_INET6
+		copy(key.Addr[:], ip)
+	}
+	return key, nil
+}
+
+// newNetFilterKey returns the filter key matching the value of the given socket address field
+func newNetFilterKey(eventType EventType, field eval.Field, value interface{}) (netFilterKey, error) {
+	switch field {
+	case "socket.family", eventType.String() + ".addr.family":
+		return netFilterKey{EventType: eventType, Kind: netFilterFamily, Value: uint16(value.(int))}, nil
+	case eventType.String() + ".addr.port":
+		return netFilterKey{EventType: eventType, Kind: netFilterPort, Value: uint16(value.(int))}, nil
+	case eventType.String() + ".addr.ip":
+		return newNetAddrFilterKey(eventType, value.(string))
+	}
+	return netFilterKey{}, errors.New("field unknown")
+}
+
+var socketCapabilities = Capabilities{
+	"socket.family": {
+		PolicyFlags:     PolicyFlagFamily,
+		FieldValueTypes: eval.ScalarValueType,
+	},
+}
+
+// sockAddrCapabilities returns the capabilities of the events reporting a socket address
+func sockAddrCapabilities(eventType eval.EventType) Capabilities {
+	return Capabilities{
+		eventType + ".addr.family": {
+			PolicyFlags:     PolicyFlagFamily,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+		eventType + ".addr.port": {
+			PolicyFlags:     PolicyFlagPort,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+		eventType + ".addr.ip": {
+			PolicyFlags:     PolicyFlagAddr,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+	}
+}
+
+// netOnNewApprovers returns the approvers handler of a network event type
+func netOnNewApprovers(eventType EventType) onApproverHandler {
+	return func(probe *Probe, approvers rules.Approvers) (activeApprovers, error) {
+		var netApprovers []activeApprover
+		for field, values := range approvers {
+			for _, value := range values {
+				key, err := newNetFilterKey(eventType, field, value.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				netApprovers = append(netApprovers, &mapEntry{
+					tableName: "net_approvers",
+					key:       key,
+					tableKey:  key,
+					value:     ebpf.ZeroUint8MapItem,
+				})
+			}
+		}
+
+		return newActiveKFilters(netApprovers...), nil
+	}
+}
+
+// netDiscarderWrapper returns a discarder handler pushing the socket address fields discarders of the given event type
+func netDiscarderWrapper(eventType EventType, fields ...eval.Field) onDiscarderHandler {
+	return func(rs *rules.RuleSet, event *Event, probe *Probe, discarder Discarder) error {
+		for _, field := range fields {
+			if discarder.Field != field {
+				continue
+			}
+
+			value, err := event.GetFieldValue(field)
+			if err != nil {
+				return err
+			}
+
+			// the address of an unsupported family can't be discarded
+			if s, ok := value.(string); ok && s == "" {
+				return nil
+			}
+
+			key, err := newNetFilterKey(eventType, field, value)
+			if err != nil {
+				return err
+			}
+
+			log.Tracef("Apply `%s` discarder for event `%s`, value: %v", field, eventType, value)
+
+			table, err := probe.Map("net_discarders")
+			if err != nil {
+				return err
+			}
+			return table.Put(key, ebpf.ZeroUint8MapItem)
+		}
+
+		return nil
+	}
+}
+
+// sockAddrDiscarderWrapper returns the discarder handler of the events reporting a socket address
+func sockAddrDiscarderWrapper(eventType EventType) onDiscarderHandler {
+	name := eventType.String()
+	return netDiscarderWrapper(eventType, name+".addr.family", name+".addr.port", name+".addr.ip")
+}
@@ -30,6 +30,9 @@ const (
 	PolicyFlagBasename PolicyFlag = 1
 	PolicyFlagFlags    PolicyFlag = 2
 	PolicyFlagMode     PolicyFlag = 4
+	PolicyFlagFamily   PolicyFlag = 16
+	PolicyFlagPort     PolicyFlag = 32
+	PolicyFlagAddr     PolicyFlag = 64
 
 	// need to be aligned with the kernel size
 	BasenameFilterSize = 32
@@ -69,5 +72,14 @@ func (f PolicyFlag) MarshalJSON() ([]byte, error) {
 	if f&PolicyFlagMode != 0 {
 		flags = append(flags, `"mode"`)
 	}
+	if f&PolicyFlagFamily != 0 {
+		flags = append(flags, `"family"`)
+	}
+	if f&PolicyFlagPort != 0 {
+		flags = append(flags, `"port"`)
+	}
+	if f&PolicyFlagAddr != 0 {
+		flags = append(flags, `"addr"`)
+	}
 	return []byte("[" + strings.Join(flags, ",") + "]"), nil
 }
@@ -479,6 +479,46 @@ func (p *Probe) handleEvent(CPU uint64, data []byte) {
 		event.updateProcessCachePointer(p.resolvers.ProcessResolver.AddExecEntry(event.Process.Pid, event.processCacheEntry))
 	case ExitEventType:
 		defer p.resolvers.ProcessResolver.DeleteEntry(event.Process.Pid, event.ResolveEventTimestamp())
+	case SocketEventType:
+		if _, err := event.Socket.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode socket event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case ConnectEventType:
+		if _, err := event.Connect.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode connect event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case BindEventType:
+		if _, err := event.Bind.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode bind event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case AcceptEventType:
+		if _, err := event.Accept.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode accept event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case LoadModuleEventType:
+		if _, err := event.LoadModule.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode load_module event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case PtraceEventType:
+		if _, err := event.Ptrace.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode ptrace event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case MMapEventType:
+		if _, err := event.MMap.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode mmap event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case MProtectEventType:
+		if _, err := event.MProtect.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode mprotect event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
 	default:
 		log.Errorf("unsupported event type %d", eventType)
 		return
@@ -913,6 +953,13 @@ func filenameDiscarderWrapper(eventType EventType, handler onDiscarderHandler, g
 func init() {
 	// approvers
 	allApproversHandlers["open"] = openOnNewApprovers
+	allApproversHandlers["socket"] = netOnNewApprovers(SocketEventType)
+	allApproversHandlers["connect"] = netOnNewApprovers(ConnectEventType)
+	allApproversHandlers["bind"] = netOnNewApprovers(BindEventType)
+	allApproversHandlers["accept"] = netOnNewApprovers(AcceptEventType)
+	allApproversHandlers["ptrace"] = ptraceOnNewApprovers
+	allApproversHandlers["mmap"] = protectionOnNewApprovers(MMapEventType)
+	allApproversHandlers["mprotect"] = protectionOnNewApprovers(MProtectEventType)
 
 	// discarders
 	SupportedDiscarders["process.filename"] = true
@@ -983,4 +1030,23 @@ func init() {
 				return "removexattr.filename", event.RemoveXAttr.MountID, event.RemoveXAttr.Inode, event.RemoveXAttr.PathID, false
 			}))
 	SupportedDiscarders["removexattr.filename"] = true
+
+	allDiscarderHandlers["socket"] = processDiscarderWrapper(SocketEventType,
+		netDiscarderWrapper(SocketEventType, "socket.family"))
+	SupportedDiscarders["socket.family"] = true
+
+	for _, eventType := range []EventType{ConnectEventType, BindEventType, AcceptEventType} {
+		allDiscarderHandlers[eventType.String()] = processDiscarderWrapper(eventType, sockAddrDiscarderWrapper(eventType))
+		SupportedDiscarders[eventType.String()+".addr.family"] = true
+		SupportedDiscarders[eventType.String()+".addr.port"] = true
+		SupportedDiscarders[eventType.String()+".addr.ip"] = true
+	}
+
+	allDiscarderHandlers["load_module"] = processDiscarderWrapper(LoadModuleEventType, nil)
+
+	allDiscarderHandlers["ptrace"] = processDiscarderWrapper(PtraceEventType, nil)
+
+	allDiscarderHandlers["mmap"] = processDiscarderWrapper(MMapEventType, nil)
+
+	allDiscarderHandlers["mprotect"] = processDiscarderWrapper(MProtectEventType, nil)
 }
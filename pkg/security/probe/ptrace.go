@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+var ptraceCapabilities = Capabilities{
+	"ptrace.request": {
+		PolicyFlags:     PolicyFlagFlags,
+		FieldValueTypes: eval.ScalarValueType,
+	},
+}
+
+func ptraceOnNewApprovers(probe *Probe, approvers rules.Approvers) (activeApprovers, error) {
+	var ptraceApprovers []activeApprover
+	for field, values := range approvers {
+		switch field {
+		case "ptrace.request":
+			for _, value := range values {
+				request := ebpf.Uint32MapItem(value.Value.(int))
+				ptraceApprovers = append(ptraceApprovers, &mapEntry{
+					tableName: "ptrace_request_approvers",
+					key:       request,
+					tableKey:  request,
+					value:     ebpf.ZeroUint8MapItem,
+				})
+			}
+
+		default:
+			return nil, errors.New("field unknown")
+		}
+	}
+
+	return newActiveKFilters(ptraceApprovers...), nil
+}
@@ -20,6 +20,8 @@ import (
 const (
 	FIMCategory     = "File Activity"
 	ProcessActivity = "Process Activity"
+	NetworkActivity = "Network Activity"
+	KernelActivity  = "Kernel Activity"
 )
 
 // FileSerializer serializes a file to JSON
@@ -90,6 +92,47 @@ type FileEventSerializer struct {
 	FSType     string `json:"fstype,omitempty"`
 }
 
+// SocketAddrSerializer serializes a socket address to JSON
+// easyjson:json
+type SocketAddrSerializer struct {
+	Family string `json:"family,omitempty"`
+	IP     string `json:"ip,omitempty"`
+	Port   uint16 `json:"port,omitempty"`
+}
+
+// NetworkEventSerializer serializes a network event to JSON
+// easyjson:json
+type NetworkEventSerializer struct {
+	Addr *SocketAddrSerializer `json:"addr,omitempty"`
+
+	// Specific to socket events
+	Family   string `json:"family,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Protocol uint16 `json:"protocol,omitempty"`
+}
+
+// ModuleEventSerializer serializes a kernel module load event to JSON
+// easyjson:json
+type ModuleEventSerializer struct {
+	Name             string `json:"name,omitempty"`
+	LoadedFromMemory bool   `json:"loaded_from_memory,omitempty"`
+}
+
+// PtraceEventSerializer serializes a ptrace event to JSON
+// easyjson:json
+type PtraceEventSerializer struct {
+	Request string `json:"request,omitempty"`
+	PID     uint32 `json:"pid,omitempty"`
+}
+
+// MemoryEventSerializer serializes a mmap or mprotect event to JSON
+// easyjson:json
+type MemoryEventSerializer struct {
+	Protection []string `json:"protection,omitempty"`
+	Flags      []string `json:"flags,omitempty"`
+	Anonymous  bool     `json:"anonymous,omitempty"`
+}
+
 // EventContextSerializer serializes an event context to JSON
 // easyjson:json
 type EventContextSerializer struct {
@@ -111,6 +154,10 @@ type ProcessContextSerializer struct {
 type EventSerializer struct {
 	*EventContextSerializer    `json:"evt,omitempty"`
 	*FileEventSerializer       `json:"file,omitempty"`
+	*NetworkEventSerializer    `json:"network,omitempty"`
+	*ModuleEventSerializer     `json:"module,omitempty"`
+	*PtraceEventSerializer     `json:"ptrace,omitempty"`
+	*MemoryEventSerializer     `json:"memory,omitempty"`
 	UserContextSerializer      UserContextSerializer       `json:"usr,omitempty"`
 	ProcessContextSerializer   *ProcessContextSerializer   `json:"process,omitempty"`
 	ContainerContextSerializer *ContainerContextSerializer `json:"container,omitempty"`
@@ -128,6 +175,14 @@ func newFileSerializer(fe *FileEvent, e *Event) *FileSerializer {
 	}
 }
 
+func newSocketAddrSerializer(a *SocketAddr, e *Event) *SocketAddrSerializer {
+	return &SocketAddrSerializer{
+		Family: AddressFamily(a.Family).String(),
+		IP:     a.ResolveIP(e),
+		Port:   a.Port,
+	}
+}
+
 func getUint64Pointer(i *uint64) *uint64 {
 	if *i == 0 {
 		return nil
@@ -377,6 +432,66 @@ func newEventSerializer(event *Event) *EventSerializer {
 		}
 		s.EventContextSerializer.Outcome = serializeSyscallRetval(0)
 		s.Category = ProcessActivity
+	case SocketEventType:
+		s.NetworkEventSerializer = &NetworkEventSerializer{
+			Family:   AddressFamily(event.Socket.Family).String(),
+			Type:     SocketType(event.Socket.Type).String(),
+			Protocol: event.Socket.Protocol,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Socket.Retval)
+		s.Category = NetworkActivity
+	case ConnectEventType:
+		s.NetworkEventSerializer = &NetworkEventSerializer{
+			Addr: newSocketAddrSerializer(&event.Connect.Addr, event),
+		}
+		retval := event.Connect.Retval
+		if retval == -int64(syscall.EINPROGRESS) {
+			// non-blocking connect in progress
+			retval = 0
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(retval)
+		s.Category = NetworkActivity
+	case BindEventType:
+		s.NetworkEventSerializer = &NetworkEventSerializer{
+			Addr: newSocketAddrSerializer(&event.Bind.Addr, event),
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Bind.Retval)
+		s.Category = NetworkActivity
+	case AcceptEventType:
+		s.NetworkEventSerializer = &NetworkEventSerializer{
+			Addr: newSocketAddrSerializer(&event.Accept.Addr, event),
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Accept.Retval)
+		s.Category = NetworkActivity
+	case LoadModuleEventType:
+		s.ModuleEventSerializer = &ModuleEventSerializer{
+			Name:             event.LoadModule.ResolveName(event),
+			LoadedFromMemory: event.LoadModule.LoadedFromMemory,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.LoadModule.Retval)
+		s.Category = KernelActivity
+	case PtraceEventType:
+		s.PtraceEventSerializer = &PtraceEventSerializer{
+			Request: PtraceRequest(event.Ptrace.Request).String(),
+			PID:     event.Ptrace.PID,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Ptrace.Retval)
+		s.Category = ProcessActivity
+	case MMapEventType:
+		s.MemoryEventSerializer = &MemoryEventSerializer{
+			Protection: Protection(event.MMap.Protection).StringArray(),
+			Flags:      MMapFlags(event.MMap.Flags).StringArray(),
+			Anonymous:  event.MMap.Anonymous,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.MMap.Retval)
+		s.Category = ProcessActivity
+	case MProtectEventType:
+		s.MemoryEventSerializer = &MemoryEventSerializer{
+			Protection: Protection(event.MProtect.Protection).StringArray(),
+			Anonymous:  event.MProtect.Anonymous,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.MProtect.Retval)
+		s.Category = ProcessActivity
 	}
 
 	return s
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestMemory(t *testing.T) {
+	ruleDefs := []*rules.RuleDefinition{
+		{
+			ID:         "test_rule_mmap",
+			Expression: fmt.Sprintf(`mmap.protection & PROT_EXEC > 0 && mmap.flags & MAP_ANONYMOUS > 0 && process.pid == %d`, os.Getpid()),
+		},
+		{
+			ID:         "test_rule_mprotect",
+			Expression: fmt.Sprintf(`mprotect.protection & PROT_EXEC > 0 && mprotect.anonymous == true && process.pid == %d`, os.Getpid()),
+		},
+	}
+
+	test, err := newTestModule(nil, ruleDefs, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	t.Run("mmap", func(t *testing.T) {
+		data, err := syscall.Mmap(-1, 0, os.Getpagesize(), syscall.PROT_READ|syscall.PROT_EXEC, syscall.MAP_PRIVATE|syscall.MAP_ANONYMOUS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer syscall.Munmap(data)
+
+		event, _, err := test.GetEvent()
+		if err != nil {
+			t.Error(err)
+		} else {
+			if event.GetType() != "mmap" {
+				t.Errorf("expected mmap event, got %s", event.GetType())
+			}
+
+			if protection := event.MMap.Protection; protection != syscall.PROT_READ|syscall.PROT_EXEC {
+				t.Errorf("expected protection %d, got %d", syscall.PROT_READ|syscall.PROT_EXEC, protection)
+			}
+
+			if !event.MMap.Anonymous {
+				t.Error("expected anonymous mapping")
+			}
+		}
+	})
+
+	t.Run("mprotect", func(t *testing.T) {
+		data, err := syscall.Mmap(-1, 0, os.Getpagesize(), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANONYMOUS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer syscall.Munmap(data)
+
+		if err := syscall.Mprotect(data, syscall.PROT_READ|syscall.PROT_EXEC); err != nil {
+			t.Fatal(err)
+		}
+
+		event, _, err := test.GetEvent()
+		if err != nil {
+			t.Error(err)
+		} else {
+			if event.GetType() != "mprotect" {
+				t.Errorf("expected mprotect event, got %s", event.GetType())
+			}
+
+			if protection := event.MProtect.Protection; protection != syscall.PROT_READ|syscall.PROT_EXEC {
+				t.Errorf("expected protection %d, got %d", syscall.PROT_READ|syscall.PROT_EXEC, protection)
+			}
+
+			if !event.MProtect.Anonymous {
+				t.Error("expected anonymous memory")
+			}
+		}
+	})
+}
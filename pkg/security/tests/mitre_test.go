@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -115,6 +116,16 @@ func TestMitre(t *testing.T) {
 			},
 			expectedRule: "kernel_module",
 		},
+		{
+			action: func(t *testing.T) {
+				data, err := syscall.Mmap(-1, 0, os.Getpagesize(), syscall.PROT_READ|syscall.PROT_WRITE|syscall.PROT_EXEC, syscall.MAP_PRIVATE|syscall.MAP_ANONYMOUS)
+				if err != nil {
+					t.Fatal(err)
+				}
+				syscall.Munmap(data)
+			},
+			expectedRule: "executable_anonymous_memory",
+		},
 	}
 
 	for _, tc := range testCases {
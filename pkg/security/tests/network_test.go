@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestNetwork(t *testing.T) {
+	ruleDefs := []*rules.RuleDefinition{
+		{
+			ID:         "test_rule_socket",
+			Expression: `socket.family == AF_INET6 && socket.type == SOCK_DGRAM && socket.protocol == 17`,
+		},
+		{
+			ID:         "test_rule_bind",
+			Expression: `bind.addr.family == AF_INET && bind.addr.ip == "127.0.0.1" && bind.addr.port == 4242`,
+		},
+		{
+			ID:         "test_rule_connect",
+			Expression: `connect.addr.ip == "127.0.0.1" && connect.addr.port == 4242`,
+		},
+		{
+			ID:         "test_rule_accept",
+			Expression: fmt.Sprintf(`accept.addr.ip == "127.0.0.1" && process.pid == %d`, os.Getpid()),
+		},
+	}
+
+	test, err := newTestModule(nil, ruleDefs, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	t.Run("socket", func(t *testing.T) {
+		fd, err := syscall.Socket(syscall.AF_INET6, syscall.SOCK_DGRAM, syscall.IPPROTO_UDP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer syscall.Close(fd)
+
+		event, _, err := test.GetEvent()
+		if err != nil {
+			t.Error(err)
+		} else {
+			if event.GetType() != "socket" {
+				t.Errorf("expected socket event, got %s", event.GetType())
+			}
+
+			if family := event.Socket.Family; family != syscall.AF_INET6 {
+				t.Errorf("expected family %d, got %d", syscall.AF_INET6, family)
+			}
+
+			if retval := event.Socket.Retval; retval != int64(fd) {
+				t.Errorf("expected retval %d, got %d", fd, retval)
+			}
+		}
+	})
+
+	serverFd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(serverFd)
+
+	if err := syscall.SetsockoptInt(serverFd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := &syscall.SockaddrInet4{Port: 4242, Addr: [4]byte{127, 0, 0, 1}}
+
+	t.Run("bind", func(t *testing.T) {
+		if err := syscall.Bind(serverFd, addr); err != nil {
+			t.Fatal(err)
+		}
+
+		event, _, err := test.GetEvent()
+		if err != nil {
+			t.Error(err)
+		} else {
+			if event.GetType() != "bind" {
+				t.Errorf("expected bind event, got %s", event.GetType())
+			}
+
+			if port := event.Bind.Addr.Port; port != 4242 {
+				t.Errorf("expected port 4242, got %d", port)
+			}
+		}
+	})
+
+	if err := syscall.Listen(serverFd, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("connect", func(t *testing.T) {
+		clientFd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer syscall.Close(clientFd)
+
+		if err := syscall.Connect(clientFd, addr); err != nil {
+			t.Fatal(err)
+		}
+
+		event, _, err := test.GetEvent()
+		if err != nil {
+			t.Error(err)
+		} else {
+			if event.GetType() != "connect" {
+				t.Errorf("expected connect event, got %s", event.GetType())
+			}
+
+			if family := event.Connect.Addr.Family; family != syscall.AF_INET {
+				t.Errorf("expected family %d, got %d", syscall.AF_INET, family)
+			}
+		}
+
+		t.Run("accept", func(t *testing.T) {
+			fd, _, err := syscall.Accept(serverFd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer syscall.Close(fd)
+
+			event, _, err := test.GetEvent()
+			if err != nil {
+				t.Error(err)
+			} else {
+				if event.GetType() != "accept" {
+					t.Errorf("expected accept event, got %s", event.GetType())
+				}
+
+				if retval := event.Accept.Retval; retval != int64(fd) {
+					t.Errorf("expected retval %d, got %d", fd, retval)
+				}
+			}
+		})
+	})
+}
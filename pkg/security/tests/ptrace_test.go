@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestPtrace(t *testing.T) {
+	rule := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: fmt.Sprintf(`ptrace.request == PTRACE_ATTACH && process.pid == %d`, os.Getpid()),
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{rule}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	// all the ptrace requests have to be issued from the thread that attached to the tracee
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	pid := cmd.Process.Pid
+	if err := syscall.PtraceAttach(pid); err != nil {
+		t.Fatal(err)
+	}
+
+	var status syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &status, syscall.WALL, nil); err == nil {
+		syscall.PtraceDetach(pid)
+	}
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "ptrace" {
+			t.Errorf("expected ptrace event, got %s", event.GetType())
+		}
+
+		if tracee := event.Ptrace.PID; tracee != uint32(pid) {
+			t.Errorf("expected tracee pid %d, got %d", pid, tracee)
+		}
+
+		if retval := event.Ptrace.Retval; retval != 0 {
+			t.Errorf("expected retval 0, got %d", retval)
+		}
+	}
+}
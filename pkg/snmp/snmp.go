@@ -53,6 +53,7 @@ type Config struct {
 	ContextName        string          `mapstructure:"context_name"`
 	IgnoredIPAddresses map[string]bool `mapstructure:"ignored_ip_addresses"`
 	ADIdentifier       string          `mapstructure:"ad_identifier"`
+	Loader             string          `mapstructure:"loader"`
 }
 
 // NewListenerConfig parses configuration and returns a built ListenerConfig
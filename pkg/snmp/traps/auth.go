@@ -26,3 +26,36 @@ func validateCredentials(p *gosnmp.SnmpPacket, c *Config) error {
 
 	return errors.New("Unknown community string")
 }
+
+// authDigestLengths maps authentication protocols to the length of the truncated HMAC sent in msgAuthenticationParameters.
+// See: https://tools.ietf.org/html/rfc3414#section-6.3.1 and https://tools.ietf.org/html/rfc7860#section-4.2.1
+var authDigestLengths = map[gosnmp.SnmpV3AuthProtocol]int{
+	gosnmp.MD5:    12,
+	gosnmp.SHA:    12,
+	gosnmp.SHA224: 16,
+	gosnmp.SHA256: 24,
+	gosnmp.SHA384: 32,
+	gosnmp.SHA512: 48,
+}
+
+// validateV3SecurityLevel checks that an SNMPv3 packet uses the security level configured for its user.
+// GoSNMP only checks the authentication digest if the user requires it and compares as many bytes as
+// the packet provides, so downgraded or truncated packets must be rejected before decoding them.
+func validateV3SecurityLevel(h *packetHeader, params *gosnmp.GoSNMP) error {
+	if h.securityModel != gosnmp.UserSecurityModel {
+		return fmt.Errorf("Unsupported security model: %d", h.securityModel)
+	}
+
+	if h.msgFlags&gosnmp.AuthPriv != params.MsgFlags&gosnmp.AuthPriv {
+		return fmt.Errorf("Unexpected security level: %d", h.msgFlags&gosnmp.AuthPriv)
+	}
+
+	if params.MsgFlags&gosnmp.AuthNoPriv != 0 {
+		usm := params.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if len(h.authParams) != authDigestLengths[usm.AuthenticationProtocol] {
+			return errors.New("Invalid authentication parameters length")
+		}
+	}
+
+	return nil
+}
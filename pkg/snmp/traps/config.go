@@ -6,8 +6,10 @@
 package traps
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/soniah/gosnmp"
@@ -18,13 +20,26 @@ func IsEnabled() bool {
 	return config.Datadog.GetBool("snmp_traps_enabled")
 }
 
+// UserV3 contains the definition of an SNMPv3 user allowed to send traps to the Agent.
+// YAML field tags provided for test marshalling purposes.
+type UserV3 struct {
+	Username     string `mapstructure:"user" yaml:"user"`
+	AuthProtocol string `mapstructure:"auth_protocol" yaml:"auth_protocol"`
+	AuthKey      string `mapstructure:"auth_key" yaml:"auth_key"`
+	PrivProtocol string `mapstructure:"priv_protocol" yaml:"priv_protocol"`
+	PrivKey      string `mapstructure:"priv_key" yaml:"priv_key"`
+	EngineID     string `mapstructure:"engine_id" yaml:"engine_id"`
+}
+
 // Config contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type Config struct {
 	Port             uint16   `mapstructure:"port" yaml:"port"`
 	CommunityStrings []string `mapstructure:"community_strings" yaml:"community_strings"`
+	Users            []UserV3 `mapstructure:"users" yaml:"users"`
 	BindHost         string   `mapstructure:"bind_host" yaml:"bind_host"`
 	StopTimeout      int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	TrapDBDir        string   `mapstructure:"trap_db_dir" yaml:"trap_db_dir"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -36,8 +51,27 @@ func ReadConfig() (*Config, error) {
 	}
 
 	// Validate required fields.
-	if c.CommunityStrings == nil || len(c.CommunityStrings) == 0 {
-		return nil, errors.New("`community_strings` is required and must be non-empty")
+	if len(c.CommunityStrings) == 0 && len(c.Users) == 0 {
+		return nil, errors.New("at least one of `community_strings` or `users` is required and must be non-empty")
+	}
+
+	// A user name may be shared by several devices, as long as each of them has its own engine ID.
+	type userKey struct{ username, engineID string }
+	users := make(map[userKey]bool, len(c.Users))
+	for _, user := range c.Users {
+		if user.Username == "" {
+			return nil, errors.New("`user` is required for all SNMPv3 users")
+		}
+		params, err := c.BuildV3Params(user)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SNMPv3 user %q: %s", user.Username, err)
+		}
+
+		key := userKey{user.Username, params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID}
+		if users[key] {
+			return nil, fmt.Errorf("SNMPv3 user %q is defined more than once for engine ID %q", user.Username, user.EngineID)
+		}
+		users[key] = true
 	}
 
 	// Set defaults.
@@ -51,6 +85,9 @@ func ReadConfig() (*Config, error) {
 	if c.StopTimeout == 0 {
 		c.StopTimeout = defaultStopTimeout
 	}
+	if c.TrapDBDir == "" {
+		c.TrapDBDir = getDefaultTrapDBDir()
+	}
 
 	return &c, nil
 }
@@ -69,3 +106,101 @@ func (c *Config) BuildV2Params() *gosnmp.GoSNMP {
 		Logger:    &trapLogger{},
 	}
 }
+
+// BuildV3Params returns a valid GoSNMP SNMPv3 params structure for the given user.
+func (c *Config) BuildV3Params(user UserV3) (*gosnmp.GoSNMP, error) {
+	authProtocol, err := parseAuthProtocol(user.AuthProtocol)
+	if err != nil {
+		return nil, err
+	}
+	privProtocol, err := parsePrivProtocol(user.PrivProtocol)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgFlags gosnmp.SnmpV3MsgFlags
+	switch {
+	case authProtocol == gosnmp.NoAuth && privProtocol != gosnmp.NoPriv:
+		return nil, errors.New("`priv_protocol` requires `auth_protocol` to be set")
+	case authProtocol == gosnmp.NoAuth:
+		msgFlags = gosnmp.NoAuthNoPriv
+	case privProtocol == gosnmp.NoPriv:
+		msgFlags = gosnmp.AuthNoPriv
+	default:
+		msgFlags = gosnmp.AuthPriv
+	}
+
+	if authProtocol != gosnmp.NoAuth && user.AuthKey == "" {
+		return nil, errors.New("`auth_key` is required when `auth_protocol` is set")
+	}
+	if privProtocol != gosnmp.NoPriv && user.PrivKey == "" {
+		return nil, errors.New("`priv_key` is required when `priv_protocol` is set")
+	}
+
+	// Traps are sent by the authoritative engine, so keys are localized using the engine ID of the device.
+	engineID, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(user.EngineID), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("`engine_id` must be a hexadecimal string: %s", err)
+	}
+	if authProtocol != gosnmp.NoAuth && len(engineID) == 0 {
+		return nil, errors.New("`engine_id` is required when `auth_protocol` is set")
+	}
+
+	return &gosnmp.GoSNMP{
+		Port:          c.Port,
+		Transport:     "udp",
+		Version:       gosnmp.Version3,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      msgFlags,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 user.Username,
+			AuthenticationProtocol:   authProtocol,
+			AuthenticationPassphrase: user.AuthKey,
+			PrivacyProtocol:          privProtocol,
+			PrivacyPassphrase:        user.PrivKey,
+			AuthoritativeEngineID:    string(engineID),
+			Logger:                   &trapLogger{},
+		},
+		Logger: &trapLogger{},
+	}, nil
+}
+
+func parseAuthProtocol(protocol string) (gosnmp.SnmpV3AuthProtocol, error) {
+	switch strings.ToUpper(protocol) {
+	case "":
+		return gosnmp.NoAuth, nil
+	case "MD5":
+		return gosnmp.MD5, nil
+	case "SHA":
+		return gosnmp.SHA, nil
+	case "SHA224":
+		return gosnmp.SHA224, nil
+	case "SHA256":
+		return gosnmp.SHA256, nil
+	case "SHA384":
+		return gosnmp.SHA384, nil
+	case "SHA512":
+		return gosnmp.SHA512, nil
+	}
+	return gosnmp.NoAuth, fmt.Errorf("unsupported `auth_protocol`: %q", protocol)
+}
+
+func parsePrivProtocol(protocol string) (gosnmp.SnmpV3PrivProtocol, error) {
+	switch strings.ToUpper(protocol) {
+	case "":
+		return gosnmp.NoPriv, nil
+	case "DES":
+		return gosnmp.DES, nil
+	case "AES":
+		return gosnmp.AES, nil
+	case "AES192":
+		return gosnmp.AES192, nil
+	case "AES256":
+		return gosnmp.AES256, nil
+	case "AES192C":
+		return gosnmp.AES192C, nil
+	case "AES256C":
+		return gosnmp.AES256C, nil
+	}
+	return gosnmp.NoPriv, fmt.Errorf("unsupported `priv_protocol`: %q", protocol)
+}
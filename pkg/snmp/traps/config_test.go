@@ -8,6 +8,7 @@ package traps
 import (
 	"github.com/soniah/gosnmp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -71,3 +72,90 @@ func TestStopTimeout(t *testing.T) {
 
 	assert.Equal(t, 11, config.StopTimeout)
 }
+
+func TestUsersWithoutCommunityStrings(t *testing.T) {
+	Configure(t, Config{
+		Users: []UserV3{{Username: "user", AuthProtocol: "sha", AuthKey: "password", EngineID: "80001f8880e9bd0c1d12667a51"}},
+	})
+	config, err := ReadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(config.Users))
+	assert.Equal(t, "user", config.Users[0].Username)
+}
+
+func TestInvalidUsers(t *testing.T) {
+	for name, user := range map[string]UserV3{
+		"missing user":          {AuthProtocol: "SHA", AuthKey: "password", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"unknown auth protocol": {Username: "user", AuthProtocol: "SHA1", AuthKey: "password", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"unknown priv protocol": {Username: "user", AuthProtocol: "SHA", AuthKey: "password", PrivProtocol: "3DES", PrivKey: "password", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"missing auth key":      {Username: "user", AuthProtocol: "SHA", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"missing priv key":      {Username: "user", AuthProtocol: "SHA", AuthKey: "password", PrivProtocol: "AES", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"priv without auth":     {Username: "user", PrivProtocol: "AES", PrivKey: "password", EngineID: "80001f8880e9bd0c1d12667a51"},
+		"missing engine id":     {Username: "user", AuthProtocol: "SHA", AuthKey: "password"},
+		"invalid engine id":     {Username: "user", AuthProtocol: "SHA", AuthKey: "password", EngineID: "not-hex"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			Configure(t, Config{Users: []UserV3{user}})
+			_, err := ReadConfig()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDuplicateUsers(t *testing.T) {
+	user := UserV3{Username: "user"}
+	Configure(t, Config{Users: []UserV3{user, user}})
+	_, err := ReadConfig()
+	assert.Error(t, err)
+
+	// Engine IDs are compared once decoded.
+	Configure(t, Config{Users: []UserV3{
+		{Username: "user", EngineID: "0x80001F8880E9BD0C1D12667A51"},
+		{Username: "user", EngineID: "80001f8880e9bd0c1d12667a51"},
+	}})
+	_, err = ReadConfig()
+	assert.Error(t, err)
+}
+
+func TestSameUserSeveralEngines(t *testing.T) {
+	Configure(t, Config{Users: []UserV3{
+		{Username: "user", AuthProtocol: "SHA", AuthKey: "password-1", EngineID: "80001f8880e9bd0c1d12667a51"},
+		{Username: "user", AuthProtocol: "SHA", AuthKey: "password-2", EngineID: "80001f8880e9bd0c1d12667a52"},
+	}})
+	c, err := ReadConfig()
+	require.NoError(t, err)
+	assert.Len(t, c.Users, 2)
+}
+
+func TestBuildV3Params(t *testing.T) {
+	config := Config{Port: 1234}
+
+	params, err := config.BuildV3Params(UserV3{
+		Username:     "user",
+		AuthProtocol: "SHA256",
+		AuthKey:      "auth-password",
+		PrivProtocol: "AES256",
+		PrivKey:      "priv-password",
+		EngineID:     "0x80001F8880E9BD0C1D12667A51",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(1234), params.Port)
+	assert.Equal(t, gosnmp.Version3, params.Version)
+	assert.Equal(t, gosnmp.UserSecurityModel, params.SecurityModel)
+	assert.Equal(t, gosnmp.AuthPriv, params.MsgFlags)
+	usm := params.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	assert.Equal(t, "user", usm.UserName)
+	assert.Equal(t, gosnmp.SHA256, usm.AuthenticationProtocol)
+	assert.Equal(t, "auth-password", usm.AuthenticationPassphrase)
+	assert.Equal(t, gosnmp.AES256, usm.PrivacyProtocol)
+	assert.Equal(t, "priv-password", usm.PrivacyPassphrase)
+	assert.Equal(t, "\x80\x00\x1f\x88\x80\xe9\xbd\x0c\x1d\x12\x66\x7a\x51", usm.AuthoritativeEngineID)
+
+	params, err = config.BuildV3Params(UserV3{Username: "user", AuthProtocol: "MD5", AuthKey: "auth-password", EngineID: "80001f8880e9bd0c1d12667a51"})
+	assert.NoError(t, err)
+	assert.Equal(t, gosnmp.AuthNoPriv, params.MsgFlags)
+
+	params, err = config.BuildV3Params(UserV3{Username: "user"})
+	assert.NoError(t, err)
+	assert.Equal(t, gosnmp.NoAuthNoPriv, params.MsgFlags)
+}
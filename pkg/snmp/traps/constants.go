@@ -9,4 +9,5 @@ const (
 	defaultPort        = uint16(162) // Standard UDP port for traps.
 	defaultStopTimeout = 5
 	packetsChanSize    = 100
+	maxPacketSize      = 65535 // Maximum size of a UDP datagram.
 )
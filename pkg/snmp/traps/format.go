@@ -34,6 +34,8 @@ func formatVersion(packet *SnmpPacket) string {
 	switch packet.Content.Version {
 	case gosnmp.Version2c:
 		return "2"
+	case gosnmp.Version3:
+		return "3"
 	default:
 		return "unknown"
 	}
@@ -62,6 +64,10 @@ func formatTrapPDUs(variables []gosnmp.SnmpPDU) (map[string]interface{}, error)
 		return nil, err
 	}
 	data["oid"] = trapOID
+	if trap, ok := trapsDB.getTrapMetadata(trapOID); ok {
+		data["snmpTrapName"] = trap.Name
+		data["snmpTrapMIB"] = trap.MIB
+	}
 
 	data["variables"] = parseVariables(variables[2:])
 
@@ -115,6 +121,12 @@ func parseVariables(variables []gosnmp.SnmpPDU) []map[string]interface{} {
 		parsedVariable["oid"] = normalizeOID(variable.Name)
 		parsedVariable["type"] = formatType(variable)
 		parsedVariable["value"] = formatValue(variable)
+		if metadata, ok := trapsDB.getVariableMetadata(parsedVariable["oid"].(string)); ok {
+			parsedVariable["name"] = metadata.Name
+			if label, ok := formatEnumLabel(variable, metadata); ok {
+				parsedVariable["enum"] = label
+			}
+		}
 		parsedVariables = append(parsedVariables, parsedVariable)
 	}
 
@@ -140,6 +152,19 @@ func formatType(variable gosnmp.SnmpPDU) string {
 	}
 }
 
+// formatEnumLabel returns the label of an enumerated integer value, eg. `down` for ifOperStatus 2.
+func formatEnumLabel(variable gosnmp.SnmpPDU, metadata variableMetadata) (string, bool) {
+	if variable.Type != gosnmp.Integer || len(metadata.Enum) == 0 {
+		return "", false
+	}
+	value, ok := variable.Value.(int)
+	if !ok {
+		return "", false
+	}
+	label, ok := metadata.Enum[value]
+	return label, ok
+}
+
 func formatValue(variable gosnmp.SnmpPDU) interface{} {
 	switch variable.Value.(type) {
 	case []byte:
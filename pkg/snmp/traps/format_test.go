@@ -49,6 +49,83 @@ func TestFormatPacketToJSON(t *testing.T) {
 	assert.Equal(t, heartBeatName["value"], "test")
 }
 
+func TestFormatPacketToJSONResolvesOIDs(t *testing.T) {
+	setTestTrapDB(t)
+	packet := createTestPacket()
+
+	data, err := FormatPacketToJSON(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.3.6.1.4.1.8072.2.3.0.1", data["oid"])
+	assert.Equal(t, "netSnmpExampleHeartbeatNotification", data["snmpTrapName"])
+	assert.Equal(t, "NET-SNMP-EXAMPLES-MIB", data["snmpTrapMIB"])
+
+	variables, ok := data["variables"].([]map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, variables, 2)
+
+	assert.Equal(t, map[string]interface{}{
+		"oid":   "1.3.6.1.4.1.8072.2.3.2.1",
+		"name":  "netSnmpExampleHeartbeatRate",
+		"type":  "integer",
+		"value": 1024,
+	}, variables[0])
+	assert.Equal(t, map[string]interface{}{
+		"oid":   "1.3.6.1.4.1.8072.2.3.2.2",
+		"name":  "netSnmpExampleHeartbeatName",
+		"type":  "string",
+		"value": "test",
+	}, variables[1])
+}
+
+func TestFormatPacketToJSONResolvesEnums(t *testing.T) {
+	setTestTrapDB(t)
+	packet := createTestPacket()
+	packet.Content.Variables = []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+		{Name: ".1.3.6.1.2.1.2.2.1.1.12", Type: gosnmp.Integer, Value: 12},
+		{Name: ".1.3.6.1.2.1.2.2.1.7.12", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.12", Type: gosnmp.Integer, Value: 2},
+		// Unknown enum value.
+		{Name: ".1.3.6.1.2.1.2.2.1.8.13", Type: gosnmp.Integer, Value: 42},
+		// Unknown variable.
+		{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("eth0")},
+	}
+
+	data, err := FormatPacketToJSON(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "linkDown", data["snmpTrapName"])
+	assert.Equal(t, "IF-MIB", data["snmpTrapMIB"])
+
+	variables, ok := data["variables"].([]map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, []map[string]interface{}{
+		{"oid": "1.3.6.1.2.1.2.2.1.1.12", "name": "ifIndex", "type": "integer", "value": 12},
+		{"oid": "1.3.6.1.2.1.2.2.1.7.12", "name": "ifAdminStatus", "type": "integer", "value": 1, "enum": "up"},
+		{"oid": "1.3.6.1.2.1.2.2.1.8.12", "name": "ifOperStatus", "type": "integer", "value": 2, "enum": "down"},
+		{"oid": "1.3.6.1.2.1.2.2.1.8.13", "name": "ifOperStatus", "type": "integer", "value": 42},
+		{"oid": "1.3.6.1.2.1.2.2.1.2.12", "type": "string", "value": "eth0"},
+	}, variables)
+}
+
+func TestFormatPacketToJSONUnknownTrap(t *testing.T) {
+	setTestTrapDB(t)
+	packet := createTestPacket()
+	packet.Content.Variables = []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.OctetString, Value: "1.3.6.1.4.1.8072.2.3.0.42"},
+	}
+
+	data, err := FormatPacketToJSON(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.3.6.1.4.1.8072.2.3.0.42", data["oid"])
+	assert.NotContains(t, data, "snmpTrapName")
+	assert.NotContains(t, data, "snmpTrapMIB")
+}
+
 func TestFormatPacketToJSONShouldFailIfNotEnoughVariables(t *testing.T) {
 	packet := createTestPacket()
 
@@ -86,11 +163,22 @@ func TestGetTags(t *testing.T) {
 	})
 }
 
-func TestGetTagsForUnsupportedVersionShouldStillSucceed(t *testing.T) {
+func TestGetTagsV3(t *testing.T) {
 	packet := createTestPacket()
 	packet.Content.Version = gosnmp.Version3
 	packet.Content.Community = ""
 	tags := GetTags(packet)
+	assert.Equal(t, tags, []string{
+		"snmp_version:3",
+		"snmp_device:127.0.0.1",
+	})
+}
+
+func TestGetTagsForUnsupportedVersionShouldStillSucceed(t *testing.T) {
+	packet := createTestPacket()
+	packet.Content.Version = gosnmp.Version1
+	packet.Content.Community = ""
+	tags := GetTags(packet)
 	assert.Equal(t, tags, []string{
 		"snmp_version:unknown",
 		"snmp_device:127.0.0.1",
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/soniah/gosnmp"
+)
+
+var errTruncatedPacket = errors.New("truncated packet")
+
+// packetHeader contains the fields of an SNMP message header needed to pick the credentials
+// a packet must be decoded with. It is parsed before handing the packet over to GoSNMP.
+type packetHeader struct {
+	version gosnmp.SnmpVersion
+
+	// The following fields are only set for SNMPv3 packets.
+	msgFlags      gosnmp.SnmpV3MsgFlags
+	securityModel gosnmp.SnmpV3SecurityModel
+	engineID      string
+	userName      string
+	authParams    []byte
+}
+
+// parsePacketHeader parses the header of a BER-encoded SNMP message.
+// See: https://tools.ietf.org/html/rfc3412#section-6 and https://tools.ietf.org/html/rfc3414#section-2.4
+func parsePacketHeader(data []byte) (*packetHeader, error) {
+	message, _, err := readTLV(data, byte(gosnmp.Sequence))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %s", err)
+	}
+
+	version, message, err := readInteger(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgVersion: %s", err)
+	}
+	header := &packetHeader{version: gosnmp.SnmpVersion(version)}
+	if header.version != gosnmp.Version3 {
+		return header, nil
+	}
+
+	globalData, message, err := readTLV(message, byte(gosnmp.Sequence))
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgGlobalData: %s", err)
+	}
+	// Skip msgID and msgMaxSize.
+	for i := 0; i < 2; i++ {
+		if _, globalData, err = readInteger(globalData); err != nil {
+			return nil, fmt.Errorf("invalid msgGlobalData: %s", err)
+		}
+	}
+	flags, globalData, err := readTLV(globalData, byte(gosnmp.OctetString))
+	if err != nil || len(flags) != 1 {
+		return nil, fmt.Errorf("invalid msgFlags: %v", err)
+	}
+	header.msgFlags = gosnmp.SnmpV3MsgFlags(flags[0])
+	securityModel, _, err := readInteger(globalData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgSecurityModel: %s", err)
+	}
+	header.securityModel = gosnmp.SnmpV3SecurityModel(securityModel)
+
+	securityParams, _, err := readTLV(message, byte(gosnmp.OctetString))
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgSecurityParameters: %s", err)
+	}
+	if header.securityModel != gosnmp.UserSecurityModel {
+		return header, nil
+	}
+
+	usm, _, err := readTLV(securityParams, byte(gosnmp.Sequence))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UsmSecurityParameters: %s", err)
+	}
+	engineID, usm, err := readTLV(usm, byte(gosnmp.OctetString))
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgAuthoritativeEngineID: %s", err)
+	}
+	header.engineID = string(engineID)
+	// Skip msgAuthoritativeEngineBoots and msgAuthoritativeEngineTime.
+	for i := 0; i < 2; i++ {
+		if _, usm, err = readInteger(usm); err != nil {
+			return nil, fmt.Errorf("invalid UsmSecurityParameters: %s", err)
+		}
+	}
+	userName, usm, err := readTLV(usm, byte(gosnmp.OctetString))
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgUserName: %s", err)
+	}
+	header.userName = string(userName)
+	header.authParams, usm, err = readTLV(usm, byte(gosnmp.OctetString))
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgAuthenticationParameters: %s", err)
+	}
+	if _, _, err = readTLV(usm, byte(gosnmp.OctetString)); err != nil {
+		return nil, fmt.Errorf("invalid msgPrivacyParameters: %s", err)
+	}
+
+	return header, nil
+}
+
+// readTLV reads a BER-encoded value of the given type at the start of data.
+// It returns the contents of the value and the bytes following it.
+func readTLV(data []byte, expectedType byte) ([]byte, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, errTruncatedPacket
+	}
+	if data[0] != expectedType {
+		return nil, nil, fmt.Errorf("unexpected type 0x%x, expected 0x%x", data[0], expectedType)
+	}
+
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		// Long form: the low bits give the number of bytes used to encode the length.
+		numBytes := length & 0x7f
+		if numBytes == 0 || numBytes > 3 {
+			return nil, nil, fmt.Errorf("unsupported length encoding 0x%x", data[1])
+		}
+		if len(data) < offset+numBytes {
+			return nil, nil, errTruncatedPacket
+		}
+		length = 0
+		for _, b := range data[offset : offset+numBytes] {
+			length = length<<8 | int(b)
+		}
+		offset += numBytes
+	}
+
+	if len(data)-offset < length {
+		return nil, nil, errTruncatedPacket
+	}
+	return data[offset : offset+length], data[offset+length:], nil
+}
+
+// readInteger reads a BER-encoded integer at the start of data. SNMP header integers are all in
+// the 0..2^31-1 range, so the value is decoded as unsigned.
+func readInteger(data []byte) (int, []byte, error) {
+	value, rest, err := readTLV(data, byte(gosnmp.Integer))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(value) == 0 || len(value) > 5 {
+		return 0, nil, errors.New("unsupported integer value")
+	}
+	result := 0
+	for _, b := range value {
+		result = result<<8 | int(b)
+	}
+	return result, rest, nil
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureTestTrap returns the raw bytes of a trap sent by the given function to a local UDP port.
+func captureTestTrap(t *testing.T, send func(config Config)) []byte {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	send(Config{Port: parsePort(t, conn.LocalAddr().String())})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	buf := make([]byte, maxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestParsePacketHeaderV2(t *testing.T) {
+	data := captureTestTrap(t, func(config Config) {
+		sendTestV2Trap(t, config, "public")
+	})
+
+	header, err := parsePacketHeader(data)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Version2c, header.version)
+}
+
+func TestParsePacketHeaderV3(t *testing.T) {
+	user := UserV3{
+		Username:     "user",
+		AuthProtocol: "SHA512",
+		AuthKey:      "auth-password",
+		PrivProtocol: "AES",
+		PrivKey:      "priv-password",
+		EngineID:     "80001f8880e9bd0c1d12667a51",
+	}
+	data := captureTestTrap(t, func(config Config) {
+		sendTestV3Trap(t, config, user)
+	})
+
+	header, err := parsePacketHeader(data)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Version3, header.version)
+	assert.Equal(t, gosnmp.AuthPriv, header.msgFlags&gosnmp.AuthPriv)
+	assert.Equal(t, gosnmp.UserSecurityModel, header.securityModel)
+	assert.Equal(t, "\x80\x00\x1f\x88\x80\xe9\xbd\x0c\x1d\x12\x66\x7a\x51", header.engineID)
+	assert.Equal(t, "user", header.userName)
+	assert.Equal(t, 48, len(header.authParams))
+}
+
+func TestParsePacketHeaderTruncated(t *testing.T) {
+	data := captureTestTrap(t, func(config Config) {
+		sendTestV3Trap(t, config, UserV3{Username: "user", EngineID: "80001f8880e9bd0c1d12667a51"})
+	})
+
+	for _, length := range []int{0, 1, 10, 40} {
+		_, err := parsePacketHeader(data[:length])
+		assert.Error(t, err)
+	}
+}
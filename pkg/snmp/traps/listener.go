@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/soniah/gosnmp"
+)
+
+// trapListener receives SNMP trap packets over UDP and decodes them using the credentials they were sent with.
+// The GoSNMP trap listener only supports a single set of params, which doesn't allow mixing SNMPv2 communities
+// and several SNMPv3 users.
+type trapListener struct {
+	config   *Config
+	conn     *net.UDPConn
+	v2Params *gosnmp.GoSNMP
+	v3Params map[string]map[string]*gosnmp.GoSNMP // by user name, then by engine ID
+	packets  PacketsChannel
+	done     chan interface{}
+}
+
+func startSNMPListener(c *Config, packets PacketsChannel) (*trapListener, error) {
+	listener := &trapListener{
+		config:   c,
+		v2Params: c.BuildV2Params(),
+		v3Params: make(map[string]map[string]*gosnmp.GoSNMP, len(c.Users)),
+		packets:  packets,
+		done:     make(chan interface{}),
+	}
+
+	for _, user := range c.Users {
+		params, err := c.BuildV3Params(user)
+		if err != nil {
+			return nil, err
+		}
+		if listener.v3Params[user.Username] == nil {
+			listener.v3Params[user.Username] = make(map[string]*gosnmp.GoSNMP)
+		}
+		engineID := params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID
+		listener.v3Params[user.Username][engineID] = params
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", c.Addr())
+	if err != nil {
+		return nil, err
+	}
+	listener.conn, err = net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	resetUsersStatus(c.Users)
+
+	log.Infof("Start listening for traps on %s", c.Addr())
+	go listener.run()
+
+	return listener, nil
+}
+
+func (l *trapListener) run() {
+	defer close(l.done)
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				log.Debugf("Error reading from %s: %s", l.config.Addr(), err)
+				continue
+			}
+			// The connection has been closed.
+			return
+		}
+
+		// Packets are decoded in place, so hand over a copy of the buffer.
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		l.handlePacket(data, addr)
+	}
+}
+
+func (l *trapListener) handlePacket(data []byte, addr *net.UDPAddr) {
+	header, err := parsePacketHeader(data)
+	if err != nil {
+		log.Debugf("Invalid packet from %s on listener %s, dropping packet: %s", addr.String(), l.config.Addr(), err)
+		return
+	}
+
+	if header.version == gosnmp.Version3 {
+		l.handleV3Packet(data, header, addr)
+		return
+	}
+
+	p := unmarshalTrap(l.v2Params, data)
+	if p == nil {
+		log.Debugf("Unable to decode packet from %s on listener %s, dropping packet", addr.String(), l.config.Addr())
+		return
+	}
+	if err := validateCredentials(p, l.config); err != nil {
+		log.Warnf("Invalid credentials from %s on listener %s, dropping packet", addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		return
+	}
+	l.forward(p, addr)
+}
+
+func (l *trapListener) handleV3Packet(data []byte, header *packetHeader, addr *net.UDPAddr) {
+	paramsByEngineID, ok := l.v3Params[header.userName]
+	if !ok {
+		log.Warnf("Unknown SNMPv3 user %q from %s on listener %s, dropping packet", header.userName, addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		return
+	}
+
+	// The same user name may be configured once per device, with the engine ID of that device.
+	// Users configured without an engine ID don't authenticate and accept any engine.
+	params, ok := paramsByEngineID[header.engineID]
+	if !ok {
+		params, ok = paramsByEngineID[""]
+	}
+	if !ok {
+		log.Warnf("Unknown engine ID %x for SNMPv3 user %q from %s on listener %s, dropping packet", header.engineID, header.userName, addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		addUserStat(header.userName, "PacketsAuthErrors")
+		return
+	}
+
+	if err := validateV3SecurityLevel(header, params); err != nil {
+		log.Warnf("Invalid credentials for SNMPv3 user %q from %s on listener %s, dropping packet: %s", header.userName, addr.String(), l.config.Addr(), err)
+		trapsPacketsAuthErrors.Add(1)
+		addUserStat(header.userName, "PacketsAuthErrors")
+		return
+	}
+
+	// UnmarshalTrap checks the authentication digest and decrypts the packet using the keys of the user.
+	p := unmarshalTrap(params, data)
+	if p == nil {
+		log.Warnf("Unable to authenticate or decrypt packet for SNMPv3 user %q from %s on listener %s, dropping packet", header.userName, addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		addUserStat(header.userName, "PacketsAuthErrors")
+		return
+	}
+
+	addUserStat(header.userName, "Packets")
+	l.forward(p, addr)
+}
+
+// unmarshalTrap decodes a trap packet, returning nil if it cannot be decoded.
+// GoSNMP may panic on malformed data, eg when a PDU has been decrypted with the wrong privacy key.
+func unmarshalTrap(params *gosnmp.GoSNMP, data []byte) (p *gosnmp.SnmpPacket) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debugf("Unable to decode packet: %v", r)
+			p = nil
+		}
+	}()
+	return params.UnmarshalTrap(data)
+}
+
+func (l *trapListener) forward(p *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	log.Debugf("Packet received from %s on listener %s", addr.String(), l.config.Addr())
+	trapsPackets.Add(1)
+	l.packets <- &SnmpPacket{Content: p, Addr: addr}
+}
+
+func (l *trapListener) close() {
+	l.conn.Close() //nolint:errcheck
+	<-l.done
+}
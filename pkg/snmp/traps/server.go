@@ -22,11 +22,11 @@ type SnmpPacket struct {
 // PacketsChannel is the type of channels of trap packets.
 type PacketsChannel = chan *SnmpPacket
 
-// TrapServer manages an SNMPv2 trap listener.
+// TrapServer manages an SNMP trap listener.
 type TrapServer struct {
 	Addr     string
 	config   *Config
-	listener *gosnmp.TrapListener
+	listener *trapListener
 	packets  PacketsChannel
 }
 
@@ -69,9 +69,16 @@ func NewTrapServer() (*TrapServer, error) {
 		return nil, err
 	}
 
+	db, err := loadTrapDB(config.TrapDBDir)
+	if err != nil {
+		// Traps are still useful without resolved names, so don't prevent the server from starting.
+		log.Errorf("Failed to load the trap database from %s: %s", config.TrapDBDir, err)
+	}
+	trapsDB = db
+
 	packets := make(PacketsChannel, packetsChanSize)
 
-	listener, err := startSNMPv2Listener(config, packets)
+	listener, err := startSNMPListener(config, packets)
 	if err != nil {
 		return nil, err
 	}
@@ -85,54 +92,13 @@ func NewTrapServer() (*TrapServer, error) {
 	return server, nil
 }
 
-func startSNMPv2Listener(c *Config, packets PacketsChannel) (*gosnmp.TrapListener, error) {
-	listener := gosnmp.NewTrapListener()
-	listener.Params = c.BuildV2Params()
-
-	listener.OnNewTrap = func(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
-		if err := validateCredentials(p, c); err != nil {
-			log.Warnf("Invalid credentials from %s on listener %s, dropping packet", u.String(), c.Addr())
-			trapsPacketsAuthErrors.Add(1)
-			return
-		}
-		log.Debugf("Packet received from %s on listener %s", u.String(), c.Addr())
-		trapsPackets.Add(1)
-		packets <- &SnmpPacket{Content: p, Addr: u}
-	}
-
-	errors := make(chan error, 1)
-
-	// Start actually listening in the background.
-	go func() {
-		log.Infof("Start listening for traps on %s", c.Addr())
-		err := listener.Listen(c.Addr())
-		if err != nil {
-			errors <- err
-		}
-	}()
-
-	select {
-	// Wait for listener to be started and listening to traps.
-	// See: https://godoc.org/github.com/soniah/gosnmp#TrapListener.Listening
-	case <-listener.Listening():
-		break
-	// If the listener failed to start (eg because it couldn't bind to a socket),
-	// we'll get an error here.
-	case err := <-errors:
-		close(errors)
-		return nil, err
-	}
-
-	return listener, nil
-}
-
 // Stop stops the TrapServer.
 func (s *TrapServer) Stop() {
 	stopped := make(chan interface{})
 
 	go func() {
 		log.Infof("Stop listening on %s", s.config.Addr())
-		s.listener.Close()
+		s.listener.close()
 		close(stopped)
 	}()
 
@@ -8,6 +8,7 @@ package traps
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,3 +57,136 @@ func TestStartFailure(t *testing.T) {
 	require.Nil(t, failedServer)
 	require.Error(t, err)
 }
+
+var testUserV3 = UserV3{
+	Username:     "datadog",
+	AuthProtocol: "SHA",
+	AuthKey:      "auth-password",
+	PrivProtocol: "AES",
+	PrivKey:      "priv-password",
+	EngineID:     "0x80001f8880e9bd0c1d12667a5100000000",
+}
+
+func TestServerV3(t *testing.T) {
+	config := Config{Port: GetPort(t), Users: []UserV3{testUserV3}}
+	Configure(t, config)
+
+	err := StartServer()
+	require.NoError(t, err)
+	defer StopServer()
+
+	sendTestV3Trap(t, config, testUserV3)
+	packet := receivePacket(t)
+	require.NotNil(t, packet)
+	assertIsValidV3Packet(t, packet, testUserV3)
+	assertV2Variables(t, packet)
+
+	users := GetStatus()["users"].(map[string]interface{})
+	userStatus := users[testUserV3.Username].(map[string]interface{})
+	assert.Equal(t, float64(1), userStatus["Packets"])
+	assert.Equal(t, float64(0), userStatus["PacketsAuthErrors"])
+}
+
+func TestServerV3AlongsideV2(t *testing.T) {
+	config := Config{Port: GetPort(t), CommunityStrings: []string{"public"}, Users: []UserV3{testUserV3}}
+	Configure(t, config)
+
+	err := StartServer()
+	require.NoError(t, err)
+	defer StopServer()
+
+	sendTestV2Trap(t, config, "public")
+	packet := receivePacket(t)
+	require.NotNil(t, packet)
+	assertIsValidV2Packet(t, packet, config)
+
+	sendTestV3Trap(t, config, testUserV3)
+	packet = receivePacket(t)
+	require.NotNil(t, packet)
+	assertIsValidV3Packet(t, packet, testUserV3)
+}
+
+func TestServerV3SameUserSeveralEngines(t *testing.T) {
+	otherDevice := testUserV3
+	otherDevice.AuthKey = "other-auth-password"
+	otherDevice.PrivKey = "other-priv-password"
+	otherDevice.EngineID = "0x80001f8880e9bd0c1d12667a5100000001"
+
+	config := Config{Port: GetPort(t), Users: []UserV3{testUserV3, otherDevice}}
+	Configure(t, config)
+
+	err := StartServer()
+	require.NoError(t, err)
+	defer StopServer()
+
+	for _, user := range []UserV3{testUserV3, otherDevice} {
+		sendTestV3Trap(t, config, user)
+		packet := receivePacket(t)
+		require.NotNil(t, packet)
+		assertIsValidV3Packet(t, packet, user)
+	}
+
+	users := GetStatus()["users"].(map[string]interface{})
+	userStatus := users[testUserV3.Username].(map[string]interface{})
+	assert.Equal(t, float64(2), userStatus["Packets"])
+	assert.Equal(t, float64(0), userStatus["PacketsAuthErrors"])
+}
+
+func TestServerV3BadCredentials(t *testing.T) {
+	config := Config{Port: GetPort(t), Users: []UserV3{testUserV3}}
+	Configure(t, config)
+
+	err := StartServer()
+	require.NoError(t, err)
+	defer StopServer()
+
+	wrongAuthKey := testUserV3
+	wrongAuthKey.AuthKey = "wrong-password"
+
+	wrongPrivKey := testUserV3
+	wrongPrivKey.PrivKey = "wrong-password"
+
+	wrongEngineID := testUserV3
+	wrongEngineID.EngineID = "0x80001f8880e9bd0c1d12667a5100000001"
+
+	noPriv := testUserV3
+	noPriv.PrivProtocol = ""
+	noPriv.PrivKey = ""
+
+	noAuthNoPriv := UserV3{Username: testUserV3.Username, EngineID: testUserV3.EngineID}
+
+	for name, user := range map[string]UserV3{
+		"wrong auth key":  wrongAuthKey,
+		"wrong priv key":  wrongPrivKey,
+		"wrong engine id": wrongEngineID,
+		"no priv":         noPriv,
+		"no auth no priv": noAuthNoPriv,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sendTestV3Trap(t, config, user)
+			assertNoPacketReceived(t)
+		})
+	}
+
+	users := GetStatus()["users"].(map[string]interface{})
+	userStatus := users[testUserV3.Username].(map[string]interface{})
+	assert.Equal(t, float64(0), userStatus["Packets"])
+	assert.Equal(t, float64(5), userStatus["PacketsAuthErrors"])
+}
+
+func TestServerV3UnknownUser(t *testing.T) {
+	config := Config{Port: GetPort(t), Users: []UserV3{testUserV3}}
+	Configure(t, config)
+
+	err := StartServer()
+	require.NoError(t, err)
+	defer StopServer()
+
+	unknownUser := testUserV3
+	unknownUser.Username = "unknown"
+	sendTestV3Trap(t, config, unknownUser)
+	assertNoPacketReceived(t)
+
+	users := GetStatus()["users"].(map[string]interface{})
+	assert.NotContains(t, users, "unknown")
+}
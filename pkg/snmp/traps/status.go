@@ -14,11 +14,31 @@ var (
 	trapsExpvars           = expvar.NewMap("snmp_traps")
 	trapsPackets           = expvar.Int{}
 	trapsPacketsAuthErrors = expvar.Int{}
+	trapsUsers             = expvar.Map{}
 )
 
 func init() {
 	trapsExpvars.Set("Packets", &trapsPackets)
 	trapsExpvars.Set("PacketsAuthErrors", &trapsPacketsAuthErrors)
+	trapsExpvars.Set("Users", &trapsUsers)
+}
+
+// resetUsersStatus sets up per-user packet counters for the given SNMPv3 users.
+func resetUsersStatus(users []UserV3) {
+	trapsUsers.Init()
+	for _, user := range users {
+		userExpvars := new(expvar.Map).Init()
+		userExpvars.Add("Packets", 0)
+		userExpvars.Add("PacketsAuthErrors", 0)
+		trapsUsers.Set(user.Username, userExpvars)
+	}
+}
+
+// addUserStat increments a packet counter of a configured SNMPv3 user.
+func addUserStat(username string, key string) {
+	if userExpvars, ok := trapsUsers.Get(username).(*expvar.Map); ok {
+		userExpvars.Add(key, 1)
+	}
 }
 
 // GetStatus returns key-value data for use in status reporting of the traps server.
@@ -28,6 +48,14 @@ func GetStatus() map[string]interface{} {
 	metricsJSON := []byte(expvar.Get("snmp_traps").String())
 	metrics := make(map[string]interface{})
 	json.Unmarshal(metricsJSON, &metrics) //nolint:errcheck
+
+	// Per-user counters are reported separately from the global ones.
+	if users, ok := metrics["Users"].(map[string]interface{}); ok {
+		delete(metrics, "Users")
+		if len(users) > 0 {
+			status["users"] = users
+		}
+	}
 	status["metrics"] = metrics
 
 	if startError != nil {
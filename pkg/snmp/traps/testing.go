@@ -84,6 +84,23 @@ func sendTestV2Trap(t *testing.T, trapConfig Config, community string) *gosnmp.G
 	return params
 }
 
+func sendTestV3Trap(t *testing.T, trapConfig Config, user UserV3) *gosnmp.GoSNMP {
+	params, err := trapConfig.BuildV3Params(user)
+	require.NoError(t, err)
+	params.Timeout = 1 * time.Second // Must be non-zero when sending traps.
+	params.Retries = 1               // Must be non-zero when sending traps.
+
+	err = params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := gosnmp.SnmpTrap{Variables: NetSNMPExampleHeartbeatNotificationVariables}
+	_, err = params.SendTrap(trap)
+	require.NoError(t, err)
+
+	return params
+}
+
 // receivePacket waits for a received trap packet and returns it.
 func receivePacket(t *testing.T) *SnmpPacket {
 	select {
@@ -106,6 +123,13 @@ func assertIsValidV2Packet(t *testing.T, packet *SnmpPacket, trapConfig Config)
 	require.True(t, communityValid)
 }
 
+func assertIsValidV3Packet(t *testing.T, packet *SnmpPacket, user UserV3) {
+	require.Equal(t, gosnmp.Version3, packet.Content.Version)
+	usm, ok := packet.Content.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	require.True(t, ok)
+	require.Equal(t, user.Username, usm.UserName)
+}
+
 func assertV2Variables(t *testing.T, packet *SnmpPacket) {
 	variables := packet.Content.Variables
 	assert.Equal(t, 4, len(variables))
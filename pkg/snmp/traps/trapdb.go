@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// trapMetadata describes a trap (a NOTIFICATION-TYPE or TRAP-TYPE MIB definition).
+type trapMetadata struct {
+	Name        string `json:"name" yaml:"name"`
+	MIB         string `json:"mib" yaml:"mib"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// variableMetadata describes an object that can be sent as a trap variable binding.
+type variableMetadata struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description" yaml:"description"`
+	Enum        map[int]string `json:"enum" yaml:"enum"`
+}
+
+// trapDBFile is the content of a trap database file, as produced by compiling MIBs. Example:
+//
+//	traps:
+//	  1.3.6.1.6.3.1.1.5.3:
+//	    name: linkDown
+//	    mib: IF-MIB
+//	vars:
+//	  1.3.6.1.2.1.2.2.1.8:
+//	    name: ifOperStatus
+//	    enum:
+//	      1: up
+//	      2: down
+type trapDBFile struct {
+	Traps map[string]trapMetadata     `json:"traps" yaml:"traps"`
+	Vars  map[string]variableMetadata `json:"vars" yaml:"vars"`
+}
+
+// trapDB resolves trap and variable OIDs to the names defined in MIBs.
+// A nil trapDB is valid and resolves nothing.
+type trapDB struct {
+	traps map[string]trapMetadata
+	vars  map[string]variableMetadata
+}
+
+// trapsDB is the database used to format trap packets. It is loaded when the trap server starts.
+var trapsDB *trapDB
+
+// getDefaultTrapDBDir returns the directory trap database files are loaded from by default.
+func getDefaultTrapDBDir() string {
+	return filepath.Join(config.Datadog.GetString("confd_path"), "snmp.d", "traps_db")
+}
+
+// loadTrapDB loads all the JSON and YAML trap database files of a directory. Files are loaded in
+// lexical order, so that a definition can be overridden by a file whose name sorts later.
+// Files that cannot be read are skipped.
+func loadTrapDB(dir string) (*trapDB, error) {
+	db := &trapDB{
+		traps: make(map[string]trapMetadata),
+		vars:  make(map[string]variableMetadata),
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Debugf("No trap database found in %s, trap OIDs will not be resolved", dir)
+			return db, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		filePath := filepath.Join(dir, file.Name())
+		content, err := readTrapDBFile(filePath)
+		if err != nil {
+			log.Warnf("Skipping trap database file %s: %s", filePath, err)
+			continue
+		}
+		if content == nil {
+			continue
+		}
+		for oid, trap := range content.Traps {
+			db.traps[normalizeOID(oid)] = trap
+		}
+		for oid, variable := range content.Vars {
+			db.vars[normalizeOID(oid)] = variable
+		}
+	}
+
+	log.Infof("Loaded %d trap and %d variable definitions from %s", len(db.traps), len(db.vars), dir)
+	return db, nil
+}
+
+// readTrapDBFile parses a trap database file. It returns nil if the file is not a trap database file.
+func readTrapDBFile(filePath string) (*trapDBFile, error) {
+	var unmarshal func([]byte, interface{}) error
+	switch filepath.Ext(filePath) {
+	case ".json":
+		unmarshal = json.Unmarshal
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	default:
+		return nil, nil
+	}
+
+	buf, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var content trapDBFile
+	if err := unmarshal(buf, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse: %s", err)
+	}
+	return &content, nil
+}
+
+// getTrapMetadata returns the metadata of a trap OID.
+func (db *trapDB) getTrapMetadata(oid string) (trapMetadata, bool) {
+	if db == nil {
+		return trapMetadata{}, false
+	}
+	trap, ok := db.traps[oid]
+	return trap, ok
+}
+
+// getVariableMetadata returns the metadata of a variable binding OID. Variable bindings usually
+// reference an instance of an object (eg. `ifOperStatus.3`), so index components are stripped
+// from the end of the OID until a known object is found.
+func (db *trapDB) getVariableMetadata(oid string) (variableMetadata, bool) {
+	if db == nil {
+		return variableMetadata{}, false
+	}
+	for {
+		if variable, ok := db.vars[oid]; ok {
+			return variable, true
+		}
+		i := strings.LastIndex(oid, ".")
+		if i < 0 {
+			return variableMetadata{}, false
+		}
+		oid = oid[:i]
+	}
+}
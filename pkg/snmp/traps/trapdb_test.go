@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestTrapDB makes the formatter use the test trap database until the end of the test.
+func setTestTrapDB(t *testing.T) {
+	db, err := loadTrapDB("testdata/traps_db")
+	require.NoError(t, err)
+	trapsDB = db
+	t.Cleanup(func() { trapsDB = nil })
+}
+
+func TestLoadTrapDB(t *testing.T) {
+	db, err := loadTrapDB("testdata/traps_db")
+	require.NoError(t, err)
+
+	// The invalid file is skipped, other files are merged.
+	assert.Len(t, db.traps, 3)
+	assert.Len(t, db.vars, 5)
+
+	trap, ok := db.getTrapMetadata("1.3.6.1.6.3.1.1.5.3")
+	require.True(t, ok)
+	assert.Equal(t, "linkDown", trap.Name)
+	assert.Equal(t, "IF-MIB", trap.MIB)
+
+	trap, ok = db.getTrapMetadata("1.3.6.1.4.1.8072.2.3.0.1")
+	require.True(t, ok)
+	assert.Equal(t, "netSnmpExampleHeartbeatNotification", trap.Name)
+	assert.Equal(t, "NET-SNMP-EXAMPLES-MIB", trap.MIB)
+
+	_, ok = db.getTrapMetadata("1.3.6.1.6.3.1.1.5.1")
+	assert.False(t, ok)
+}
+
+func TestLoadTrapDBMissingDirectory(t *testing.T) {
+	db, err := loadTrapDB("testdata/does_not_exist")
+	require.NoError(t, err)
+
+	_, ok := db.getTrapMetadata("1.3.6.1.6.3.1.1.5.3")
+	assert.False(t, ok)
+}
+
+func TestGetVariableMetadata(t *testing.T) {
+	db, err := loadTrapDB("testdata/traps_db")
+	require.NoError(t, err)
+
+	variable, ok := db.getVariableMetadata("1.3.6.1.2.1.2.2.1.8")
+	require.True(t, ok)
+	assert.Equal(t, "ifOperStatus", variable.Name)
+	assert.Equal(t, "down", variable.Enum[2])
+
+	// Instance OIDs resolve to the object they are an instance of.
+	variable, ok = db.getVariableMetadata("1.3.6.1.2.1.2.2.1.8.12")
+	require.True(t, ok)
+	assert.Equal(t, "ifOperStatus", variable.Name)
+
+	_, ok = db.getVariableMetadata("1.3.6.1.2.1.2.2.1.2.12")
+	assert.False(t, ok)
+}
+
+func TestNilTrapDB(t *testing.T) {
+	var db *trapDB
+
+	_, ok := db.getTrapMetadata("1.3.6.1.6.3.1.1.5.3")
+	assert.False(t, ok)
+	_, ok = db.getVariableMetadata("1.3.6.1.2.1.2.2.1.8.12")
+	assert.False(t, ok)
+}
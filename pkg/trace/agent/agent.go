@@ -48,6 +48,10 @@ type Agent struct {
 	TraceWriter        *writer.TraceWriter
 	StatsWriter        *writer.StatsWriter
 
+	// TailSampler is set when tail-based sampling is enabled. It then receives all
+	// traces and makes the final sampling decision once they are complete.
+	TailSampler *TailSampler
+
 	// obfuscator is used to obfuscate sensitive data from various span
 	// tags based on their type.
 	obfuscator *obfuscate.Obfuscator
@@ -70,7 +74,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 	statsChan := make(chan []stats.Bucket, 100)
 
 	agnt := &Agent{
-		Concentrator:       stats.NewConcentrator(conf.BucketInterval.Nanoseconds(), statsChan),
+		Concentrator:       stats.NewConcentrator(conf, statsChan),
 		Blacklister:        filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:           filters.NewReplacer(conf.ReplaceTags),
 		ScoreSampler:       NewScoreSampler(conf),
@@ -86,6 +90,9 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		ctx:                ctx,
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt)
+	if conf.TailSampling != nil && conf.TailSampling.Enabled {
+		agnt.TailSampler = NewTailSampler(conf.TailSampling, agnt.TraceWriter.In)
+	}
 	return agnt
 }
 
@@ -101,6 +108,9 @@ func (a *Agent) Run() {
 	} {
 		starter.Start()
 	}
+	if a.TailSampler != nil {
+		a.TailSampler.Start()
+	}
 
 	go a.TraceWriter.Run()
 	go a.StatsWriter.Run()
@@ -135,6 +145,10 @@ func (a *Agent) loop() {
 				log.Error(err)
 			}
 			a.Concentrator.Stop()
+			if a.TailSampler != nil {
+				// flush the buffered traces before stopping the writer
+				a.TailSampler.Stop()
+			}
 			a.TraceWriter.Stop()
 			a.StatsWriter.Stop()
 			a.ScoreSampler.Stop()
@@ -226,8 +240,10 @@ func (a *Agent) Process(p *api.Payload, sublayerCalculator *stats.SublayerCalcul
 		}
 
 		events, keep := a.sample(ts, pt)
+		// with tail-based sampling, any trace may end up being kept
+		mayKeep := keep || a.TailSampler != nil
 
-		if sublayerCalculator.ShouldCompute(keep) {
+		if sublayerCalculator.ShouldCompute(mayKeep) {
 			pt.Sublayers = make(map[*pb.Span][]stats.SublayerValue)
 			subtraces := stats.ExtractSubtraces(t, root)
 			for _, subtrace := range subtraces {
@@ -235,7 +251,7 @@ func (a *Agent) Process(p *api.Payload, sublayerCalculator *stats.SublayerCalcul
 				if sublayerCalculator.WithStats() {
 					pt.Sublayers[subtrace.Root] = subtraceSublayers
 				}
-				if keep {
+				if mayKeep {
 					stats.SetSublayersOnSpan(subtrace.Root, subtraceSublayers)
 				}
 			}
@@ -246,7 +262,9 @@ func (a *Agent) Process(p *api.Payload, sublayerCalculator *stats.SublayerCalcul
 			Env:           pt.Env,
 			SublayersOnly: p.ClientComputedStats,
 		})
-		if keep {
+		if a.TailSampler != nil {
+			a.TailSampler.Add(t, keep)
+		} else if keep {
 			ss.Traces = append(ss.Traces, traceutil.APITrace(t))
 			ss.Size += t.Msgsize()
 			ss.SpanCount += int64(len(t))
@@ -0,0 +1,348 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// tailDecisionCacheSize is the number of past decisions remembered to sample
+	// the spans of already decided traces which arrive late.
+	tailDecisionCacheSize = 10000
+
+	// tailFlushPeriod is the frequency at which the tail sampler looks for traces
+	// that waited long enough.
+	tailFlushPeriod = time.Second
+)
+
+// tailReason is the reason for which a trace is kept by the tail sampler.
+type tailReason int
+
+const (
+	tailReasonError tailReason = iota
+	tailReasonLatency
+	tailReasonRule
+	tailReasonHead
+	tailReasonCount // number of reasons, not a reason
+)
+
+// tailReasons holds the value of the "reason" tag of each tailReason.
+var tailReasons = [tailReasonCount]string{
+	tailReasonError:   "error",
+	tailReasonLatency: "latency",
+	tailReasonRule:    "rule",
+	tailReasonHead:    "head",
+}
+
+// TailSampler buffers the spans of traces by trace ID for a configurable window and then
+// makes a sampling decision on the complete trace. Traces are kept if they contain an error,
+// exceed a latency threshold, match a tag rule or if any of their chunks was kept by the
+// head-based samplers, unless the user explicitly rejected them. Kept traces are sent to out.
+//
+// The number of buffered spans is bounded: when the limit is reached, the oldest traces are
+// decided early (evicted) to make room for the new ones.
+type TailSampler struct {
+	// Variables accessed through the 'atomic' package must be 64bits aligned.
+	evicted      int64
+	lateSpans    int64
+	dropped      int64
+	keptByReason [tailReasonCount]int64
+
+	conf             *config.TailSamplingConfig
+	wait             time.Duration
+	latencyThreshold int64 // nanoseconds, 0 if disabled
+	out              chan<- *writer.SampledSpans
+
+	mu       sync.Mutex
+	traces   map[uint64]*tailTrace
+	order    *list.List // buffered traces, oldest first
+	spans    int        // number of buffered spans
+	decision *decisionCache
+
+	exit   chan struct{}
+	exitWG sync.WaitGroup
+}
+
+// tailTrace holds the buffered chunks of a trace.
+type tailTrace struct {
+	id       uint64
+	spans    pb.Trace
+	headKeep bool      // true if any chunk was kept by head-based sampling
+	userDrop bool      // true if any chunk was rejected by the user (PriorityUserDrop)
+	deadline time.Time // time at which a decision is made
+	elem     *list.Element
+}
+
+// NewTailSampler returns a new TailSampler sending kept traces to out.
+func NewTailSampler(conf *config.TailSamplingConfig, out chan<- *writer.SampledSpans) *TailSampler {
+	return &TailSampler{
+		conf:             conf,
+		wait:             time.Duration(conf.DecisionWaitSeconds * float64(time.Second)),
+		latencyThreshold: int64(conf.LatencyThresholdMs * float64(time.Millisecond)),
+		out:              out,
+		traces:           make(map[uint64]*tailTrace),
+		order:            list.New(),
+		decision:         newDecisionCache(tailDecisionCacheSize),
+		exit:             make(chan struct{}),
+	}
+}
+
+// Start starts the goroutine making decisions on traces that waited long enough.
+func (s *TailSampler) Start() {
+	s.exitWG.Add(1)
+	go func() {
+		defer watchdog.LogOnPanic()
+		defer s.exitWG.Done()
+		s.run()
+	}()
+}
+
+func (s *TailSampler) run() {
+	flush := time.NewTicker(tailFlushPeriod)
+	defer flush.Stop()
+	report := time.NewTicker(10 * time.Second)
+	defer report.Stop()
+	for {
+		select {
+		case now := <-flush.C:
+			s.flush(now)
+		case <-report.C:
+			s.report()
+		case <-s.exit:
+			log.Info("Exiting tail sampler, deciding on remaining traces")
+			s.flush(time.Now().Add(s.wait))
+			s.report()
+			return
+		}
+	}
+}
+
+// Stop makes a decision on all buffered traces and stops the sampler.
+func (s *TailSampler) Stop() {
+	close(s.exit)
+	s.exitWG.Wait()
+}
+
+// Add buffers the given trace chunk. headKeep reports whether the chunk was kept
+// by the head-based samplers.
+func (s *TailSampler) Add(t pb.Trace, headKeep bool) {
+	if len(t) == 0 {
+		return
+	}
+	id := t[0].TraceID
+	now := time.Now()
+
+	s.mu.Lock()
+	if keep, ok := s.decision.get(id); ok {
+		// the trace was already decided, apply the same decision to late spans
+		s.mu.Unlock()
+		atomic.AddInt64(&s.lateSpans, int64(len(t)))
+		if keep {
+			s.send([]pb.Trace{t})
+		}
+		return
+	}
+	tt, ok := s.traces[id]
+	if !ok {
+		tt = &tailTrace{id: id, deadline: now.Add(s.wait)}
+		tt.elem = s.order.PushBack(tt)
+		s.traces[id] = tt
+	}
+	tt.spans = append(tt.spans, t...)
+	tt.headKeep = tt.headKeep || headKeep
+	if p, ok := sampler.GetSamplingPriority(traceutil.GetRoot(t)); ok && p == sampler.PriorityUserDrop {
+		tt.userDrop = true
+	}
+	s.spans += len(t)
+
+	var kept []pb.Trace
+	for s.spans > s.conf.MaxBufferedSpans && s.order.Len() > 0 {
+		atomic.AddInt64(&s.evicted, 1)
+		if t, keep := s.decide(s.order.Front().Value.(*tailTrace)); keep {
+			kept = append(kept, t)
+		}
+	}
+	s.mu.Unlock()
+	s.send(kept)
+}
+
+// flush makes a decision on all traces whose deadline is before now.
+func (s *TailSampler) flush(now time.Time) {
+	var kept []pb.Trace
+	s.mu.Lock()
+	for s.order.Len() > 0 {
+		tt := s.order.Front().Value.(*tailTrace)
+		if tt.deadline.After(now) {
+			// traces are ordered by deadline
+			break
+		}
+		if t, keep := s.decide(tt); keep {
+			kept = append(kept, t)
+		}
+	}
+	s.mu.Unlock()
+	s.send(kept)
+}
+
+// decide removes the trace from the buffer and returns it along with the sampling decision.
+// Callers must guard!
+func (s *TailSampler) decide(tt *tailTrace) (pb.Trace, bool) {
+	s.order.Remove(tt.elem)
+	delete(s.traces, tt.id)
+	s.spans -= len(tt.spans)
+
+	reason, keep := s.keepReason(tt)
+	s.decision.add(tt.id, keep)
+	if keep {
+		atomic.AddInt64(&s.keptByReason[reason], 1)
+	} else {
+		atomic.AddInt64(&s.dropped, 1)
+	}
+	return tt.spans, keep
+}
+
+// keepReason returns the reason why the trace should be kept, and false if it should be dropped.
+// Traces rejected by the user are always dropped.
+func (s *TailSampler) keepReason(tt *tailTrace) (tailReason, bool) {
+	if tt.userDrop {
+		return 0, false
+	}
+	if s.conf.KeepErrors && traceContainsError(tt.spans) {
+		return tailReasonError, true
+	}
+	if s.latencyThreshold > 0 && traceDuration(tt.spans) >= s.latencyThreshold {
+		return tailReasonLatency, true
+	}
+	if len(s.conf.Rules) > 0 && traceMatchesRules(tt.spans, s.conf.Rules) {
+		return tailReasonRule, true
+	}
+	if tt.headKeep {
+		return tailReasonHead, true
+	}
+	return 0, false
+}
+
+// send sends the given traces to the trace writer.
+func (s *TailSampler) send(traces []pb.Trace) {
+	if len(traces) == 0 {
+		return
+	}
+	ss := new(writer.SampledSpans)
+	for _, t := range traces {
+		ss.Traces = append(ss.Traces, traceutil.APITrace(t))
+		ss.Size += t.Msgsize()
+		ss.SpanCount += int64(len(t))
+		if ss.Size > writer.MaxPayloadSize {
+			s.out <- ss
+			ss = new(writer.SampledSpans)
+		}
+	}
+	if ss.Size > 0 {
+		s.out <- ss
+	}
+}
+
+func (s *TailSampler) report() {
+	s.mu.Lock()
+	spans, traces := s.spans, len(s.traces)
+	s.mu.Unlock()
+	metrics.Gauge("datadog.trace_agent.sampler.tail.buffered_spans", float64(spans), nil, 1)
+	metrics.Gauge("datadog.trace_agent.sampler.tail.buffered_traces", float64(traces), nil, 1)
+	metrics.Count("datadog.trace_agent.sampler.tail.evicted", atomic.SwapInt64(&s.evicted, 0), nil, 1)
+	metrics.Count("datadog.trace_agent.sampler.tail.late_spans", atomic.SwapInt64(&s.lateSpans, 0), nil, 1)
+	metrics.Count("datadog.trace_agent.sampler.tail.dropped", atomic.SwapInt64(&s.dropped, 0), nil, 1)
+	for i, reason := range tailReasons {
+		metrics.Count("datadog.trace_agent.sampler.tail.kept", atomic.SwapInt64(&s.keptByReason[i], 0), []string{"reason:" + reason}, 1)
+	}
+}
+
+// traceDuration returns the time elapsed between the start of the first span of
+// the trace and the end of its last span.
+func traceDuration(t pb.Trace) int64 {
+	start, end := t[0].Start, t[0].Start+t[0].Duration
+	for _, span := range t[1:] {
+		if span.Start < start {
+			start = span.Start
+		}
+		if e := span.Start + span.Duration; e > end {
+			end = e
+		}
+	}
+	return end - start
+}
+
+// traceMatchesRules reports whether any span of the trace matches any of the rules.
+func traceMatchesRules(t pb.Trace, rules []*config.TailSamplingRule) bool {
+	for _, span := range t {
+		for _, rule := range rules {
+			var v string
+			switch rule.Name {
+			case "resource.name":
+				v = span.Resource
+			case "service.name":
+				v = span.Service
+			default:
+				var ok bool
+				if v, ok = span.Meta[rule.Name]; !ok {
+					continue
+				}
+			}
+			if rule.Re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decisionCache remembers the most recent sampling decisions, by trace ID.
+// It is not safe for concurrent use.
+type decisionCache struct {
+	decisions map[uint64]bool
+	ids       []uint64 // ring buffer of the IDs in decisions, in insertion order
+	next      int      // index in ids of the next insertion
+}
+
+func newDecisionCache(size int) *decisionCache {
+	return &decisionCache{
+		decisions: make(map[uint64]bool, size),
+		ids:       make([]uint64, 0, size),
+	}
+}
+
+// add records the decision for the given trace, forgetting the oldest one if full.
+func (c *decisionCache) add(id uint64, keep bool) {
+	if _, ok := c.decisions[id]; ok {
+		c.decisions[id] = keep
+		return
+	}
+	if len(c.ids) < cap(c.ids) {
+		c.ids = append(c.ids, id)
+	} else {
+		delete(c.decisions, c.ids[c.next])
+		c.ids[c.next] = id
+		c.next = (c.next + 1) % len(c.ids)
+	}
+	c.decisions[id] = keep
+}
+
+func (c *decisionCache) get(id uint64) (keep, ok bool) {
+	keep, ok = c.decisions[id]
+	return keep, ok
+}
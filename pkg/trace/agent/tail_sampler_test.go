@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTailSampler(conf *config.TailSamplingConfig) (*TailSampler, chan *writer.SampledSpans) {
+	out := make(chan *writer.SampledSpans, 100)
+	if conf.MaxBufferedSpans == 0 {
+		conf.MaxBufferedSpans = 1000
+	}
+	return NewTailSampler(conf, out), out
+}
+
+// keptTraces returns the IDs of the traces received on out.
+func keptTraces(out chan *writer.SampledSpans) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case ss := <-out:
+			for _, t := range ss.Traces {
+				ids = append(ids, t.TraceID)
+			}
+		default:
+			return ids
+		}
+	}
+}
+
+func tailTestSpan(traceID, spanID uint64, start, duration int64) *pb.Span {
+	return &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		Service:  "svc",
+		Name:     "op",
+		Resource: "GET /",
+		Start:    start,
+		Duration: duration,
+		Meta:     map[string]string{},
+	}
+}
+
+func TestTailSamplerDecisions(t *testing.T) {
+	s, out := newTestTailSampler(&config.TailSamplingConfig{
+		KeepErrors:         true,
+		LatencyThresholdMs: 100,
+		Rules: []*config.TailSamplingRule{
+			{Name: "customer_tier", Re: regexp.MustCompile("^gold$")},
+			{Name: "resource.name", Re: regexp.MustCompile("^POST")},
+		},
+	})
+
+	// error in a chunk received after the root
+	s.Add(pb.Trace{tailTestSpan(1, 1, 0, 10)}, false)
+	errSpan := tailTestSpan(1, 2, 1, 5)
+	errSpan.Error = 1
+	s.Add(pb.Trace{errSpan}, false)
+	// slow trace spread over two chunks
+	s.Add(pb.Trace{tailTestSpan(2, 1, 0, 60*1e6)}, false)
+	s.Add(pb.Trace{tailTestSpan(2, 2, 50*1e6, 60*1e6)}, false)
+	// fast trace
+	s.Add(pb.Trace{tailTestSpan(3, 1, 0, 90*1e6)}, false)
+	// tag rule
+	tagged := tailTestSpan(4, 1, 0, 10)
+	tagged.Meta["customer_tier"] = "gold"
+	s.Add(pb.Trace{tagged}, false)
+	// resource rule
+	post := tailTestSpan(5, 1, 0, 10)
+	post.Resource = "POST /checkout"
+	s.Add(pb.Trace{post}, false)
+	// head sampling decision
+	s.Add(pb.Trace{tailTestSpan(6, 1, 0, 10)}, false)
+	s.Add(pb.Trace{tailTestSpan(6, 2, 0, 10)}, true)
+	// error in a trace rejected by the user
+	userDrop := tailTestSpan(7, 1, 0, 10)
+	userDrop.Error = 1
+	sampler.SetSamplingPriority(userDrop, sampler.PriorityUserDrop)
+	s.Add(pb.Trace{userDrop}, false)
+
+	assert.Empty(t, keptTraces(out), "traces must be buffered until their deadline")
+	assert.Equal(t, 10, s.spans)
+
+	s.flush(time.Now())
+	assert.Equal(t, []uint64{1, 2, 4, 5, 6}, keptTraces(out))
+	assert.Equal(t, 0, s.spans)
+	assert.Empty(t, s.traces)
+	assert.EqualValues(t, 2, s.dropped)
+	assert.Equal(t, [tailReasonCount]int64{
+		tailReasonError:   1,
+		tailReasonLatency: 1,
+		tailReasonRule:    2,
+		tailReasonHead:    1,
+	}, s.keptByReason)
+}
+
+func TestTailSamplerWait(t *testing.T) {
+	s, out := newTestTailSampler(&config.TailSamplingConfig{DecisionWaitSeconds: 10})
+	s.Add(pb.Trace{tailTestSpan(1, 1, 0, 10)}, true)
+
+	s.flush(time.Now())
+	assert.Empty(t, keptTraces(out))
+	assert.Equal(t, 1, s.spans)
+
+	s.flush(time.Now().Add(11 * time.Second))
+	assert.Equal(t, []uint64{1}, keptTraces(out))
+}
+
+func TestTailSamplerLateSpans(t *testing.T) {
+	s, out := newTestTailSampler(&config.TailSamplingConfig{})
+	s.Add(pb.Trace{tailTestSpan(1, 1, 0, 10)}, true)
+	s.Add(pb.Trace{tailTestSpan(2, 1, 0, 10)}, false)
+	s.flush(time.Now())
+	assert.Equal(t, []uint64{1}, keptTraces(out))
+
+	// late spans follow the decision made on their trace
+	s.Add(pb.Trace{tailTestSpan(1, 2, 0, 10)}, false)
+	s.Add(pb.Trace{tailTestSpan(2, 2, 0, 10)}, true)
+	assert.Equal(t, []uint64{1}, keptTraces(out))
+	assert.Equal(t, 0, s.spans)
+	assert.EqualValues(t, 2, s.lateSpans)
+}
+
+func TestTailSamplerEviction(t *testing.T) {
+	s, out := newTestTailSampler(&config.TailSamplingConfig{
+		DecisionWaitSeconds: 10,
+		MaxBufferedSpans:    3,
+	})
+	s.Add(pb.Trace{tailTestSpan(1, 1, 0, 10), tailTestSpan(1, 2, 0, 10)}, true)
+	s.Add(pb.Trace{tailTestSpan(2, 1, 0, 10)}, true)
+	assert.Empty(t, keptTraces(out))
+
+	// going over the limit evicts the oldest trace
+	s.Add(pb.Trace{tailTestSpan(3, 1, 0, 10)}, true)
+	assert.Equal(t, []uint64{1}, keptTraces(out))
+	assert.Equal(t, 2, s.spans)
+	assert.EqualValues(t, 1, s.evicted)
+
+	// stopping flushes everything
+	s.Start()
+	s.Stop()
+	assert.Equal(t, []uint64{2, 3}, keptTraces(out))
+	assert.Equal(t, 0, s.spans)
+}
+
+func TestDecisionCache(t *testing.T) {
+	c := newDecisionCache(2)
+	c.add(1, true)
+	c.add(2, false)
+	keep, ok := c.get(1)
+	assert.True(t, ok)
+	assert.True(t, keep)
+	keep, ok = c.get(2)
+	assert.True(t, ok)
+	assert.False(t, keep)
+
+	c.add(3, true)
+	_, ok = c.get(1)
+	assert.False(t, ok, "oldest decision must be forgotten")
+	_, ok = c.get(3)
+	assert.True(t, ok)
+	c.add(4, true)
+	_, ok = c.get(2)
+	assert.False(t, ok)
+	assert.Len(t, c.decisions, 2)
+}
@@ -119,6 +119,7 @@ func (r *HTTPReceiver) buildMux() *http.ServeMux {
 	mux.HandleFunc("/v0.4/services", r.handleWithVersion(v04, r.handleServices))
 	mux.HandleFunc("/v0.5/traces", r.handleWithVersion(v05, r.handleTraces))
 	mux.HandleFunc("/v0.5/stats", r.handleStats)
+	mux.HandleFunc("/v1/traces", r.handleOTLPTraces)
 	mux.Handle("/profiling/v1/input", r.profileProxyHandler())
 
 	return mux
@@ -480,6 +481,11 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 		ClientComputedTopLevel: req.Header.Get(headerComputedTopLevel) != "",
 		ClientComputedStats:    req.Header.Get(headerComputedStats) != "",
 	}
+	r.sendPayload(payload)
+}
+
+// sendPayload sends the payload to the output channel without ever dropping it.
+func (r *HTTPReceiver) sendPayload(payload *Payload) {
 	select {
 	case r.out <- payload:
 		// ok
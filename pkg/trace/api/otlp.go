@@ -0,0 +1,857 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// This file decodes the subset of the OTLP trace protocol (opentelemetry-proto,
+// collector/trace/v1/trace_service.proto) needed to convert OpenTelemetry spans
+// into Datadog spans, from both its protobuf and JSON encodings.
+
+// otlpSpanKind mirrors the OTLP Span.SpanKind enum.
+type otlpSpanKind int32
+
+// OTLP span kinds
+const (
+	otlpSpanKindUnspecified otlpSpanKind = iota
+	otlpSpanKindInternal
+	otlpSpanKindServer
+	otlpSpanKindClient
+	otlpSpanKindProducer
+	otlpSpanKindConsumer
+)
+
+// otlpStatusCodeError is the OTLP Status.StatusCode of failed spans.
+const otlpStatusCodeError = 2
+
+// otlpDefaultServiceName is the service of the spans whose resource has no service.name.
+const otlpDefaultServiceName = "OTLPResourceNoServiceName"
+
+var otlpSpanKindNames = map[otlpSpanKind]string{
+	otlpSpanKindUnspecified: "unspecified",
+	otlpSpanKindInternal:    "internal",
+	otlpSpanKindServer:      "server",
+	otlpSpanKindClient:      "client",
+	otlpSpanKindProducer:    "producer",
+	otlpSpanKindConsumer:    "consumer",
+}
+
+// String returns the lowercase name of the span kind.
+func (k otlpSpanKind) String() string {
+	if name, ok := otlpSpanKindNames[k]; ok {
+		return name
+	}
+	return otlpSpanKindNames[otlpSpanKindUnspecified]
+}
+
+// UnmarshalJSON accepts both the integer and the SPAN_KIND_* string representations.
+func (k *otlpSpanKind) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		var n int32
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		*k = otlpSpanKind(n)
+		return nil
+	}
+	name = strings.ToLower(strings.TrimPrefix(name, "SPAN_KIND_"))
+	for kind, kindName := range otlpSpanKindNames {
+		if kindName == name {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown span kind %q", name)
+}
+
+// otlpUint64 is a 64-bit integer which the OTLP JSON encoding represents
+// either as a number or as a decimal string.
+type otlpUint64 uint64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *otlpUint64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseUint(string(bytes.Trim(b, `"`)), 10, 64)
+	*n = otlpUint64(v)
+	return err
+}
+
+// otlpInt64 is the signed counterpart of otlpUint64.
+type otlpInt64 int64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *otlpInt64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(b, `"`)), 10, 64)
+	*n = otlpInt64(v)
+	return err
+}
+
+// otlpID is a trace or span ID, hex encoded in the OTLP JSON encoding.
+// Base64 is also accepted, as used by early versions of the OTLP/HTTP JSON encoding.
+type otlpID []byte
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *otlpID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if v, err := hex.DecodeString(s); err == nil {
+		*id = v
+		return nil
+	}
+	v, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid trace or span ID %q", s)
+	}
+	*id = v
+	return nil
+}
+
+// uint64 returns the 64 lowest bits of the ID, which is how Datadog represents
+// the 128-bit trace IDs of OpenTelemetry.
+func (id otlpID) uint64() uint64 {
+	if len(id) < 8 {
+		var padded [8]byte
+		copy(padded[8-len(id):], id)
+		return binary.BigEndian.Uint64(padded[:])
+	}
+	return binary.BigEndian.Uint64(id[len(id)-8:])
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	InstrumentationLibrarySpans []otlpLibrarySpans `json:"instrumentationLibrarySpans"`
+	// ScopeSpans replaces InstrumentationLibrarySpans in recent versions of the protocol.
+	ScopeSpans []otlpLibrarySpans `json:"scopeSpans"`
+}
+
+type otlpLibrary struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpLibrarySpans struct {
+	InstrumentationLibrary otlpLibrary `json:"instrumentationLibrary"`
+	Scope                  otlpLibrary `json:"scope"`
+	Spans                  []otlpSpan  `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           otlpID         `json:"traceId"`
+	SpanID            otlpID         `json:"spanId"`
+	ParentSpanID      otlpID         `json:"parentSpanId"`
+	Name              string         `json:"name"`
+	Kind              otlpSpanKind   `json:"kind"`
+	StartTimeUnixNano otlpUint64     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   otlpUint64     `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Events            []otlpEvent    `json:"events"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	Name       string         `json:"name"`
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpStatus struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string     `json:"stringValue"`
+	BoolValue   *bool       `json:"boolValue"`
+	IntValue    *otlpInt64  `json:"intValue"`
+	DoubleValue *float64    `json:"doubleValue"`
+	ArrayValue  *otlpArray  `json:"arrayValue"`
+	KvlistValue *otlpKvlist `json:"kvlistValue"`
+	BytesValue  []byte      `json:"bytesValue"`
+}
+
+type otlpArray struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// String returns the string representation of the value.
+func (v otlpAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.ArrayValue != nil:
+		values := make([]string, 0, len(v.ArrayValue.Values))
+		for _, value := range v.ArrayValue.Values {
+			values = append(values, strconv.Quote(value.String()))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case v.KvlistValue != nil:
+		values := make([]string, 0, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			values = append(values, strconv.Quote(kv.Key)+":"+strconv.Quote(kv.Value.String()))
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	case v.BytesValue != nil:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	}
+	return ""
+}
+
+// number returns the value of numeric attributes.
+func (v otlpAnyValue) number() (float64, bool) {
+	switch {
+	case v.IntValue != nil:
+		return float64(*v.IntValue), true
+	case v.DoubleValue != nil:
+		return *v.DoubleValue, true
+	}
+	return 0, false
+}
+
+// decodeOTLPJSON decodes an ExportTraceServiceRequest in the OTLP JSON encoding.
+func decodeOTLPJSON(b []byte) (*otlpRequest, error) {
+	var req otlpRequest
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// decodeOTLPProto decodes an ExportTraceServiceRequest in the OTLP protobuf encoding.
+func decodeOTLPProto(b []byte) (*otlpRequest, error) {
+	var req otlpRequest
+	err := forEachProtoField(b, func(field int, r *protoReader) error {
+		if field != 1 {
+			return r.skip()
+		}
+		var rs otlpResourceSpans
+		if err := r.message(rs.unmarshalProto); err != nil {
+			return err
+		}
+		req.ResourceSpans = append(req.ResourceSpans, rs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (rs *otlpResourceSpans) unmarshalProto(b []byte) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		switch field {
+		case 1: // resource
+			return r.message(func(b []byte) error {
+				return forEachProtoField(b, func(field int, r *protoReader) error {
+					if field != 1 {
+						return r.skip()
+					}
+					var kv otlpKeyValue
+					err := r.message(kv.unmarshalProto)
+					rs.Resource.Attributes = append(rs.Resource.Attributes, kv)
+					return err
+				})
+			})
+		case 2: // instrumentation_library_spans or scope_spans
+			var ls otlpLibrarySpans
+			err := r.message(ls.unmarshalProto)
+			rs.InstrumentationLibrarySpans = append(rs.InstrumentationLibrarySpans, ls)
+			return err
+		}
+		return r.skip()
+	})
+}
+
+func (ls *otlpLibrarySpans) unmarshalProto(b []byte) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		switch field {
+		case 1: // instrumentation_library or scope
+			return r.message(func(b []byte) error {
+				return forEachProtoField(b, func(field int, r *protoReader) error {
+					var err error
+					switch field {
+					case 1:
+						ls.InstrumentationLibrary.Name, err = r.string()
+					case 2:
+						ls.InstrumentationLibrary.Version, err = r.string()
+					default:
+						err = r.skip()
+					}
+					return err
+				})
+			})
+		case 2: // spans
+			var span otlpSpan
+			err := r.message(span.unmarshalProto)
+			ls.Spans = append(ls.Spans, span)
+			return err
+		}
+		return r.skip()
+	})
+}
+
+func (s *otlpSpan) unmarshalProto(b []byte) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		var err error
+		switch field {
+		case 1:
+			s.TraceID, err = r.bytes()
+		case 2:
+			s.SpanID, err = r.bytes()
+		case 4:
+			s.ParentSpanID, err = r.bytes()
+		case 5:
+			s.Name, err = r.string()
+		case 6:
+			var kind uint64
+			kind, err = r.varint()
+			s.Kind = otlpSpanKind(kind)
+		case 7:
+			var start uint64
+			start, err = r.fixed64()
+			s.StartTimeUnixNano = otlpUint64(start)
+		case 8:
+			var end uint64
+			end, err = r.fixed64()
+			s.EndTimeUnixNano = otlpUint64(end)
+		case 9:
+			var kv otlpKeyValue
+			err = r.message(kv.unmarshalProto)
+			s.Attributes = append(s.Attributes, kv)
+		case 11:
+			var event otlpEvent
+			err = r.message(event.unmarshalProto)
+			s.Events = append(s.Events, event)
+		case 15:
+			err = r.message(s.Status.unmarshalProto)
+		default:
+			err = r.skip()
+		}
+		return err
+	})
+}
+
+func (e *otlpEvent) unmarshalProto(b []byte) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		var err error
+		switch field {
+		case 2:
+			e.Name, err = r.string()
+		case 3:
+			var kv otlpKeyValue
+			err = r.message(kv.unmarshalProto)
+			e.Attributes = append(e.Attributes, kv)
+		default:
+			err = r.skip()
+		}
+		return err
+	})
+}
+
+func (s *otlpStatus) unmarshalProto(b []byte) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		var err error
+		switch field {
+		case 2:
+			s.Message, err = r.string()
+		case 3:
+			var code uint64
+			code, err = r.varint()
+			s.Code = int32(code)
+		default:
+			err = r.skip()
+		}
+		return err
+	})
+}
+
+func (kv *otlpKeyValue) unmarshalProto(b []byte) error {
+	return kv.unmarshalProtoDepth(b, 0)
+}
+
+// unmarshalProtoDepth decodes a key-value nested in depth array or kvlist values.
+func (kv *otlpKeyValue) unmarshalProtoDepth(b []byte, depth int) error {
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		var err error
+		switch field {
+		case 1:
+			kv.Key, err = r.string()
+		case 2:
+			err = r.message(func(b []byte) error {
+				return kv.Value.unmarshalProto(b, depth)
+			})
+		default:
+			err = r.skip()
+		}
+		return err
+	})
+}
+
+// unmarshalProto decodes a value nested in depth array or kvlist values.
+func (v *otlpAnyValue) unmarshalProto(b []byte, depth int) error {
+	if depth >= otlpMaxValueDepth {
+		return errOTLPTooDeep
+	}
+	return forEachProtoField(b, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			s, err := r.string()
+			v.StringValue = &s
+			return err
+		case 2:
+			n, err := r.varint()
+			b := n != 0
+			v.BoolValue = &b
+			return err
+		case 3:
+			n, err := r.varint()
+			i := otlpInt64(n)
+			v.IntValue = &i
+			return err
+		case 4:
+			n, err := r.fixed64()
+			f := math.Float64frombits(n)
+			v.DoubleValue = &f
+			return err
+		case 5:
+			v.ArrayValue = &otlpArray{}
+			return r.message(func(b []byte) error {
+				return forEachProtoField(b, func(field int, r *protoReader) error {
+					if field != 1 {
+						return r.skip()
+					}
+					var value otlpAnyValue
+					err := r.message(func(b []byte) error {
+						return value.unmarshalProto(b, depth+1)
+					})
+					v.ArrayValue.Values = append(v.ArrayValue.Values, value)
+					return err
+				})
+			})
+		case 6:
+			v.KvlistValue = &otlpKvlist{}
+			return r.message(func(b []byte) error {
+				return forEachProtoField(b, func(field int, r *protoReader) error {
+					if field != 1 {
+						return r.skip()
+					}
+					var kv otlpKeyValue
+					err := r.message(func(b []byte) error {
+						return kv.unmarshalProtoDepth(b, depth+1)
+					})
+					v.KvlistValue.Values = append(v.KvlistValue.Values, kv)
+					return err
+				})
+			})
+		case 7:
+			b, err := r.bytes()
+			v.BytesValue = b
+			return err
+		}
+		return r.skip()
+	})
+}
+
+var (
+	// errOTLPMalformed is returned when a protobuf payload is truncated or invalid.
+	errOTLPMalformed = errors.New("malformed OTLP protobuf payload")
+	// errOTLPTooDeep is returned when attribute values are nested deeper than otlpMaxValueDepth.
+	errOTLPTooDeep = errors.New("OTLP attribute values are nested too deeply")
+)
+
+// otlpMaxValueDepth is the maximum nesting of array and kvlist attribute values, which
+// are decoded recursively.
+const otlpMaxValueDepth = 32
+
+// Protobuf wire types
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// protoReader reads the value of the current field of a protobuf message.
+type protoReader struct {
+	buf      []byte
+	wireType int
+}
+
+// forEachProtoField calls fn for every field of the protobuf message b,
+// fn must consume the value of the field by calling one of the reader methods.
+func forEachProtoField(b []byte, fn func(field int, r *protoReader) error) error {
+	r := &protoReader{buf: b}
+	for len(r.buf) > 0 {
+		key, n := binary.Uvarint(r.buf)
+		if n <= 0 {
+			return errOTLPMalformed
+		}
+		r.buf = r.buf[n:]
+		r.wireType = int(key & 0x7)
+		if err := fn(int(key>>3), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *protoReader) varint() (uint64, error) {
+	if r.wireType != protoWireVarint {
+		return 0, errOTLPMalformed
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		return 0, errOTLPMalformed
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *protoReader) fixed64() (uint64, error) {
+	if r.wireType != protoWireFixed64 || len(r.buf) < 8 {
+		return 0, errOTLPMalformed
+	}
+	v := binary.LittleEndian.Uint64(r.buf)
+	r.buf = r.buf[8:]
+	return v, nil
+}
+
+func (r *protoReader) bytes() ([]byte, error) {
+	if r.wireType != protoWireBytes {
+		return nil, errOTLPMalformed
+	}
+	l, n := binary.Uvarint(r.buf)
+	if n <= 0 || uint64(len(r.buf)-n) < l {
+		return nil, errOTLPMalformed
+	}
+	v := r.buf[n : n+int(l)]
+	r.buf = r.buf[n+int(l):]
+	return v, nil
+}
+
+func (r *protoReader) string() (string, error) {
+	b, err := r.bytes()
+	return string(b), err
+}
+
+func (r *protoReader) message(unmarshal func([]byte) error) error {
+	b, err := r.bytes()
+	if err != nil {
+		return err
+	}
+	return unmarshal(b)
+}
+
+// skip discards the value of an unknown field.
+func (r *protoReader) skip() error {
+	var err error
+	switch r.wireType {
+	case protoWireVarint:
+		_, err = r.varint()
+	case protoWireFixed64:
+		_, err = r.fixed64()
+	case protoWireBytes:
+		_, err = r.bytes()
+	case protoWireFixed32:
+		if len(r.buf) < 4 {
+			return errOTLPMalformed
+		}
+		r.buf = r.buf[4:]
+	default:
+		return errOTLPMalformed
+	}
+	return err
+}
+
+// otlpTraces converts the spans of an OTLP request into Datadog traces,
+// grouping them by trace ID.
+func otlpTraces(req *otlpRequest) pb.Traces {
+	var traces pb.Traces
+	traceIndex := make(map[uint64]int)
+	for _, rs := range req.ResourceSpans {
+		resource := otlpAttributesMap(rs.Resource.Attributes)
+		for _, ls := range append(rs.InstrumentationLibrarySpans, rs.ScopeSpans...) {
+			library := ls.InstrumentationLibrary
+			if library.Name == "" {
+				library = ls.Scope
+			}
+			for _, s := range ls.Spans {
+				span := otlpSpanToDatadog(s, resource, library)
+				i, ok := traceIndex[span.TraceID]
+				if !ok {
+					i = len(traces)
+					traceIndex[span.TraceID] = i
+					traces = append(traces, pb.Trace{})
+				}
+				traces[i] = append(traces[i], span)
+			}
+		}
+	}
+	return traces
+}
+
+func otlpAttributesMap(attributes []otlpKeyValue) map[string]otlpAnyValue {
+	m := make(map[string]otlpAnyValue, len(attributes))
+	for _, kv := range attributes {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+// otlpSpanToDatadog converts an OTLP span into a Datadog span, resource attributes
+// and span attributes are respectively mapped to the metadata of the span.
+func otlpSpanToDatadog(s otlpSpan, resource map[string]otlpAnyValue, library otlpLibrary) *pb.Span {
+	attributes := otlpAttributesMap(s.Attributes)
+	span := &pb.Span{
+		Service:  otlpServiceName(resource, attributes),
+		Name:     otlpOperationName(library, s.Kind),
+		Resource: otlpResourceName(s, attributes),
+		TraceID:  s.TraceID.uint64(),
+		SpanID:   s.SpanID.uint64(),
+		ParentID: s.ParentSpanID.uint64(),
+		Start:    int64(s.StartTimeUnixNano),
+		Duration: int64(s.EndTimeUnixNano) - int64(s.StartTimeUnixNano),
+		Type:     otlpSpanType(s.Kind, attributes),
+		Meta:     make(map[string]string, len(resource)+len(attributes)+2),
+		Metrics:  make(map[string]float64),
+	}
+	for _, attrs := range []map[string]otlpAnyValue{resource, attributes} {
+		for k, v := range attrs {
+			if n, ok := v.number(); ok {
+				span.Metrics[k] = n
+				if k != "http.status_code" {
+					continue
+				}
+			}
+			span.Meta[k] = v.String()
+		}
+	}
+	if env, ok := resource["deployment.environment"]; ok {
+		span.Meta["env"] = env.String()
+	}
+	if version, ok := resource["service.version"]; ok {
+		span.Meta["version"] = version.String()
+	}
+	span.Meta["span.kind"] = s.Kind.String()
+	if library.Name != "" {
+		span.Meta["otel.library.name"] = library.Name
+	}
+	if library.Version != "" {
+		span.Meta["otel.library.version"] = library.Version
+	}
+
+	if s.Status.Code == otlpStatusCodeError {
+		span.Error = 1
+		if s.Status.Message != "" {
+			span.Meta["error.msg"] = s.Status.Message
+		}
+		for _, event := range s.Events {
+			if event.Name != "exception" {
+				continue
+			}
+			exception := otlpAttributesMap(event.Attributes)
+			for otlpKey, ddKey := range map[string]string{
+				"exception.type":       "error.type",
+				"exception.message":    "error.msg",
+				"exception.stacktrace": "error.stack",
+			} {
+				if v, ok := exception[otlpKey]; ok {
+					span.Meta[ddKey] = v.String()
+				}
+			}
+		}
+	}
+	return span
+}
+
+func otlpServiceName(resource, attributes map[string]otlpAnyValue) string {
+	if service, ok := resource["service.name"]; ok && service.String() != "" {
+		return service.String()
+	}
+	if service, ok := attributes["service.name"]; ok && service.String() != "" {
+		return service.String()
+	}
+	return otlpDefaultServiceName
+}
+
+// otlpOperationName returns the name of a span, made of the instrumentation library and the span kind,
+// e.g. "opentelemetry.server", the OTLP span name being used as the resource.
+func otlpOperationName(library otlpLibrary, kind otlpSpanKind) string {
+	name := library.Name
+	if name == "" {
+		name = "opentelemetry"
+	}
+	return name + "." + kind.String()
+}
+
+func otlpResourceName(s otlpSpan, attributes map[string]otlpAnyValue) string {
+	if method, ok := attributes["http.method"]; ok {
+		for _, key := range []string{"http.route", "http.target"} {
+			if route, ok := attributes[key]; ok {
+				// drop the query string of http.target
+				return method.String() + " " + strings.SplitN(route.String(), "?", 2)[0]
+			}
+		}
+		return method.String()
+	}
+	if statement, ok := attributes["db.statement"]; ok {
+		return statement.String()
+	}
+	if operation, ok := attributes["messaging.operation"]; ok {
+		if destination, ok := attributes["messaging.destination"]; ok {
+			return operation.String() + " " + destination.String()
+		}
+	}
+	if method, ok := attributes["rpc.method"]; ok {
+		if service, ok := attributes["rpc.service"]; ok {
+			return service.String() + "/" + method.String()
+		}
+		return method.String()
+	}
+	return s.Name
+}
+
+// otlpDBTypes maps db.system values to the span types handled by the obfuscator.
+var otlpDBTypes = map[string]string{
+	"redis":         "redis",
+	"memcached":     "memcached",
+	"mongodb":       "mongodb",
+	"elasticsearch": "elasticsearch",
+	"cassandra":     "cassandra",
+}
+
+func otlpSpanType(kind otlpSpanKind, attributes map[string]otlpAnyValue) string {
+	if system, ok := attributes["db.system"]; ok {
+		if t, ok := otlpDBTypes[system.String()]; ok {
+			return t
+		}
+		return "sql"
+	}
+	switch kind {
+	case otlpSpanKindServer:
+		return "web"
+	case otlpSpanKindClient:
+		if _, ok := attributes["http.method"]; ok {
+			return "http"
+		}
+	}
+	return "custom"
+}
+
+// handleOTLPTraces handles OTLP/HTTP trace export requests, encoded in protobuf or JSON.
+func (r *HTTPReceiver) handleOTLPTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ts := r.tagStats(otlpV1, req)
+	tags := []string{"handler:traces", fmt.Sprintf("v:%s", otlpV1)}
+
+	mediaType := getMediaType(req)
+	if mediaType != "application/x-protobuf" && mediaType != "application/json" {
+		httpFormatError(w, otlpV1, fmt.Errorf("unsupported media type: %q", mediaType))
+		return
+	}
+
+	rd := NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+	body := io.Reader(rd)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(rd)
+		if err != nil {
+			httpDecodingError(err, tags, w)
+			return
+		}
+		defer gz.Close()
+		// limit the decompressed size as well, a small payload can decompress to a huge one
+		body = NewLimitedReader(gz, r.conf.MaxRequestBytes)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := io.Copy(buf, body); err != nil {
+		httpDecodingError(err, tags, w)
+		return
+	}
+
+	var (
+		otlpReq *otlpRequest
+		err     error
+	)
+	if mediaType == "application/json" {
+		otlpReq, err = decodeOTLPJSON(buf.Bytes())
+	} else {
+		otlpReq, err = decodeOTLPProto(buf.Bytes())
+	}
+	if err != nil {
+		httpDecodingError(err, tags, w)
+		atomic.AddInt64(&ts.TracesDropped.DecodingError, 1)
+		log.Errorf("Cannot decode %s traces payload: %v", otlpV1, err)
+		return
+	}
+
+	traces := otlpTraces(otlpReq)
+	if r.rateLimited(int64(len(traces))) {
+		// this payload can not be accepted
+		w.WriteHeader(r.rateLimiterResponse)
+		atomic.AddInt64(&ts.PayloadRefused, 1)
+		return
+	}
+
+	// reply with an empty ExportTraceServiceResponse
+	w.Header().Set("Content-Type", mediaType)
+	if mediaType == "application/json" {
+		io.WriteString(w, "{}")
+	}
+
+	atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+	atomic.AddInt64(&ts.TracesBytes, rd.Count)
+	atomic.AddInt64(&ts.PayloadAccepted, 1)
+
+	r.sendPayload(&Payload{
+		Source:        ts,
+		Traces:        traces,
+		ContainerTags: getContainerTags(req.Header.Get(headerContainerID)),
+	})
+}
@@ -0,0 +1,333 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const otlpTestJSONPayload = `{
+  "resourceSpans": [{
+    "resource": {
+      "attributes": [
+        {"key": "service.name", "value": {"stringValue": "checkout"}},
+        {"key": "deployment.environment", "value": {"stringValue": "prod"}},
+        {"key": "service.version", "value": {"stringValue": "1.2.3"}}
+      ]
+    },
+    "instrumentationLibrarySpans": [{
+      "instrumentationLibrary": {"name": "go.opentelemetry.io/otel/net/http", "version": "0.20.0"},
+      "spans": [
+        {
+          "traceId": "5b8efff798038103d269b633813fc60c",
+          "spanId": "eee19b7ec3c1b174",
+          "name": "/cart",
+          "kind": "SPAN_KIND_SERVER",
+          "startTimeUnixNano": "1544712660000000000",
+          "endTimeUnixNano": "1544712661000000000",
+          "attributes": [
+            {"key": "http.method", "value": {"stringValue": "GET"}},
+            {"key": "http.target", "value": {"stringValue": "/cart?id=42"}},
+            {"key": "http.status_code", "value": {"intValue": "500"}}
+          ],
+          "events": [{
+            "name": "exception",
+            "attributes": [
+              {"key": "exception.type", "value": {"stringValue": "*errors.errorString"}},
+              {"key": "exception.message", "value": {"stringValue": "boom"}}
+            ]
+          }],
+          "status": {"code": 2, "message": "internal error"}
+        },
+        {
+          "traceId": "5b8efff798038103d269b633813fc60c",
+          "spanId": "eee19b7ec3c1b175",
+          "parentSpanId": "eee19b7ec3c1b174",
+          "name": "query",
+          "kind": 3,
+          "startTimeUnixNano": 1544712660100000000,
+          "endTimeUnixNano": 1544712660200000000,
+          "attributes": [
+            {"key": "db.system", "value": {"stringValue": "postgresql"}},
+            {"key": "db.statement", "value": {"stringValue": "SELECT * FROM carts WHERE id = 42"}},
+            {"key": "db.rows", "value": {"doubleValue": 1.5}}
+          ]
+        },
+        {
+          "traceId": "5b8efff798038103d269b633813fc60d",
+          "spanId": "eee19b7ec3c1b176",
+          "name": "background"
+        }
+      ]
+    }]
+  }]
+}`
+
+func TestOTLPJSON(t *testing.T) {
+	assert := assert.New(t)
+	req, err := decodeOTLPJSON([]byte(otlpTestJSONPayload))
+	require.NoError(t, err)
+
+	traces := otlpTraces(req)
+	require.Len(t, traces, 2)
+	require.Len(t, traces[0], 2)
+	require.Len(t, traces[1], 1)
+
+	root := traces[0][0]
+	assert.Equal(uint64(0xd269b633813fc60c), root.TraceID)
+	assert.Equal(uint64(0xeee19b7ec3c1b174), root.SpanID)
+	assert.Equal(uint64(0), root.ParentID)
+	assert.Equal("checkout", root.Service)
+	assert.Equal("go.opentelemetry.io/otel/net/http.server", root.Name)
+	assert.Equal("GET /cart", root.Resource)
+	assert.Equal("web", root.Type)
+	assert.Equal(int64(1544712660000000000), root.Start)
+	assert.Equal(int64(time.Second), root.Duration)
+	assert.Equal(int32(1), root.Error)
+	assert.Equal("prod", root.Meta["env"])
+	assert.Equal("1.2.3", root.Meta["version"])
+	assert.Equal("server", root.Meta["span.kind"])
+	assert.Equal("500", root.Meta["http.status_code"])
+	assert.Equal(float64(500), root.Metrics["http.status_code"])
+	assert.Equal("boom", root.Meta["error.msg"])
+	assert.Equal("*errors.errorString", root.Meta["error.type"])
+
+	child := traces[0][1]
+	assert.Equal(root.TraceID, child.TraceID)
+	assert.Equal(root.SpanID, child.ParentID)
+	assert.Equal("go.opentelemetry.io/otel/net/http.client", child.Name)
+	assert.Equal("SELECT * FROM carts WHERE id = 42", child.Resource)
+	assert.Equal("sql", child.Type)
+	assert.Equal(1.5, child.Metrics["db.rows"])
+	assert.Equal(int32(0), child.Error)
+
+	other := traces[1][0]
+	assert.Equal("background", other.Resource)
+	assert.Equal("custom", other.Type)
+	assert.Equal("go.opentelemetry.io/otel/net/http.unspecified", other.Name)
+}
+
+func TestOTLPJSONErrors(t *testing.T) {
+	for name, payload := range map[string]string{
+		"invalid json": `{"resourceSpans": [`,
+		"invalid id":   `{"resourceSpans": [{"instrumentationLibrarySpans": [{"spans": [{"traceId": "$$"}]}]}]}`,
+		"invalid kind": `{"resourceSpans": [{"instrumentationLibrarySpans": [{"spans": [{"kind": "SPAN_KIND_UNKNOWN"}]}]}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeOTLPJSON([]byte(payload))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// protoMessage is a minimal protobuf encoder used to build OTLP test payloads.
+type protoMessage []byte
+
+func (m protoMessage) uvarint(v uint64) protoMessage {
+	var buf [binary.MaxVarintLen64]byte
+	return append(m, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func (m protoMessage) key(field, wireType int) protoMessage {
+	return m.uvarint(uint64(field<<3 | wireType))
+}
+
+func (m protoMessage) varint(field int, v uint64) protoMessage {
+	return m.key(field, protoWireVarint).uvarint(v)
+}
+
+func (m protoMessage) fixed64(field int, v uint64) protoMessage {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(m.key(field, protoWireFixed64), buf[:]...)
+}
+
+func (m protoMessage) bytes(field int, b []byte) protoMessage {
+	return append(m.key(field, protoWireBytes).uvarint(uint64(len(b))), b...)
+}
+
+func (m protoMessage) string(field int, s string) protoMessage {
+	return m.bytes(field, []byte(s))
+}
+
+func protoAttribute(key string, value protoMessage) protoMessage {
+	return protoMessage{}.string(1, key).bytes(2, value)
+}
+
+func otlpTestProtoPayload() []byte {
+	span := protoMessage{}.
+		bytes(1, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}).
+		bytes(2, []byte{0, 0, 0, 0, 0, 0, 0, 3}).
+		string(5, "GET /users").
+		varint(6, uint64(otlpSpanKindClient)).
+		fixed64(7, 1000).
+		fixed64(8, 3000).
+		bytes(9, protoAttribute("http.method", protoMessage{}.string(1, "GET"))).
+		bytes(9, protoAttribute("http.url", protoMessage{}.string(1, "http://api/users"))).
+		bytes(9, protoAttribute("retried", protoMessage{}.varint(2, 1))).
+		bytes(9, protoAttribute("retries", protoMessage{}.varint(3, 2))).
+		bytes(9, protoAttribute("ratio", protoMessage{}.fixed64(4, math.Float64bits(0.5)))).
+		varint(99, 1). // unknown fields are skipped
+		bytes(15, protoMessage{}.string(2, "timeout").varint(3, otlpStatusCodeError))
+	library := protoMessage{}.
+		bytes(1, protoMessage{}.string(1, "mylib").string(2, "1.0")).
+		bytes(2, span)
+	resource := protoMessage{}.
+		bytes(1, protoAttribute("service.name", protoMessage{}.string(1, "users")))
+	resourceSpans := protoMessage{}.
+		bytes(1, resource).
+		bytes(2, library)
+	return protoMessage{}.bytes(1, resourceSpans)
+}
+
+func TestOTLPProto(t *testing.T) {
+	assert := assert.New(t)
+	req, err := decodeOTLPProto(otlpTestProtoPayload())
+	require.NoError(t, err)
+
+	traces := otlpTraces(req)
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 1)
+	span := traces[0][0]
+	assert.Equal(uint64(2), span.TraceID)
+	assert.Equal(uint64(3), span.SpanID)
+	assert.Equal("users", span.Service)
+	assert.Equal("mylib.client", span.Name)
+	assert.Equal("GET", span.Resource)
+	assert.Equal("http", span.Type)
+	assert.Equal(int64(1000), span.Start)
+	assert.Equal(int64(2000), span.Duration)
+	assert.Equal(int32(1), span.Error)
+	assert.Equal("timeout", span.Meta["error.msg"])
+	assert.Equal("http://api/users", span.Meta["http.url"])
+	assert.Equal("true", span.Meta["retried"])
+	assert.Equal(float64(2), span.Metrics["retries"])
+	assert.Equal(0.5, span.Metrics["ratio"])
+	assert.Equal("mylib", span.Meta["otel.library.name"])
+	assert.Equal("1.0", span.Meta["otel.library.version"])
+}
+
+func TestOTLPProtoMalformed(t *testing.T) {
+	payload := otlpTestProtoPayload()
+	_, err := decodeOTLPProto(payload[:len(payload)-3])
+	assert.Equal(t, errOTLPMalformed, err)
+}
+
+func TestOTLPProtoTooDeep(t *testing.T) {
+	nested := func(depth int) []byte {
+		value := protoMessage{}.string(1, "leaf")
+		for i := 1; i < depth; i++ {
+			value = protoMessage{}.bytes(5, protoMessage{}.bytes(1, value))
+		}
+		span := protoMessage{}.bytes(9, protoAttribute("nested", value))
+		library := protoMessage{}.bytes(2, span)
+		return protoMessage{}.bytes(1, protoMessage{}.bytes(2, library))
+	}
+
+	_, err := decodeOTLPProto(nested(otlpMaxValueDepth))
+	assert.NoError(t, err)
+	_, err = decodeOTLPProto(nested(otlpMaxValueDepth + 1))
+	assert.Equal(t, errOTLPTooDeep, err)
+}
+
+func TestHandleOTLPTraces(t *testing.T) {
+	gzipped := func(b []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(b)
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	for name, tc := range map[string]struct {
+		contentType     string
+		contentEncoding string
+		body            []byte
+		traces          int
+	}{
+		"json":     {"application/json", "", []byte(otlpTestJSONPayload), 2},
+		"protobuf": {"application/x-protobuf", "", otlpTestProtoPayload(), 1},
+		"gzip":     {"application/x-protobuf", "gzip", gzipped(otlpTestProtoPayload()), 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReceiverFromConfig(newTestReceiverConfig())
+			server := httptest.NewServer(http.HandlerFunc(r.handleOTLPTraces))
+			defer server.Close()
+
+			req, err := http.NewRequest("POST", server.URL, bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+			req.Header.Set("Content-Encoding", tc.contentEncoding)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+
+			select {
+			case p := <-r.out:
+				assert.Len(t, p.Traces, tc.traces)
+				assert.Equal(t, int64(tc.traces), p.Source.TracesReceived)
+				assert.Equal(t, string(otlpV1), p.Source.EndpointVersion)
+			case <-time.After(time.Second):
+				t.Fatal("no data received")
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		r := newTestReceiverFromConfig(newTestReceiverConfig())
+		server := httptest.NewServer(http.HandlerFunc(r.handleOTLPTraces))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+		resp, err = http.Post(server.URL, "application/msgpack", bytes.NewReader(nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		resp, err = http.Post(server.URL, "application/json", bytes.NewReader([]byte("{")))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Len(t, r.out, 0)
+	})
+
+	t.Run("gzip-too-large", func(t *testing.T) {
+		conf := newTestReceiverConfig()
+		conf.MaxRequestBytes = 1024
+		r := newTestReceiverFromConfig(conf)
+		server := httptest.NewServer(http.HandlerFunc(r.handleOTLPTraces))
+		defer server.Close()
+
+		// compresses to much less than the limit
+		body := gzipped(make([]byte, 10*conf.MaxRequestBytes))
+		require.True(t, int64(len(body)) < conf.MaxRequestBytes)
+		req, err := http.NewRequest("POST", server.URL, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Len(t, r.out, 0)
+	})
+}
@@ -68,4 +68,11 @@ const (
 	// 		The dictionary in this case would be []string{""}, having only the empty string at index 0.
 	//
 	v05 Version = "v0.5"
+
+	// otlpV1
+	//
+	// Content-Type: application/x-protobuf or application/json
+	// Payload: OpenTelemetry ExportTraceServiceRequest, as defined by the OTLP/HTTP protocol.
+	// Response: An empty ExportTraceServiceResponse.
+	otlpV1 Version = "otlp.v1"
 )
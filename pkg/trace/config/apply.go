@@ -53,6 +53,21 @@ type ObfuscationConfig struct {
 	// Memcached holds the configuration for obfuscating the "memcached.command" tag
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
+
+	// GraphQL holds the configuration for obfuscating literals found in the resource and
+	// the "graphql.query" tag for spans of type "graphql".
+	GraphQL Enablable `mapstructure:"graphql"`
+
+	// Kafka holds the configuration for obfuscating message keys for spans of type "kafka".
+	Kafka Enablable `mapstructure:"kafka"`
+
+	// AMQP holds the configuration for obfuscating routing keys and message IDs for spans
+	// of type "amqp".
+	AMQP Enablable `mapstructure:"amqp"`
+
+	// DynamoDB holds the configuration for obfuscating PartiQL statements and expression
+	// attribute values for spans of type "dynamodb".
+	DynamoDB Enablable `mapstructure:"dynamodb"`
 }
 
 // HTTPObfuscationConfig holds the configuration settings for HTTP obfuscation.
@@ -62,6 +77,10 @@ type HTTPObfuscationConfig struct {
 
 	// RemovePathDigits determines digits in path segments to be obfuscated.
 	RemovePathDigits bool `mapstructure:"remove_paths_with_digits"`
+
+	// ObfuscateSQLValues specifies a set of query string parameters for which their values
+	// will be passed through SQL obfuscation.
+	ObfuscateSQLValues []string `mapstructure:"obfuscate_sql_values"`
 }
 
 // Enablable can represent any option that has an "enabled" boolean sub-field.
@@ -102,6 +121,44 @@ type ReplaceRule struct {
 	Repl string `mapstructure:"repl"`
 }
 
+// TailSamplingConfig holds the configuration of tail-based sampling, which buffers the
+// spans of traces and makes sampling decisions on complete traces.
+type TailSamplingConfig struct {
+	// Enabled reports whether tail-based sampling is enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// DecisionWaitSeconds specifies for how long the spans of a trace are buffered,
+	// starting from its first received span, before a sampling decision is made.
+	DecisionWaitSeconds float64 `mapstructure:"decision_wait_seconds"`
+
+	// MaxBufferedSpans specifies the maximum number of spans buffered at any time. When
+	// it is reached, decisions are made early on the oldest traces to make room.
+	MaxBufferedSpans int `mapstructure:"max_buffered_spans"`
+
+	// KeepErrors specifies whether traces containing any span with an error are kept.
+	KeepErrors bool `mapstructure:"keep_errors"`
+
+	// LatencyThresholdMs specifies the duration, in milliseconds, above which traces are
+	// kept. Zero disables the latency rule.
+	LatencyThresholdMs float64 `mapstructure:"latency_threshold_ms"`
+
+	// Rules specifies tag rules, traces having a span matching any of them are kept.
+	Rules []*TailSamplingRule `mapstructure:"rules"`
+}
+
+// TailSamplingRule specifies a tail-based sampling rule matching spans on their tags.
+type TailSamplingRule struct {
+	// Name specifies the name of the tag that the rule addresses. "resource.name"
+	// and "service.name" target the resource and the service of the span.
+	Name string `mapstructure:"name"`
+
+	// Pattern specifies the regexp pattern that the tag value must match. It must compile.
+	Pattern string `mapstructure:"pattern"`
+
+	// Re holds the compiled Pattern and is only used internally.
+	Re *regexp.Regexp `mapstructure:"-"`
+}
+
 // WriterConfig specifies configuration for an API writer.
 type WriterConfig struct {
 	// ConnectionLimit specifies the maximum number of concurrent outgoing
@@ -210,6 +267,24 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.connection_limit") {
 		c.ConnectionLimit = config.Datadog.GetInt("apm_config.connection_limit")
 	}
+	if k := "apm_config.extra_aggregators"; config.Datadog.IsSet(k) {
+		// the legacy trace.concentrator.extra_aggregators option is a comma separated string
+		c.ExtraAggregators = nil
+		for _, tags := range config.Datadog.GetStringSlice(k) {
+			for _, tag := range strings.Split(tags, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					c.ExtraAggregators = append(c.ExtraAggregators, tag)
+				}
+			}
+		}
+	}
+	if k := "apm_config.extra_aggregators_max_cardinality"; config.Datadog.IsSet(k) {
+		if n := config.Datadog.GetInt(k); n > 0 {
+			c.ExtraAggregatorsMaxCardinality = n
+		} else {
+			log.Warnf("Invalid value for %s: %d, it must be positive. Using the default: %d", k, n, c.ExtraAggregatorsMaxCardinality)
+		}
+	}
 	if config.Datadog.IsSet("apm_config.extra_sample_rate") {
 		c.ExtraSampleRate = config.Datadog.GetFloat64("apm_config.extra_sample_rate")
 	}
@@ -265,6 +340,25 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if k := "apm_config.tail_sampling"; config.Datadog.IsSet(k) {
+		ts := *c.TailSampling // start from the defaults
+		if err := config.Datadog.UnmarshalKey(k, &ts); err != nil {
+			log.Errorf("Bad format for %q: %v", k, err)
+		} else if err := compileTailSamplingRules(ts.Rules); err != nil {
+			log.Errorf("Invalid rules in %q, tail-based sampling is disabled: %v", k, err)
+		} else {
+			if ts.MaxBufferedSpans <= 0 {
+				log.Warnf("Invalid value for %s.max_buffered_spans: %d (must be positive), using default: %d", k, ts.MaxBufferedSpans, c.TailSampling.MaxBufferedSpans)
+				ts.MaxBufferedSpans = c.TailSampling.MaxBufferedSpans
+			}
+			if ts.DecisionWaitSeconds < 0 {
+				log.Warnf("Invalid value for %s.decision_wait_seconds: %v (must not be negative), using default: %v", k, ts.DecisionWaitSeconds, c.TailSampling.DecisionWaitSeconds)
+				ts.DecisionWaitSeconds = c.TailSampling.DecisionWaitSeconds
+			}
+			c.TailSampling = &ts
+		}
+	}
+
 	// undocumented
 	if config.Datadog.IsSet("apm_config.max_cpu_percent") {
 		c.MaxCPU = config.Datadog.GetFloat64("apm_config.max_cpu_percent") / 100
@@ -396,6 +490,23 @@ func compileReplaceRules(rules []*ReplaceRule) error {
 	return nil
 }
 
+func compileTailSamplingRules(rules []*TailSamplingRule) error {
+	for _, r := range rules {
+		if r.Name == "" {
+			return errors.New(`all rules must have a "name" property`)
+		}
+		if r.Pattern == "" {
+			return errors.New(`all rules must have a "pattern"`)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("key %q: %s", r.Name, err)
+		}
+		r.Re = re
+	}
+	return nil
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
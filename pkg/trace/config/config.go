@@ -61,8 +61,9 @@ type AgentConfig struct {
 	Endpoints []*Endpoint
 
 	// Concentrator
-	BucketInterval   time.Duration // the size of our pre-aggregation per bucket
-	ExtraAggregators []string
+	BucketInterval                 time.Duration // the size of our pre-aggregation per bucket
+	ExtraAggregators               []string      // span tags used as additional stats aggregation dimensions
+	ExtraAggregatorsMaxCardinality int           // maximum number of distinct values per extra aggregator in a bucket
 
 	// Sampler configuration
 	ExtraSampleRate float64
@@ -116,6 +117,9 @@ type AgentConfig struct {
 
 	// Obfuscation holds sensitive data obufscator's configuration.
 	Obfuscation *ObfuscationConfig
+
+	// TailSampling holds the tail-based sampling configuration.
+	TailSampling *TailSamplingConfig
 }
 
 // New returns a configuration with the default values.
@@ -125,7 +129,8 @@ func New() *AgentConfig {
 		DefaultEnv: "none",
 		Endpoints:  []*Endpoint{{Host: "https://trace.agent.datadoghq.com"}},
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:                 time.Duration(10) * time.Second,
+		ExtraAggregatorsMaxCardinality: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -155,6 +160,12 @@ func New() *AgentConfig {
 		AnalyzedSpansByService:      make(map[string]map[string]float64),
 
 		DDAgentBin: defaultDDAgentBin,
+
+		TailSampling: &TailSamplingConfig{
+			DecisionWaitSeconds: 10,
+			MaxBufferedSpans:    100000,
+			KeepErrors:          true,
+		},
 	}
 }
 
@@ -159,6 +159,16 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.Equal(0.5, c.ExtraSampleRate)
 	assert.Equal(5.0, c.TargetTPS)
 	assert.Equal(50.0, c.MaxEPS)
+	assert.Equal([]string{"customer_tier", "region"}, c.ExtraAggregators)
+	assert.Equal(20, c.ExtraAggregatorsMaxCardinality)
+	assert.True(c.TailSampling.Enabled)
+	assert.Equal(5.0, c.TailSampling.DecisionWaitSeconds)
+	assert.Equal(5000, c.TailSampling.MaxBufferedSpans)
+	assert.False(c.TailSampling.KeepErrors)
+	assert.Equal(500.0, c.TailSampling.LatencyThresholdMs)
+	assert.Len(c.TailSampling.Rules, 1)
+	assert.Equal("customer_tier", c.TailSampling.Rules[0].Name)
+	assert.True(c.TailSampling.Rules[0].Re.MatchString("gold"))
 	assert.Equal(0.5, c.MaxCPU)
 	assert.EqualValues(123.4, c.MaxMemory)
 	assert.Equal("0.0.0.0", c.ReceiverHost)
@@ -215,6 +225,26 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(c.Obfuscation.Redis.Enabled)
 	assert.True(c.Obfuscation.Memcached.Enabled)
+	assert.EqualValues([]string{"query"}, o.HTTP.ObfuscateSQLValues)
+	assert.True(o.GraphQL.Enabled)
+	assert.True(o.Kafka.Enabled)
+	assert.True(o.AMQP.Enabled)
+	assert.True(o.DynamoDB.Enabled)
+}
+
+func TestLegacyExtraAggregators(t *testing.T) {
+	defer cleanConfig()()
+	origcfg := config.Datadog
+	config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+	defer func() {
+		config.Datadog = origcfg
+	}()
+
+	// trace.concentrator.extra_aggregators is converted from datadog.conf as a comma separated string
+	config.Datadog.Set("apm_config.extra_aggregators", "customer_tier, region")
+	c := New()
+	assert.NoError(t, c.applyDatadogConfig())
+	assert.Equal(t, []string{"customer_tier", "region"}, c.ExtraAggregators)
 }
 
 func TestUndocumentedYamlConfig(t *testing.T) {
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const (
+	// dynamoDBStatementTag holds the PartiQL statement of ExecuteStatement operations.
+	dynamoDBStatementTag = "aws.dynamodb.statement"
+	// dynamoDBAttributeValuesTag holds the JSON encoded ExpressionAttributeValues of
+	// the condition, filter, key condition or update expressions of the operation.
+	dynamoDBAttributeValuesTag = "aws.dynamodb.expression_attribute_values"
+	// dynamoDBKeyTag holds the JSON encoded primary key of the item.
+	dynamoDBKeyTag = "aws.dynamodb.key"
+)
+
+// obfuscateDynamoDB obfuscates the literals of PartiQL statements and the attribute
+// values used by the DynamoDB expressions of the span. Expressions only refer to
+// values through placeholders (e.g. ":id"), so obfuscating the values is enough to
+// obfuscate them.
+func (o *Obfuscator) obfuscateDynamoDB(span *pb.Span) {
+	if span.Meta == nil {
+		return
+	}
+	if statement := span.Meta[dynamoDBStatementTag]; statement != "" {
+		span.Meta[dynamoDBStatementTag] = sqlObfuscationTransformer(o)(statement)
+	}
+	o.obfuscateJSON(span, dynamoDBAttributeValuesTag, o.dynamodb)
+	o.obfuscateJSON(span, dynamoDBKeyTag, o.dynamodb)
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateDynamoDB(t *testing.T) {
+	newSpan := func() *pb.Span {
+		return &pb.Span{
+			Type:     "dynamodb",
+			Resource: "DynamoDB.Query",
+			Meta: map[string]string{
+				dynamoDBStatementTag:                    `SELECT * FROM "users" WHERE id = 'jane' AND age > 30`,
+				dynamoDBAttributeValuesTag:              `{":id":{"S":"jane"},":age":{"N":"30"}}`,
+				dynamoDBKeyTag:                          `{"id":{"S":"jane"}}`,
+				"aws.dynamodb.key_condition_expression": "id = :id",
+			},
+		}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		span := newSpan()
+		NewObfuscator(&config.ObfuscationConfig{DynamoDB: config.Enablable{Enabled: true}}).Obfuscate(span)
+		assert.Equal(t, "DynamoDB.Query", span.Resource)
+		assert.Equal(t, `SELECT * FROM users WHERE id = ? AND age > ?`, span.Meta[dynamoDBStatementTag])
+		assert.Equal(t, `{":id":{"S":"?"},":age":{"N":"?"}}`, span.Meta[dynamoDBAttributeValuesTag])
+		assert.Equal(t, `{"id":{"S":"?"}}`, span.Meta[dynamoDBKeyTag])
+		assert.Equal(t, "id = :id", span.Meta["aws.dynamodb.key_condition_expression"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		span := newSpan()
+		NewObfuscator(nil).Obfuscate(span)
+		assert.Equal(t, newSpan(), span)
+	})
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const graphQLQueryTag = "graphql.query"
+
+// obfuscateGraphQL obfuscates the literals found in the resource and in the
+// "graphql.query" tag of the span.
+func (o *Obfuscator) obfuscateGraphQL(span *pb.Span) {
+	span.Resource = ObfuscateGraphQLString(span.Resource)
+	if span.Meta == nil || span.Meta[graphQLQueryTag] == "" {
+		return
+	}
+	span.Meta[graphQLQueryTag] = ObfuscateGraphQLString(span.Meta[graphQLQueryTag])
+}
+
+// ObfuscateGraphQLString replaces all string, block string and numeric literals of the
+// given GraphQL document with '?', and removes its comments. Names, variables, enum
+// values and the structure of the document are kept, so that queries only differing
+// by their inline arguments are obfuscated the same way.
+//
+// Example: `query { user(id: 42, name: "john") { id } }` becomes
+// `query { user(id: ?, name: ?) { id } }`.
+func ObfuscateGraphQLString(in string) string {
+	var out strings.Builder
+	out.Grow(len(in))
+	for i := 0; i < len(in); {
+		c := in[i]
+		switch {
+		case c == '#':
+			// comments run until the end of the line
+			for i < len(in) && in[i] != '\n' && in[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(in[i:], `"""`):
+			// block strings end with the first unescaped triple quote
+			i += 3
+			for i < len(in) && !strings.HasPrefix(in[i:], `"""`) {
+				if strings.HasPrefix(in[i:], `\"""`) {
+					i++
+				}
+				i++
+			}
+			i += 3
+			out.WriteByte('?')
+		case c == '"':
+			i++
+			for i < len(in) && in[i] != '"' && in[i] != '\n' {
+				if in[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			out.WriteByte('?')
+		case c == '-' || isDigit(rune(c)):
+			// numeric literals: -?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?
+			i++
+			for i < len(in) && isGraphQLNumberChar(in[i]) {
+				i++
+			}
+			out.WriteByte('?')
+		case c == '$' || isGraphQLNameChar(c):
+			// names and variables, which may contain digits
+			start := i
+			i++
+			for i < len(in) && (isGraphQLNameChar(in[i]) || isDigit(rune(in[i]))) {
+				i++
+			}
+			out.WriteString(in[start:i])
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return compactWhitespaces(strings.NewReplacer("\n", " ", "\r", " ", "\t", " ").Replace(out.String()))
+}
+
+// isGraphQLNumberChar reports whether c can be part of a GraphQL numeric literal,
+// after its first character.
+func isGraphQLNumberChar(c byte) bool {
+	return isDigit(rune(c)) || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
+
+// isGraphQLNameChar reports whether c can start a GraphQL name.
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
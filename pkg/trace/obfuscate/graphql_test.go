@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQLString(t *testing.T) {
+	for _, tt := range []inOutTest{
+		{
+			`query { user(id: 42) { name } }`,
+			`query { user(id: ?) { name } }`,
+		},
+		{
+			`query GetUser($id: ID!) { user(id: $id) { name } }`,
+			`query GetUser($id: ID!) { user(id: $id) { name } }`,
+		},
+		{
+			`mutation { login(email: "jane@example.com", password: "hunter2", remember: true) { token } }`,
+			`mutation { login(email: ?, password: ?, remember: true) { token } }`,
+		},
+		{
+			`{ products(first: 10, minPrice: -1.5e3, status: ACTIVE) { sku2 } }`,
+			`{ products(first: ?, minPrice: ?, status: ACTIVE) { sku2 } }`,
+		},
+		{
+			"query {\n  # fetch the user\n  user(bio: \"\"\"multi\nline \\\"\"\" bio\"\"\") {\n\tid\n  }\n}",
+			`query { user(bio: ?) { id } }`,
+		},
+		{
+			`{ search(text: "escaped \" quote", tags: ["a", "b"]) { id } }`,
+			`{ search(text: ?, tags: [?, ?]) { id } }`,
+		},
+		{
+			`{ unterminated(text: "abc`,
+			`{ unterminated(text: ?`,
+		},
+	} {
+		assert.Equal(t, tt.out, ObfuscateGraphQLString(tt.in))
+	}
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	query := `query { user(id: 42) { name } }`
+	span := &pb.Span{
+		Type:     "graphql",
+		Resource: query,
+		Meta:     map[string]string{graphQLQueryTag: query},
+	}
+	o := NewObfuscator(&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}})
+	o.Obfuscate(span)
+	assert.Equal(t, `query { user(id: ?) { name } }`, span.Resource)
+	assert.Equal(t, `query { user(id: ?) { name } }`, span.Meta[graphQLQueryTag])
+}
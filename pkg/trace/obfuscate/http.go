@@ -12,13 +12,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 )
 
-// obfuscateHTTP obfuscates query strings and path segments containing digits in the span's
-// "http.url" tag, when one or both of these options are enabled.
+// obfuscateHTTP obfuscates query strings, SQL query string parameters and path segments
+// containing digits in the span's "http.url" tag, when any of these options are enabled.
 func (o *Obfuscator) obfuscateHTTP(span *pb.Span) {
 	if span.Meta == nil {
 		return
 	}
-	if !o.opts.HTTP.RemoveQueryString && !o.opts.HTTP.RemovePathDigits {
+	if !o.opts.HTTP.RemoveQueryString && !o.opts.HTTP.RemovePathDigits && len(o.httpSQLParams) == 0 {
 		// nothing to do
 		return
 	}
@@ -37,6 +37,8 @@ func (o *Obfuscator) obfuscateHTTP(span *pb.Span) {
 	if o.opts.HTTP.RemoveQueryString && u.RawQuery != "" {
 		u.ForceQuery = true // add the '?'
 		u.RawQuery = ""
+	} else if len(o.httpSQLParams) > 0 && u.RawQuery != "" {
+		u.RawQuery = o.obfuscateSQLQueryParams(u.RawQuery)
 	}
 	if o.opts.HTTP.RemovePathDigits {
 		segs := strings.Split(u.Path, "/")
@@ -59,3 +61,26 @@ func (o *Obfuscator) obfuscateHTTP(span *pb.Span) {
 	}
 	span.Meta[k] = strings.Replace(u.String(), "/REDACTED/", "?", -1)
 }
+
+// obfuscateSQLQueryParams passes the values of the query string parameters configured in
+// "obfuscate_sql_values" through SQL obfuscation, leaving the rest of the query string as is.
+func (o *Obfuscator) obfuscateSQLQueryParams(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, err := url.QueryUnescape(kv[0])
+		if err != nil || !o.httpSQLParams[key] {
+			continue
+		}
+		val, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			params[i] = kv[0] + "=?"
+			continue
+		}
+		params[i] = kv[0] + "=" + url.QueryEscape(sqlObfuscationTransformer(o)(val))
+	}
+	return strings.Join(params, "&")
+}
@@ -145,6 +145,32 @@ func TestObfuscateHTTP(t *testing.T) {
 		}
 	})
 
+	t.Run("sql-values", func(t *testing.T) {
+		conf := &config.ObfuscationConfig{HTTP: config.HTTPObfuscationConfig{
+			ObfuscateSQLValues: []string{"query", "filter"},
+		}}
+		for ti, tt := range []inOutTest{
+			{
+				in:  "http://foo.com/search?query=SELECT+*+FROM+users+WHERE+id+%3D+42&page=2",
+				out: "http://foo.com/search?query=SELECT+%2A+FROM+users+WHERE+id+%3D+%3F&page=2",
+			},
+			{
+				in:  "http://foo.com/search?page=2&filter=name%3D%27jane%27",
+				out: "http://foo.com/search?page=2&filter=name+%3D+%3F",
+			},
+			{
+				in:  "http://foo.com/search?q=SELECT+1&query",
+				out: "http://foo.com/search?q=SELECT+1&query",
+			},
+			{
+				in:  "http://foo.com/1/search?query=bad%zz",
+				out: "http://foo.com/1/search?query=?",
+			},
+		} {
+			t.Run(strconv.Itoa(ti), testHTTPObfuscation(&tt, conf))
+		}
+	})
+
 	t.Run("wrong-type", func(t *testing.T) {
 		assert := assert.New(t)
 		span := pb.Span{Type: "web_server", Meta: map[string]string{"http.url": testURL}}
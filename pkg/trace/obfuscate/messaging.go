@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// kafkaSensitiveTags holds the tags of "kafka" spans which are replaced with '?'.
+var kafkaSensitiveTags = []string{
+	"kafka.key",
+	"kafka.message_key",
+	"messaging.kafka.message_key",
+}
+
+// amqpSensitiveTags holds the tags of "amqp" spans which are replaced with '?'.
+var amqpSensitiveTags = []string{
+	"amqp.message_id",
+	"messaging.message_id",
+}
+
+// amqpRoutingKeyTags holds the tags of "amqp" spans containing routing keys.
+var amqpRoutingKeyTags = []string{
+	"amqp.routing_key",
+	"messaging.rabbitmq.routing_key",
+}
+
+// obfuscateKafka removes the message keys from the tags of the span.
+func (*Obfuscator) obfuscateKafka(span *pb.Span) {
+	obfuscateTags(span, kafkaSensitiveTags)
+}
+
+// obfuscateAMQP removes the message IDs from the tags of the span and replaces
+// the digits found in its routing keys, which usually identify the receiver or the
+// entity that the message is about (e.g. "orders.eu.1234").
+func (*Obfuscator) obfuscateAMQP(span *pb.Span) {
+	span.Resource = obfuscateAMQPRoutingKey(span.Resource)
+	obfuscateTags(span, amqpSensitiveTags)
+	if span.Meta == nil {
+		return
+	}
+	for _, k := range amqpRoutingKeyTags {
+		if v, ok := span.Meta[k]; ok {
+			span.Meta[k] = obfuscateAMQPRoutingKey(v)
+		}
+	}
+}
+
+// obfuscateAMQPRoutingKey replaces the sequences of digits of the given routing key with '?'.
+func obfuscateAMQPRoutingKey(key string) string {
+	return string(replaceDigits([]byte(key)))
+}
+
+// obfuscateTags replaces the non-empty values of the given tags with '?'.
+func obfuscateTags(span *pb.Span, tags []string) {
+	if span.Meta == nil {
+		return
+	}
+	for _, k := range tags {
+		if span.Meta[k] != "" {
+			span.Meta[k] = "?"
+		}
+	}
+}
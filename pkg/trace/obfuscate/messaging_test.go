@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateKafka(t *testing.T) {
+	span := &pb.Span{
+		Type:     "kafka",
+		Resource: "Produce Topic orders",
+		Meta: map[string]string{
+			"kafka.key":                   "user-1234",
+			"messaging.kafka.message_key": "user-1234",
+			"kafka.partition":             "3",
+		},
+	}
+	NewObfuscator(&config.ObfuscationConfig{Kafka: config.Enablable{Enabled: true}}).Obfuscate(span)
+	assert.Equal(t, "Produce Topic orders", span.Resource)
+	assert.Equal(t, map[string]string{
+		"kafka.key":                   "?",
+		"messaging.kafka.message_key": "?",
+		"kafka.partition":             "3",
+	}, span.Meta)
+}
+
+func TestObfuscateAMQP(t *testing.T) {
+	span := &pb.Span{
+		Type:     "amqp",
+		Resource: "basic.publish orders -> orders.eu.1234",
+		Meta: map[string]string{
+			"amqp.routing_key":     "orders.eu.1234",
+			"amqp.message_id":      "a1b2c3",
+			"amqp.exchange":        "orders",
+			"messaging.message_id": "a1b2c3",
+		},
+	}
+	NewObfuscator(&config.ObfuscationConfig{AMQP: config.Enablable{Enabled: true}}).Obfuscate(span)
+	assert.Equal(t, "basic.publish orders -> orders.eu.?", span.Resource)
+	assert.Equal(t, map[string]string{
+		"amqp.routing_key":     "orders.eu.?",
+		"amqp.message_id":      "?",
+		"amqp.exchange":        "orders",
+		"messaging.message_id": "?",
+	}, span.Meta)
+}
@@ -24,6 +24,9 @@ type Obfuscator struct {
 	mongo                *jsonObfuscator // nil if disabled
 	sqlExecPlan          *jsonObfuscator // nil if disabled
 	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
+	dynamodb             *jsonObfuscator // nil if disabled
+	// httpSQLParams holds the query string parameters of HTTP URLs whose values are SQL queries.
+	httpSQLParams map[string]bool
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// A non-zero value means 'yes'. Different SQL engines behave in different ways and the tokenizer needs
 	// to be generic.
@@ -68,6 +71,15 @@ func NewObfuscator(cfg *config.ObfuscationConfig) *Obfuscator {
 	if cfg.SQLExecPlanNormalize.Enabled {
 		o.sqlExecPlanNormalize = newJSONObfuscator(&cfg.SQLExecPlanNormalize, &o)
 	}
+	if cfg.DynamoDB.Enabled {
+		o.dynamodb = newJSONObfuscator(&config.JSONObfuscationConfig{Enabled: true}, &o)
+	}
+	if len(cfg.HTTP.ObfuscateSQLValues) > 0 {
+		o.httpSQLParams = make(map[string]bool, len(cfg.HTTP.ObfuscateSQLValues))
+		for _, k := range cfg.HTTP.ObfuscateSQLValues {
+			o.httpSQLParams[k] = true
+		}
+	}
 	return &o
 }
 
@@ -95,6 +107,22 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 		o.obfuscateJSON(span, "mongodb.query", o.mongo)
 	case "elasticsearch":
 		o.obfuscateJSON(span, "elasticsearch.body", o.es)
+	case "graphql":
+		if o.opts.GraphQL.Enabled {
+			o.obfuscateGraphQL(span)
+		}
+	case "kafka":
+		if o.opts.Kafka.Enabled {
+			o.obfuscateKafka(span)
+		}
+	case "amqp":
+		if o.opts.AMQP.Enabled {
+			o.obfuscateAMQP(span)
+		}
+	case "dynamodb":
+		if o.opts.DynamoDB.Enabled {
+			o.obfuscateDynamoDB(span)
+		}
 	}
 }
 
@@ -111,6 +139,14 @@ func (o *Obfuscator) ObfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if o.opts.GraphQL.Enabled {
+			b.Resource = ObfuscateGraphQLString(b.Resource)
+		}
+	case "amqp":
+		if o.opts.AMQP.Enabled {
+			b.Resource = obfuscateAMQPRoutingKey(b.Resource)
+		}
 	}
 }
 
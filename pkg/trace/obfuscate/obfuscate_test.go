@@ -136,6 +136,23 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("sql", "SELECT 1\nFROM Blogs AS [b\nORDER BY [b]"), nonParsableResource},
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
+		{statsGroup("graphql", "query { user(id: 42) { name } }"), "query { user(id: 42) { name } }"},
+		{statsGroup("amqp", "basic.publish orders -> orders.1234"), "basic.publish orders -> orders.1234"},
+	} {
+		o.ObfuscateStatsGroup(tt.in)
+		assert.Equal(t, tt.in.Resource, tt.out)
+	}
+
+	o = NewObfuscator(&config.ObfuscationConfig{
+		GraphQL: config.Enablable{Enabled: true},
+		AMQP:    config.Enablable{Enabled: true},
+	})
+	for _, tt := range []struct {
+		in  *pb.ClientGroupedStats // input stats
+		out string                 // output obfuscated resource
+	}{
+		{statsGroup("graphql", "query { user(id: 42) { name } }"), "query { user(id: ?) { name } }"},
+		{statsGroup("amqp", "basic.publish orders -> orders.1234"), "basic.publish orders -> orders.?"},
 	} {
 		o.ObfuscateStatsGroup(tt.in)
 		assert.Equal(t, tt.in.Resource, tt.out)
@@ -265,6 +282,46 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{Memcached: config.Enablable{Enabled: true}},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ user(name: "jane") { id } }`,
+		`{ user(name: ?) { id } }`,
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ user(name: "jane") { id } }`,
+		`{ user(name: "jane") { id } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("kafka/enabled", testConfig(
+		"kafka",
+		"kafka.key",
+		"user-1234",
+		"?",
+		&config.ObfuscationConfig{Kafka: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("kafka/disabled", testConfig(
+		"kafka",
+		"kafka.key",
+		"user-1234",
+		"user-1234",
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("amqp/disabled", testConfig(
+		"amqp",
+		"amqp.routing_key",
+		"orders.1234",
+		"orders.1234",
+		&config.ObfuscationConfig{},
+	))
+
 	t.Run("memcached/disabled", testConfig(
 		"memcached",
 		"memcached.command",
@@ -25,6 +25,9 @@ type Aggregation struct {
 	StatusCode string
 	Version    string
 	Synthetics bool
+	// ExtraTags holds the values of the user-defined extra aggregation tags of the span,
+	// in their canonical "tag1:value1,tag2:value2" form, sorted by tag name.
+	ExtraTags string
 }
 
 // NewAggregationFromSpan creates a new aggregation from the provided span and env
@@ -73,6 +76,9 @@ func (aggr *Aggregation) ToTagSet() TagSet {
 	if aggr.Synthetics {
 		tagSet = append(tagSet, Tag{tagSynthetics, "true"})
 	}
+	if len(aggr.ExtraTags) > 0 {
+		tagSet = append(tagSet, NewTagSetFromString(aggr.ExtraTags)...)
+	}
 	return tagSet
 }
 
@@ -95,6 +101,10 @@ func (aggr *Aggregation) KeyLen() int {
 		// +2 for "," and ":" separator
 		length += 1 + len(tagSynthetics) + 1 + len("true")
 	}
+	if len(aggr.ExtraTags) > 0 {
+		// +1 for "," separator
+		length += 1 + len(aggr.ExtraTags)
+	}
 	return length
 }
 
@@ -124,4 +134,9 @@ func (aggr *Aggregation) WriteKey(b *strings.Builder) {
 		b.WriteString("," + tagSynthetics + ":")
 		b.WriteString("true")
 	}
+	// extra tags are user-defined and always written last
+	if len(aggr.ExtraTags) > 0 {
+		b.WriteString(",")
+		b.WriteString(aggr.ExtraTags)
+	}
 }
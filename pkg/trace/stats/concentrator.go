@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -33,6 +34,11 @@ type Concentrator struct {
 	// wait such time before flushing the stats.
 	// This only applies to past buckets. Stats buckets in the future are allowed with no restriction.
 	bufferLen int
+	// extraAggregators holds the span tags used as additional aggregation dimensions.
+	extraAggregators []string
+	// maxExtraCardinality is the maximum number of distinct values of each extra aggregation
+	// tag within a bucket, additional values are aggregated together.
+	maxExtraCardinality int
 
 	In  chan []Input
 	Out chan []Bucket
@@ -45,7 +51,8 @@ type Concentrator struct {
 }
 
 // NewConcentrator initializes a new concentrator ready to be started
-func NewConcentrator(bsize int64, out chan []Bucket) *Concentrator {
+func NewConcentrator(conf *config.AgentConfig, out chan []Bucket) *Concentrator {
+	bsize := conf.BucketInterval.Nanoseconds()
 	c := Concentrator{
 		bsize:   bsize,
 		buckets: make(map[int64]*RawBucket),
@@ -53,7 +60,9 @@ func NewConcentrator(bsize int64, out chan []Bucket) *Concentrator {
 		// override buckets which could have been sent before an Agent restart.
 		oldestTs: alignTs(time.Now().UnixNano(), bsize),
 		// TODO: Move to configuration.
-		bufferLen: defaultBufferLen,
+		bufferLen:           defaultBufferLen,
+		extraAggregators:    conf.ExtraAggregators,
+		maxExtraCardinality: conf.ExtraAggregatorsMaxCardinality,
 
 		In:  make(chan []Input, 100),
 		Out: out,
@@ -152,6 +161,7 @@ func (c *Concentrator) addNow(i *Input) {
 		b, ok := c.buckets[btime]
 		if !ok {
 			b = NewRawBucket(btime, c.bsize)
+			b.extraTags = newExtraTagsAggregator(c.extraAggregators, c.maxExtraCardinality)
 			c.buckets[btime] = b
 		}
 
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 
@@ -23,7 +24,14 @@ var (
 
 func NewTestConcentrator() *Concentrator {
 	statsChan := make(chan []Bucket)
-	return NewConcentrator(time.Second.Nanoseconds(), statsChan)
+	return NewConcentrator(newTestConcentratorConfig(time.Second.Nanoseconds()), statsChan)
+}
+
+// newTestConcentratorConfig returns a configuration using the given bucket size.
+func newTestConcentratorConfig(bsize int64) *config.AgentConfig {
+	conf := config.New()
+	conf.BucketInterval = time.Duration(bsize)
+	return conf
 }
 
 // getTsInBucket gives a timestamp in ns which is `offset` buckets late
@@ -160,7 +168,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 	t.Run("cold", func(t *testing.T) {
 		// Running cold, all spans in the past should end up in the current time bucket.
 		flushTime := now
-		c := NewConcentrator(testBucketInterval, statsChan)
+		c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 		c.addNow(testTrace)
 
 		for i := 0; i < c.bufferLen; i++ {
@@ -189,7 +197,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 
 	t.Run("hot", func(t *testing.T) {
 		flushTime := now
-		c := NewConcentrator(testBucketInterval, statsChan)
+		c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 		c.oldestTs = alignTs(now, c.bsize) - int64(c.bufferLen-1)*c.bsize
 		c.addNow(testTrace)
 
@@ -236,7 +244,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 func TestConcentratorStatsTotals(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator(testBucketInterval, statsChan)
+	c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := alignTs(now, c.bsize)
@@ -343,7 +351,7 @@ func TestConcentratorStatsTotals(t *testing.T) {
 func TestConcentratorStatsCounts(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator(testBucketInterval, statsChan)
+	c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := alignTs(now, c.bsize)
@@ -462,7 +470,7 @@ func TestConcentratorStatsCounts(t *testing.T) {
 func TestConcentratorSublayersStatsCounts(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator(testBucketInterval, statsChan)
+	c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := now - now%c.bsize
@@ -626,10 +634,69 @@ func TestConcentratorAdd(t *testing.T) {
 				sublayers[subtrace.Root] = subtraceSublayers
 			}
 			testTrace.Sublayers = sublayers
-			c := NewConcentrator(testBucketInterval, statsChan)
+			c := NewConcentrator(newTestConcentratorConfig(testBucketInterval), statsChan)
 			c.addNow(testTrace)
 			stats := c.flushNow(now + (int64(c.bufferLen) * testBucketInterval))
 			countValsEq(t, test.out, stats[0].Counts)
 		})
 	}
 }
+
+// TestConcentratorExtraAggregators tests that spans are aggregated by the configured extra tags,
+// and that values over the cardinality limit are aggregated in the overflow bucket.
+func TestConcentratorExtraAggregators(t *testing.T) {
+	assert := assert.New(t)
+	statsChan := make(chan []Bucket)
+	conf := newTestConcentratorConfig(testBucketInterval)
+	conf.ExtraAggregators = []string{"region", "customer_tier"}
+	conf.ExtraAggregatorsMaxCardinality = 2
+	c := NewConcentrator(conf, statsChan)
+
+	now := time.Now().UnixNano()
+	withTags := func(s *pb.Span, tags map[string]string) *pb.Span {
+		s.Meta = tags
+		return s
+	}
+	trace := pb.Trace{
+		withTags(testSpan(1, 0, 10, 0, "A1", "resource1", 0), map[string]string{"region": "us", "customer_tier": "gold"}),
+		withTags(testSpan(2, 0, 20, 0, "A1", "resource1", 1), map[string]string{"region": "us", "customer_tier": "gold"}),
+		withTags(testSpan(3, 0, 30, 0, "A1", "resource1", 0), map[string]string{"region": "eu,west"}),
+		withTags(testSpan(4, 0, 40, 0, "A1", "resource1", 0), map[string]string{"region": "ap"}),
+		withTags(testSpan(5, 0, 50, 0, "A1", "resource1", 0), map[string]string{"region": "sa"}),
+		testSpan(6, 0, 60, 0, "A1", "resource1", 0),
+	}
+	traceutil.ComputeTopLevel(trace)
+	c.addNow(&Input{
+		Env:   "none",
+		Trace: NewWeightedTrace(trace, traceutil.GetRoot(trace)),
+	})
+
+	stats := c.flushNow(now + int64(c.bufferLen)*c.bsize)
+	if !assert.Equal(1, len(stats), "We should get exactly 1 Bucket") {
+		t.FailNow()
+	}
+	expected := map[string]float64{
+		"query|duration|env:none,resource:resource1,service:A1,customer_tier:gold,region:us": 30,
+		"query|hits|env:none,resource:resource1,service:A1,customer_tier:gold,region:us":     2,
+		"query|errors|env:none,resource:resource1,service:A1,customer_tier:gold,region:us":   1,
+		"query|duration|env:none,resource:resource1,service:A1,region:eu_west":               30,
+		"query|hits|env:none,resource:resource1,service:A1,region:eu_west":                   1,
+		"query|errors|env:none,resource:resource1,service:A1,region:eu_west":                 0,
+		"query|duration|env:none,resource:resource1,service:A1,region:_overflow":             90,
+		"query|hits|env:none,resource:resource1,service:A1,region:_overflow":                 2,
+		"query|errors|env:none,resource:resource1,service:A1,region:_overflow":               0,
+		"query|duration|env:none,resource:resource1,service:A1":                              60,
+		"query|hits|env:none,resource:resource1,service:A1":                                  1,
+		"query|errors|env:none,resource:resource1,service:A1":                                0,
+	}
+	countValsEq(t, expected, stats[0].Counts)
+
+	count := stats[0].Counts["query|hits|env:none,resource:resource1,service:A1,customer_tier:gold,region:us"]
+	assert.Equal(TagSet{
+		{"env", "none"},
+		{"resource", "resource1"},
+		{"service", "A1"},
+		{"customer_tier", "gold"},
+		{"region", "us"},
+	}, count.TagSet)
+}
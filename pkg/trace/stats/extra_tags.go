@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// tagOverflowValue is the value given to an extra aggregation tag once the number
+// of distinct values seen for it in a bucket reached the maximum cardinality.
+const tagOverflowValue = "_overflow"
+
+// extraTagsAggregator computes the extra aggregation tags of spans, capping the number
+// of distinct values per tag. It is not safe for concurrent use.
+type extraTagsAggregator struct {
+	tags           []string              // sorted tag names
+	maxCardinality int                   // maximum number of distinct values per tag
+	values         []map[string]struct{} // distinct values seen for each tag
+}
+
+// newExtraTagsAggregator returns an aggregator for the given tags, or nil if there are none.
+func newExtraTagsAggregator(tags []string, maxCardinality int) *extraTagsAggregator {
+	if len(tags) == 0 {
+		return nil
+	}
+	sorted := make([]string, len(tags))
+	copy(sorted, tags)
+	sort.Strings(sorted)
+	values := make([]map[string]struct{}, len(sorted))
+	for i := range values {
+		values[i] = make(map[string]struct{})
+	}
+	return &extraTagsAggregator{
+		tags:           sorted,
+		maxCardinality: maxCardinality,
+		values:         values,
+	}
+}
+
+// key returns the extra aggregation tags of the span in their canonical form, as expected
+// by Aggregation.ExtraTags. Values seen once the cardinality of their tag reached the
+// maximum are replaced with tagOverflowValue, so that they are aggregated together.
+func (e *extraTagsAggregator) key(s *pb.Span) string {
+	if e == nil {
+		return ""
+	}
+	var b strings.Builder
+	for i, tag := range e.tags {
+		v := s.Meta[tag]
+		if v == "" {
+			continue
+		}
+		// commas separate the tags of the canonical form
+		v = strings.Replace(v, ",", "_", -1)
+		if _, ok := e.values[i][v]; !ok {
+			if e.maxCardinality > 0 && len(e.values[i]) >= e.maxCardinality {
+				v = tagOverflowValue
+			} else {
+				e.values[i][v] = struct{}{}
+			}
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(tag)
+		b.WriteByte(':')
+		b.WriteString(v)
+	}
+	return b.String()
+}
@@ -63,6 +63,9 @@ type RawBucket struct {
 	// this should really remain private as it's subject to refactoring
 	data map[statsKey]*groupedStats
 
+	// extraTags computes the user-defined extra aggregation tags, nil if there are none
+	extraTags *extraTagsAggregator
+
 	// internal buffer for aggregate strings - not threadsafe
 	keyBuf strings.Builder
 }
@@ -152,6 +155,7 @@ func (sb *RawBucket) HandleSpan(s *WeightedSpan, env string, sublayers []Sublaye
 	}
 
 	aggr := NewAggregationFromSpan(s.Span, env)
+	aggr.ExtraTags = sb.extraTags.key(s.Span)
 	sb.add(s, aggr, sublayers, skipStats)
 }
 
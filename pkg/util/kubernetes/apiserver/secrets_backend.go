@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+)
+
+func init() {
+	secrets.RegisterBackend("k8s_secret", fetchKubernetesSecret)
+}
+
+// fetchKubernetesSecret is the `k8s_secret` secret backend, it resolves handles of the
+// form `ENC[k8s_secret@<namespace>/<name>/<key>]` by reading Kubernetes Secrets. As it can
+// read any Secret allowed by the RBAC of the agent, it is only used once listed in
+// secret_backend_builtin_backends.
+func fetchKubernetesSecret(reference string) (string, error) {
+	c, err := GetAPIClient()
+	if err != nil {
+		return "", fmt.Errorf("could not connect to the apiserver: %s", err)
+	}
+	return getSecretValue(c.Cl, reference)
+}
+
+// getSecretValue returns the value of a key of a Secret, the reference is `<namespace>/<name>/<key>`
+func getSecretValue(cl kubernetes.Interface, reference string) (string, error) {
+	parts := strings.Split(reference, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid reference '%s', must be '<namespace>/<name>/<key>'", reference)
+	}
+	namespace, name, key := parts[0], parts[1], parts[2]
+
+	secret, err := cl.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value, found := secret.Data[key]
+	if !found {
+		return "", fmt.Errorf("key '%s' not found in secret %s/%s", key, namespace, name)
+	}
+	return string(value), nil
+}
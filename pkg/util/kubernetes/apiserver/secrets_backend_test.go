@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetSecretValue(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	})
+
+	value, err := getSecretValue(client, "prod/db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	_, err = getSecretValue(client, "prod/db/user")
+	assert.EqualError(t, err, "key 'user' not found in secret prod/db")
+
+	_, err = getSecretValue(client, "dev/db/password")
+	assert.Error(t, err)
+
+	_, err = getSecretValue(client, "prod/db")
+	assert.EqualError(t, err, "invalid reference 'prod/db', must be '<namespace>/<name>/<key>'")
+}